@@ -0,0 +1,165 @@
+// Package audit keeps a local JSONL log of the writes sent to the API.
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	appDir   = "recuerd0"
+	fileName = "audit.jsonl"
+)
+
+// Results recorded for each write.
+const (
+	ResultOK    = "ok"
+	ResultError = "error"
+)
+
+// logPath can be overridden for testing.
+var logPath string
+
+// SetPath overrides the audit log location. An empty string restores the
+// default.
+func SetPath(path string) {
+	logPath = path
+}
+
+// Path returns the audit log location, under the XDG state directory.
+func Path() string {
+	if logPath != "" {
+		return logPath
+	}
+	if xdg := os.Getenv("XDG_STATE_HOME"); xdg != "" {
+		return filepath.Join(xdg, appDir, fileName)
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, ".local", "state", appDir, fileName)
+	}
+	return filepath.Join(os.TempDir(), appDir, fileName)
+}
+
+// Record is one audited write.
+type Record struct {
+	Time      time.Time `json:"time"`
+	Account   string    `json:"account,omitempty"`
+	APIURL    string    `json:"api_url,omitempty"`
+	Actor     string    `json:"actor,omitempty"`
+	Command   string    `json:"command"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	Workspace string    `json:"workspace,omitempty"`
+	MemoryID  string    `json:"memory_id,omitempty"`
+	BodyHash  string    `json:"body_sha256,omitempty"`
+	Status    int       `json:"status,omitempty"`
+	Result    string    `json:"result"`
+	Error     string    `json:"error,omitempty"`
+}
+
+var pathIDs = regexp.MustCompile(`^/workspaces/([^/?]+)(?:/memories/([^/?]+))?`)
+
+// ParsePath extracts the workspace and memory IDs from an API path.
+func ParsePath(path string) (workspace, memoryID string) {
+	if m := pathIDs.FindStringSubmatch(path); m != nil {
+		return m[1], m[2]
+	}
+	return "", ""
+}
+
+var mu sync.Mutex
+
+// Append adds r to the log. The log is readable only by the owner.
+func Append(r Record) error {
+	line, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("marshaling audit record: %w", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	path := Path()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("creating audit directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("opening audit log: %w", err)
+	}
+	defer f.Close()
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+// Filter selects records. Zero fields match everything.
+type Filter struct {
+	Since     time.Time
+	Until     time.Time
+	Account   string
+	Workspace string
+	MemoryID  string
+	Method    string
+	Actor     string
+	Failed    bool
+	Limit     int
+}
+
+func (f Filter) match(r Record) bool {
+	switch {
+	case !f.Since.IsZero() && r.Time.Before(f.Since),
+		!f.Until.IsZero() && r.Time.After(f.Until),
+		f.Account != "" && r.Account != f.Account,
+		f.Workspace != "" && r.Workspace != f.Workspace,
+		f.MemoryID != "" && r.MemoryID != f.MemoryID,
+		f.Method != "" && !strings.EqualFold(r.Method, f.Method),
+		f.Actor != "" && r.Actor != f.Actor,
+		f.Failed && r.Result != ResultError:
+		return false
+	}
+	return true
+}
+
+// Read returns the records matching f, newest first, up to f.Limit when it
+// is positive. Lines that cannot be parsed are skipped.
+func Read(f Filter) ([]Record, error) {
+	file, err := os.Open(Path())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Record{}, nil
+		}
+		return nil, fmt.Errorf("opening audit log: %w", err)
+	}
+	defer file.Close()
+
+	var matched []Record
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var r Record
+		if json.Unmarshal(scanner.Bytes(), &r) != nil {
+			continue
+		}
+		if f.match(r) {
+			matched = append(matched, r)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading audit log: %w", err)
+	}
+
+	records := make([]Record, 0, len(matched))
+	for i := len(matched) - 1; i >= 0; i-- {
+		if f.Limit > 0 && len(records) == f.Limit {
+			break
+		}
+		records = append(records, matched[i])
+	}
+	return records, nil
+}
@@ -0,0 +1,101 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func setupTestLog(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "state", "audit.jsonl")
+	SetPath(path)
+	t.Cleanup(func() { SetPath("") })
+	return path
+}
+
+func TestAppendAndRead(t *testing.T) {
+	path := setupTestLog(t)
+
+	if records, err := Read(Filter{}); err != nil || len(records) != 0 {
+		t.Fatalf("Read on missing log = %v, %v", records, err)
+	}
+
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	_ = Append(Record{Time: base, Method: "POST", Workspace: "5", Result: ResultOK, Actor: "agent-a"})
+	_ = Append(Record{Time: base.Add(time.Hour), Method: "PATCH", Workspace: "5", MemoryID: "42", Result: ResultError, Status: 422})
+	_ = Append(Record{Time: base.Add(2 * time.Hour), Method: "DELETE", Workspace: "6", MemoryID: "7", Result: ResultOK})
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	all, _ := Read(Filter{})
+	if len(all) != 3 || all[0].Method != "DELETE" || all[2].Method != "POST" {
+		t.Errorf("expected newest first, got %+v", all)
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   int
+	}{
+		{"workspace", Filter{Workspace: "5"}, 2},
+		{"memory", Filter{MemoryID: "42"}, 1},
+		{"method case-insensitive", Filter{Method: "delete"}, 1},
+		{"actor", Filter{Actor: "agent-a"}, 1},
+		{"failed", Filter{Failed: true}, 1},
+		{"since", Filter{Since: base.Add(30 * time.Minute)}, 2},
+		{"until", Filter{Until: base.Add(30 * time.Minute)}, 1},
+		{"limit", Filter{Limit: 2}, 2},
+	}
+	for _, tt := range tests {
+		got, err := Read(tt.filter)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if len(got) != tt.want {
+			t.Errorf("%s: got %d records, want %d", tt.name, len(got), tt.want)
+		}
+	}
+}
+
+func TestRead_SkipsCorruptLines(t *testing.T) {
+	path := setupTestLog(t)
+	_ = Append(Record{Method: "POST", Result: ResultOK})
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	_, _ = f.WriteString("{truncated\n")
+	f.Close()
+	_ = Append(Record{Method: "DELETE", Result: ResultOK})
+
+	records, err := Read(Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Errorf("expected 2 records, got %d", len(records))
+	}
+}
+
+func TestParsePath(t *testing.T) {
+	tests := []struct {
+		path, ws, id string
+	}{
+		{"/workspaces/5/memories/42", "5", "42"},
+		{"/workspaces/5/memories/42/versions", "5", "42"},
+		{"/workspaces/5/memories", "5", ""},
+		{"/workspaces/5/archive", "5", ""},
+		{"/workspaces", "", ""},
+	}
+	for _, tt := range tests {
+		ws, id := ParsePath(tt.path)
+		if ws != tt.ws || id != tt.id {
+			t.Errorf("ParsePath(%q) = %q, %q; want %q, %q", tt.path, ws, id, tt.ws, tt.id)
+		}
+	}
+}
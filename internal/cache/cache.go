@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const appDir = "recuerd0"
+
+// NoExpiry can be passed to Get for entries that never go stale.
+const NoExpiry = time.Duration(math.MaxInt64)
+
+// cacheDir can be overridden for testing.
+var cacheDir string
+
+// SetDir overrides the cache directory. An empty string restores the default.
+func SetDir(dir string) {
+	cacheDir = dir
+}
+
+// Dir returns the directory cache entries are stored in.
+func Dir() string {
+	if cacheDir != "" {
+		return cacheDir
+	}
+	if xdg := os.Getenv("XDG_CACHE_HOME"); xdg != "" {
+		return filepath.Join(xdg, appDir)
+	}
+	if dir, err := os.UserCacheDir(); err == nil {
+		return filepath.Join(dir, appDir)
+	}
+	return filepath.Join(os.TempDir(), appDir)
+}
+
+// Key builds a cache key from a name and the parts that scope it, such as
+// the API URL and token. Scope parts are hashed so secrets never reach disk.
+func Key(name string, scope ...string) string {
+	h := sha256.Sum256([]byte(strings.Join(scope, "\x00")))
+	return name + "-" + hex.EncodeToString(h[:8])
+}
+
+type entry struct {
+	StoredAt time.Time       `json:"stored_at"`
+	Value    json.RawMessage `json:"value"`
+}
+
+// Path returns the file the entry stored under key lives in.
+func Path(key string) string {
+	return filepath.Join(Dir(), key+".json")
+}
+
+// Get loads the entry stored under key into v. It returns false if the entry
+// is missing, unreadable or older than maxAge.
+func Get(key string, maxAge time.Duration, v interface{}) bool {
+	data, err := os.ReadFile(Path(key))
+	if err != nil {
+		return false
+	}
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return false
+	}
+	if time.Since(e.StoredAt) > maxAge {
+		return false
+	}
+	return json.Unmarshal(e.Value, v) == nil
+}
+
+// Put stores v under key.
+func Put(key string, v interface{}) error {
+	value, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("marshaling cache entry: %w", err)
+	}
+	data, err := json.Marshal(entry{StoredAt: time.Now(), Value: value})
+	if err != nil {
+		return fmt.Errorf("marshaling cache entry: %w", err)
+	}
+	if err := os.MkdirAll(Dir(), 0700); err != nil {
+		return fmt.Errorf("creating cache directory: %w", err)
+	}
+	return os.WriteFile(Path(key), data, 0600)
+}
+
+// Delete removes the entry stored under key, if any.
+func Delete(key string) {
+	os.Remove(Path(key))
+}
@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func setupTestDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	SetDir(dir)
+	t.Cleanup(func() { SetDir("") })
+	return dir
+}
+
+func TestPutAndGet(t *testing.T) {
+	setupTestDir(t)
+
+	if err := Put("things", []string{"a", "b"}); err != nil {
+		t.Fatalf("put error: %v", err)
+	}
+	var got []string
+	if !Get("things", time.Minute, &got) {
+		t.Fatal("expected cache hit")
+	}
+	if len(got) != 2 || got[0] != "a" {
+		t.Errorf("unexpected value: %v", got)
+	}
+}
+
+func TestGet_Expired(t *testing.T) {
+	setupTestDir(t)
+
+	_ = Put("things", 1)
+	var got int
+	if Get("things", -time.Second, &got) {
+		t.Error("expected expired entry to miss")
+	}
+}
+
+func TestGet_Missing(t *testing.T) {
+	setupTestDir(t)
+
+	var got int
+	if Get("nothing", time.Minute, &got) {
+		t.Error("expected miss")
+	}
+}
+
+func TestDelete(t *testing.T) {
+	dir := setupTestDir(t)
+
+	_ = Put("things", 1)
+	Delete("things")
+	if _, err := os.Stat(dir + "/things.json"); !os.IsNotExist(err) {
+		t.Error("expected entry to be removed")
+	}
+}
+
+func TestKey_HidesScope(t *testing.T) {
+	k := Key("workspaces", "https://api.example.com", "tok_secret")
+	if strings.Contains(k, "tok_secret") {
+		t.Errorf("key leaks scope: %s", k)
+	}
+	if k != Key("workspaces", "https://api.example.com", "tok_secret") {
+		t.Error("expected stable key")
+	}
+	if k == Key("workspaces", "https://api.example.com", "tok_other") {
+		t.Error("expected different scope to give different key")
+	}
+}
@@ -0,0 +1,373 @@
+// Package cassette records the HTTP interactions of a client to a file and
+// serves them back, so a run can be reproduced without the API or a token.
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Version is written to every cassette; newer cassettes are refused.
+const Version = 1
+
+// Marker replaces every redacted value.
+const Marker = "[REDACTED]"
+
+// Always redacted, besides the configured fields and headers.
+var (
+	DefaultFields  = []string{"token", "password", "secret", "api_key"}
+	DefaultHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+)
+
+// Cassette is the file written by a Recorder and read by a Player.
+type Cassette struct {
+	Version int `json:"version" yaml:"version"`
+	// Redacted lists what was replaced, so a Player can redact live
+	// requests the same way before matching them.
+	Redacted     Redaction     `json:"redacted" yaml:"redacted"`
+	Interactions []Interaction `json:"interactions" yaml:"interactions"`
+}
+
+// Interaction is one request and the response it got.
+type Interaction struct {
+	Request  Request  `json:"request" yaml:"request"`
+	Response Response `json:"response" yaml:"response"`
+}
+
+// Request is a recorded request. Path holds the path and query without the
+// scheme and host, so a cassette replays against any API URL.
+type Request struct {
+	Method  string            `json:"method" yaml:"method"`
+	Path    string            `json:"path" yaml:"path"`
+	Headers map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Body    string            `json:"body,omitempty" yaml:"body,omitempty"`
+}
+
+// Response is a recorded response.
+type Response struct {
+	Status  int               `json:"status" yaml:"status"`
+	Headers map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Body    string            `json:"body,omitempty" yaml:"body,omitempty"`
+}
+
+// Redaction names the JSON fields, at any depth, and the headers whose
+// values are replaced with Marker. Names match case-insensitively.
+type Redaction struct {
+	Fields  []string `json:"fields,omitempty" yaml:"fields,omitempty"`
+	Headers []string `json:"headers,omitempty" yaml:"headers,omitempty"`
+}
+
+// WithDefaults returns r plus DefaultFields and DefaultHeaders, sorted and
+// without duplicates.
+func (r Redaction) WithDefaults() Redaction {
+	return Redaction{
+		Fields:  merge(DefaultFields, r.Fields),
+		Headers: merge(DefaultHeaders, r.Headers),
+	}
+}
+
+func merge(lists ...[]string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, list := range lists {
+		for _, name := range list {
+			if key := strings.ToLower(name); name != "" && !seen[key] {
+				seen[key] = true
+				out = append(out, name)
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// headers flattens h, replacing redacted values. Content-Length is left out
+// as redaction may change the body.
+func (r Redaction) headers(h http.Header) map[string]string {
+	if len(h) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(h))
+	for name, values := range h {
+		switch {
+		case strings.EqualFold(name, "Content-Length"):
+		case strings.EqualFold(name, "Authorization"), strings.EqualFold(name, "Proxy-Authorization"):
+			// Keep the scheme, which tells a reader how the client authenticated.
+			scheme, _, _ := strings.Cut(strings.Join(values, ", "), " ")
+			out[name] = scheme + " " + Marker
+		case contains(r.Headers, name):
+			out[name] = Marker
+		default:
+			out[name] = strings.Join(values, ", ")
+		}
+	}
+	return out
+}
+
+// body returns b with the redacted fields replaced, and then every secret.
+// JSON bodies are re-encoded, so their keys end up sorted; anything else is
+// kept as it is.
+func (r Redaction) body(b []byte, secrets []string) string {
+	s := string(b)
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v interface{}
+	if len(b) > 0 && dec.Decode(&v) == nil && !dec.More() {
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		if enc.Encode(r.value(v)) == nil {
+			s = strings.TrimSuffix(buf.String(), "\n")
+		}
+	}
+	for _, secret := range secrets {
+		if secret != "" {
+			s = strings.ReplaceAll(s, secret, Marker)
+		}
+	}
+	return s
+}
+
+func (r Redaction) value(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, item := range v {
+			if contains(r.Fields, k) && item != nil {
+				v[k] = Marker
+			} else {
+				v[k] = r.value(item)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = r.value(item)
+		}
+	}
+	return v
+}
+
+// bearerToken returns the token of a request, which is replaced wherever
+// it appears.
+func bearerToken(h http.Header) string {
+	scheme, token, ok := strings.Cut(h.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// readBody returns the body of req and leaves a fresh reader in its place.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+	b, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(b))
+	return b, nil
+}
+
+// isJSON reports whether path is a JSON cassette; other files are YAML.
+func isJSON(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".json")
+}
+
+// Load reads a cassette written by a Recorder.
+func Load(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading cassette: %w", err)
+	}
+	var c Cassette
+	if isJSON(path) {
+		err = json.Unmarshal(data, &c)
+	} else {
+		err = yaml.Unmarshal(data, &c)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing cassette %s: %w", path, err)
+	}
+	if c.Version > Version {
+		return nil, fmt.Errorf("cassette %s has version %d; this CLI reads up to %d", path, c.Version, Version)
+	}
+	return &c, nil
+}
+
+// Save writes c to path, as JSON when it ends in .json and YAML otherwise.
+// Cassettes hold API data, so the file is private to the user.
+func Save(path string, c *Cassette) error {
+	var (
+		data []byte
+		err  error
+	)
+	if isJSON(path) {
+		data, err = json.MarshalIndent(c, "", "  ")
+		data = append(data, '\n')
+	} else {
+		data, err = yaml.Marshal(c)
+	}
+	if err != nil {
+		return fmt.Errorf("encoding cassette: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("writing cassette: %w", err)
+	}
+	return nil
+}
+
+// Recorder is an http.RoundTripper that sends requests through Transport
+// and writes each interaction, redacted, to its cassette file. The file is
+// rewritten after every response, so it is complete however the process
+// exits.
+type Recorder struct {
+	Transport http.RoundTripper
+
+	path     string
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+// NewRecorder records to path, replacing any cassette there.
+func NewRecorder(path string, transport http.RoundTripper, r Redaction) *Recorder {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &Recorder{
+		Transport: transport,
+		path:      path,
+		cassette:  Cassette{Version: Version, Redacted: r.WithDefaults(), Interactions: []Interaction{}},
+	}
+}
+
+// Start writes the empty cassette, so a bad path is reported before any
+// request is sent.
+func (r *Recorder) Start() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return Save(r.path, &r.cassette)
+}
+
+// RoundTrip sends req and records it with its response. Responses are read
+// whole to be recorded.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.Transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	red := r.cassette.Redacted
+	secrets := []string{bearerToken(req.Header)}
+	in := Interaction{
+		Request: Request{
+			Method:  req.Method,
+			Path:    req.URL.RequestURI(),
+			Headers: red.headers(req.Header),
+			Body:    red.body(reqBody, secrets),
+		},
+		Response: Response{
+			Status:  resp.StatusCode,
+			Headers: red.headers(resp.Header),
+			Body:    red.body(respBody, secrets),
+		},
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, in)
+	if err := Save(r.path, &r.cassette); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Player is an http.RoundTripper that answers requests from a cassette
+// without sending them. A request matches an interaction with the same
+// method, path and redacted body; each interaction answers once, in order,
+// after which the last match is repeated.
+type Player struct {
+	mu       sync.Mutex
+	cassette *Cassette
+	used     []bool
+}
+
+// NewPlayer serves the interactions of c.
+func NewPlayer(c *Cassette) *Player {
+	return &Player{cassette: c, used: make([]bool, len(c.Interactions))}
+}
+
+// RoundTrip returns the recorded response for req, or an error naming the
+// request when the cassette has none.
+func (p *Player) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	path := req.URL.RequestURI()
+	body := p.cassette.Redacted.body(reqBody, []string{bearerToken(req.Header)})
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	found := -1
+	for i, in := range p.cassette.Interactions {
+		if in.Request.Method != req.Method || in.Request.Path != path || in.Request.Body != body {
+			continue
+		}
+		found = i
+		if !p.used[i] {
+			break
+		}
+	}
+	if found < 0 {
+		return nil, fmt.Errorf("cassette has no interaction for %s %s", req.Method, path)
+	}
+	p.used[found] = true
+
+	recorded := p.cassette.Interactions[found].Response
+	header := http.Header{}
+	for name, value := range recorded.Headers {
+		header.Set(name, value)
+	}
+	return &http.Response{
+		StatusCode:    recorded.Status,
+		Status:        fmt.Sprintf("%d %s", recorded.Status, http.StatusText(recorded.Status)),
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(recorded.Body)),
+		ContentLength: int64(len(recorded.Body)),
+		Request:       req,
+	}, nil
+}
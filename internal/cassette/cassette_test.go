@@ -0,0 +1,130 @@
+package cassette
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecorder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Set-Cookie", "session=abc")
+		w.Header().Set("X-Request-Id", "r1")
+		io.WriteString(w, `{"echo":`+string(body)+`,"token":"t_1","note":"sent with tok_secret"}`)
+	}))
+	defer server.Close()
+
+	for _, name := range []string{"run.yaml", "run.json"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			rec := NewRecorder(path, nil, Redaction{Fields: []string{"Email"}, Headers: []string{"x-api-key"}})
+			if err := rec.Start(); err != nil {
+				t.Fatal(err)
+			}
+
+			req, _ := http.NewRequest("POST", server.URL+"/memories?page=2", strings.NewReader(`{"title":"T","email":"a@example.com"}`))
+			req.Header.Set("Authorization", "Bearer tok_secret")
+			req.Header.Set("X-Api-Key", "k_1")
+			resp, err := (&http.Client{Transport: rec}).Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			live, _ := io.ReadAll(resp.Body)
+			if !strings.Contains(string(live), "a@example.com") {
+				t.Errorf("expected the caller to get the response unredacted, got %s", live)
+			}
+
+			c, err := Load(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(c.Interactions) != 1 {
+				t.Fatalf("expected one interaction, got %+v", c)
+			}
+			in := c.Interactions[0]
+			if in.Request.Path != "/memories?page=2" || in.Request.Method != "POST" {
+				t.Errorf("unexpected request %+v", in.Request)
+			}
+			if in.Request.Body != `{"email":"[REDACTED]","title":"T"}` {
+				t.Errorf("unexpected request body %s", in.Request.Body)
+			}
+			if in.Request.Headers["Authorization"] != "Bearer [REDACTED]" || in.Request.Headers["X-Api-Key"] != Marker {
+				t.Errorf("unexpected request headers %v", in.Request.Headers)
+			}
+			if in.Response.Headers["Set-Cookie"] != Marker || in.Response.Headers["X-Request-Id"] != "r1" {
+				t.Errorf("unexpected response headers %v", in.Response.Headers)
+			}
+			want := `{"echo":{"email":"[REDACTED]","title":"T"},"note":"sent with [REDACTED]","token":"[REDACTED]"}`
+			if in.Response.Status != 200 || in.Response.Body != want {
+				t.Errorf("unexpected response %d %s", in.Response.Status, in.Response.Body)
+			}
+			raw, _ := os.ReadFile(path)
+			if strings.Contains(string(raw), "tok_secret") {
+				t.Errorf("expected the token to be redacted:\n%s", raw)
+			}
+		})
+	}
+}
+
+func TestPlayer(t *testing.T) {
+	c := &Cassette{
+		Version:  Version,
+		Redacted: Redaction{Fields: []string{"email"}},
+		Interactions: []Interaction{
+			{Request{Method: "GET", Path: "/memories/1"}, Response{Status: 200, Body: `{"v":1}`, Headers: map[string]string{"Link": `</memories?page=2>; rel="next"`}}},
+			{Request{Method: "GET", Path: "/memories/1"}, Response{Status: 200, Body: `{"v":2}`}},
+			{Request{Method: "POST", Path: "/memories", Body: `{"email":"[REDACTED]","title":"T"}`}, Response{Status: 201, Body: `{"id":3}`}},
+		},
+	}
+	api := &http.Client{Transport: NewPlayer(c)}
+	do := func(method, body string, path string) (string, error) {
+		t.Helper()
+		req, _ := http.NewRequest(method, "https://anywhere.example.com"+path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer other")
+		resp, err := api.Do(req)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		b, _ := io.ReadAll(resp.Body)
+		return string(b), nil
+	}
+
+	// Matching interactions answer in order, then the last one repeats.
+	for _, want := range []string{`{"v":1}`, `{"v":2}`, `{"v":2}`} {
+		if got, err := do("GET", "", "/memories/1"); err != nil || got != want {
+			t.Errorf("expected %s, got %s %v", want, got, err)
+		}
+	}
+
+	// Bodies are compared after the same redaction, whatever the key order.
+	if got, err := do("POST", `{"title":"T","email":"b@example.com"}`, "/memories"); err != nil || got != `{"id":3}` {
+		t.Errorf("expected the recorded create, got %s %v", got, err)
+	}
+	if _, err := do("POST", `{"title":"U"}`, "/memories"); err == nil || !strings.Contains(err.Error(), "no interaction for POST /memories") {
+		t.Errorf("expected a different body to miss, got %v", err)
+	}
+	if _, err := do("DELETE", "", "/memories/1"); err == nil {
+		t.Error("expected a different method to miss")
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := Load(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Error("expected a missing file to fail")
+	}
+	newer := filepath.Join(dir, "newer.json")
+	os.WriteFile(newer, []byte(`{"version":2,"interactions":[]}`), 0600)
+	if _, err := Load(newer); err == nil || !strings.Contains(err.Error(), "version 2") {
+		t.Errorf("expected a newer cassette to be refused, got %v", err)
+	}
+	if err := NewRecorder(filepath.Join(dir, "no", "such", "dir.yaml"), nil, Redaction{}).Start(); err == nil {
+		t.Error("expected an unwritable path to fail")
+	}
+}
@@ -0,0 +1,219 @@
+// Package catalog describes the command tree in a machine-readable form:
+// every command with its arguments, flags, exit codes and examples. The
+// same description renders the agent skill file and tool schemas, so they
+// cannot drift from the real flags.
+package catalog
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/maquina/recuerd0-cli/internal/errors"
+	"github.com/maquina/recuerd0-cli/internal/response"
+)
+
+// Command annotations. A command inherits them from its parents.
+const (
+	// Local marks commands that never call the API, so they cannot fail
+	// with authentication, HTTP or network errors.
+	Local = "recuerd0.local"
+	// Interactive marks commands meant for a person at a terminal; they
+	// are left out of tool schemas.
+	Interactive = "recuerd0.interactive"
+)
+
+// Catalog is the whole command tree.
+type Catalog struct {
+	Name        string                `json:"name"`
+	Version     string                `json:"version,omitempty"`
+	GlobalFlags []Flag                `json:"global_flags"`
+	ExitCodes   []errors.ExitCodeInfo `json:"exit_codes"`
+	Commands    []Command             `json:"commands"`
+}
+
+// Command is one runnable command.
+type Command struct {
+	Path        string                `json:"command"`
+	Usage       string                `json:"usage"`
+	Short       string                `json:"short"`
+	Long        string                `json:"long,omitempty"`
+	Args        []Arg                 `json:"args"`
+	Flags       []Flag                `json:"flags"`
+	ExitCodes   []int                 `json:"exit_codes"`
+	Local       bool                  `json:"local,omitempty"`
+	Interactive bool                  `json:"interactive,omitempty"`
+	Examples    []response.Breadcrumb `json:"examples"`
+}
+
+// Arg is a positional argument, parsed from the command's Use line:
+// <name> is required, [name] optional and name... repeatable.
+type Arg struct {
+	Name     string `json:"name"`
+	Required bool   `json:"required"`
+	Variadic bool   `json:"variadic,omitempty"`
+}
+
+// Flag is a command-line flag.
+type Flag struct {
+	Name        string      `json:"name"`
+	Type        string      `json:"type"`
+	Default     interface{} `json:"default,omitempty"`
+	Description string      `json:"description"`
+	Required    bool        `json:"required,omitempty"`
+}
+
+// Build walks the tree under root. Hidden commands, help and the
+// generated completion command are left out, as are commands that only
+// group others.
+func Build(root *cobra.Command, version string) *Catalog {
+	c := &Catalog{
+		Name:        root.Name(),
+		Version:     version,
+		GlobalFlags: flags(root.PersistentFlags()),
+		ExitCodes:   errors.ExitCodes,
+		Commands:    []Command{},
+	}
+	var walk func(cmd *cobra.Command)
+	walk = func(cmd *cobra.Command) {
+		for _, sub := range cmd.Commands() {
+			if !sub.IsAvailableCommand() || sub.Name() == "help" || (cmd == root && sub.Name() == "completion") {
+				continue
+			}
+			if sub.Runnable() {
+				c.Commands = append(c.Commands, command(sub))
+			}
+			walk(sub)
+		}
+	}
+	walk(root)
+	return c
+}
+
+// Find returns the command with the given path, such as "memory show".
+func (c *Catalog) Find(path string) (Command, bool) {
+	for _, cmd := range c.Commands {
+		if cmd.Path == path {
+			return cmd, true
+		}
+	}
+	return Command{}, false
+}
+
+// Group returns the commands whose path starts with one of the given
+// top-level commands, in order.
+func (c *Catalog) Group(names ...string) []Command {
+	var out []Command
+	for _, name := range names {
+		for _, cmd := range c.Commands {
+			if cmd.Path == name || strings.HasPrefix(cmd.Path, name+" ") {
+				out = append(out, cmd)
+			}
+		}
+	}
+	return out
+}
+
+func command(cmd *cobra.Command) Command {
+	path := strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+	out := Command{
+		Path:        path,
+		Usage:       strings.TrimSpace(cmd.Root().Name() + " " + path + " " + strings.TrimSpace(strings.TrimPrefix(cmd.Use, cmd.Name()))),
+		Short:       cmd.Short,
+		Long:        cmd.Long,
+		Args:        args(cmd.Use),
+		Flags:       flags(cmd.LocalFlags()),
+		Local:       annotated(cmd, Local),
+		Interactive: annotated(cmd, Interactive),
+	}
+	for _, e := range errors.ExitCodes {
+		if out.Local && e.ExitCode > errors.ExitInvalidArgs {
+			break
+		}
+		out.ExitCodes = append(out.ExitCodes, e.ExitCode)
+	}
+	out.Examples = examples(cmd, out.Usage)
+	return out
+}
+
+func annotated(cmd *cobra.Command, key string) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c.Annotations[key] != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func args(use string) []Arg {
+	fields := strings.Fields(use)
+	out := []Arg{}
+	if len(fields) < 2 {
+		return out
+	}
+	for _, f := range fields[1:] {
+		a := Arg{Required: strings.HasPrefix(f, "<")}
+		name := strings.Trim(f, "<>[]")
+		if strings.HasSuffix(name, "...") {
+			a.Variadic = true
+			name = strings.TrimSuffix(name, "...")
+		}
+		a.Name = name
+		out = append(out, a)
+	}
+	return out
+}
+
+func flags(fs *pflag.FlagSet) []Flag {
+	out := []Flag{}
+	fs.VisitAll(func(f *pflag.Flag) {
+		if f.Hidden || f.Name == "help" {
+			return
+		}
+		out = append(out, Flag{
+			Name:        f.Name,
+			Type:        f.Value.Type(),
+			Default:     defaultValue(f),
+			Description: f.Usage,
+			Required:    len(f.Annotations[cobra.BashCompOneRequiredFlag]) > 0,
+		})
+	})
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// defaultValue returns a flag's default as a JSON value, or nil when it
+// is empty.
+func defaultValue(f *pflag.Flag) interface{} {
+	switch f.Value.Type() {
+	case "bool":
+		return f.DefValue == "true"
+	case "int", "int64":
+		if n, err := strconv.Atoi(f.DefValue); err == nil {
+			return n
+		}
+	}
+	if f.DefValue == "" || f.DefValue == "[]" {
+		return nil
+	}
+	return f.DefValue
+}
+
+// examples turns the lines of a command's Example that start with the
+// root command into breadcrumbs, falling back to its usage.
+func examples(cmd *cobra.Command, usage string) []response.Breadcrumb {
+	var out []response.Breadcrumb
+	for _, line := range strings.Split(cmd.Example, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, cmd.Root().Name()+" ") {
+			out = append(out, response.NewBreadcrumb(cmd.Name(), line, cmd.Short))
+		}
+	}
+	if len(out) == 0 {
+		out = append(out, response.NewBreadcrumb(cmd.Name(), usage, cmd.Short))
+	}
+	return out
+}
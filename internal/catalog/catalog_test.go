@@ -0,0 +1,128 @@
+package catalog
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func testTree() *cobra.Command {
+	run := func(*cobra.Command, []string) {}
+	root := &cobra.Command{Use: "app"}
+	root.PersistentFlags().Bool("pretty", false, "pretty-print JSON output")
+
+	note := &cobra.Command{Use: "note", Short: "Manage notes"}
+	show := &cobra.Command{Use: "show <note_id>", Short: "Show a note", Example: "  app note show 42", Run: run}
+	show.Flags().String("workspace", "", "workspace ID")
+	tag := &cobra.Command{Use: "tag <note_id...> <tags>", Short: "Tag notes", Run: run}
+	tag.Flags().Int("concurrency", 4, "requests at once")
+	tag.Flags().StringArray("label", nil, "label to add")
+	tag.Flags().String("title-match", "", "regular expression")
+	hidden := &cobra.Command{Use: "secret", Hidden: true, Run: run}
+	note.AddCommand(show, tag, hidden)
+
+	cfg := &cobra.Command{Use: "config", Annotations: map[string]string{Local: "true"}}
+	cfg.AddCommand(&cobra.Command{Use: "get <key>", Short: "Read a setting", Run: run})
+	repl := &cobra.Command{Use: "repl", Short: "Interactive session", Annotations: map[string]string{Interactive: "true"}, Run: run}
+
+	root.AddCommand(note, cfg, repl)
+	return root
+}
+
+func TestBuild(t *testing.T) {
+	c := Build(testTree(), "1.2.3")
+
+	var paths []string
+	for _, cmd := range c.Commands {
+		paths = append(paths, cmd.Path)
+	}
+	if got := strings.Join(paths, ","); got != "config get,note show,note tag,repl" {
+		t.Fatalf("unexpected commands %s", got)
+	}
+	if len(c.GlobalFlags) != 1 || c.GlobalFlags[0].Name != "pretty" || c.GlobalFlags[0].Default != false {
+		t.Errorf("unexpected global flags %+v", c.GlobalFlags)
+	}
+
+	tag, _ := c.Find("note tag")
+	if tag.Usage != "app note tag <note_id...> <tags>" {
+		t.Errorf("unexpected usage %q", tag.Usage)
+	}
+	if len(tag.Args) != 2 || !tag.Args[0].Variadic || !tag.Args[0].Required || tag.Args[0].Name != "note_id" {
+		t.Errorf("unexpected args %+v", tag.Args)
+	}
+	if tag.Flags[0].Name != "concurrency" || tag.Flags[0].Type != "int" || tag.Flags[0].Default != 4 {
+		t.Errorf("unexpected flag %+v", tag.Flags[0])
+	}
+	if tag.Flags[1].Default != nil {
+		t.Errorf("expected an empty array default to be left out, got %v", tag.Flags[1].Default)
+	}
+	if len(tag.ExitCodes) != 9 || tag.Examples[0].Cmd != tag.Usage {
+		t.Errorf("expected every exit code and the usage as example, got %v %+v", tag.ExitCodes, tag.Examples)
+	}
+
+	show, _ := c.Find("note show")
+	if len(show.Examples) != 1 || show.Examples[0].Cmd != "app note show 42" || show.Examples[0].Action != "show" {
+		t.Errorf("expected the Example line, got %+v", show.Examples)
+	}
+
+	get, _ := c.Find("config get")
+	if !get.Local || len(get.ExitCodes) != 3 {
+		t.Errorf("expected a local command with exit codes 0-2, got %+v", get)
+	}
+}
+
+func TestTools(t *testing.T) {
+	c := Build(testTree(), "")
+
+	tools, err := c.Tools(FormatAnthropic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tools) != 3 {
+		t.Fatalf("expected the interactive command to be left out, got %d tools", len(tools))
+	}
+	tag := tools[2]
+	if tag["name"] != "note_tag" {
+		t.Fatalf("unexpected tool %v", tag["name"])
+	}
+	schema := tag["input_schema"].(map[string]interface{})
+	props := schema["properties"].(map[string]interface{})
+	if props["note_id"].(map[string]interface{})["type"] != "array" || props["label"].(map[string]interface{})["type"] != "array" {
+		t.Errorf("expected arrays for the variadic argument and repeatable flag, got %v", props)
+	}
+	if props["concurrency"].(map[string]interface{})["type"] != "integer" || props["title_match"] == nil {
+		t.Errorf("unexpected properties %v", props)
+	}
+	if req := schema["required"].([]string); strings.Join(req, ",") != "note_id,tags" {
+		t.Errorf("unexpected required %v", req)
+	}
+
+	openai, _ := c.Tools(FormatOpenAI)
+	fn := openai[0]["function"].(map[string]interface{})
+	if openai[0]["type"] != "function" || fn["name"] != "config_get" || fn["parameters"] == nil {
+		t.Errorf("unexpected OpenAI tool %v", openai[0])
+	}
+	if _, err := c.Tools("xml"); err == nil {
+		t.Error("expected an unknown format to fail")
+	}
+}
+
+func TestSynopsis(t *testing.T) {
+	c := Build(testTree(), "")
+	tag, _ := c.Find("note tag")
+	want := "app note tag <note_id...> <tags> [--concurrency N] [--label LABEL]... [--title-match TITLE_MATCH]"
+	if got := Synopsis(tag); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	long := Command{Usage: "app long"}
+	for i := 0; i < 12; i++ {
+		long.Flags = append(long.Flags, Flag{Name: "option-" + string(rune('a'+i)), Type: "string"})
+	}
+	for _, line := range strings.Split(Synopsis(long), "\n") {
+		if len(line) > synopsisWidth+2 {
+			t.Errorf("line is %d long: %q", len(line), line)
+		}
+	}
+}
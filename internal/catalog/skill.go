@@ -0,0 +1,106 @@
+package catalog
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+//go:embed skill.md.tmpl
+var skillTemplate string
+
+// synopsisWidth is where long synopses wrap onto continuation lines.
+const synopsisWidth = 100
+
+// Skill renders the agent skill file. The guidance is written in
+// skill.md.tmpl; flags, synopses and exit codes come from the catalog.
+func (c *Catalog) Skill() (string, error) {
+	tmpl, err := template.New("skill").Funcs(template.FuncMap{
+		"globalFlags": c.globalFlagRows,
+		"synopsis":    c.synopsis,
+		"exitCodes":   c.exitCodeRows,
+	}).Parse(skillTemplate)
+	if err != nil {
+		return "", fmt.Errorf("parsing skill template: %w", err)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, c); err != nil {
+		return "", fmt.Errorf("rendering skill: %w", err)
+	}
+	return b.String(), nil
+}
+
+func (c *Catalog) globalFlagRows() string {
+	rows := make([]string, len(c.GlobalFlags))
+	for i, f := range c.GlobalFlags {
+		rows[i] = fmt.Sprintf("| `%s` | %s |", flagUsage(f), f.Description)
+	}
+	return strings.Join(rows, "\n")
+}
+
+func (c *Catalog) exitCodeRows() string {
+	rows := make([]string, len(c.ExitCodes))
+	for i, e := range c.ExitCodes {
+		code := ""
+		if e.ErrorCode != "" {
+			code = "`" + e.ErrorCode + "`"
+		}
+		rows[i] = fmt.Sprintf("| %d | %s | %s |", e.ExitCode, code, e.Description)
+	}
+	return strings.Join(rows, "\n")
+}
+
+// synopsis renders a code block with one synopsis per command in the
+// given groups.
+func (c *Catalog) synopsis(groups ...string) string {
+	var lines []string
+	for _, cmd := range c.Group(groups...) {
+		lines = append(lines, Synopsis(cmd))
+	}
+	return "```bash\n" + strings.Join(lines, "\n") + "\n```"
+}
+
+// Synopsis renders a command's usage followed by its flags in brackets,
+// wrapped onto indented continuation lines when it gets long.
+func Synopsis(cmd Command) string {
+	words := []string{cmd.Usage}
+	for _, f := range cmd.Flags {
+		words = append(words, "["+flagUsage(f)+"]"+variadic(f))
+	}
+	var b strings.Builder
+	line := 0
+	for i, w := range words {
+		if i > 0 {
+			if line+1+len(w) > synopsisWidth {
+				b.WriteString(" \\\n   ")
+				line = 3
+			}
+			b.WriteString(" ")
+			line++
+		}
+		b.WriteString(w)
+		line += len(w)
+	}
+	return b.String()
+}
+
+// flagUsage renders a flag with a placeholder for its value: N for
+// numbers, the upper-cased name otherwise, nothing for booleans.
+func flagUsage(f Flag) string {
+	switch f.Type {
+	case "bool":
+		return "--" + f.Name
+	case "int", "int64", "uint":
+		return "--" + f.Name + " N"
+	}
+	return "--" + f.Name + " " + strings.ToUpper(strings.ReplaceAll(f.Name, "-", "_"))
+}
+
+// variadic marks flags that can be repeated.
+func variadic(f Flag) string {
+	if f.Type == "stringArray" || f.Type == "stringSlice" {
+		return "..."
+	}
+	return ""
+}
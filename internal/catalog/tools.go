@@ -0,0 +1,104 @@
+package catalog
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Tool schema formats.
+const (
+	FormatAnthropic = "anthropic"
+	FormatOpenAI    = "openai"
+)
+
+// Tools returns a tool definition for every non-interactive command, in
+// the Anthropic ({name, description, input_schema}) or OpenAI
+// ({type: function, function: {name, description, parameters}}) format.
+// Arguments and flags become properties named with underscores, so
+// --title-match is title_match.
+func (c *Catalog) Tools(format string) ([]map[string]interface{}, error) {
+	if format != FormatAnthropic && format != FormatOpenAI {
+		return nil, fmt.Errorf("unknown tool format %q (use %s or %s)", format, FormatAnthropic, FormatOpenAI)
+	}
+	out := []map[string]interface{}{}
+	for _, cmd := range c.Commands {
+		if cmd.Interactive {
+			continue
+		}
+		name := ToolName(cmd.Path)
+		description := cmd.Short + ". Runs `" + cmd.Usage + "` and returns its JSON envelope."
+		schema := inputSchema(cmd)
+		if format == FormatOpenAI {
+			out = append(out, map[string]interface{}{
+				"type": "function",
+				"function": map[string]interface{}{
+					"name":        name,
+					"description": description,
+					"parameters":  schema,
+				},
+			})
+			continue
+		}
+		out = append(out, map[string]interface{}{
+			"name":         name,
+			"description":  description,
+			"input_schema": schema,
+		})
+	}
+	return out, nil
+}
+
+// ToolName turns a command path such as "memory version create" into a
+// tool name, memory_version_create.
+func ToolName(path string) string {
+	return strings.NewReplacer(" ", "_", "-", "_").Replace(path)
+}
+
+func inputSchema(cmd Command) map[string]interface{} {
+	properties := map[string]interface{}{}
+	required := []string{}
+	for _, a := range cmd.Args {
+		name := ToolName(a.Name)
+		prop := map[string]interface{}{"type": "string", "description": "The <" + a.Name + "> argument"}
+		if a.Variadic {
+			prop = map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}, "description": "One or more <" + a.Name + "> arguments"}
+		}
+		properties[name] = prop
+		if a.Required {
+			required = append(required, name)
+		}
+	}
+	for _, f := range cmd.Flags {
+		name := ToolName(f.Name)
+		prop := map[string]interface{}{"type": jsonType(f.Type), "description": f.Description}
+		if prop["type"] == "array" {
+			prop["items"] = map[string]interface{}{"type": "string"}
+		}
+		if f.Default != nil {
+			prop["default"] = f.Default
+		}
+		properties[name] = prop
+		if f.Required {
+			required = append(required, name)
+		}
+	}
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+}
+
+func jsonType(flagType string) string {
+	switch flagType {
+	case "bool":
+		return "boolean"
+	case "int", "int64", "uint":
+		return "integer"
+	case "float64":
+		return "number"
+	case "stringArray", "stringSlice":
+		return "array"
+	}
+	return "string"
+}
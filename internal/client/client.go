@@ -2,10 +2,13 @@ package client
 
 import (
 	"bytes"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"regexp"
 	"strings"
@@ -14,12 +17,41 @@ import (
 	"github.com/maquina/recuerd0-cli/internal/errors"
 )
 
+// DefaultTimeout is used when Options.Timeout is zero.
+const DefaultTimeout = 30 * time.Second
+
 // Client implements the API interface for making HTTP requests to the Recuerd0 API.
 type Client struct {
 	BaseURL    string
 	Token      string
 	HTTPClient *http.Client
 	Verbose    bool
+	// Headers are extra headers sent with every request. They cannot
+	// replace Authorization, Content-Type or Accept.
+	Headers map[string]string
+	// OnMutation, when set, is called after every POST, PATCH and DELETE,
+	// whether it succeeded or not.
+	OnMutation func(Mutation)
+}
+
+// Mutation describes a completed write request.
+type Mutation struct {
+	Method string
+	Path   string
+	Body   []byte // JSON request body, nil if none
+	Status int    // HTTP status, 0 if no response was received
+	Err    error
+}
+
+// Options configures the HTTP transport of a Client.
+type Options struct {
+	Timeout            time.Duration
+	Proxy              string // proxy URL; empty uses HTTPS_PROXY/HTTP_PROXY
+	CACertFile         string // PEM bundle added to the system pool
+	ClientCertFile     string // PEM certificate for mutual TLS
+	ClientKeyFile      string // PEM private key for mutual TLS
+	InsecureSkipVerify bool
+	Headers            map[string]string
 }
 
 // New creates a new API client.
@@ -29,12 +61,79 @@ func New(baseURL, token string, verbose bool) *Client {
 		BaseURL: baseURL,
 		Token:   token,
 		HTTPClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout: DefaultTimeout,
 		},
 		Verbose: verbose,
 	}
 }
 
+// maxIdleConnsPerHost bounds the idle connections kept to the API host.
+const maxIdleConnsPerHost = 16
+
+// NewWithOptions creates an API client with a custom transport.
+func NewWithOptions(baseURL, token string, verbose bool, opts Options) (*Client, error) {
+	c := New(baseURL, token, verbose)
+	c.Headers = opts.Headers
+	if opts.Timeout > 0 {
+		c.HTTPClient.Timeout = opts.Timeout
+	}
+
+	tlsConfig, err := TLSConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	// Bulk commands keep several requests in flight to the one API host;
+	// keep their connections for reuse rather than the default two.
+	transport.MaxIdleConnsPerHost = maxIdleConnsPerHost
+	if opts.Proxy != "" {
+		proxyURL, err := url.Parse(opts.Proxy)
+		if err != nil || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL %q", opts.Proxy)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+	c.HTTPClient.Transport = transport
+	return c, nil
+}
+
+// TLSConfig builds the TLS settings described by opts.
+func TLSConfig(opts Options) (*tls.Config, error) {
+	cfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: opts.InsecureSkipVerify,
+	}
+
+	if opts.CACertFile != "" {
+		pem, err := os.ReadFile(opts.CACertFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading CA bundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", opts.CACertFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if opts.ClientCertFile != "" || opts.ClientKeyFile != "" {
+		if opts.ClientCertFile == "" || opts.ClientKeyFile == "" {
+			return nil, fmt.Errorf("client_cert and client_key must be set together")
+		}
+		cert, err := tls.LoadX509KeyPair(opts.ClientCertFile, opts.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
+
 func (c *Client) buildURL(path string) string {
 	if strings.HasPrefix(path, "http") {
 		return path
@@ -43,22 +142,42 @@ func (c *Client) buildURL(path string) string {
 }
 
 func (c *Client) doRequest(method, path string, body interface{}) (*APIResponse, error) {
+	return c.request(method, path, body, nil)
+}
+
+// request sends a request and decodes the JSON response as it is read.
+// When each is set, the items of a top-level array, or of an object's
+// "results" array, are passed to it one at a time instead of being kept in
+// Data; the object's other fields still are.
+func (c *Client) request(method, path string, body interface{}, each func(item interface{}) error) (apiResp *APIResponse, err error) {
 	url := c.buildURL(path)
 
-	var reqBody io.Reader
+	var (
+		reqBody io.Reader
+		data    []byte
+		status  int
+	)
 	if body != nil {
-		data, err := json.Marshal(body)
+		data, err = json.Marshal(body)
 		if err != nil {
 			return nil, errors.NewError(fmt.Sprintf("marshaling request body: %v", err))
 		}
 		reqBody = bytes.NewReader(data)
 	}
+	if method != http.MethodGet && c.OnMutation != nil {
+		defer func() {
+			c.OnMutation(Mutation{Method: method, Path: path, Body: data, Status: status, Err: err})
+		}()
+	}
 
 	req, err := http.NewRequest(method, url, reqBody)
 	if err != nil {
 		return nil, errors.NewNetworkError(fmt.Sprintf("creating request: %v", err))
 	}
 
+	for k, v := range c.Headers {
+		req.Header.Set(k, v)
+	}
 	req.Header.Set("Authorization", "Bearer "+c.Token)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
@@ -72,37 +191,43 @@ func (c *Client) doRequest(method, path string, body interface{}) (*APIResponse,
 		return nil, errors.NewNetworkError(fmt.Sprintf("request failed: %v", err))
 	}
 	defer resp.Body.Close()
-
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, errors.NewNetworkError(fmt.Sprintf("reading response: %v", err))
-	}
+	status = resp.StatusCode
 
 	if c.Verbose {
 		fmt.Fprintf(os.Stderr, "<-- %d %s\n", resp.StatusCode, http.StatusText(resp.StatusCode))
 	}
 
-	apiResp := &APIResponse{
+	// Error bodies are small; keep them whole for the message.
+	if resp.StatusCode >= 400 {
+		raw, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, errors.NewNetworkError(fmt.Sprintf("reading response: %v", err))
+		}
+		var parsed interface{}
+		_ = json.Unmarshal(raw, &parsed)
+		return nil, errors.FromHTTPStatus(resp.StatusCode, extractErrorMessage(parsed, raw))
+	}
+
+	apiResp = &APIResponse{
 		StatusCode: resp.StatusCode,
-		Body:       respBody,
 		Location:   resp.Header.Get("Location"),
 		LinkNext:   parseLinkNext(resp.Header.Get("Link")),
+		Header:     resp.Header,
 	}
 
-	// Parse JSON body
-	if len(respBody) > 0 {
-		var data interface{}
-		if err := json.Unmarshal(respBody, &data); err == nil {
-			apiResp.Data = data
+	dec := json.NewDecoder(resp.Body)
+	if each == nil {
+		// An empty or malformed body leaves Data nil, as before.
+		var parsed interface{}
+		if err := dec.Decode(&parsed); err == nil {
+			apiResp.Data = parsed
 		}
+		return apiResp, nil
 	}
-
-	// Handle error status codes
-	if resp.StatusCode >= 400 {
-		msg := extractErrorMessage(apiResp.Data, respBody)
-		return nil, errors.FromHTTPStatus(resp.StatusCode, msg)
+	apiResp.Data, err = decodeEach(dec, each)
+	if err != nil {
+		return nil, err
 	}
-
 	return apiResp, nil
 }
 
@@ -126,6 +251,78 @@ func (c *Client) GetWithPagination(path string) (*APIResponse, error) {
 	return c.Get(path)
 }
 
+// GetEach gets path and calls fn with each listed item as it is decoded, so
+// a large page is never held whole. The returned Data holds what is left:
+// nil for an array, or the object without its "results".
+func (c *Client) GetEach(path string, fn func(item interface{}) error) (*APIResponse, error) {
+	return c.request("GET", path, nil, fn)
+}
+
+// decodeEach reads a top-level array, or an object with a "results" array,
+// from dec and passes the items to fn. Any other JSON value is returned
+// whole.
+func decodeEach(dec *json.Decoder, fn func(item interface{}) error) (interface{}, error) {
+	tok, err := dec.Token()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.NewNetworkError(fmt.Sprintf("reading response: %v", err))
+	}
+	switch tok {
+	case json.Delim('['):
+		return nil, decodeItems(dec, fn)
+	case json.Delim('{'):
+	default:
+		return tok, nil
+	}
+
+	rest := map[string]interface{}{}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return nil, errors.NewNetworkError(fmt.Sprintf("reading response: %v", err))
+		}
+		name, _ := key.(string)
+		if name == "results" {
+			if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+				return nil, errors.NewNetworkError("reading response: results is not an array")
+			}
+			if err := decodeItems(dec, fn); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		var v interface{}
+		if err := dec.Decode(&v); err != nil {
+			return nil, errors.NewNetworkError(fmt.Sprintf("reading response: %v", err))
+		}
+		rest[name] = v
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, errors.NewNetworkError(fmt.Sprintf("reading response: %v", err))
+	}
+	return rest, nil
+}
+
+// decodeItems passes the elements of an array whose opening bracket has
+// been read to fn, then reads the closing bracket.
+func decodeItems(dec *json.Decoder, fn func(item interface{}) error) error {
+	for dec.More() {
+		var item interface{}
+		if err := dec.Decode(&item); err != nil {
+			return errors.NewNetworkError(fmt.Sprintf("reading response: %v", err))
+		}
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		return errors.NewNetworkError(fmt.Sprintf("reading response: %v", err))
+	}
+	return nil
+}
+
 // parseLinkNext extracts the "next" URL from a Link header (RFC 5988).
 func parseLinkNext(linkHeader string) string {
 	if linkHeader == "" {
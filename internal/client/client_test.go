@@ -1,10 +1,21 @@
 package client
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/json"
+	"encoding/pem"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/maquina/recuerd0-cli/internal/errors"
 )
@@ -213,3 +224,317 @@ func TestExtractErrorMessage(t *testing.T) {
 		})
 	}
 }
+
+func TestGet_ExposesHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "42")
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{"id": "1"})
+	}))
+	defer server.Close()
+
+	c := New(server.URL, "tok_test", false)
+	resp, err := c.Get("/workspaces")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") != "42" {
+		t.Errorf("expected rate limit header, got %q", resp.Header.Get("X-RateLimit-Remaining"))
+	}
+}
+
+func writePEM(t *testing.T, dir, name, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// newClientCert creates a self-signed client certificate and returns the
+// certificate, its PEM file and its key file.
+func newClientCert(t *testing.T, dir string) (*x509.Certificate, string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "recuerd0-test-client"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, writePEM(t, dir, "client.pem", "CERTIFICATE", der), writePEM(t, dir, "client-key.pem", "EC PRIVATE KEY", keyDER)
+}
+
+func okHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"ok": "true"})
+}
+
+func TestNewWithOptions_CustomCA(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(okHandler))
+	defer server.Close()
+	caFile := writePEM(t, t.TempDir(), "ca.pem", "CERTIFICATE", server.Certificate().Raw)
+
+	// Without the CA the self-signed server is rejected.
+	c, err := NewWithOptions(server.URL, "tok_test", false, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Get("/"); err == nil {
+		t.Fatal("expected certificate verification error")
+	}
+
+	c, err = NewWithOptions(server.URL, "tok_test", false, Options{CACertFile: caFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Get("/"); err != nil {
+		t.Fatalf("unexpected error with CA bundle: %v", err)
+	}
+}
+
+func TestNewWithOptions_InsecureSkipVerify(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(okHandler))
+	defer server.Close()
+
+	c, err := NewWithOptions(server.URL, "tok_test", false, Options{InsecureSkipVerify: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Get("/"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestNewWithOptions_ClientCertificate(t *testing.T) {
+	dir := t.TempDir()
+	clientCert, certFile, keyFile := newClientCert(t, dir)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(clientCert)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(okHandler))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: pool}
+	server.StartTLS()
+	defer server.Close()
+	caFile := writePEM(t, dir, "ca.pem", "CERTIFICATE", server.Certificate().Raw)
+
+	c, _ := NewWithOptions(server.URL, "tok_test", false, Options{CACertFile: caFile})
+	if _, err := c.Get("/"); err == nil {
+		t.Fatal("expected handshake failure without client certificate")
+	}
+
+	c, err := NewWithOptions(server.URL, "tok_test", false, Options{
+		CACertFile:     caFile,
+		ClientCertFile: certFile,
+		ClientKeyFile:  keyFile,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Get("/"); err != nil {
+		t.Fatalf("unexpected error with client certificate: %v", err)
+	}
+}
+
+func TestNewWithOptions_Proxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		okHandler(w, r)
+	}))
+	defer proxy.Close()
+
+	c, err := NewWithOptions("http://api.internal.example", "tok_test", false, Options{Proxy: proxy.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Get("/workspaces"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if proxied != "http://api.internal.example/workspaces" {
+		t.Errorf("expected request through proxy, got %q", proxied)
+	}
+}
+
+func TestNewWithOptions_HeadersAndTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Gateway-Key") != "abc" {
+			t.Errorf("expected extra header, got %q", r.Header.Get("X-Gateway-Key"))
+		}
+		if r.Header.Get("Authorization") != "Bearer tok_test" {
+			t.Errorf("extra headers must not replace Authorization, got %q", r.Header.Get("Authorization"))
+		}
+		if r.URL.Path == "/slow" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		okHandler(w, r)
+	}))
+	defer server.Close()
+
+	c, err := NewWithOptions(server.URL, "tok_test", false, Options{
+		Timeout: 50 * time.Millisecond,
+		Headers: map[string]string{"X-Gateway-Key": "abc", "Authorization": "Basic nope"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Get("/"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = c.Get("/slow")
+	cliErr, ok := err.(*errors.CLIError)
+	if !ok || cliErr.Code != errors.CodeNetwork {
+		t.Errorf("expected network timeout error, got %v", err)
+	}
+}
+
+func TestNewWithOptions_InvalidSettings(t *testing.T) {
+	if _, err := NewWithOptions("https://x", "t", false, Options{CACertFile: "/nonexistent/ca.pem"}); err == nil {
+		t.Error("expected error for missing CA bundle")
+	}
+	if _, err := NewWithOptions("https://x", "t", false, Options{ClientCertFile: "cert.pem"}); err == nil {
+		t.Error("expected error for client cert without key")
+	}
+	if _, err := NewWithOptions("https://x", "t", false, Options{Proxy: "::bad"}); err == nil {
+		t.Error("expected error for invalid proxy")
+	}
+}
+
+func TestOnMutation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "PATCH" {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			w.Write([]byte(`{"error":"title is too long"}`))
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":1}`))
+	}))
+	defer server.Close()
+
+	var got []Mutation
+	c := New(server.URL, "tok_test", false)
+	c.OnMutation = func(m Mutation) { got = append(got, m) }
+
+	_, _ = c.Get("/workspaces/1")
+	_, _ = c.Post("/workspaces/1/memories", map[string]string{"title": "x"})
+	_, _ = c.Patch("/workspaces/1/memories/2", map[string]string{"title": "y"})
+
+	if len(got) != 2 {
+		t.Fatalf("expected 2 mutations (no GET), got %d", len(got))
+	}
+	if got[0].Method != "POST" || got[0].Status != 201 || got[0].Err != nil || string(got[0].Body) != `{"title":"x"}` {
+		t.Errorf("unexpected POST mutation: %+v", got[0])
+	}
+	if got[1].Method != "PATCH" || got[1].Status != 422 || got[1].Err == nil {
+		t.Errorf("unexpected PATCH mutation: %+v", got[1])
+	}
+
+	server.Close()
+	got = nil
+	_, _ = c.Delete("/workspaces/1/memories/2")
+	if len(got) != 1 || got[0].Status != 0 || got[0].Err == nil {
+		t.Errorf("expected a failed DELETE without status, got %+v", got)
+	}
+}
+
+func TestGetEach(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  string
+		items int
+		rest  interface{}
+	}{
+		{"array", `[{"id":1},{"id":2},{"id":3}]`, 3, nil},
+		{"results", `{"query":"q","results":[{"id":1},{"id":2}],"total_results":2}`, 2, map[string]interface{}{"query": "q", "total_results": float64(2)}},
+		{"object", `{"id":1}`, 0, map[string]interface{}{"id": float64(1)}},
+		{"empty", ``, 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Link", `</workspaces?page=2>; rel="next"`)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			var got []interface{}
+			resp, err := New(server.URL, "tok_test", false).GetEach("/workspaces", func(item interface{}) error {
+				got = append(got, item)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) != tt.items {
+				t.Errorf("expected %d items, got %v", tt.items, got)
+			}
+			if resp.LinkNext != "/workspaces?page=2" {
+				t.Errorf("expected the next link, got %q", resp.LinkNext)
+			}
+			if data, _ := json.Marshal(resp.Data); string(data) != mustJSON(t, tt.rest) {
+				t.Errorf("expected the other fields %v, got %v", tt.rest, resp.Data)
+			}
+		})
+	}
+}
+
+func TestGetEach_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"Not found"}`))
+		case "/truncated":
+			w.Write([]byte(`[{"id":1},{"id":`))
+		default:
+			w.Write([]byte(`[{"id":1},{"id":2}]`))
+		}
+	}))
+	defer server.Close()
+	c := New(server.URL, "tok_test", false)
+
+	_, err := c.GetEach("/missing", func(interface{}) error { return nil })
+	if cliErr, ok := err.(*errors.CLIError); !ok || cliErr.Code != errors.CodeNotFound || cliErr.Message != "Not found" {
+		t.Errorf("expected NOT_FOUND, got %v", err)
+	}
+
+	n := 0
+	_, err = c.GetEach("/truncated", func(interface{}) error { n++; return nil })
+	if cliErr, ok := err.(*errors.CLIError); !ok || cliErr.Code != errors.CodeNetwork || n != 1 {
+		t.Errorf("expected a network error after one item, got %d %v", n, err)
+	}
+
+	stop := errors.NewError("stop")
+	n = 0
+	if _, err = c.GetEach("/list", func(interface{}) error { n++; return stop }); err != stop || n != 1 {
+		t.Errorf("expected the callback's error to stop decoding, got %d %v", n, err)
+	}
+}
+
+func mustJSON(t *testing.T, v interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
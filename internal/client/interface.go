@@ -1,12 +1,15 @@
 package client
 
+import "net/http"
+
 // APIResponse holds the parsed response from the API.
 type APIResponse struct {
 	StatusCode int
-	Body       []byte
 	Location   string
 	LinkNext   string
-	Data       interface{}
+	Header     http.Header
+	// Data is the decoded JSON body, nil if it was empty.
+	Data interface{}
 }
 
 // API defines the interface for the Recuerd0 API client.
@@ -16,4 +19,6 @@ type API interface {
 	Patch(path string, body interface{}) (*APIResponse, error)
 	Delete(path string) (*APIResponse, error)
 	GetWithPagination(path string) (*APIResponse, error)
+	// GetEach streams the items of a listing to fn as they are decoded.
+	GetEach(path string, fn func(item interface{}) error) (*APIResponse, error)
 }
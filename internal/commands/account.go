@@ -2,17 +2,20 @@ package commands
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/spf13/cobra"
 
+	"github.com/maquina/recuerd0-cli/internal/catalog"
 	"github.com/maquina/recuerd0-cli/internal/config"
 	"github.com/maquina/recuerd0-cli/internal/errors"
 	"github.com/maquina/recuerd0-cli/internal/response"
 )
 
 var accountCmd = &cobra.Command{
-	Use:   "account",
-	Short: "Manage configured accounts",
+	Use:         "account",
+	Short:       "Manage configured accounts",
+	Annotations: map[string]string{catalog.Local: "true"},
 }
 
 // account add
@@ -24,7 +27,9 @@ var (
 var accountAddCmd = &cobra.Command{
 	Use:   "add <name>",
 	Short: "Add a new account",
-	Args:  cobra.ExactArgs(1),
+	Example: `  recuerd0 account add personal --token tok_abc123
+  recuerd0 account add work --token tok_xyz789 --api-url https://work.recuerd0.ai`,
+	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		name := args[0]
 		if accountAddToken == "" {
@@ -69,8 +74,15 @@ var accountListCmd = &cobra.Command{
 			Current bool   `json:"current"`
 		}
 
-		accounts := make([]accountEntry, 0, len(globalCfg.Accounts))
-		for name, acct := range globalCfg.Accounts {
+		names := make([]string, 0, len(globalCfg.Accounts))
+		for name := range globalCfg.Accounts {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		accounts := make([]accountEntry, 0, len(names))
+		for _, name := range names {
+			acct := globalCfg.Accounts[name]
 			accounts = append(accounts, accountEntry{
 				Name:    name,
 				APIURL:  acct.APIURL,
@@ -80,7 +92,7 @@ var accountListCmd = &cobra.Command{
 
 		summary := fmt.Sprintf("%d account(s)", len(accounts))
 		bc := []response.Breadcrumb{
-			breadcrumb("add", "recuerd0 account add <name> --token TOKEN", "Add a new account"),
+			breadcrumb("add", "recuerd0 account add <name> --token <token>", "Add a new account"),
 		}
 
 		printSuccessWithBreadcrumbs(accounts, summary, bc)
@@ -89,9 +101,10 @@ var accountListCmd = &cobra.Command{
 
 // account select
 var accountSelectCmd = &cobra.Command{
-	Use:   "select <name>",
-	Short: "Set the active account",
-	Args:  cobra.ExactArgs(1),
+	Use:               "select <name>",
+	Short:             "Set the active account",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeAccounts,
 	Run: func(cmd *cobra.Command, args []string) {
 		name := args[0]
 		if err := config.SetCurrent(name); err != nil {
@@ -112,9 +125,10 @@ var accountSelectCmd = &cobra.Command{
 
 // account remove
 var accountRemoveCmd = &cobra.Command{
-	Use:   "remove <name>",
-	Short: "Remove an account",
-	Args:  cobra.ExactArgs(1),
+	Use:               "remove <name>",
+	Short:             "Remove an account",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeAccounts,
 	Run: func(cmd *cobra.Command, args []string) {
 		name := args[0]
 		if err := config.RemoveAccount(name); err != nil {
@@ -0,0 +1,320 @@
+package commands
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/maquina/recuerd0-cli/internal/audit"
+	"github.com/maquina/recuerd0-cli/internal/catalog"
+	"github.com/maquina/recuerd0-cli/internal/client"
+	"github.com/maquina/recuerd0-cli/internal/errors"
+	"github.com/maquina/recuerd0-cli/internal/response"
+	"github.com/maquina/recuerd0-cli/internal/shellwords"
+)
+
+// actorEnv labels audit records with who is driving the CLI, such as an
+// agent name.
+const actorEnv = "RECUERD0_ACTOR"
+
+// redactedValue replaces flag values left out of the audit log and the
+// shell history.
+const redactedValue = "[REDACTED]"
+
+// keptFlagValues are the value flags whose values are references or
+// options, and so are kept in the audit log and the shell history. Every
+// other value, such as --content, --title, --tags or --token, is replaced.
+var keptFlagValues = map[string]bool{
+	"account":     true,
+	"api-url":     true,
+	"workspace":   true,
+	"page":        true,
+	"limit":       true,
+	"concurrency": true,
+	"budget":      true,
+	"format":      true,
+	"output":      true,
+	"sort":        true,
+	"date-field":  true,
+	"since":       true,
+	"until":       true,
+	"method":      true,
+	"memory":      true,
+	"tools":       true,
+}
+
+// scrubFlagValue returns the value to record for flag name.
+func scrubFlagValue(name, value string) string {
+	if keptFlagValues[name] {
+		return value
+	}
+	return redactedValue
+}
+
+// lookupFlag finds a flag of cmd, including those inherited from its
+// parents.
+func lookupFlag(cmd *cobra.Command, name string) *pflag.Flag {
+	if f := cmd.Flags().Lookup(name); f != nil {
+		return f
+	}
+	return cmd.InheritedFlags().Lookup(name)
+}
+
+// scrubArgs returns command-line words with the values of flags scrubbed,
+// for words that have not been parsed yet, such as a line typed in the
+// shell. Arguments are kept.
+func scrubArgs(words []string) []string {
+	cmd, _, err := rootCmd.Find(words)
+	if err != nil {
+		cmd = rootCmd
+	}
+	out := make([]string, 0, len(words))
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		out = append(out, word)
+		if word == "--" {
+			return append(out, words[i+1:]...)
+		}
+		if !strings.HasPrefix(word, "--") {
+			continue
+		}
+		name, value, inline := strings.Cut(strings.TrimPrefix(word, "--"), "=")
+		f := lookupFlag(cmd, name)
+		if f == nil || f.NoOptDefVal != "" {
+			// Unknown or boolean flags carry no value worth hiding.
+			continue
+		}
+		if inline {
+			out[len(out)-1] = "--" + name + "=" + scrubFlagValue(name, value)
+		} else if i+1 < len(words) {
+			i++
+			out = append(out, scrubFlagValue(name, words[i]))
+		}
+	}
+	return out
+}
+
+// auditCommandLine renders the command being run from its parsed flags and
+// arguments, with flag values scrubbed. It reads cmd rather than os.Args,
+// so commands run inside the shell are recorded as themselves.
+func auditCommandLine(cmd *cobra.Command) string {
+	return commandLine(cmd, func(name, value string) (string, bool) {
+		return scrubFlagValue(name, value), true
+	})
+}
+
+// commandLine renders cmd with the flags that were set, sorted, followed by
+// its arguments. keep maps each flag value to the one shown and reports
+// whether the flag is shown at all.
+func commandLine(cmd *cobra.Command, keep func(name, value string) (string, bool)) string {
+	if cmd == nil {
+		return "recuerd0"
+	}
+	words := strings.Fields(cmd.CommandPath())
+	// Visit would also list flags set by an earlier command in the shell,
+	// as resetFlags can only clear Changed.
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		switch {
+		case !f.Changed:
+		case f.NoOptDefVal != "":
+			if _, ok := keep(f.Name, f.Value.String()); !ok {
+				return
+			}
+			if f.Value.String() == f.NoOptDefVal {
+				words = append(words, "--"+f.Name)
+			} else {
+				words = append(words, "--"+f.Name+"="+f.Value.String())
+			}
+		default:
+			values := []string{f.Value.String()}
+			if sv, ok := f.Value.(pflag.SliceValue); ok {
+				values = sv.GetSlice()
+			}
+			for _, v := range values {
+				if shown, ok := keep(f.Name, v); ok {
+					words = append(words, "--"+f.Name, shown)
+				}
+			}
+		}
+	})
+	words = append(words, cmd.Flags().Args()...)
+	return shellwords.Join(words)
+}
+
+// recordMutation appends an audit record for a write sent by the client.
+// Failing to write the log never fails the command.
+func recordMutation(m client.Mutation) {
+	r := audit.Record{
+		Time:    time.Now().UTC(),
+		Actor:   os.Getenv(actorEnv),
+		Command: auditCommandLine(currentCmd),
+		Method:  m.Method,
+		Path:    m.Path,
+		Status:  m.Status,
+		Result:  audit.ResultOK,
+	}
+	if cfg != nil {
+		r.Account = cfg.Account
+		r.APIURL = cfg.APIURL
+	}
+	r.Workspace, r.MemoryID = audit.ParsePath(m.Path)
+	if m.Body != nil {
+		sum := sha256.Sum256(m.Body)
+		r.BodyHash = hex.EncodeToString(sum[:])
+	}
+	if m.Err != nil {
+		r.Result = audit.ResultError
+		r.Error = m.Err.Error()
+	}
+	if err := audit.Append(r); err != nil && cfgVerbose {
+		fmt.Fprintf(os.Stderr, "audit: %v\n", err)
+	}
+}
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Inspect the local log of writes",
+	Long: `Every POST, PATCH and DELETE sent to the API is recorded, whether it
+succeeded or failed, in a JSONL file under the XDG state directory
+(~/.local/state/recuerd0/audit.jsonl by default). Each record holds the
+time, account, workspace, memory ID, command line (flag values such as
+--content and --token replaced, IDs and options kept), a
+SHA-256 of the request body, the HTTP status and result, and the value of
+RECUERD0_ACTOR when set.`,
+	Annotations: map[string]string{catalog.Local: "true"},
+}
+
+// audit log
+var (
+	auditLogSince     string
+	auditLogUntil     string
+	auditLogAccount   string
+	auditLogWorkspace string
+	auditLogMemory    string
+	auditLogMethod    string
+	auditLogActor     string
+	auditLogFailed    bool
+	auditLogLimit     int
+	auditLogOutput    string
+)
+
+// auditOutput receives --output jsonl and csv, overridable for tests.
+var auditOutput io.Writer = os.Stdout
+
+var auditLogCmd = &cobra.Command{
+	Use:   "log",
+	Short: "Show audit records, newest first",
+	Long: `Show audit records, newest first.
+
+--output json (default) prints the usual envelope; jsonl prints one raw
+record per line and csv a header row and one row per record, for export.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		f := audit.Filter{
+			Account:   auditLogAccount,
+			Workspace: auditLogWorkspace,
+			MemoryID:  auditLogMemory,
+			Method:    auditLogMethod,
+			Actor:     auditLogActor,
+			Failed:    auditLogFailed,
+			Limit:     auditLogLimit,
+		}
+		var err error
+		if auditLogSince != "" {
+			if f.Since, err = parseFilterTime(auditLogSince); err != nil {
+				exitWithError(errors.NewInvalidArgsError(fmt.Sprintf("--since: %v", err)))
+				return
+			}
+		}
+		if auditLogUntil != "" {
+			if f.Until, err = parseFilterTime(auditLogUntil); err != nil {
+				exitWithError(errors.NewInvalidArgsError(fmt.Sprintf("--until: %v", err)))
+				return
+			}
+		}
+		switch auditLogOutput {
+		case "json", "jsonl", "csv":
+		default:
+			exitWithError(errors.NewInvalidArgsError(fmt.Sprintf("--output must be json, jsonl or csv, got %q", auditLogOutput)))
+			return
+		}
+
+		records, err := audit.Read(f)
+		if err != nil {
+			exitWithError(errors.NewError(err.Error()))
+			return
+		}
+
+		switch auditLogOutput {
+		case "jsonl":
+			enc := json.NewEncoder(auditOutput)
+			for _, r := range records {
+				if err := enc.Encode(r); err != nil {
+					exitWithError(errors.NewError(fmt.Sprintf("writing records: %v", err)))
+					return
+				}
+			}
+			return
+		case "csv":
+			if err := writeAuditCSV(auditOutput, records); err != nil {
+				exitWithError(errors.NewError(fmt.Sprintf("writing records: %v", err)))
+			}
+			return
+		}
+
+		bc := []response.Breadcrumb{
+			breadcrumb("failed", "recuerd0 audit log --failed", "Show failed writes"),
+			breadcrumb("export", "recuerd0 audit log --limit 0 --output csv", "Export the whole log"),
+		}
+		printSuccessWithBreadcrumbs(records, fmt.Sprintf("%d audit record(s) from %s", len(records), audit.Path()), bc)
+	},
+}
+
+var auditCSVHeader = []string{"time", "account", "api_url", "actor", "command", "method", "path", "workspace", "memory_id", "body_sha256", "status", "result", "error"}
+
+func writeAuditCSV(w io.Writer, records []audit.Record) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(auditCSVHeader); err != nil {
+		return err
+	}
+	for _, r := range records {
+		status := ""
+		if r.Status != 0 {
+			status = strconv.Itoa(r.Status)
+		}
+		row := []string{
+			r.Time.Format(time.RFC3339), r.Account, r.APIURL, r.Actor, r.Command,
+			r.Method, r.Path, r.Workspace, r.MemoryID, r.BodyHash, status, r.Result, r.Error,
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+
+	auditLogCmd.Flags().StringVar(&auditLogSince, "since", "", "only records at or after this time (RFC3339, YYYY-MM-DD or 7d/12h)")
+	auditLogCmd.Flags().StringVar(&auditLogUntil, "until", "", "only records at or before this time")
+	auditLogCmd.Flags().StringVar(&auditLogAccount, "account", "", "only records for this account")
+	auditLogCmd.Flags().StringVar(&auditLogWorkspace, "workspace", "", "only records for this workspace ID")
+	auditLogCmd.Flags().StringVar(&auditLogMemory, "memory", "", "only records for this memory ID")
+	auditLogCmd.Flags().StringVar(&auditLogMethod, "method", "", "only POST, PATCH or DELETE records")
+	auditLogCmd.Flags().StringVar(&auditLogActor, "actor", "", "only records with this "+actorEnv+" label")
+	auditLogCmd.Flags().BoolVar(&auditLogFailed, "failed", false, "only failed writes")
+	auditLogCmd.Flags().IntVar(&auditLogLimit, "limit", 50, "maximum records to show (0 for all)")
+	auditLogCmd.Flags().StringVar(&auditLogOutput, "output", "json", "output format: json, jsonl or csv")
+	auditCmd.AddCommand(auditLogCmd)
+}
@@ -0,0 +1,175 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maquina/recuerd0-cli/internal/audit"
+	"github.com/maquina/recuerd0-cli/internal/cassette"
+	"github.com/maquina/recuerd0-cli/internal/client"
+	"github.com/maquina/recuerd0-cli/internal/errors"
+	"github.com/maquina/recuerd0-cli/internal/shellwords"
+)
+
+func resetAuditLogFlags() {
+	auditLogSince, auditLogUntil, auditLogAccount, auditLogWorkspace = "", "", "", ""
+	auditLogMemory, auditLogMethod, auditLogActor = "", "", ""
+	auditLogFailed = false
+	auditLogLimit = 50
+	auditLogOutput = "json"
+}
+
+func TestRecordMutation(t *testing.T) {
+	SetTestMode(NewMockClient())
+	SetTestConfigFull("tok_test", "https://api.example.com", "5")
+	cfg.Account = "work"
+	defer ResetTestMode()
+	t.Setenv(actorEnv, "release-bot")
+
+	recordMutation(client.Mutation{Method: "PATCH", Path: "/workspaces/5/memories/42", Body: []byte(`{"memory":{}}`), Status: 200})
+	recordMutation(client.Mutation{Method: "DELETE", Path: "/workspaces/5/memories/43", Status: 404, Err: errors.NewNotFoundError("not found")})
+
+	records, err := audit.Read(audit.Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+	failed, ok := records[0], records[1]
+	if ok.Account != "work" || ok.Workspace != "5" || ok.MemoryID != "42" || ok.Actor != "release-bot" || ok.Result != audit.ResultOK {
+		t.Errorf("unexpected record: %+v", ok)
+	}
+	if len(ok.BodyHash) != 64 {
+		t.Errorf("expected a SHA-256 body hash, got %q", ok.BodyHash)
+	}
+	if failed.Result != audit.ResultError || failed.Status != 404 || failed.Error != "not found" || failed.BodyHash != "" {
+		t.Errorf("unexpected failed record: %+v", failed)
+	}
+}
+
+func TestAuditCommandLine(t *testing.T) {
+	SetTestMode(NewMockClient().WithPostData(map[string]interface{}{"id": float64(42)}))
+	SetTestConfigFull("tok_test", "https://api.example.com", "5")
+	defer ResetTestMode()
+	defer resetFlags(rootCmd)
+
+	RunTestCommand(func() {
+		rootCmd.SetArgs([]string{"memory", "create", "--token", "tok_secret", "--workspace", "5", "--title", "Two words", "--content", "sk_live_123", "--tags", "a,b", "--no-defaults"})
+		_ = rootCmd.Execute()
+	})
+	got := auditCommandLine(currentCmd)
+	want := "recuerd0 memory create --content [REDACTED] --no-defaults --tags [REDACTED] --title [REDACTED] --token [REDACTED] --workspace 5"
+	if got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
+func TestScrubArgs(t *testing.T) {
+	tests := []struct {
+		words []string
+		want  string
+	}{
+		{[]string{"memory", "create", "--title", "T", "--content=sk_live_123", "--workspace", "5"}, "memory create --title [REDACTED] --content=[REDACTED] --workspace 5"},
+		{[]string{"--token", "tok_secret", "memory", "list", "--tag", "ops", "--page", "2"}, "--token [REDACTED] memory list --tag [REDACTED] --page 2"},
+		{[]string{"memory", "delete", "7", "--yes", "--", "--content"}, "memory delete 7 --yes -- --content"},
+		{[]string{"search", "deploy notes", "--unknown", "x"}, `search "deploy notes" --unknown x`},
+	}
+	for _, tt := range tests {
+		if got := shellwords.Join(scrubArgs(tt.words)); got != tt.want {
+			t.Errorf("%v: expected %s, got %s", tt.words, tt.want, got)
+		}
+	}
+}
+
+func TestRecordMutation_InShell(t *testing.T) {
+	api := replayClient(t, "memory.yaml").(*client.Client)
+	api.OnMutation = recordMutation
+	SetTestMode(api)
+	SetTestConfigFull(cassette.Marker, "https://api.example.com", "5")
+	defer ResetTestMode()
+
+	var out bytes.Buffer
+	newShellSession(strings.NewReader("memory update 7 --title \"Deploy checklist\"\n"), &out, -1).loop()
+
+	records, err := audit.Read(audit.Filter{})
+	if err != nil || len(records) != 1 {
+		t.Fatalf("expected one record, got %v %v (%s)", records, err, out.String())
+	}
+	if got := records[0].Command; got != "recuerd0 memory update --title [REDACTED] 7" {
+		t.Errorf("expected the command run in the shell, got %s", got)
+	}
+}
+
+func TestAuditLog(t *testing.T) {
+	result := SetTestMode(NewMockClient())
+	SetTestConfig("tok_test", "https://api.example.com")
+	defer ResetTestMode()
+	resetAuditLogFlags()
+	defer resetAuditLogFlags()
+
+	now := time.Now().UTC()
+	_ = audit.Append(audit.Record{Time: now.Add(-48 * time.Hour), Method: "POST", Path: "/workspaces/5/memories", Workspace: "5", Result: audit.ResultOK})
+	_ = audit.Append(audit.Record{Time: now.Add(-time.Hour), Method: "DELETE", Path: "/workspaces/5/memories/7", Workspace: "5", MemoryID: "7", Status: 403, Result: audit.ResultError, Error: "forbidden"})
+
+	RunTestCommand(func() {
+		auditLogCmd.Run(auditLogCmd, []string{})
+	})
+	if records := result.Response.Data.([]audit.Record); len(records) != 2 || records[0].Method != "DELETE" {
+		t.Fatalf("unexpected records: %+v", records)
+	}
+
+	auditLogSince = "1d"
+	RunTestCommand(func() {
+		auditLogCmd.Run(auditLogCmd, []string{})
+	})
+	if records := result.Response.Data.([]audit.Record); len(records) != 1 {
+		t.Errorf("expected --since to filter, got %d records", len(records))
+	}
+
+	auditLogSince = "yesterday"
+	RunTestCommand(func() {
+		auditLogCmd.Run(auditLogCmd, []string{})
+	})
+	if result.ExitCode != errors.ExitInvalidArgs {
+		t.Errorf("expected exit code %d for a bad --since, got %d", errors.ExitInvalidArgs, result.ExitCode)
+	}
+}
+
+func TestAuditLog_OutputFormats(t *testing.T) {
+	SetTestMode(NewMockClient())
+	SetTestConfig("tok_test", "https://api.example.com")
+	defer ResetTestMode()
+	resetAuditLogFlags()
+	defer resetAuditLogFlags()
+	var buf bytes.Buffer
+	orig := auditOutput
+	auditOutput = &buf
+	defer func() { auditOutput = orig }()
+
+	_ = audit.Append(audit.Record{Time: time.Now().UTC(), Method: "POST", Path: "/workspaces", Status: 201, Result: audit.ResultOK})
+
+	auditLogOutput = "jsonl"
+	RunTestCommand(func() {
+		auditLogCmd.Run(auditLogCmd, []string{})
+	})
+	if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); len(lines) != 1 || !strings.Contains(lines[0], `"method":"POST"`) {
+		t.Errorf("unexpected jsonl output: %q", buf.String())
+	}
+
+	buf.Reset()
+	auditLogOutput = "csv"
+	RunTestCommand(func() {
+		auditLogCmd.Run(auditLogCmd, []string{})
+	})
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || rows[0][0] != "time" || rows[1][5] != "POST" || rows[1][10] != "201" {
+		t.Errorf("unexpected csv output: %v", rows)
+	}
+}
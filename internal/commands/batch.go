@@ -0,0 +1,554 @@
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maquina/recuerd0-cli/internal/client"
+	"github.com/maquina/recuerd0-cli/internal/errors"
+	"github.com/maquina/recuerd0-cli/internal/response"
+)
+
+// batchString accepts a JSON string or number, so IDs can be given either way.
+type batchString string
+
+func (s *batchString) UnmarshalJSON(b []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case string:
+		*s = batchString(v)
+	case float64:
+		*s = batchString(strconv.FormatFloat(v, 'f', -1, 64))
+	case nil:
+		*s = ""
+	default:
+		return fmt.Errorf("expected a string or number, got %s", b)
+	}
+	return nil
+}
+
+// batchTags accepts a JSON array of tags or a comma-separated string.
+type batchTags []string
+
+func (t *batchTags) UnmarshalJSON(b []byte) error {
+	var list []string
+	if err := json.Unmarshal(b, &list); err == nil {
+		*t = list
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("tags must be an array or a comma-separated string")
+	}
+	*t = parseTags(s)
+	return nil
+}
+
+// batchOp is one line of batch input. Fields mirror the flags of the
+// matching command.
+type batchOp struct {
+	Op           string      `json:"op"`
+	Ref          batchString `json:"ref,omitempty"`
+	Workspace    batchString `json:"workspace,omitempty"`
+	ID           batchString `json:"id,omitempty"`
+	Title        string      `json:"title,omitempty"`
+	Content      string      `json:"content,omitempty"`
+	Source       string      `json:"source,omitempty"`
+	Tags         batchTags   `json:"tags,omitempty"`
+	Query        string      `json:"query,omitempty"`
+	Page         batchString `json:"page,omitempty"`
+	NoDefaults   bool        `json:"no_defaults,omitempty"`
+	AllowSecrets bool        `json:"allow_secrets,omitempty"`
+	Yes          bool        `json:"yes,omitempty"`
+}
+
+// batchCall sends a prepared operation and returns its data, summary and
+// any meta recorded after the response arrived.
+type batchCall func(apiClient client.API) (interface{}, string, map[string]interface{}, error)
+
+// batchPrepare validates an operation and does its local work (workspace
+// lookup, defaults, secret scan, encryption) before anything is sent.
+type batchPrepare func(op *batchOp) (batchCall, error)
+
+var batchOps = map[string]batchPrepare{
+	"memory.show":           prepareBatchShow,
+	"memory.create":         prepareBatchCreate,
+	"memory.update":         prepareBatchUpdate,
+	"memory.delete":         prepareBatchDelete,
+	"memory.version.create": prepareBatchVersion,
+	"search":                prepareBatchSearch,
+}
+
+func batchOpNames() []string {
+	names := make([]string, 0, len(batchOps))
+	for name := range batchOps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// batchMetaMu serializes the steps that record meta with setMeta or write
+// the offline index, which are not safe to run concurrently.
+var batchMetaMu sync.Mutex
+
+// captureMeta runs fn and returns the meta it recorded, keeping it apart
+// from the meta of other operations.
+func captureMeta(fn func()) map[string]interface{} {
+	batchMetaMu.Lock()
+	defer batchMetaMu.Unlock()
+	saved := pendingMeta
+	pendingMeta = nil
+	fn()
+	captured := pendingMeta
+	pendingMeta = saved
+	return captured
+}
+
+func batchMemoryPath(op *batchOp, ws string) (string, error) {
+	if op.ID == "" {
+		return "", errors.NewInvalidArgsError(fmt.Sprintf("%s needs an id", op.Op))
+	}
+	return fmt.Sprintf("/workspaces/%s/memories/%s", ws, op.ID), nil
+}
+
+// batchFields collects the memory fields set on op and applies defaults,
+// the secret scan and encryption as the matching command would.
+func batchFields(op *batchOp, ws string, fillMissing bool) (map[string]interface{}, string, error) {
+	fields := map[string]interface{}{}
+	if op.Title != "" {
+		fields["title"] = op.Title
+	}
+	if op.Content != "" {
+		fields["content"] = op.Content
+	}
+	if op.Source != "" {
+		fields["source"] = op.Source
+	}
+	if op.Tags != nil {
+		fields["tags"] = []string(op.Tags)
+	}
+	if !op.NoDefaults {
+		if applied := applyProjectDefaults(fields, fillMissing); applied != nil {
+			setMeta("defaults_applied", applied)
+		}
+	}
+	if err := scanForSecrets(fields, op.AllowSecrets); err != nil {
+		return nil, "", err
+	}
+	plaintext, err := encryptFields(ws, fields)
+	if err != nil {
+		return nil, "", err
+	}
+	return fields, plaintext, nil
+}
+
+// indexWrite adds content encrypted locally to the offline index once the
+// server has answered.
+func indexWrite(ws string, data interface{}, plaintext string) map[string]interface{} {
+	if plaintext == "" {
+		return nil
+	}
+	return captureMeta(func() { indexMemoryResponse(ws, data, plaintext) })
+}
+
+func prepareBatchShow(op *batchOp) (batchCall, error) {
+	ws, err := resolveWorkspace(string(op.Workspace))
+	if err != nil {
+		return nil, err
+	}
+	path, err := batchMemoryPath(op, ws)
+	if err != nil {
+		return nil, err
+	}
+	return func(apiClient client.API) (interface{}, string, map[string]interface{}, error) {
+		resp, err := apiClient.Get(path)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		meta := captureMeta(func() {
+			if plaintext, ok := decryptMemory(resp.Data); ok {
+				indexMemoryResponse(ws, resp.Data, plaintext)
+			}
+		})
+		return resp.Data, "Memory details", meta, nil
+	}, nil
+}
+
+func prepareBatchCreate(op *batchOp) (batchCall, error) {
+	ws, err := resolveWorkspace(string(op.Workspace))
+	if err != nil {
+		return nil, err
+	}
+	if op.ID != "" {
+		return nil, errors.NewInvalidArgsError("memory.create does not take an id")
+	}
+	fields, plaintext, err := batchFields(op, ws, true)
+	if err != nil {
+		return nil, err
+	}
+	return func(apiClient client.API) (interface{}, string, map[string]interface{}, error) {
+		resp, err := apiClient.Post(fmt.Sprintf("/workspaces/%s/memories", ws), map[string]interface{}{"memory": fields})
+		if err != nil {
+			return nil, "", nil, err
+		}
+		return resp.Data, "Memory created", indexWrite(ws, resp.Data, plaintext), nil
+	}, nil
+}
+
+func prepareBatchUpdate(op *batchOp) (batchCall, error) {
+	ws, err := resolveWorkspace(string(op.Workspace))
+	if err != nil {
+		return nil, err
+	}
+	path, err := batchMemoryPath(op, ws)
+	if err != nil {
+		return nil, err
+	}
+	if op.Title == "" && op.Content == "" && op.Source == "" && op.Tags == nil {
+		return nil, errors.NewInvalidArgsError("at least one field to update is required")
+	}
+	fields, plaintext, err := batchFields(op, ws, false)
+	if err != nil {
+		return nil, err
+	}
+	return func(apiClient client.API) (interface{}, string, map[string]interface{}, error) {
+		resp, err := apiClient.Patch(path, map[string]interface{}{"memory": fields})
+		if err != nil {
+			return nil, "", nil, err
+		}
+		return resp.Data, "Memory updated", indexWrite(ws, resp.Data, plaintext), nil
+	}, nil
+}
+
+func prepareBatchVersion(op *batchOp) (batchCall, error) {
+	ws, err := resolveWorkspace(string(op.Workspace))
+	if err != nil {
+		return nil, err
+	}
+	path, err := batchMemoryPath(op, ws)
+	if err != nil {
+		return nil, err
+	}
+	fields, plaintext, err := batchFields(op, ws, false)
+	if err != nil {
+		return nil, err
+	}
+	return func(apiClient client.API) (interface{}, string, map[string]interface{}, error) {
+		resp, err := apiClient.Post(path+"/versions", map[string]interface{}{"version": fields})
+		if err != nil {
+			return nil, "", nil, err
+		}
+		return resp.Data, "Version created", indexWrite(ws, resp.Data, plaintext), nil
+	}, nil
+}
+
+func prepareBatchDelete(op *batchOp) (batchCall, error) {
+	ws, err := resolveWorkspace(string(op.Workspace))
+	if err != nil {
+		return nil, err
+	}
+	path, err := batchMemoryPath(op, ws)
+	if err != nil {
+		return nil, err
+	}
+	if !op.Yes && !cfgDryRun {
+		return nil, errors.NewInvalidArgsError(`memory.delete permanently removes the memory and all its versions; set "yes": true to confirm`)
+	}
+	id := string(op.ID)
+	return func(apiClient client.API) (interface{}, string, map[string]interface{}, error) {
+		resp, err := apiClient.Get(path)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		memory, _ := resp.Data.(map[string]interface{})
+		entry, err := trashAndDelete(apiClient, ws, id, memory)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		data := map[string]string{"deleted": id}
+		if entry != nil {
+			data["trash_id"] = entry.ID
+		}
+		return data, fmt.Sprintf("Memory %s deleted", id), nil, nil
+	}, nil
+}
+
+func prepareBatchSearch(op *batchOp) (batchCall, error) {
+	if err := validateSearchQuery(op.Query); err != nil {
+		return nil, err
+	}
+	params := url.Values{}
+	params.Set("q", op.Query)
+	if op.Workspace != "" {
+		ws, err := lookupWorkspaceID(string(op.Workspace))
+		if err != nil {
+			return nil, err
+		}
+		params.Set("workspace_id", ws)
+	}
+	if op.Page != "" {
+		params.Set("page", string(op.Page))
+	}
+	path := "/search?" + params.Encode()
+	return func(apiClient client.API) (interface{}, string, map[string]interface{}, error) {
+		resp, err := apiClient.Get(path)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		return resp.Data, fmt.Sprintf("%d result(s) for %q", countSearchResults(resp.Data), op.Query), nil, nil
+	}, nil
+}
+
+// batchStats is the final summary of a batch run.
+type batchStats struct {
+	Total     int `json:"total"`
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+	Skipped   int `json:"skipped"`
+	// ExitCodes counts failed operations by the exit code each would have
+	// had as a separate command.
+	ExitCodes map[string]int `json:"exit_codes"`
+	ExitCode  int            `json:"exit_code"`
+
+	firstErr *errors.CLIError
+	codes    map[int]bool
+}
+
+func (s *batchStats) fail(cliErr *errors.CLIError) {
+	s.Failed++
+	s.ExitCodes[strconv.Itoa(cliErr.ExitCode)]++
+	s.codes[cliErr.ExitCode] = true
+	if s.firstErr == nil {
+		s.firstErr = cliErr
+	}
+}
+
+// exitCode is zero when every operation succeeded, the shared exit code
+// when all failures agree, and 1 otherwise.
+func (s *batchStats) exitCode() int {
+	switch len(s.codes) {
+	case 0:
+		return errors.ExitSuccess
+	case 1:
+		return s.firstErr.ExitCode
+	}
+	return errors.ExitError
+}
+
+// batchOutput receives the per-operation envelopes, overridable for tests.
+var batchOutput io.Writer = os.Stdout
+
+var (
+	batchConcurrency int
+	batchStopOnError bool
+)
+
+var batchCmd = &cobra.Command{
+	Use:   "batch [file|-]",
+	Short: "Run many operations from NDJSON over one connection",
+	Long: `Run operations read one per line as JSON, from a file or stdin, over a
+single API client with up to --concurrency requests in flight.
+
+Each line names an op and the fields of the matching command:
+
+  {"op":"memory.create","workspace":"22","title":"T","content":"C","tags":["a","b"]}
+  {"op":"memory.update","id":42,"tags":"a,b"}
+  {"op":"memory.version.create","id":42,"content":"C"}
+  {"op":"memory.show","id":42}
+  {"op":"memory.delete","id":42,"yes":true}
+  {"op":"search","query":"caching","workspace":"22"}
+
+Other fields are source, page, no_defaults and allow_secrets, plus ref, an
+optional label echoed back in the result. workspace defaults to the
+configured one.
+
+One envelope is written per operation as it finishes, so lines can arrive
+out of order; meta.batch carries the input line, op and ref. A final
+envelope summarises the run. Its exit code is 0 when everything succeeded,
+the shared exit code when every failure had the same one, and 1 otherwise.
+With --stop-on-error no new operation starts after a failure; those still
+in flight finish and the rest are counted as skipped.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := requireAuth(); err != nil {
+			exitWithError(err)
+			return
+		}
+		if err := validConcurrency(batchConcurrency); err != nil {
+			exitWithError(err)
+			return
+		}
+
+		var in io.Reader = stdinReader()
+		if len(args) == 1 && args[0] != "-" {
+			f, err := os.Open(args[0])
+			if err != nil {
+				exitWithError(errors.NewInvalidArgsError(fmt.Sprintf("reading %s: %v", args[0], err)))
+				return
+			}
+			defer f.Close()
+			in = f
+		}
+
+		stats, err := runBatch(in, getClient())
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+
+		stats.ExitCode = stats.exitCode()
+		summary := fmt.Sprintf("%d of %d operation(s) succeeded", stats.Succeeded, stats.Total)
+		if stats.Skipped > 0 {
+			summary += fmt.Sprintf(", %d skipped", stats.Skipped)
+		}
+		if stats.Failed == 0 {
+			printSuccessWithBreadcrumbs(stats, summary, nil)
+			return
+		}
+		code := errors.CodeError
+		if len(stats.codes) == 1 {
+			code = stats.firstErr.Code
+		}
+		setMeta("batch", stats)
+		exitWithError(&errors.CLIError{
+			Code:     code,
+			Message:  fmt.Sprintf("%d of %d operation(s) failed; first: %s", stats.Failed, stats.Total, stats.firstErr.Message),
+			ExitCode: stats.ExitCode,
+		})
+	},
+}
+
+// runBatch reads operations from in and runs them, writing one envelope per
+// operation to batchOutput. Operations are prepared in input order, as that
+// may resolve workspace names or read config, and sent concurrently.
+func runBatch(in io.Reader, apiClient client.API) (*batchStats, error) {
+	stats := &batchStats{ExitCodes: map[string]int{}, codes: map[int]bool{}}
+	var outMu sync.Mutex
+	enc := json.NewEncoder(batchOutput)
+	var stopped atomic.Bool
+
+	emit := func(line int, op *batchOp, meta map[string]interface{}, data interface{}, summary string, err error) {
+		var resp *response.Response
+		var cliErr *errors.CLIError
+		if err != nil {
+			var ok bool
+			if cliErr, ok = err.(*errors.CLIError); !ok {
+				cliErr = errors.NewError(err.Error())
+			}
+			resp = response.Error(cliErr)
+			if batchStopOnError {
+				stopped.Store(true)
+			}
+		} else {
+			resp = response.SuccessWithSummary(data, summary)
+		}
+		for k, v := range meta {
+			resp.Meta[k] = v
+		}
+		info := map[string]interface{}{"line": line}
+		if op != nil {
+			info["op"] = op.Op
+			if op.Ref != "" {
+				info["ref"] = string(op.Ref)
+			}
+		}
+		resp.Meta["batch"] = info
+		if cfgDryRun {
+			// Operations run concurrently, so their local writes are
+			// listed once, in the final envelope.
+			resp.Meta["dry_run"] = true
+		}
+
+		outMu.Lock()
+		defer outMu.Unlock()
+		if cliErr != nil {
+			stats.fail(cliErr)
+		} else {
+			stats.Succeeded++
+		}
+		_ = enc.Encode(resp)
+	}
+
+	sem := make(chan struct{}, batchConcurrency)
+	var wg sync.WaitGroup
+	reader := bufio.NewReader(in)
+	for line := 1; ; line++ {
+		raw, readErr := reader.ReadBytes('\n')
+		if readErr != nil && readErr != io.EOF {
+			wg.Wait()
+			return stats, errors.NewError(fmt.Sprintf("reading operations: %v", readErr))
+		}
+		if raw = bytes.TrimSpace(raw); len(raw) > 0 {
+			stats.Total++
+			if stopped.Load() {
+				stats.Skipped++
+			} else if op, call, meta, err := prepareBatchLine(raw); err != nil {
+				emit(line, op, meta, nil, "", err)
+			} else {
+				sem <- struct{}{}
+				wg.Add(1)
+				go func(line int, op *batchOp, call batchCall, meta map[string]interface{}) {
+					defer wg.Done()
+					defer func() { <-sem }()
+					data, summary, callMeta, err := call(apiClient)
+					for k, v := range callMeta {
+						meta[k] = v
+					}
+					emit(line, op, meta, data, summary, err)
+				}(line, op, call, meta)
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+	}
+	wg.Wait()
+	return stats, nil
+}
+
+// prepareBatchLine decodes one input line and prepares its operation,
+// returning the meta recorded while doing so.
+func prepareBatchLine(raw []byte) (*batchOp, batchCall, map[string]interface{}, error) {
+	op := &batchOp{}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(op); err != nil {
+		return nil, nil, nil, errors.NewInvalidArgsError(fmt.Sprintf("invalid operation: %v", err))
+	}
+	prepare, ok := batchOps[op.Op]
+	if !ok {
+		return op, nil, nil, errors.NewInvalidArgsError(fmt.Sprintf("unknown op %q; expected one of %s", op.Op, strings.Join(batchOpNames(), ", ")))
+	}
+	var (
+		call batchCall
+		err  error
+	)
+	meta := captureMeta(func() { call, err = prepare(op) })
+	if meta == nil {
+		meta = map[string]interface{}{}
+	}
+	return op, call, meta, err
+}
+
+func init() {
+	rootCmd.AddCommand(batchCmd)
+
+	batchCmd.Flags().IntVar(&batchConcurrency, "concurrency", defaultConcurrency, "maximum requests in flight")
+	batchCmd.Flags().BoolVar(&batchStopOnError, "stop-on-error", false, "start no new operations after one fails")
+}
@@ -0,0 +1,170 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/maquina/recuerd0-cli/internal/client"
+	"github.com/maquina/recuerd0-cli/internal/errors"
+)
+
+// batchLine is the part of a per-operation envelope the tests look at.
+type batchLine struct {
+	Success bool                   `json:"success"`
+	Data    interface{}            `json:"data"`
+	Error   *struct{ Code string } `json:"error"`
+	Meta    struct {
+		Batch struct {
+			Line int    `json:"line"`
+			Op   string `json:"op"`
+			Ref  string `json:"ref"`
+		} `json:"batch"`
+	} `json:"meta"`
+}
+
+func runBatchInput(t *testing.T, mock client.API, input string, concurrency int, stopOnError bool) (*CommandResult, map[int]batchLine) {
+	t.Helper()
+	result := SetTestMode(mock)
+	SetTestConfigFull("tok_test", "https://api.example.com", "5")
+	t.Cleanup(ResetTestMode)
+
+	var out bytes.Buffer
+	origOutput, origReader := batchOutput, stdinReader
+	batchOutput = &out
+	stdinReader = func() io.Reader { return strings.NewReader(input) }
+	batchConcurrency, batchStopOnError = concurrency, stopOnError
+	t.Cleanup(func() {
+		batchOutput, stdinReader = origOutput, origReader
+		batchConcurrency, batchStopOnError = defaultConcurrency, false
+	})
+
+	RunTestCommand(func() {
+		batchCmd.Run(batchCmd, []string{})
+	})
+
+	lines := map[int]batchLine{}
+	dec := json.NewDecoder(&out)
+	for dec.More() {
+		var l batchLine
+		if err := dec.Decode(&l); err != nil {
+			t.Fatalf("decoding output: %v", err)
+		}
+		lines[l.Meta.Batch.Line] = l
+	}
+	return result, lines
+}
+
+func TestBatch_AllSucceed(t *testing.T) {
+	mock := NewMockClient().WithPostData(map[string]interface{}{"id": 7})
+	mock.GetResponse.Data = map[string]interface{}{"id": 42, "title": "Runbook"}
+	input := `{"op":"memory.create","title":"One","content":"First","tags":"a, b","ref":"first"}
+{"op":"memory.create","workspace":9,"title":"Two","tags":["c"]}
+
+{"op":"memory.show","id":42}
+{"op":"memory.update","id":42,"source":"agent"}
+`
+	result, lines := runBatchInput(t, mock, input, 2, false)
+
+	if result.ExitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d: %+v", result.ExitCode, result.Response)
+	}
+	stats := result.Response.Data.(*batchStats)
+	if stats.Total != 4 || stats.Succeeded != 4 || stats.Failed != 0 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+	if len(lines) != 4 || !lines[1].Success || lines[1].Meta.Batch.Ref != "first" || lines[5].Meta.Batch.Op != "memory.update" {
+		t.Errorf("unexpected lines: %+v", lines)
+	}
+
+	paths := map[string]interface{}{}
+	for _, c := range mock.PostCalls {
+		paths[c.Path] = c.Body.(map[string]interface{})["memory"].(map[string]interface{})["tags"]
+	}
+	if len(paths["/workspaces/5/memories"].([]string)) != 2 || paths["/workspaces/9/memories"].([]string)[0] != "c" {
+		t.Errorf("unexpected creates: %v", paths)
+	}
+	if len(mock.PatchCalls) != 1 || mock.PatchCalls[0].Path != "/workspaces/5/memories/42" {
+		t.Errorf("unexpected patches: %+v", mock.PatchCalls)
+	}
+}
+
+func TestBatch_FailuresAggregate(t *testing.T) {
+	t.Run("same exit code", func(t *testing.T) {
+		input := `{"op":"memory.create","title":"ok"}
+{"op":"memory.rename","id":1}
+not json
+{"op":"memory.update","id":1}
+`
+		result, lines := runBatchInput(t, NewMockClient(), input, 4, false)
+		if result.ExitCode != errors.ExitInvalidArgs {
+			t.Fatalf("expected exit code %d, got %d", errors.ExitInvalidArgs, result.ExitCode)
+		}
+		if result.Response.Error.Code != errors.CodeInvalidArgs {
+			t.Errorf("expected code %s, got %s", errors.CodeInvalidArgs, result.Response.Error.Code)
+		}
+		stats := result.Response.Meta["batch"].(*batchStats)
+		if stats.Succeeded != 1 || stats.Failed != 3 || stats.ExitCodes["2"] != 3 {
+			t.Errorf("unexpected stats: %+v", stats)
+		}
+		if lines[3].Success || lines[3].Error.Code != errors.CodeInvalidArgs {
+			t.Errorf("expected line 3 to fail, got %+v", lines[3])
+		}
+	})
+	t.Run("mixed exit codes", func(t *testing.T) {
+		mock := NewMockClient()
+		mock.PatchError = errors.NewNotFoundError("memory not found")
+		input := `{"op":"memory.update","id":1,"title":"T"}
+{"op":"memory.delete","id":1}
+`
+		result, _ := runBatchInput(t, mock, input, 1, false)
+		if result.ExitCode != errors.ExitError {
+			t.Errorf("expected exit code %d, got %d", errors.ExitError, result.ExitCode)
+		}
+		stats := result.Response.Meta["batch"].(*batchStats)
+		if stats.ExitCodes["5"] != 1 || stats.ExitCodes["2"] != 1 {
+			t.Errorf("unexpected exit codes: %v", stats.ExitCodes)
+		}
+	})
+}
+
+func TestBatch_StopOnError(t *testing.T) {
+	mock := NewMockClient()
+	input := `{"op":"memory.create","title":"one"}
+{"op":"search","query":"x"}
+{"op":"memory.create","title":"three"}
+{"op":"memory.create","title":"four"}
+`
+	result, lines := runBatchInput(t, mock, input, 1, true)
+
+	if result.ExitCode != errors.ExitInvalidArgs {
+		t.Fatalf("expected exit code %d, got %d", errors.ExitInvalidArgs, result.ExitCode)
+	}
+	stats := result.Response.Meta["batch"].(*batchStats)
+	if stats.Total != 4 || stats.Succeeded != 1 || stats.Failed != 1 || stats.Skipped != 2 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+	if len(lines) != 2 || len(mock.PostCalls) != 1 {
+		t.Errorf("expected no operations after the failure, got %d lines and %d creates", len(lines), len(mock.PostCalls))
+	}
+}
+
+func TestBatch_DeleteNeedsYes(t *testing.T) {
+	mock := deletableMemory()
+	input := `{"op":"memory.delete","id":42,"yes":true}
+{"op":"memory.delete","id":43}
+`
+	_, lines := runBatchInput(t, mock, input, 1, false)
+
+	if !lines[1].Success || lines[1].Data.(map[string]interface{})["trash_id"] == "" {
+		t.Errorf("expected the confirmed delete to succeed with a trash ID, got %+v", lines[1])
+	}
+	if lines[2].Success {
+		t.Error(`expected a delete without "yes" to be refused`)
+	}
+	if len(mock.DeleteCalls) != 1 || mock.DeleteCalls[0].Path != "/workspaces/5/memories/42" {
+		t.Errorf("unexpected deletes: %+v", mock.DeleteCalls)
+	}
+}
@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/maquina/recuerd0-cli/internal/cassette"
+	"github.com/maquina/recuerd0-cli/internal/errors"
+)
+
+var (
+	cfgRecord string
+	cfgReplay string
+)
+
+// The cassette round tripper is shared by every client of a run, so one
+// cassette holds all of their requests.
+var (
+	cassetteMu sync.Mutex
+	cassetteRT http.RoundTripper
+)
+
+// replaying reports whether requests are answered from a cassette.
+func replaying() bool {
+	return cfgReplay != ""
+}
+
+// cassetteTransport returns the transport for --record or --replay: base
+// wrapped by a recorder, or a player that sends nothing. Without either
+// flag, or when --dry-run keeps --record from writing its file, it returns
+// base.
+func cassetteTransport(base http.RoundTripper) (http.RoundTripper, error) {
+	if cfgRecord == "" && cfgReplay == "" {
+		return base, nil
+	}
+	if cfgRecord != "" && cfgReplay != "" {
+		return nil, errors.NewInvalidArgsError("--record and --replay cannot be combined")
+	}
+
+	cassetteMu.Lock()
+	defer cassetteMu.Unlock()
+	if cassetteRT != nil {
+		return cassetteRT, nil
+	}
+
+	if replaying() {
+		c, err := cassette.Load(cfgReplay)
+		if err != nil {
+			return nil, errors.NewInvalidArgsError(err.Error())
+		}
+		cassetteRT = cassette.NewPlayer(c)
+		return cassetteRT, nil
+	}
+
+	if skipLocalWrite("write", "cassette", cfgRecord) {
+		return base, nil
+	}
+	var red cassette.Redaction
+	if cfg != nil {
+		red = cassette.Redaction{Fields: cfg.Cassette.Fields, Headers: cfg.Cassette.Headers}
+	}
+	rec := cassette.NewRecorder(cfgRecord, base, red)
+	if err := rec.Start(); err != nil {
+		return nil, errors.NewInvalidArgsError(fmt.Sprintf("--record: %v", err))
+	}
+	cassetteRT = rec
+	return cassetteRT, nil
+}
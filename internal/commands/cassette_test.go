@@ -0,0 +1,136 @@
+package commands
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/maquina/recuerd0-cli/internal/cassette"
+	"github.com/maquina/recuerd0-cli/internal/client"
+	"github.com/maquina/recuerd0-cli/internal/errors"
+)
+
+// replayClient is a real client answered from a cassette in
+// testdata/cassettes, for tests that want the HTTP layer as well.
+func replayClient(t *testing.T, name string) client.API {
+	t.Helper()
+	c, err := cassette.Load(filepath.Join("testdata", "cassettes", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	api := client.New("https://api.example.com", cassette.Marker, false)
+	api.HTTPClient.Transport = cassette.NewPlayer(c)
+	return api
+}
+
+func TestCassette_Replay(t *testing.T) {
+	result := SetTestMode(replayClient(t, "memory.yaml"))
+	SetTestConfigFull(cassette.Marker, "https://api.example.com", "5")
+	defer ResetTestMode()
+
+	t.Run("list", func(t *testing.T) {
+		memoryListFilter.Tags = []string{"ops"}
+		defer func() { memoryListFilter.Tags = nil }()
+		RunTestCommand(func() {
+			memoryListCmd.Run(memoryListCmd, nil)
+		})
+		if result.ExitCode != 0 || result.Response.Summary != "2 of 3 memory(ies) match" {
+			t.Fatalf("expected both recorded pages, got %d %+v", result.ExitCode, result.Response)
+		}
+	})
+
+	t.Run("show", func(t *testing.T) {
+		RunTestCommand(func() {
+			memoryShowCmd.Run(memoryShowCmd, []string{"7"})
+		})
+		data, _ := result.Response.Data.(map[string]interface{})
+		if result.ExitCode != 0 || data["title"] != "Deploy notes" {
+			t.Fatalf("expected the recorded memory, got %d %+v", result.ExitCode, result.Response)
+		}
+		if author := data["author"].(map[string]interface{}); author["email"] != cassette.Marker {
+			t.Errorf("expected the email to be redacted, got %v", author["email"])
+		}
+
+		RunTestCommand(func() {
+			memoryShowCmd.Run(memoryShowCmd, []string{"99"})
+		})
+		if result.ExitCode != errors.ExitNotFound || result.Response.Error.Message != "Memory not found" {
+			t.Errorf("expected the recorded 404, got %d %+v", result.ExitCode, result.Response.Error)
+		}
+	})
+
+	t.Run("update", func(t *testing.T) {
+		defer func() { memoryUpdateTitle = "" }()
+		memoryUpdateTitle = "Deploy checklist"
+		RunTestCommand(func() {
+			memoryUpdateCmd.Run(memoryUpdateCmd, []string{"7"})
+		})
+		if result.ExitCode != 0 || result.Response.Data.(map[string]interface{})["title"] != "Deploy checklist" {
+			t.Fatalf("expected the recorded update, got %d %+v", result.ExitCode, result.Response)
+		}
+
+		// The body is part of the match.
+		memoryUpdateTitle = "Something else"
+		RunTestCommand(func() {
+			memoryUpdateCmd.Run(memoryUpdateCmd, []string{"7"})
+		})
+		if result.ExitCode != errors.ExitNetwork || !strings.Contains(result.Response.Error.Message, "no interaction for PATCH /workspaces/5/memories/7") {
+			t.Errorf("expected an unmatched request, got %d %+v", result.ExitCode, result.Response.Error)
+		}
+	})
+}
+
+func TestCassette_RecordThenReplay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"id":7,"title":"Notes","owner":{"api_key":"k_live","email":"ana@example.com"},"echo":"tok_secret"}`)
+	}))
+	defer server.Close()
+
+	result := SetTestMode(nil)
+	defer ResetTestMode()
+	clientFactory = nil
+	SetTestConfigFull("tok_secret", server.URL, "5")
+	cfg.Cassette.Fields = []string{"email"}
+
+	path := filepath.Join(t.TempDir(), "run.json")
+	cfgRecord = path
+	RunTestCommand(func() {
+		memoryShowCmd.Run(memoryShowCmd, []string{"7"})
+	})
+	if result.ExitCode != 0 {
+		t.Fatalf("expected the recorded run to succeed, got %+v", result.Response)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"tok_secret", "k_live", "ana@example.com"} {
+		if strings.Contains(string(raw), secret) {
+			t.Errorf("expected %q to be redacted from the cassette:\n%s", secret, raw)
+		}
+	}
+
+	// Replay needs neither the server nor the token.
+	server.Close()
+	cfgRecord, cfgReplay, cassetteRT = "", path, nil
+	SetTestConfigFull(cassette.Marker, "https://elsewhere.example.com", "5")
+	RunTestCommand(func() {
+		memoryShowCmd.Run(memoryShowCmd, []string{"7"})
+	})
+	if result.ExitCode != 0 || result.Response.Data.(map[string]interface{})["title"] != "Notes" {
+		t.Fatalf("expected the replayed memory, got %d %+v", result.ExitCode, result.Response)
+	}
+
+	cfgRecord, cassetteRT = path, nil
+	RunTestCommand(func() {
+		memoryShowCmd.Run(memoryShowCmd, []string{"7"})
+	})
+	if result.ExitCode != errors.ExitInvalidArgs || !strings.Contains(result.Response.Error.Message, "cannot be combined") {
+		t.Errorf("expected --record with --replay to be refused, got %+v", result.Response)
+	}
+}
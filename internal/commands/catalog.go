@@ -0,0 +1,136 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maquina/recuerd0-cli/internal/catalog"
+	"github.com/maquina/recuerd0-cli/internal/errors"
+	"github.com/maquina/recuerd0-cli/internal/response"
+)
+
+// catalogOutput receives commands --json, schema and skill generate output
+// when it is not written to a file, overridable for tests.
+var catalogOutput io.Writer = os.Stdout
+
+// commands
+var commandsJSON bool
+
+var commandsCmd = &cobra.Command{
+	Use:   "commands",
+	Short: "List every command with its arguments, flags and exit codes",
+	Long: `List every command with its arguments, flags (type, default and
+description), exit codes and examples, read from the command tree itself.
+
+--json prints the catalog document without the envelope, for tools that
+generate bindings from it.`,
+	Annotations: map[string]string{catalog.Local: "true"},
+	Run: func(cmd *cobra.Command, args []string) {
+		c := catalog.Build(rootCmd, version)
+		if commandsJSON {
+			if err := writeIndentedJSON(catalogOutput, c); err != nil {
+				exitWithError(err)
+			}
+			return
+		}
+		bc := []response.Breadcrumb{
+			breadcrumb("skill", "recuerd0 skill generate --output skills/recuerd0/SKILL.md", "Regenerate the agent skill file"),
+			breadcrumb("tools", "recuerd0 skill generate --tools anthropic", "Print tool schemas"),
+		}
+		printSuccessWithBreadcrumbs(c, fmt.Sprintf("%d command(s)", len(c.Commands)), bc)
+	},
+}
+
+func writeIndentedJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return errors.NewError(fmt.Sprintf("writing output: %v", err))
+	}
+	return nil
+}
+
+var skillCmd = &cobra.Command{
+	Use:         "skill",
+	Short:       "Generate the agent skill file",
+	Annotations: map[string]string{catalog.Local: "true"},
+}
+
+// skill generate
+var (
+	skillGenerateOutput string
+	skillGenerateTools  string
+)
+
+var skillGenerateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Render SKILL.md or tool schemas from the command catalog",
+	Long: `Render the agent skill file from the command catalog: the guidance is
+built in, while flags, command synopses and exit codes are read from the
+command tree, so the file always matches this binary.
+
+--tools anthropic|openai prints tool definitions instead, one per
+non-interactive command, with arguments and flags as JSON Schema
+properties. Without --output the result goes to stdout.`,
+	Example: `  recuerd0 skill generate --output skills/recuerd0/SKILL.md
+  recuerd0 skill generate --tools anthropic --output tools.json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		// The version is left out so the file only changes with the commands.
+		c := catalog.Build(rootCmd, "")
+
+		var text string
+		if skillGenerateTools != "" {
+			tools, err := c.Tools(skillGenerateTools)
+			if err != nil {
+				exitWithError(errors.NewInvalidArgsError(err.Error()))
+				return
+			}
+			data, err := json.MarshalIndent(tools, "", "  ")
+			if err != nil {
+				exitWithError(errors.NewError(fmt.Sprintf("encoding tools: %v", err)))
+				return
+			}
+			text = string(data) + "\n"
+		} else {
+			skill, err := c.Skill()
+			if err != nil {
+				exitWithError(errors.NewError(err.Error()))
+				return
+			}
+			text = skill
+		}
+
+		if skillGenerateOutput == "" {
+			if _, err := io.WriteString(catalogOutput, text); err != nil {
+				exitWithError(errors.NewError(fmt.Sprintf("writing output: %v", err)))
+			}
+			return
+		}
+		if !skipLocalWrite("write", "skill file", skillGenerateOutput) {
+			if err := os.WriteFile(skillGenerateOutput, []byte(text), 0644); err != nil {
+				exitWithError(errors.NewError(fmt.Sprintf("writing %s: %v", skillGenerateOutput, err)))
+				return
+			}
+		}
+		printSuccessWithBreadcrumbs(
+			map[string]interface{}{"path": skillGenerateOutput, "bytes": len(text), "commands": len(c.Commands)},
+			fmt.Sprintf("Wrote %s", skillGenerateOutput),
+			[]response.Breadcrumb{breadcrumb("commands", "recuerd0 commands --json", "Show the catalog it was built from")},
+		)
+	},
+}
+
+func init() {
+	commandsCmd.Flags().BoolVar(&commandsJSON, "json", false, "print the catalog without the envelope")
+	rootCmd.AddCommand(commandsCmd)
+
+	skillGenerateCmd.Flags().StringVar(&skillGenerateOutput, "output", "", "write to this file instead of stdout")
+	skillGenerateCmd.Flags().StringVar(&skillGenerateTools, "tools", "", "print tool schemas instead: anthropic or openai")
+	skillCmd.AddCommand(skillGenerateCmd)
+	rootCmd.AddCommand(skillCmd)
+}
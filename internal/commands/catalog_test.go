@@ -0,0 +1,99 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/maquina/recuerd0-cli/internal/catalog"
+	"github.com/maquina/recuerd0-cli/internal/errors"
+)
+
+// TestSkillFileUpToDate fails when skills/recuerd0/SKILL.md no longer
+// matches the command tree.
+func TestSkillFileUpToDate(t *testing.T) {
+	want, err := catalog.Build(rootCmd, "").Skill()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(filepath.Join("..", "..", "skills", "recuerd0", "SKILL.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Error("skills/recuerd0/SKILL.md is stale; run: make skill")
+	}
+}
+
+func TestCommands_JSON(t *testing.T) {
+	SetTestMode(NewMockClient())
+	defer ResetTestMode()
+	var buf bytes.Buffer
+	orig := catalogOutput
+	catalogOutput = &buf
+	defer func() { catalogOutput = orig; commandsJSON = false }()
+
+	commandsJSON = true
+	RunTestCommand(func() {
+		commandsCmd.Run(commandsCmd, nil)
+	})
+
+	var c catalog.Catalog
+	if err := json.Unmarshal(buf.Bytes(), &c); err != nil {
+		t.Fatalf("expected the bare catalog, got %v:\n%s", err, buf.String())
+	}
+	show, ok := c.Find("memory show")
+	if !ok || show.Args[0].Name != "memory_id" || len(show.ExitCodes) != len(errors.ExitCodes) {
+		t.Fatalf("unexpected memory show entry %+v", show)
+	}
+	if list, _ := c.Find("account list"); !list.Local {
+		t.Error("expected account commands to be local")
+	}
+	if _, ok := c.Find("shell"); !ok {
+		t.Error("expected interactive commands in the catalog")
+	}
+}
+
+func TestSkillGenerate(t *testing.T) {
+	t.Run("tools", func(t *testing.T) {
+		result := SetTestMode(NewMockClient())
+		defer ResetTestMode()
+		out := filepath.Join(t.TempDir(), "tools.json")
+		skillGenerateTools, skillGenerateOutput = "openai", out
+		defer func() { skillGenerateTools, skillGenerateOutput = "", "" }()
+
+		RunTestCommand(func() {
+			skillGenerateCmd.Run(skillGenerateCmd, nil)
+		})
+
+		if result.ExitCode != 0 {
+			t.Fatalf("expected success, got %d", result.ExitCode)
+		}
+		data, _ := os.ReadFile(out)
+		var tools []map[string]interface{}
+		if err := json.Unmarshal(data, &tools); err != nil || len(tools) == 0 {
+			t.Fatalf("expected tool definitions, got %v", err)
+		}
+		for _, tool := range tools {
+			name := tool["function"].(map[string]interface{})["name"]
+			if name == "shell" || name == "tui" {
+				t.Errorf("expected no tool for %s", name)
+			}
+		}
+	})
+	t.Run("unknown format", func(t *testing.T) {
+		result := SetTestMode(NewMockClient())
+		defer ResetTestMode()
+		skillGenerateTools = "xml"
+		defer func() { skillGenerateTools = "" }()
+
+		RunTestCommand(func() {
+			skillGenerateCmd.Run(skillGenerateCmd, nil)
+		})
+		if result.ExitCode != errors.ExitInvalidArgs {
+			t.Errorf("expected exit code %d, got %d", errors.ExitInvalidArgs, result.ExitCode)
+		}
+	})
+}
@@ -0,0 +1,268 @@
+package commands
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maquina/recuerd0-cli/internal/cache"
+	"github.com/maquina/recuerd0-cli/internal/config"
+)
+
+// Dynamic shell completion. Cobra calls these functions through its hidden
+// __complete command, where PersistentPreRun does not run, so each one
+// resolves the config itself and returns nothing rather than an error when
+// it cannot reach the API.
+
+// completionCacheTTL is how long memory listings are reused between Tab
+// presses, so completion stays fast while a command is being typed.
+const completionCacheTTL = 30 * time.Second
+
+// memoryRef is the subset of a memory offered as a completion.
+type memoryRef struct {
+	ID    string   `json:"id"`
+	Title string   `json:"title"`
+	Tags  []string `json:"tags,omitempty"`
+}
+
+// completionReady resolves the config from the global flags when no
+// command has done so yet, and reports whether the API can be called.
+func completionReady() bool {
+	if cfg == nil {
+		resolved, err := config.Resolve(config.ResolvedConfig{
+			Account:   cfgAccount,
+			Token:     cfgToken,
+			APIURL:    cfgAPIURL,
+			Workspace: cfgWorkspace,
+		})
+		if err != nil {
+			return false
+		}
+		cfg = resolved
+	}
+	return cfg.Token != ""
+}
+
+// completionWorkspace returns the workspace a command's --workspace flag or
+// the config points at, as an ID.
+func completionWorkspace(cmd *cobra.Command) (string, bool) {
+	ref, _ := cmd.Flags().GetString("workspace")
+	if ref == "" {
+		ref = cfg.Workspace
+	}
+	if ref == "" {
+		return "", false
+	}
+	id, err := lookupWorkspaceID(ref)
+	return id, err == nil
+}
+
+// completionMemories lists a workspace's memories, cached briefly.
+func completionMemories(ws string) ([]memoryRef, error) {
+	key := cache.Key("completion-memories", cfg.APIURL, cfg.Token, ws)
+	var refs []memoryRef
+	if cache.Get(key, completionCacheTTL, &refs) {
+		return refs, nil
+	}
+	memories, err := workspaceMemories(getClient(), ws)
+	if err != nil {
+		return nil, err
+	}
+	refs = make([]memoryRef, 0, len(memories))
+	for _, m := range memories {
+		title, _ := m["title"].(string)
+		refs = append(refs, memoryRef{ID: stringID(m["id"]), Title: title, Tags: memoryTags(m)})
+	}
+	_ = cache.Put(key, refs)
+	return refs, nil
+}
+
+// completeAccounts suggests configured account names with their API URL.
+func completeAccounts(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	global, err := config.ListAccounts()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var out []cobra.Completion
+	for name, acct := range global.Accounts {
+		if strings.HasPrefix(name, toComplete) {
+			url := acct.APIURL
+			if url == "" {
+				url = config.DefaultAPIURL
+			}
+			out = append(out, cobra.CompletionWithDesc(name, url))
+		}
+	}
+	sort.Strings(out)
+	return out, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeWorkspaceArg completes the single workspace argument of
+// workspace show, update, archive and unarchive.
+func completeWorkspaceArg(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeWorkspaces(cmd, args, toComplete)
+}
+
+// completeWorkspaces suggests workspace IDs described by their names. A
+// word that is not a number completes to matching names instead, which
+// --workspace also accepts.
+func completeWorkspaces(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if !completionReady() {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	refs, _, err := listWorkspaceRefs(false)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	byName := toComplete != "" && !isNumericID(toComplete)
+	var out []cobra.Completion
+	for _, w := range refs {
+		switch {
+		case byName && strings.HasPrefix(strings.ToLower(w.Name), strings.ToLower(toComplete)):
+			out = append(out, cobra.CompletionWithDesc(w.Name, "id "+w.ID))
+		case !byName && strings.HasPrefix(w.ID, toComplete):
+			out = append(out, cobra.CompletionWithDesc(w.ID, w.Name))
+		}
+	}
+	return out, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeMemoryIDs suggests memory IDs described by their titles, from
+// the workspace given by --workspace or the config. Memories already on
+// the command line are left out.
+func completeMemoryIDs(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if !completionReady() {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	ws, ok := completionWorkspace(cmd)
+	if !ok {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	refs, err := completionMemories(ws)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	used := map[string]bool{}
+	for _, a := range args {
+		used[a] = true
+	}
+	var out []cobra.Completion
+	for _, m := range refs {
+		if !used[m.ID] && strings.HasPrefix(m.ID, toComplete) {
+			out = append(out, cobra.CompletionWithDesc(m.ID, m.Title))
+		}
+	}
+	return out, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeSingleMemoryID completes commands that take one memory ID.
+func completeSingleMemoryID(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeMemoryIDs(cmd, args, toComplete)
+}
+
+// workspaceTags returns the workspace's tags, most used first.
+func workspaceTags(cmd *cobra.Command) []string {
+	if !completionReady() {
+		return nil
+	}
+	ws, ok := completionWorkspace(cmd)
+	if !ok {
+		return nil
+	}
+	refs, err := completionMemories(ws)
+	if err != nil {
+		return nil
+	}
+	counts := map[string]int{}
+	for _, m := range refs {
+		for _, t := range m.Tags {
+			counts[t]++
+		}
+	}
+	tags := make([]string, 0, len(counts))
+	for t := range counts {
+		tags = append(tags, t)
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if counts[tags[i]] != counts[tags[j]] {
+			return counts[tags[i]] > counts[tags[j]]
+		}
+		return tags[i] < tags[j]
+	})
+	return tags
+}
+
+// completeTagList completes the last entry of a comma-separated --tags
+// value, keeping the tags before it and leaving out ones already listed.
+func completeTagList(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	head, last := "", toComplete
+	if i := strings.LastIndex(toComplete, ","); i >= 0 {
+		head, last = toComplete[:i+1], toComplete[i+1:]
+	}
+	listed := map[string]bool{}
+	for _, t := range parseTags(head) {
+		listed[t] = true
+	}
+	var out []cobra.Completion
+	for _, t := range workspaceTags(cmd) {
+		if !listed[t] && strings.HasPrefix(t, last) {
+			out = append(out, head+t)
+		}
+	}
+	return out, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+}
+
+// completeTagArg completes the tag to rename, the first argument of
+// tag rename.
+func completeTagArg(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeTag(cmd, args, toComplete)
+}
+
+// completeTag completes a single tag, as taken by --tag.
+func completeTag(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	var out []cobra.Completion
+	for _, t := range workspaceTags(cmd) {
+		if strings.HasPrefix(t, toComplete) {
+			out = append(out, t)
+		}
+	}
+	return out, cobra.ShellCompDirectiveNoFileComp
+}
+
+// flagCompletions maps flag names to their completion, wherever they are
+// defined.
+var flagCompletions = map[string]cobra.CompletionFunc{
+	"account":   completeAccounts,
+	"workspace": completeWorkspaces,
+	"tags":      completeTagList,
+	"tag":       completeTag,
+}
+
+// registerFlagCompletions registers flagCompletions on cmd and every
+// command below it. Flags are defined in each command file's init, so
+// this runs once from Execute, after all of them.
+func registerFlagCompletions(cmd *cobra.Command) {
+	for name, fn := range flagCompletions {
+		if cmd.Flags().Lookup(name) != nil || cmd.PersistentFlags().Lookup(name) != nil {
+			// Errors only mean the flag was registered through a parent.
+			_ = cmd.RegisterFlagCompletionFunc(name, fn)
+		}
+	}
+	for _, sub := range cmd.Commands() {
+		registerFlagCompletions(sub)
+	}
+}
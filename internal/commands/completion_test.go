@@ -0,0 +1,102 @@
+package commands
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maquina/recuerd0-cli/internal/config"
+)
+
+func TestComplete_Accounts(t *testing.T) {
+	setupAccountTest(t)
+	_ = config.AddAccount("personal", "tok_a", "")
+	_ = config.AddAccount("work", "tok_b", "https://recuerd0.example.com")
+
+	got, directive := completeAccounts(accountSelectCmd, nil, "")
+	want := []string{"personal\t" + config.DefaultAPIURL, "work\thttps://recuerd0.example.com"}
+	if strings.Join(got, "|") != strings.Join(want, "|") || directive != cobra.ShellCompDirectiveNoFileComp {
+		t.Errorf("got %q (%d), want %q", got, directive, want)
+	}
+	if got, _ := completeAccounts(accountSelectCmd, nil, "w"); len(got) != 1 {
+		t.Errorf("expected the prefix to filter, got %q", got)
+	}
+	if got, _ := completeAccounts(accountSelectCmd, []string{"work"}, ""); len(got) != 0 {
+		t.Errorf("expected nothing after the account, got %q", got)
+	}
+}
+
+func TestComplete_WorkspacesMemoriesAndTags(t *testing.T) {
+	mock := shellMock()
+	SetTestMode(mock)
+	SetTestConfigFull("tok_test", "https://api.example.com", "5")
+	defer ResetTestMode()
+
+	tests := []struct {
+		name       string
+		fn         cobra.CompletionFunc
+		args       []string
+		toComplete string
+		want       []string
+	}{
+		{"workspace IDs", completeWorkspaceArg, nil, "", []string{"5\tNotes", "6\tOps runbooks"}},
+		{"workspace names", completeWorkspaces, nil, "op", []string{"Ops runbooks\tid 6"}},
+		{"memory IDs", completeSingleMemoryID, nil, "", []string{"7\tCaching strategy", "8\tDeploy checklist"}},
+		{"memory IDs skip listed", completeMemoryIDs, []string{"7"}, "", []string{"8\tDeploy checklist"}},
+		{"single memory only", completeSingleMemoryID, []string{"7"}, "", nil},
+		{"tag list", completeTagList, nil, "ops,", []string{"ops,redis"}},
+		{"tag prefix", completeTag, nil, "re", []string{"redis"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := tt.fn(memoryShowCmd, tt.args, tt.toComplete)
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	fetches := 0
+	for _, c := range mock.GetCalls {
+		if c.Path == "/workspaces/5/memories" {
+			fetches++
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("expected the memory listing to be cached, got %d fetches", fetches)
+	}
+}
+
+func TestComplete_Command(t *testing.T) {
+	SetTestMode(shellMock())
+	SetTestConfigFull("tok_test", "https://api.example.com", "5")
+	defer ResetTestMode()
+	registerFlagCompletions(rootCmd)
+
+	run := func(args ...string) string {
+		var out bytes.Buffer
+		rootCmd.SetOut(&out)
+		rootCmd.SetArgs(append([]string{cobra.ShellCompRequestCmd}, args...))
+		defer func() {
+			rootCmd.SetOut(nil)
+			rootCmd.SetArgs(nil)
+			memoryCreateTags, memoryShowWorkspace = "", ""
+		}()
+		if err := rootCmd.Execute(); err != nil {
+			t.Fatal(err)
+		}
+		return out.String()
+	}
+
+	if out := run("memory", "show", "--workspace", "5", ""); !strings.Contains(out, "8\tDeploy checklist") {
+		t.Errorf("expected memory IDs, got:\n%s", out)
+	}
+	if out := run("memory", "create", "--tags", "r"); !strings.Contains(out, "redis\n") {
+		t.Errorf("expected tags for --tags, got:\n%s", out)
+	}
+	if out := run("search", "--workspace", ""); !strings.Contains(out, "6\tOps runbooks") {
+		t.Errorf("expected workspaces for --workspace, got:\n%s", out)
+	}
+}
@@ -0,0 +1,284 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maquina/recuerd0-cli/internal/catalog"
+	"github.com/maquina/recuerd0-cli/internal/config"
+	"github.com/maquina/recuerd0-cli/internal/errors"
+	"github.com/maquina/recuerd0-cli/internal/response"
+)
+
+var configCmd = &cobra.Command{
+	Use:         "config",
+	Short:       "Read and write configuration settings",
+	Annotations: map[string]string{catalog.Local: "true"},
+}
+
+func configScope(local bool) string {
+	if local {
+		return config.ScopeLocal
+	}
+	return config.ScopeGlobal
+}
+
+// maskToken hides all but the edges of a token so it can be shown safely.
+func maskToken(token string) string {
+	if len(token) <= 8 {
+		return strings.Repeat("*", len(token))
+	}
+	return token[:4] + "..." + token[len(token)-4:]
+}
+
+// isSecretKey reports whether a setting may hold a credential. Extra
+// headers are treated as secrets since they often carry gateway keys.
+func isSecretKey(key string) bool {
+	return key == "token" || strings.HasSuffix(key, ".token") ||
+		strings.HasPrefix(key, "headers.") || strings.Contains(key, ".headers.")
+}
+
+// maskSecrets masks value if key is a secret. Maps, as returned for a
+// key with settings beneath it, are masked entry by entry.
+func maskSecrets(key string, value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		if isSecretKey(key) {
+			return maskToken(v)
+		}
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			out[k] = maskSecrets(key+"."+k, item)
+		}
+		return out
+	}
+	return value
+}
+
+// config get
+var (
+	configGetLocal  bool
+	configGetReveal bool
+)
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print a single setting",
+	Long: `Print a single setting, or every setting beneath a key such as
+accounts.work. Tokens and extra headers are masked unless --reveal is given.`,
+	Example: `  recuerd0 config get accounts.work.api_url
+  recuerd0 config get accounts.work.token --reveal`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		scope := configScope(configGetLocal)
+		value, err := config.GetValue(scope, args[0])
+		if err != nil {
+			if config.IsNotSet(err) {
+				exitWithError(errors.NewNotFoundError(fmt.Sprintf("%s is not set in %s config", args[0], scope)))
+				return
+			}
+			exitWithError(errors.NewError(fmt.Sprintf("reading config: %v", err)))
+			return
+		}
+		if !configGetReveal {
+			value = maskSecrets(args[0], value)
+		}
+
+		printSuccessWithBreadcrumbs(
+			map[string]interface{}{"key": args[0], "value": value, "scope": scope},
+			fmt.Sprintf("%s config %s", scope, args[0]),
+			[]response.Breadcrumb{
+				breadcrumb("explain", "recuerd0 config explain", "Show effective settings and their sources"),
+			},
+		)
+	},
+}
+
+// config set
+var configSetLocal bool
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Set a single setting",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		scope := configScope(configSetLocal)
+		if err := config.SetValue(scope, args[0], args[1]); err != nil {
+			if config.IsUnknownKey(err) {
+				exitWithError(errors.NewInvalidArgsError(err.Error()))
+				return
+			}
+			exitWithError(errors.NewError(fmt.Sprintf("setting config: %v", err)))
+			return
+		}
+
+		value := args[1]
+		if isSecretKey(args[0]) {
+			value = maskToken(value)
+		}
+
+		printSuccessWithBreadcrumbs(
+			map[string]interface{}{"key": args[0], "value": value, "scope": scope},
+			fmt.Sprintf("Set %s in %s config", args[0], scope),
+			[]response.Breadcrumb{
+				breadcrumb("list", fmt.Sprintf("recuerd0 config list%s", localFlagSuffix(configSetLocal)), "List settings"),
+				breadcrumb("explain", "recuerd0 config explain", "Show effective settings and their sources"),
+			},
+		)
+	},
+}
+
+// config unset
+var configUnsetLocal bool
+
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset <key>",
+	Short: "Remove a single setting",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		scope := configScope(configUnsetLocal)
+		if err := config.UnsetValue(scope, args[0]); err != nil {
+			if config.IsNotSet(err) {
+				exitWithError(errors.NewNotFoundError(fmt.Sprintf("%s is not set in %s config", args[0], scope)))
+				return
+			}
+			exitWithError(errors.NewError(fmt.Sprintf("unsetting config: %v", err)))
+			return
+		}
+
+		printSuccessWithBreadcrumbs(
+			map[string]interface{}{"key": args[0], "scope": scope},
+			fmt.Sprintf("Unset %s in %s config", args[0], scope),
+			[]response.Breadcrumb{
+				breadcrumb("list", fmt.Sprintf("recuerd0 config list%s", localFlagSuffix(configUnsetLocal)), "List settings"),
+			},
+		)
+	},
+}
+
+// config list
+var configListLocal bool
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all settings in a config file",
+	Run: func(cmd *cobra.Command, args []string) {
+		scope := configScope(configListLocal)
+		values, err := config.ListValues(scope)
+		if err != nil {
+			exitWithError(errors.NewError(fmt.Sprintf("reading config: %v", err)))
+			return
+		}
+
+		type entry struct {
+			Key   string      `json:"key"`
+			Value interface{} `json:"value"`
+		}
+
+		keys := make([]string, 0, len(values))
+		for k := range values {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		entries := make([]entry, 0, len(keys))
+		for _, k := range keys {
+			v := values[k]
+			if s, ok := v.(string); ok && isSecretKey(k) {
+				v = maskToken(s)
+			}
+			entries = append(entries, entry{Key: k, Value: v})
+		}
+
+		printSuccessWithBreadcrumbs(entries, fmt.Sprintf("%d %s setting(s)", len(entries), scope), []response.Breadcrumb{
+			breadcrumb("set", fmt.Sprintf("recuerd0 config set%s <key> <value>", localFlagSuffix(configListLocal)), "Change a setting"),
+			breadcrumb("explain", "recuerd0 config explain", "Show effective settings and their sources"),
+		})
+	},
+}
+
+// config explain
+var configExplainCmd = &cobra.Command{
+	Use:   "explain",
+	Short: "Show every effective setting with its source and precedence",
+	Run: func(cmd *cobra.Command, args []string) {
+		settings, err := config.Explain(config.ResolvedConfig{
+			Account:   cfgAccount,
+			Token:     cfgToken,
+			APIURL:    cfgAPIURL,
+			Workspace: cfgWorkspace,
+		})
+		if err != nil {
+			exitWithError(errors.NewError(fmt.Sprintf("loading config: %v", err)))
+			return
+		}
+
+		type layerEntry struct {
+			Source string `json:"source"`
+			Origin string `json:"origin"`
+			Value  string `json:"value"`
+		}
+		type settingEntry struct {
+			Key    string       `json:"key"`
+			Value  string       `json:"value"`
+			Source string       `json:"source,omitempty"`
+			Layers []layerEntry `json:"layers"`
+			Note   string       `json:"note,omitempty"`
+		}
+
+		entries := make([]settingEntry, 0, len(settings))
+		for _, s := range settings {
+			mask := func(v string) string { return v }
+			if isSecretKey(s.Key) {
+				mask = maskToken
+			}
+			e := settingEntry{Key: s.Key, Value: mask(s.Value), Source: s.Source, Layers: []layerEntry{}, Note: s.Note}
+			for _, l := range s.Layers {
+				e.Layers = append(e.Layers, layerEntry{Source: l.Source, Origin: l.Origin, Value: mask(l.Value)})
+			}
+			entries = append(entries, e)
+		}
+
+		data := map[string]interface{}{
+			"settings": entries,
+			"precedence": []string{
+				config.SourceFlag, config.SourceEnv, config.SourceLocal, config.SourceGlobal, config.SourceDefault,
+			},
+		}
+
+		printSuccessWithBreadcrumbs(data, "Effective configuration", []response.Breadcrumb{
+			breadcrumb("init", "recuerd0 init --workspace <workspace_id>", "Create .recuerd0.yaml for this project"),
+			breadcrumb("doctor", "recuerd0 doctor", "Diagnose configuration problems"),
+		})
+	},
+}
+
+func localFlagSuffix(local bool) string {
+	if local {
+		return " --local"
+	}
+	return ""
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+
+	configGetCmd.Flags().BoolVar(&configGetLocal, "local", false, "read from .recuerd0.yaml instead of the global config")
+	configGetCmd.Flags().BoolVar(&configGetReveal, "reveal", false, "print tokens and headers unmasked")
+	configCmd.AddCommand(configGetCmd)
+
+	configSetCmd.Flags().BoolVar(&configSetLocal, "local", false, "write to .recuerd0.yaml instead of the global config")
+	configCmd.AddCommand(configSetCmd)
+
+	configUnsetCmd.Flags().BoolVar(&configUnsetLocal, "local", false, "write to .recuerd0.yaml instead of the global config")
+	configCmd.AddCommand(configUnsetCmd)
+
+	configListCmd.Flags().BoolVar(&configListLocal, "local", false, "list .recuerd0.yaml instead of the global config")
+	configCmd.AddCommand(configListCmd)
+
+	configCmd.AddCommand(configExplainCmd)
+}
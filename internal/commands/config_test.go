@@ -0,0 +1,269 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/maquina/recuerd0-cli/internal/client"
+	"github.com/maquina/recuerd0-cli/internal/config"
+	"github.com/maquina/recuerd0-cli/internal/errors"
+)
+
+func TestConfigSetAndGet(t *testing.T) {
+	setupAccountTest(t)
+	_ = config.AddAccount("work", "tok_work_123456", "")
+
+	mock := NewMockClient()
+	result := SetTestMode(mock)
+	defer ResetTestMode()
+
+	RunTestCommand(func() {
+		configSetCmd.Run(configSetCmd, []string{"accounts.work.api_url", "https://self.example.com"})
+	})
+	if result.ExitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", result.ExitCode)
+	}
+
+	RunTestCommand(func() {
+		configGetCmd.Run(configGetCmd, []string{"accounts.work.api_url"})
+	})
+	data := result.Response.Data.(map[string]interface{})
+	if data["value"] != "https://self.example.com" {
+		t.Errorf("unexpected value: %v", data["value"])
+	}
+}
+
+func TestConfigSet_UnknownKey(t *testing.T) {
+	setupAccountTest(t)
+
+	mock := NewMockClient()
+	result := SetTestMode(mock)
+	defer ResetTestMode()
+
+	RunTestCommand(func() {
+		configSetCmd.Run(configSetCmd, []string{"colour", "blue"})
+	})
+	if result.ExitCode != errors.ExitInvalidArgs {
+		t.Errorf("expected exit code %d, got %d", errors.ExitInvalidArgs, result.ExitCode)
+	}
+}
+
+func TestConfigGet_NotSet(t *testing.T) {
+	setupAccountTest(t)
+
+	mock := NewMockClient()
+	result := SetTestMode(mock)
+	defer ResetTestMode()
+
+	RunTestCommand(func() {
+		configGetCmd.Run(configGetCmd, []string{"current"})
+	})
+	if result.ExitCode != errors.ExitNotFound {
+		t.Errorf("expected exit code %d, got %d", errors.ExitNotFound, result.ExitCode)
+	}
+}
+
+func TestConfigList_MasksTokens(t *testing.T) {
+	setupAccountTest(t)
+	_ = config.AddAccount("work", "tok_work_123456", "")
+
+	mock := NewMockClient()
+	result := SetTestMode(mock)
+	defer ResetTestMode()
+
+	configListLocal = false
+	RunTestCommand(func() {
+		configListCmd.Run(configListCmd, []string{})
+	})
+
+	raw, _ := result.Response.JSON()
+	if strings.Contains(string(raw), "tok_work_123456") {
+		t.Errorf("expected token to be masked: %s", raw)
+	}
+}
+
+func TestConfigGet_MasksSecrets(t *testing.T) {
+	setupAccountTest(t)
+	_ = config.AddAccount("work", "tok_work_123456", "")
+	_ = config.SetValue(config.ScopeGlobal, "accounts.work.headers.X-Api-Key", "key_abcdef_7890")
+
+	result := SetTestMode(NewMockClient())
+	defer ResetTestMode()
+	defer func() { configGetReveal = false }()
+
+	for _, key := range []string{"accounts.work.token", "accounts.work.headers.X-Api-Key", "accounts.work.headers", "accounts.work", "accounts"} {
+		RunTestCommand(func() {
+			configGetCmd.Run(configGetCmd, []string{key})
+		})
+		raw, _ := result.Response.JSON()
+		if result.ExitCode != 0 || strings.Contains(string(raw), "tok_work_123456") || strings.Contains(string(raw), "key_abcdef_7890") {
+			t.Errorf("%s: expected secrets to be masked, got %s", key, raw)
+		}
+	}
+	if v := result.Response.Data.(map[string]interface{})["value"].(map[string]interface{}); v["work.api_url"] != config.DefaultAPIURL {
+		t.Errorf("expected other settings unmasked, got %v", v)
+	}
+
+	configGetReveal = true
+	RunTestCommand(func() {
+		configGetCmd.Run(configGetCmd, []string{"accounts.work.token"})
+	})
+	if v := result.Response.Data.(map[string]interface{})["value"]; v != "tok_work_123456" {
+		t.Errorf("expected --reveal to print the token, got %v", v)
+	}
+}
+
+func TestConfigExplain(t *testing.T) {
+	setupAccountTest(t)
+	_ = config.AddAccount("work", "tok_work_123456", "")
+	t.Setenv("RECUERD0_WORKSPACE", "7")
+
+	mock := NewMockClient()
+	result := SetTestMode(mock)
+	defer ResetTestMode()
+
+	RunTestCommand(func() {
+		configExplainCmd.Run(configExplainCmd, []string{})
+	})
+
+	if result.ExitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", result.ExitCode)
+	}
+	raw, _ := result.Response.JSON()
+	if strings.Contains(string(raw), "tok_work_123456") {
+		t.Errorf("expected token to be masked: %s", raw)
+	}
+	if !strings.Contains(string(raw), "RECUERD0_WORKSPACE") {
+		t.Errorf("expected env origin in output: %s", raw)
+	}
+}
+
+func TestInit_WritesLocalConfig(t *testing.T) {
+	setupAccountTest(t)
+	_ = config.AddAccount("work", "tok_work", "")
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	mock := NewMockClient()
+	mock.GetResponse = &client.APIResponse{StatusCode: 200, Data: map[string]interface{}{"id": 5, "name": "Team"}}
+	result := SetTestMode(mock)
+	defer ResetTestMode()
+
+	cfgWorkspace = "5"
+	defer func() { cfgWorkspace = "" }()
+
+	RunTestCommand(func() {
+		initCmd.Run(initCmd, []string{})
+	})
+
+	if result.ExitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d: %+v", result.ExitCode, result.Response.Error)
+	}
+	local, err := config.LoadLocal(filepath.Join(dir, ".recuerd0.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if local.Account != "work" || local.Workspace != "5" {
+		t.Errorf("unexpected local config: %+v", local)
+	}
+	if mock.GetCalls[0].Path != "/workspaces/5" {
+		t.Errorf("unexpected path: %s", mock.GetCalls[0].Path)
+	}
+}
+
+func TestInit_RefusesOverwrite(t *testing.T) {
+	setupAccountTest(t)
+	_ = config.AddAccount("work", "tok_work", "")
+	dir := t.TempDir()
+	t.Chdir(dir)
+	os.WriteFile(filepath.Join(dir, ".recuerd0.yaml"), []byte("workspace: \"1\"\n"), 0644)
+
+	mock := NewMockClient()
+	result := SetTestMode(mock)
+	defer ResetTestMode()
+
+	cfgWorkspace = "5"
+	defer func() { cfgWorkspace = "" }()
+
+	RunTestCommand(func() {
+		initCmd.Run(initCmd, []string{})
+	})
+
+	if result.ExitCode != errors.ExitInvalidArgs {
+		t.Errorf("expected exit code %d, got %d", errors.ExitInvalidArgs, result.ExitCode)
+	}
+}
+
+func TestInit_RequiresWorkspaceNonInteractive(t *testing.T) {
+	setupAccountTest(t)
+	_ = config.AddAccount("work", "tok_work", "")
+	t.Chdir(t.TempDir())
+
+	orig := stdinIsTerminal
+	stdinIsTerminal = func() bool { return false }
+	defer func() { stdinIsTerminal = orig }()
+
+	mock := NewMockClient()
+	result := SetTestMode(mock)
+	defer ResetTestMode()
+
+	RunTestCommand(func() {
+		initCmd.Run(initCmd, []string{})
+	})
+
+	if result.ExitCode != errors.ExitInvalidArgs {
+		t.Errorf("expected exit code %d, got %d", errors.ExitInvalidArgs, result.ExitCode)
+	}
+}
+
+func TestClientOptions(t *testing.T) {
+	opts, err := clientOptions(config.Transport{Timeout: "90s", Proxy: "http://proxy:3128"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if opts.Timeout.Seconds() != 90 || opts.Proxy != "http://proxy:3128" {
+		t.Errorf("unexpected options: %+v", opts)
+	}
+
+	if _, err := clientOptions(config.Transport{Timeout: "soon"}); err == nil {
+		t.Error("expected error for invalid timeout")
+	}
+}
+
+func TestConfigSet_TransportSettings(t *testing.T) {
+	setupAccountTest(t)
+	_ = config.AddAccount("work", "tok_work_123456", "")
+
+	mock := NewMockClient()
+	result := SetTestMode(mock)
+	defer ResetTestMode()
+
+	for _, kv := range [][2]string{
+		{"accounts.work.timeout", "60s"},
+		{"accounts.work.insecure_skip_verify", "true"},
+		{"accounts.work.headers.X-Gateway-Key", "secret-gateway-key"},
+	} {
+		RunTestCommand(func() {
+			configSetCmd.Run(configSetCmd, []string{kv[0], kv[1]})
+		})
+		if result.ExitCode != 0 {
+			t.Fatalf("setting %s: exit code %d: %+v", kv[0], result.ExitCode, result.Response.Error)
+		}
+	}
+
+	globalCfg, _ := config.LoadGlobal()
+	acct := globalCfg.Accounts["work"]
+	if acct.Timeout != "60s" || !acct.InsecureSkipVerify || acct.Headers["X-Gateway-Key"] != "secret-gateway-key" {
+		t.Errorf("unexpected transport: %+v", acct.Transport)
+	}
+
+	RunTestCommand(func() {
+		configListCmd.Run(configListCmd, []string{})
+	})
+	raw, _ := result.Response.JSON()
+	if strings.Contains(string(raw), "secret-gateway-key") {
+		t.Errorf("expected header value to be masked: %s", raw)
+	}
+}
@@ -0,0 +1,422 @@
+package commands
+
+import (
+	"crypto/sha256"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maquina/recuerd0-cli/internal/client"
+	"github.com/maquina/recuerd0-cli/internal/crypt"
+	"github.com/maquina/recuerd0-cli/internal/errors"
+	"github.com/maquina/recuerd0-cli/internal/response"
+)
+
+// minContextChunk is the smallest trimmed memory worth including, in tokens.
+const minContextChunk = 64
+
+var (
+	contextBudget    int
+	contextFormat    string
+	contextWorkspace string
+	contextLimit     int
+)
+
+// contextHit is a ranked search result.
+type contextHit struct {
+	ID          string
+	WorkspaceID string
+	Workspace   string
+	Title       string
+	Version     string
+	UpdatedAt   string
+	URL         string
+	Tags        []string
+	Score       float64
+}
+
+// contextEntry describes one memory placed in the bundle.
+type contextEntry struct {
+	ID          string  `json:"id"`
+	WorkspaceID string  `json:"workspace_id"`
+	Title       string  `json:"title"`
+	Score       float64 `json:"score"`
+	Tokens      int     `json:"tokens"`
+	Truncated   bool    `json:"truncated"`
+}
+
+var contextCmd = &cobra.Command{
+	Use:   "context <query>",
+	Short: "Pack the memories most relevant to a query into one bundle",
+	Long: `Search memories, rank and deduplicate the hits, fetch their content and
+emit a single Markdown or XML bundle with a citation header per memory. The
+bundle is trimmed to fit --budget tokens, estimated locally.
+
+Use jq -r .data.bundle to get the bundle text.`,
+	Example: `  recuerd0 context "authentication" --budget 8000
+  recuerd0 context "database schema" --workspace 22 --format xml --budget 4000`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := requireAuth(); err != nil {
+			exitWithError(err)
+			return
+		}
+		query := strings.TrimSpace(args[0])
+		if err := validateSearchQuery(query); err != nil {
+			exitWithError(err)
+			return
+		}
+		if contextBudget <= 0 {
+			exitWithError(errors.NewInvalidArgsError("--budget must be a positive number of tokens"))
+			return
+		}
+		if contextLimit <= 0 {
+			exitWithError(errors.NewInvalidArgsError("--limit must be positive"))
+			return
+		}
+		if contextFormat != "markdown" && contextFormat != "xml" {
+			exitWithError(errors.NewInvalidArgsError(fmt.Sprintf("--format must be markdown or xml, got %q", contextFormat)))
+			return
+		}
+
+		params := url.Values{}
+		params.Set("q", query)
+		if contextWorkspace != "" {
+			ws, err := lookupWorkspaceID(contextWorkspace)
+			if err != nil {
+				exitWithError(err)
+				return
+			}
+			params.Set("workspace_id", ws)
+		}
+
+		apiClient := getClient()
+		hits, err := searchContextHits(apiClient, "/search?"+params.Encode(), query, contextLimit)
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+
+		bundle := newContextBundle(contextFormat, query, contextBudget)
+		omitted := []string{}
+		seen := map[[32]byte]bool{}
+		for _, hit := range hits {
+			body, err := fetchMemoryBody(apiClient, hit)
+			if err != nil {
+				if cliErr, ok := err.(*errors.CLIError); err == errUndecryptable || ok && cliErr.Code == errors.CodeNotFound {
+					omitted = append(omitted, hit.ID)
+					continue
+				}
+				exitWithError(err)
+				return
+			}
+			sum := sha256.Sum256([]byte(strings.TrimSpace(body)))
+			if seen[sum] {
+				continue
+			}
+			seen[sum] = true
+			if !bundle.add(hit, body) {
+				omitted = append(omitted, hit.ID)
+			}
+		}
+
+		text := bundle.String()
+		tokens := estimateTokens(text)
+		data := map[string]interface{}{
+			"query":    query,
+			"format":   contextFormat,
+			"budget":   contextBudget,
+			"tokens":   tokens,
+			"memories": bundle.entries,
+			"omitted":  omitted,
+			"bundle":   text,
+		}
+		summary := fmt.Sprintf("%d memory(ies) in ~%d of %d tokens", len(bundle.entries), tokens, contextBudget)
+
+		bc := []response.Breadcrumb{
+			breadcrumb("search", fmt.Sprintf("recuerd0 search %q", query), "See all search hits"),
+		}
+		if len(omitted) > 0 {
+			bc = append(bc, breadcrumb("budget", fmt.Sprintf("recuerd0 context %q --budget %d", query, contextBudget*2), "Include more memories"))
+		}
+
+		printSuccessWithBreadcrumbs(data, summary, bc)
+	},
+}
+
+// searchContextHits runs the search, following pages until limit unique
+// memories are found, and returns them ranked.
+func searchContextHits(apiClient client.API, path, query string, limit int) ([]contextHit, error) {
+	terms := queryTerms(query)
+	var hits []contextHit
+	seen := map[string]bool{}
+	position := 0
+	pages := newPageIterator(apiClient.GetWithPagination, path, maxListPages)
+	for len(hits) < limit {
+		resp, err := pages.Next()
+		if err != nil {
+			return nil, err
+		}
+		if resp == nil {
+			break
+		}
+		for _, item := range pageItems(resp.Data) {
+			m, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			hit := contextHit{ID: stringID(m["id"]), Version: stringID(m["version"])}
+			hit.Title, _ = m["title"].(string)
+			hit.UpdatedAt, _ = m["updated_at"].(string)
+			hit.URL, _ = m["url"].(string)
+			if ws, ok := m["workspace"].(map[string]interface{}); ok {
+				hit.WorkspaceID = stringID(ws["id"])
+				hit.Workspace, _ = ws["name"].(string)
+			}
+			if tags, ok := m["tags"].([]interface{}); ok {
+				for _, t := range tags {
+					hit.Tags = append(hit.Tags, fmt.Sprint(t))
+				}
+			}
+			key := hit.WorkspaceID + "/" + hit.ID
+			if hit.ID == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			snippet, _ := m["snippet"].(string)
+			hit.Score = scoreContextHit(hit, snippet, terms, position)
+			position++
+			hits = append(hits, hit)
+			if len(hits) == limit {
+				break
+			}
+		}
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].Score > hits[j].Score })
+	return hits, nil
+}
+
+// queryTerms extracts the lower-cased words of a search query, dropping
+// operators and column prefixes.
+func queryTerms(query string) []string {
+	var terms []string
+	for _, w := range strings.FieldsFunc(query, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != ':'
+	}) {
+		if i := strings.LastIndex(w, ":"); i >= 0 {
+			w = w[i+1:]
+		}
+		switch w {
+		case "", "AND", "OR", "NOT", "NEAR":
+			continue
+		}
+		terms = append(terms, strings.ToLower(w))
+	}
+	return terms
+}
+
+// scoreContextHit ranks a hit by the server's order, boosted by query terms
+// found in its title, tags and snippet.
+func scoreContextHit(hit contextHit, snippet string, terms []string, position int) float64 {
+	score := 1 / float64(position+1)
+	title := strings.ToLower(hit.Title)
+	snippet = strings.ToLower(snippet)
+	for _, term := range terms {
+		if strings.Contains(title, term) {
+			score += 2
+		}
+		for _, tag := range hit.Tags {
+			if strings.EqualFold(tag, term) {
+				score += 1.5
+			}
+		}
+		if strings.Contains(snippet, term) {
+			score += 0.5
+		}
+	}
+	return score
+}
+
+func fetchMemoryBody(apiClient client.API, hit contextHit) (string, error) {
+	resp, err := apiClient.Get(fmt.Sprintf("/workspaces/%s/memories/%s", hit.WorkspaceID, hit.ID))
+	if err != nil {
+		return "", err
+	}
+	m, _ := resp.Data.(map[string]interface{})
+	body := memoryBody(m)
+	if crypt.IsEncrypted(body) {
+		plaintext, _, err := decryptBody(body)
+		if err != nil {
+			return "", errUndecryptable
+		}
+		indexMemory(hit.WorkspaceID, hit.ID, hit.Title, plaintext)
+		return plaintext, nil
+	}
+	return body, nil
+}
+
+// errUndecryptable marks an encrypted memory none of the local keys can
+// open; it is left out of the bundle.
+var errUndecryptable = errors.NewError("no local key can decrypt this memory")
+
+// estimateTokens approximates an LLM token count without a vocabulary: about
+// four characters per token for prose, at least 4/3 tokens per word for
+// code and short words.
+func estimateTokens(s string) int {
+	chars := utf8.RuneCountInString(s)
+	words := len(strings.Fields(s))
+	byChars := (chars + 3) / 4
+	byWords := (words*4 + 2) / 3
+	if byWords > byChars {
+		return byWords
+	}
+	return byChars
+}
+
+// truncateToTokens cuts s at a line or word boundary so it fits max tokens.
+func truncateToTokens(s string, max int) string {
+	runes := []rune(s)
+	if n := max * 4; n < len(runes) {
+		runes = runes[:n]
+	}
+	for len(runes) > 0 && estimateTokens(string(runes)) > max {
+		runes = runes[:len(runes)*9/10]
+	}
+	out := string(runes)
+	if i := strings.LastIndexAny(out, "\n "); i > len(out)/2 {
+		out = out[:i]
+	}
+	return strings.TrimRight(out, " \n")
+}
+
+// contextBundle accumulates memories within a token budget.
+type contextBundle struct {
+	format  string
+	query   string
+	budget  int
+	parts   []string
+	used    int
+	full    bool
+	entries []contextEntry
+}
+
+func newContextBundle(format, query string, budget int) *contextBundle {
+	b := &contextBundle{format: format, query: query, budget: budget, entries: []contextEntry{}}
+	b.used = estimateTokens(b.header() + b.footer())
+	return b
+}
+
+func (b *contextBundle) header() string {
+	if b.format == "xml" {
+		return fmt.Sprintf("<context query=\"%s\">\n", xmlEscape(b.query))
+	}
+	return fmt.Sprintf("# Context for %q\n\n", b.query)
+}
+
+func (b *contextBundle) footer() string {
+	if b.format == "xml" {
+		return "</context>\n"
+	}
+	return ""
+}
+
+func (b *contextBundle) citation(hit contextHit, n int) string {
+	if b.format == "xml" {
+		return fmt.Sprintf("<memory n=\"%d\" id=\"%s\" workspace_id=\"%s\" workspace=\"%s\" title=\"%s\" version=\"%s\" updated_at=\"%s\" url=\"%s\">\n",
+			n, xmlEscape(hit.ID), xmlEscape(hit.WorkspaceID), xmlEscape(hit.Workspace), xmlEscape(hit.Title),
+			xmlEscape(hit.Version), xmlEscape(hit.UpdatedAt), xmlEscape(hit.URL))
+	}
+	return fmt.Sprintf("## [%d] %s\n\n> Source: %s (workspace %s), memory %s v%s, updated %s\n> %s\n\n",
+		n, hit.Title, hit.Workspace, hit.WorkspaceID, hit.ID, hit.Version, hit.UpdatedAt, hit.URL)
+}
+
+func (b *contextBundle) wrap(head, body string, truncated bool) string {
+	if truncated {
+		body += "\n\n[truncated]"
+	}
+	if b.format == "xml" {
+		return head + xmlText(body) + "\n</memory>\n"
+	}
+	return head + body + "\n\n"
+}
+
+// add places a memory in the bundle, trimming it if only part of it fits.
+// It returns false when the memory was left out.
+func (b *contextBundle) add(hit contextHit, body string) bool {
+	if b.full {
+		return false
+	}
+	head := b.citation(hit, len(b.entries)+1)
+	body = strings.TrimSpace(body)
+	chunk := b.wrap(head, body, false)
+	cost := estimateTokens(chunk)
+	truncated := false
+
+	if b.used+cost > b.budget {
+		room := b.budget - b.used - estimateTokens(b.wrap(head, "", true))
+		if room < minContextChunk {
+			b.full = true
+			return false
+		}
+		chunk = b.wrap(head, truncateToTokens(body, room), true)
+		cost = estimateTokens(chunk)
+		truncated = true
+		b.full = true
+	}
+
+	b.parts = append(b.parts, chunk)
+	b.used += cost
+	b.entries = append(b.entries, contextEntry{
+		ID:          hit.ID,
+		WorkspaceID: hit.WorkspaceID,
+		Title:       hit.Title,
+		Score:       hit.Score,
+		Tokens:      cost,
+		Truncated:   truncated,
+	})
+	return true
+}
+
+func (b *contextBundle) String() string {
+	return b.header() + strings.Join(b.parts, "") + b.footer()
+}
+
+// xmlEscape escapes s for an attribute value.
+func xmlEscape(s string) string {
+	var sb strings.Builder
+	_ = xml.EscapeText(&sb, []byte(s))
+	return sb.String()
+}
+
+// xmlText escapes s for element content. Newlines and tabs are kept as they
+// are, where xml.EscapeText would write character references, so memory
+// bodies still read as written.
+func xmlText(s string) string {
+	var sb strings.Builder
+	for {
+		i := strings.IndexAny(s, "\n\t")
+		if i < 0 {
+			break
+		}
+		_ = xml.EscapeText(&sb, []byte(s[:i]))
+		sb.WriteByte(s[i])
+		s = s[i+1:]
+	}
+	_ = xml.EscapeText(&sb, []byte(s))
+	return sb.String()
+}
+
+func init() {
+	contextCmd.Flags().IntVar(&contextBudget, "budget", 8000, "maximum tokens in the bundle")
+	contextCmd.Flags().StringVar(&contextFormat, "format", "markdown", "bundle format: markdown or xml")
+	contextCmd.Flags().StringVar(&contextWorkspace, "workspace", "", "limit to workspace (ID, name or alias)")
+	contextCmd.Flags().IntVar(&contextLimit, "limit", 10, "maximum number of memories to consider")
+	rootCmd.AddCommand(contextCmd)
+}
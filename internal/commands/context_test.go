@@ -0,0 +1,169 @@
+package commands
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/maquina/recuerd0-cli/internal/client"
+	"github.com/maquina/recuerd0-cli/internal/errors"
+)
+
+func contextSearchResponse() *client.APIResponse {
+	ws := map[string]interface{}{"id": float64(3), "name": "Project"}
+	return &client.APIResponse{
+		StatusCode: 200,
+		Data: map[string]interface{}{
+			"results": []interface{}{
+				map[string]interface{}{"id": float64(1), "title": "Standup notes", "version": float64(1), "snippet": "misc", "workspace": ws},
+				map[string]interface{}{"id": float64(2), "title": "Auth architecture", "version": float64(2), "snippet": "auth tokens", "workspace": ws},
+				map[string]interface{}{"id": float64(2), "title": "Auth architecture", "version": float64(2), "workspace": ws},
+				map[string]interface{}{"id": float64(4), "title": "Auth copy", "version": float64(1), "workspace": ws},
+			},
+		},
+	}
+}
+
+func memoryWithBody(body string) *client.APIResponse {
+	return &client.APIResponse{StatusCode: 200, Data: map[string]interface{}{
+		"content": map[string]interface{}{"body": body},
+	}}
+}
+
+func setupContextTest(t *testing.T, budget int) (*MockClient, *CommandResult) {
+	t.Helper()
+	mock := NewMockClient()
+	mock.GetResponses = map[string]*client.APIResponse{
+		"/search?q=auth":           contextSearchResponse(),
+		"/workspaces/3/memories/1": memoryWithBody("Daily standup."),
+		"/workspaces/3/memories/2": memoryWithBody("We use **JWT** tokens <signed> & rotated."),
+		"/workspaces/3/memories/4": memoryWithBody("We use **JWT** tokens <signed> & rotated."),
+	}
+	result := SetTestMode(mock)
+	SetTestConfig("tok_test", "https://api.example.com")
+
+	contextBudget, contextFormat, contextWorkspace, contextLimit = budget, "markdown", "", 10
+	t.Cleanup(func() {
+		ResetTestMode()
+		contextBudget, contextFormat, contextWorkspace, contextLimit = 8000, "markdown", "", 10
+	})
+	return mock, result
+}
+
+func TestContext_RanksAndDeduplicates(t *testing.T) {
+	mock, result := setupContextTest(t, 8000)
+
+	RunTestCommand(func() {
+		contextCmd.Run(contextCmd, []string{"auth"})
+	})
+
+	if result.ExitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", result.ExitCode)
+	}
+	data := result.Response.Data.(map[string]interface{})
+	entries := data["memories"].([]contextEntry)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 memories after dedup, got %d", len(entries))
+	}
+	if entries[0].ID != "2" {
+		t.Errorf("expected title match ranked first, got %s", entries[0].ID)
+	}
+	bundle := data["bundle"].(string)
+	if !strings.Contains(bundle, "## [1] Auth architecture") || !strings.Contains(bundle, "memory 2 v2") {
+		t.Errorf("missing citation header:\n%s", bundle)
+	}
+	// Memory 2 is listed twice in results and memory 4 has identical content.
+	if len(mock.GetCalls) != 4 {
+		t.Errorf("expected 4 requests, got %d", len(mock.GetCalls))
+	}
+}
+
+func TestContext_XML(t *testing.T) {
+	_, result := setupContextTest(t, 8000)
+	contextFormat = "xml"
+
+	RunTestCommand(func() {
+		contextCmd.Run(contextCmd, []string{"auth"})
+	})
+
+	bundle := result.Response.Data.(map[string]interface{})["bundle"].(string)
+	if !strings.HasPrefix(bundle, `<context query="auth">`) || !strings.HasSuffix(bundle, "</context>\n") {
+		t.Errorf("unexpected bundle:\n%s", bundle)
+	}
+	if !strings.Contains(bundle, "&lt;signed&gt; &amp; rotated") {
+		t.Errorf("expected escaped content:\n%s", bundle)
+	}
+	dec := xml.NewDecoder(strings.NewReader(bundle))
+	for {
+		if _, err := dec.Token(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("bundle is not well-formed XML: %v\n%s", err, bundle)
+		}
+	}
+}
+
+func TestContext_XMLEscape(t *testing.T) {
+	if got := xmlEscape(`a "b" <c> & 'd'` + "\n"); got != "a &#34;b&#34; &lt;c&gt; &amp; &#39;d&#39;&#xA;" {
+		t.Errorf("xmlEscape = %q", got)
+	}
+	if got := xmlText("if a < b {\n\treturn a & b\n}"); got != "if a &lt; b {\n\treturn a &amp; b\n}" {
+		t.Errorf("xmlText = %q", got)
+	}
+}
+
+func TestContext_TrimsToBudget(t *testing.T) {
+	mock, result := setupContextTest(t, 120)
+	mock.GetResponses["/workspaces/3/memories/2"] = memoryWithBody(strings.Repeat("token rotation policy details ", 200))
+
+	RunTestCommand(func() {
+		contextCmd.Run(contextCmd, []string{"auth"})
+	})
+
+	data := result.Response.Data.(map[string]interface{})
+	if tokens := data["tokens"].(int); tokens > 120 {
+		t.Errorf("bundle exceeds budget: %d tokens", tokens)
+	}
+	entries := data["memories"].([]contextEntry)
+	if len(entries) != 1 || !entries[0].Truncated {
+		t.Fatalf("expected one truncated memory, got %+v", entries)
+	}
+	if omitted := data["omitted"].([]string); len(omitted) == 0 {
+		t.Error("expected lower-ranked memories to be omitted")
+	}
+}
+
+func TestContext_InvalidArgs(t *testing.T) {
+	mock, result := setupContextTest(t, 0)
+
+	RunTestCommand(func() {
+		contextCmd.Run(contextCmd, []string{"auth"})
+	})
+
+	if result.ExitCode != errors.ExitInvalidArgs {
+		t.Errorf("expected exit code %d, got %d", errors.ExitInvalidArgs, result.ExitCode)
+	}
+	if len(mock.GetCalls) != 0 {
+		t.Errorf("expected no API calls, got %d", len(mock.GetCalls))
+	}
+}
+
+func TestEstimateTokens(t *testing.T) {
+	if got := estimateTokens(""); got != 0 {
+		t.Errorf("expected 0, got %d", got)
+	}
+	if got := estimateTokens("abcdefgh"); got != 2 {
+		t.Errorf("expected 2 for 8 chars, got %d", got)
+	}
+	if got := estimateTokens("a b c d e f"); got != 8 {
+		t.Errorf("expected word-based estimate 8, got %d", got)
+	}
+}
+
+func TestQueryTerms(t *testing.T) {
+	got := strings.Join(queryTerms(`title:Auth AND ("jwt tokens" OR NOT x)`), ",")
+	if got != "auth,jwt,tokens,x" {
+		t.Errorf("unexpected terms: %s", got)
+	}
+}
@@ -0,0 +1,70 @@
+package commands
+
+import "strings"
+
+// applyProjectDefaults merges the project defaults from .recuerd0.yaml into
+// the fields of a memory or version request and returns what was applied,
+// or nil if nothing was.
+//
+// Default tags are added to any tags being sent and the title prefix is
+// prepended to any title being sent. When fillMissing is set, default tags
+// and source are also used for fields the caller left empty. Only create
+// sets it: update and version create leave omitted fields alone so stored or
+// inherited values are never overwritten.
+func applyProjectDefaults(fields map[string]interface{}, fillMissing bool) map[string]interface{} {
+	if cfg == nil {
+		return nil
+	}
+	defaults := cfg.Defaults
+	applied := map[string]interface{}{}
+
+	if len(defaults.Tags) > 0 {
+		tags, hasTags := fields["tags"].([]string)
+		if hasTags || fillMissing {
+			merged, added := mergeTags(tags, defaults.Tags)
+			if len(added) > 0 {
+				fields["tags"] = merged
+				applied["tags"] = added
+			}
+		}
+	}
+
+	if defaults.Source != "" && fillMissing {
+		if _, ok := fields["source"]; !ok {
+			fields["source"] = defaults.Source
+			applied["source"] = defaults.Source
+		}
+	}
+
+	if defaults.TitlePrefix != "" {
+		if title, ok := fields["title"].(string); ok && !strings.HasPrefix(title, defaults.TitlePrefix) {
+			fields["title"] = defaults.TitlePrefix + title
+			applied["title_prefix"] = defaults.TitlePrefix
+		}
+	}
+
+	if len(applied) == 0 {
+		return nil
+	}
+	return applied
+}
+
+// mergeTags appends extra tags not already present (case-insensitively) and
+// returns the merged list along with the tags that were added.
+func mergeTags(tags, extra []string) ([]string, []string) {
+	seen := make(map[string]bool, len(tags))
+	merged := make([]string, 0, len(tags)+len(extra))
+	for _, t := range tags {
+		seen[strings.ToLower(t)] = true
+		merged = append(merged, t)
+	}
+	var added []string
+	for _, t := range extra {
+		if !seen[strings.ToLower(t)] {
+			seen[strings.ToLower(t)] = true
+			merged = append(merged, t)
+			added = append(added, t)
+		}
+	}
+	return merged, added
+}
@@ -0,0 +1,389 @@
+package commands
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maquina/recuerd0-cli/internal/client"
+	"github.com/maquina/recuerd0-cli/internal/config"
+	"github.com/maquina/recuerd0-cli/internal/errors"
+	"github.com/maquina/recuerd0-cli/internal/response"
+)
+
+// Check statuses reported by doctor.
+const (
+	checkPass = "pass"
+	checkWarn = "warn"
+	checkFail = "fail"
+)
+
+const (
+	clockSkewWarn     = 30 * time.Second
+	clockSkewFail     = 5 * time.Minute
+	certExpiryWarn    = 14 * 24 * time.Hour
+	rateLimitLowPct   = 10
+	doctorDialTimeout = 10 * time.Second
+)
+
+// doctorCheck is a single diagnostic result.
+type doctorCheck struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	Fix     string `json:"fix,omitempty"`
+}
+
+// doctorLookupHost resolves a hostname, overridable for tests.
+var doctorLookupHost = net.LookupHost
+
+// doctorTLSProbe performs a TLS handshake and returns the leaf certificate
+// expiry, overridable for tests.
+var doctorTLSProbe = func(addr string, tlsConfig *tls.Config) (time.Time, error) {
+	dialer := &net.Dialer{Timeout: doctorDialTimeout}
+	conn, err := tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer conn.Close()
+	certs := conn.ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return time.Time{}, fmt.Errorf("server presented no certificates")
+	}
+	return certs[0].NotAfter, nil
+}
+
+// doctorNow returns the local clock, overridable for tests.
+var doctorNow = time.Now
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose configuration, connectivity and permission problems",
+	Long: `Check the config files, the API URL, DNS, TLS, the token, the clock and
+the configured workspaces, and suggest a fix for each problem found.
+
+Exits 0 when no check failed, even with warnings. When a check fails the
+command fails with exit code 1 and the checks are listed in meta.doctor.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var checks []doctorCheck
+		add := func(name, status, message, fix string) {
+			checks = append(checks, doctorCheck{Name: name, Status: status, Message: message, Fix: fix})
+		}
+
+		// Global config
+		globalPath := config.GlobalConfigPath()
+		global, err := config.LoadGlobal()
+		switch {
+		case err != nil:
+			add("global_config", checkFail, err.Error(),
+				fmt.Sprintf("Fix the YAML syntax in %s, or move it aside and run: recuerd0 account add <name> --token TOKEN", globalPath))
+		case len(global.Accounts) == 0:
+			add("global_config", checkWarn, fmt.Sprintf("%s has no accounts", globalPath),
+				"Run: recuerd0 account add <name> --token TOKEN")
+		default:
+			add("global_config", checkPass, fmt.Sprintf("%s parsed, %d account(s)", globalPath, len(global.Accounts)), "")
+		}
+
+		// Global config permissions
+		if info, statErr := os.Stat(globalPath); statErr == nil {
+			perm := info.Mode().Perm()
+			switch {
+			case runtime.GOOS == "windows":
+				add("config_permissions", checkPass, "permission bits are not checked on Windows", "")
+			case perm&0077 != 0:
+				add("config_permissions", checkFail, fmt.Sprintf("%s is %04o and readable by other users", globalPath, perm),
+					fmt.Sprintf("Run: chmod 600 %s", globalPath))
+			case perm != 0600:
+				add("config_permissions", checkWarn, fmt.Sprintf("%s is %04o, expected 0600", globalPath, perm),
+					fmt.Sprintf("Run: chmod 600 %s", globalPath))
+			default:
+				add("config_permissions", checkPass, fmt.Sprintf("%s is 0600", globalPath), "")
+			}
+		}
+
+		// Local config
+		cwd, _ := os.Getwd()
+		localPath := config.FindLocalPath(cwd)
+		local, localErr := config.FindLocal(cwd)
+		switch {
+		case localPath == "":
+			add("local_config", checkPass, "no .recuerd0.yaml found", "")
+		case localErr != nil:
+			add("local_config", checkFail, localErr.Error(),
+				fmt.Sprintf("Fix the YAML syntax in %s", localPath))
+		default:
+			add("local_config", checkPass, fmt.Sprintf("%s parsed", localPath), "")
+			if local.Account != "" && global != nil {
+				if _, ok := global.Accounts[local.Account]; ok {
+					add("local_account", checkPass, fmt.Sprintf("account %q exists", local.Account), "")
+				} else {
+					add("local_account", checkFail, fmt.Sprintf("%s refers to unknown account %q", localPath, local.Account),
+						fmt.Sprintf("Run: recuerd0 account add %s --token TOKEN, or change account in %s", local.Account, localPath))
+				}
+			}
+		}
+
+		// Resolved connection settings
+		resolved, err := config.Resolve(config.ResolvedConfig{
+			Account:   cfgAccount,
+			Token:     cfgToken,
+			APIURL:    cfgAPIURL,
+			Workspace: cfgWorkspace,
+		})
+		if err != nil {
+			printDoctorResult(checks)
+			return
+		}
+
+		apiURL, err := url.Parse(resolved.APIURL)
+		if err != nil || apiURL.Host == "" {
+			add("api_url", checkFail, fmt.Sprintf("invalid api_url %q", resolved.APIURL),
+				"Set a full URL such as https://recuerd0.ai with: recuerd0 account add <name> --token TOKEN --api-url URL")
+			printDoctorResult(checks)
+			return
+		}
+		host := apiURL.Hostname()
+		if apiURL.Scheme == "http" && host != "localhost" && host != "127.0.0.1" {
+			add("api_url", checkWarn, fmt.Sprintf("%s uses plain HTTP; the token is sent unencrypted", resolved.APIURL),
+				"Use an https:// api_url")
+		} else {
+			add("api_url", checkPass, resolved.APIURL, "")
+		}
+
+		// DNS
+		addrs, err := doctorLookupHost(host)
+		if err != nil {
+			add("dns", checkFail, fmt.Sprintf("cannot resolve %s: %v", host, err),
+				"Check your network connection, VPN and DNS settings")
+			printDoctorResult(checks)
+			return
+		}
+		add("dns", checkPass, fmt.Sprintf("%s resolves to %d address(es)", host, len(addrs)), "")
+
+		// TLS
+		opts, optsErr := clientOptions(resolved.Transport)
+		var tlsConfig *tls.Config
+		if optsErr == nil {
+			tlsConfig, optsErr = client.TLSConfig(opts)
+		}
+		if optsErr != nil {
+			add("transport", checkFail, optsErr.Error(),
+				fmt.Sprintf("Fix the transport settings of account %q with: recuerd0 config set accounts.%s.KEY VALUE", resolved.Account, resolved.Account))
+			printDoctorResult(checks)
+			return
+		}
+		if resolved.Transport.InsecureSkipVerify {
+			add("transport", checkWarn, "insecure_skip_verify is enabled; server certificates are not verified",
+				fmt.Sprintf("Run: recuerd0 config unset accounts.%s.insecure_skip_verify", resolved.Account))
+		}
+
+		switch {
+		case apiURL.Scheme != "https":
+		case resolved.Transport.Proxy != "":
+			add("tls", checkPass, fmt.Sprintf("not probed directly; requests go through proxy %s", resolved.Transport.Proxy), "")
+		default:
+			port := apiURL.Port()
+			if port == "" {
+				port = "443"
+			}
+			tlsConfig.ServerName = host
+			notAfter, err := doctorTLSProbe(net.JoinHostPort(host, port), tlsConfig)
+			switch {
+			case err != nil:
+				add("tls", checkFail, fmt.Sprintf("TLS handshake with %s failed: %v", host, err),
+					fmt.Sprintf("Check for an intercepting proxy, or point accounts.%s.ca_cert at your CA bundle", resolved.Account))
+				printDoctorResult(checks)
+				return
+			case notAfter.Sub(doctorNow()) < certExpiryWarn:
+				add("tls", checkWarn, fmt.Sprintf("certificate for %s expires %s", host, notAfter.UTC().Format(time.RFC3339)),
+					"Contact the server administrator to renew the certificate")
+			default:
+				add("tls", checkPass, fmt.Sprintf("certificate valid until %s", notAfter.UTC().Format(time.RFC3339)), "")
+			}
+		}
+
+		// Token
+		if resolved.Token == "" {
+			add("token", checkFail, "no API token configured",
+				"Run: recuerd0 account add <name> --token TOKEN, or set RECUERD0_TOKEN")
+			printDoctorResult(checks)
+			return
+		}
+
+		cfg = resolved
+		apiClient := getClient()
+		sent := doctorNow()
+		resp, err := apiClient.Get("/workspaces")
+		if err != nil {
+			cliErr, _ := err.(*errors.CLIError)
+			switch {
+			case cliErr != nil && cliErr.Code == errors.CodeAuth:
+				add("token", checkFail, "token was rejected by the server",
+					"Create a new token and run: recuerd0 account add <name> --token TOKEN")
+			case cliErr != nil && cliErr.Code == errors.CodeForbidden:
+				add("token", checkFail, "token is not allowed to list workspaces",
+					"Create a token with read_only or full_access permission")
+			case cliErr != nil && cliErr.Code == errors.CodeRateLimited:
+				add("token", checkWarn, "token is valid but currently rate limited", "Wait a minute and retry")
+				add("rate_limit", checkFail, "rate limit exhausted",
+					"Reduce request volume; the API allows 100 requests per minute per token")
+			default:
+				add("token", checkFail, fmt.Sprintf("request failed: %v", err),
+					"Re-run with --verbose to see the HTTP exchange")
+			}
+			printDoctorResult(checks)
+			return
+		}
+		add("token", checkPass, fmt.Sprintf("token accepted for account %q", resolved.Account), "")
+
+		checks = append(checks, clockSkewCheck(resp, sent))
+		checks = append(checks, rateLimitCheck(resp))
+
+		// Workspace: the one in .recuerd0.yaml, and the one in effect when
+		// --workspace or RECUERD0_WORKSPACE overrides it.
+		checkWorkspace := func(name, ws, source, fix string) {
+			id, err := lookupWorkspaceID(ws)
+			if err == nil {
+				_, err = apiClient.Get("/workspaces/" + id)
+			}
+			if err != nil {
+				add(name, checkFail, fmt.Sprintf("workspace %s from %s is not accessible: %v", ws, source, err), fix)
+			} else {
+				add(name, checkPass, fmt.Sprintf("workspace %s from %s exists (id %s)", ws, source, id), "")
+			}
+		}
+		localWorkspace := ""
+		if local != nil {
+			localWorkspace = local.Workspace
+		}
+		if localWorkspace != "" {
+			checkWorkspace("local_workspace", localWorkspace, localPath,
+				"Run: recuerd0 workspace list, then update the workspace key in "+localPath)
+		}
+		if resolved.Workspace != "" && resolved.Workspace != localWorkspace {
+			checkWorkspace("workspace", resolved.Workspace, "--workspace or RECUERD0_WORKSPACE",
+				"Run: recuerd0 workspace list, then update --workspace or RECUERD0_WORKSPACE")
+		}
+
+		printDoctorResult(checks)
+	},
+}
+
+// clockSkewCheck compares the server Date header against the local clock.
+func clockSkewCheck(resp *client.APIResponse, sent time.Time) doctorCheck {
+	check := doctorCheck{Name: "clock_skew"}
+	var date string
+	if resp.Header != nil {
+		date = resp.Header.Get("Date")
+	}
+	serverTime, err := http.ParseTime(date)
+	if date == "" || err != nil {
+		check.Status = checkWarn
+		check.Message = "server did not send a usable Date header"
+		return check
+	}
+
+	skew := serverTime.Sub(sent)
+	if skew < 0 {
+		skew = -skew
+	}
+	skew = skew.Truncate(time.Second)
+	switch {
+	case skew >= clockSkewFail:
+		check.Status = checkFail
+		check.Message = fmt.Sprintf("local clock differs from server by %s", skew)
+		check.Fix = "Enable NTP time synchronization on this machine"
+	case skew >= clockSkewWarn:
+		check.Status = checkWarn
+		check.Message = fmt.Sprintf("local clock differs from server by %s", skew)
+		check.Fix = "Enable NTP time synchronization on this machine"
+	default:
+		check.Status = checkPass
+		check.Message = fmt.Sprintf("local clock within %s of server", clockSkewWarn)
+	}
+	return check
+}
+
+// rateLimitCheck reports the remaining request budget when the server exposes it.
+func rateLimitCheck(resp *client.APIResponse) doctorCheck {
+	check := doctorCheck{Name: "rate_limit", Status: checkPass}
+	remaining, limit := -1, -1
+	if resp.Header != nil {
+		for _, prefix := range []string{"X-RateLimit-", "RateLimit-"} {
+			if v, err := strconv.Atoi(resp.Header.Get(prefix + "Remaining")); err == nil {
+				remaining = v
+				if l, err := strconv.Atoi(resp.Header.Get(prefix + "Limit")); err == nil {
+					limit = l
+				}
+				break
+			}
+		}
+	}
+
+	switch {
+	case remaining < 0:
+		check.Message = "server does not report rate limit headers; the limit is 100 requests per minute per token"
+	case limit > 0 && remaining*100 < limit*rateLimitLowPct:
+		check.Status = checkWarn
+		check.Message = fmt.Sprintf("%d of %d requests remaining in the current window", remaining, limit)
+		check.Fix = "Reduce request volume or wait for the window to reset"
+	case limit > 0:
+		check.Message = fmt.Sprintf("%d of %d requests remaining in the current window", remaining, limit)
+	default:
+		check.Message = fmt.Sprintf("%d requests remaining in the current window", remaining)
+	}
+	return check
+}
+
+// printDoctorResult prints the checks, failing with exit code 1 when any
+// of them failed.
+func printDoctorResult(checks []doctorCheck) {
+	var passed, warned, failed int
+	var failedNames []string
+	for _, c := range checks {
+		switch c.Status {
+		case checkPass:
+			passed++
+		case checkWarn:
+			warned++
+		case checkFail:
+			failed++
+			failedNames = append(failedNames, c.Name)
+		}
+	}
+
+	data := map[string]interface{}{
+		"checks":   checks,
+		"passed":   passed,
+		"warnings": warned,
+		"failed":   failed,
+	}
+	summary := fmt.Sprintf("%d passed, %d warning(s), %d failed", passed, warned, failed)
+
+	bc := []response.Breadcrumb{
+		breadcrumb("accounts", "recuerd0 account list", "List configured accounts"),
+		breadcrumb("verbose", "recuerd0 --verbose workspace list", "Show the HTTP exchange for a simple request"),
+	}
+
+	if failed == 0 {
+		printSuccessWithBreadcrumbs(data, summary, bc)
+		return
+	}
+	// A failed check fails the command, so scripts can gate on doctor; the
+	// checks are kept in meta.doctor.
+	setMeta("doctor", data)
+	exitWithError(errors.NewError(fmt.Sprintf("%d doctor check(s) failed: %s", failed, strings.Join(failedNames, ", "))))
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
@@ -0,0 +1,252 @@
+package commands
+
+import (
+	"crypto/tls"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maquina/recuerd0-cli/internal/client"
+	"github.com/maquina/recuerd0-cli/internal/config"
+	"github.com/maquina/recuerd0-cli/internal/errors"
+)
+
+func setupDoctorTest(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	config.SetConfigDir(dir)
+	t.Setenv("RECUERD0_ACCOUNT", "")
+	t.Setenv("RECUERD0_TOKEN", "")
+	t.Setenv("RECUERD0_API_URL", "")
+	t.Setenv("RECUERD0_WORKSPACE", "")
+
+	origLookup, origTLS, origNow := doctorLookupHost, doctorTLSProbe, doctorNow
+	doctorLookupHost = func(host string) ([]string, error) { return []string{"127.0.0.1"}, nil }
+	doctorTLSProbe = func(addr string, tlsConfig *tls.Config) (time.Time, error) {
+		return time.Now().Add(90 * 24 * time.Hour), nil
+	}
+	doctorNow = time.Now
+
+	t.Cleanup(func() {
+		config.SetConfigDir("")
+		doctorLookupHost, doctorTLSProbe, doctorNow = origLookup, origTLS, origNow
+	})
+	return dir
+}
+
+// doctorChecks returns the checks by name, from data when all passed or
+// from meta.doctor when one failed.
+func doctorChecks(t *testing.T, result *CommandResult) map[string]doctorCheck {
+	t.Helper()
+	data, ok := result.Response.Data.(map[string]interface{})
+	if !result.Response.Success {
+		data, ok = result.Response.Meta["doctor"].(map[string]interface{})
+	}
+	if !ok {
+		t.Fatalf("expected the checks, got %+v", result.Response)
+	}
+	checks, ok := data["checks"].([]doctorCheck)
+	if !ok {
+		t.Fatalf("expected []doctorCheck, got %T", data["checks"])
+	}
+	byName := make(map[string]doctorCheck, len(checks))
+	for _, c := range checks {
+		byName[c.Name] = c
+	}
+	return byName
+}
+
+func TestDoctor_AllPass(t *testing.T) {
+	setupDoctorTest(t)
+	_ = config.AddAccount("personal", "tok_a", "")
+
+	mock := NewMockClient()
+	mock.GetResponse = &client.APIResponse{
+		StatusCode: 200,
+		Data:       []interface{}{},
+		Header: http.Header{
+			"Date":                  []string{time.Now().UTC().Format(http.TimeFormat)},
+			"X-Ratelimit-Limit":     []string{"100"},
+			"X-Ratelimit-Remaining": []string{"97"},
+		},
+	}
+	result := SetTestMode(mock)
+	defer ResetTestMode()
+
+	RunTestCommand(func() {
+		doctorCmd.Run(doctorCmd, []string{})
+	})
+
+	if result.ExitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", result.ExitCode)
+	}
+	checks := doctorChecks(t, result)
+	for _, name := range []string{"global_config", "config_permissions", "api_url", "dns", "tls", "token", "clock_skew", "rate_limit"} {
+		c, ok := checks[name]
+		if !ok {
+			t.Errorf("missing check %q", name)
+			continue
+		}
+		if c.Status != checkPass {
+			t.Errorf("check %q: expected pass, got %s (%s)", name, c.Status, c.Message)
+		}
+	}
+}
+
+func TestDoctor_BadPermissions(t *testing.T) {
+	dir := setupDoctorTest(t)
+	_ = config.AddAccount("personal", "tok_a", "")
+	if err := os.Chmod(filepath.Join(dir, "config.yaml"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	mock := NewMockClient()
+	result := SetTestMode(mock)
+	defer ResetTestMode()
+
+	RunTestCommand(func() {
+		doctorCmd.Run(doctorCmd, []string{})
+	})
+
+	c := doctorChecks(t, result)["config_permissions"]
+	if c.Status != checkFail {
+		t.Errorf("expected fail, got %s", c.Status)
+	}
+	if c.Fix == "" {
+		t.Error("expected a suggested fix")
+	}
+}
+
+func TestDoctor_InvalidGlobalConfig(t *testing.T) {
+	dir := setupDoctorTest(t)
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte("accounts: [unclosed"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	mock := NewMockClient()
+	result := SetTestMode(mock)
+	defer ResetTestMode()
+
+	RunTestCommand(func() {
+		doctorCmd.Run(doctorCmd, []string{})
+	})
+
+	if result.ExitCode != errors.ExitError || result.Response.Success {
+		t.Fatalf("expected a failed check to fail doctor, got %d", result.ExitCode)
+	}
+	if msg := result.Response.Error.Message; msg != "1 doctor check(s) failed: global_config" {
+		t.Errorf("unexpected error message %q", msg)
+	}
+	if c := doctorChecks(t, result)["global_config"]; c.Status != checkFail {
+		t.Errorf("expected global_config fail, got %s", c.Status)
+	}
+	if len(mock.GetCalls) != 0 {
+		t.Errorf("expected no API calls, got %d", len(mock.GetCalls))
+	}
+}
+
+func TestDoctor_TokenRejected(t *testing.T) {
+	setupDoctorTest(t)
+	_ = config.AddAccount("personal", "tok_bad", "")
+
+	mock := NewMockClient()
+	mock.GetError = errors.NewAuthError("unauthorized")
+	result := SetTestMode(mock)
+	defer ResetTestMode()
+
+	RunTestCommand(func() {
+		doctorCmd.Run(doctorCmd, []string{})
+	})
+
+	checks := doctorChecks(t, result)
+	if checks["token"].Status != checkFail {
+		t.Errorf("expected token fail, got %s", checks["token"].Status)
+	}
+	if _, ok := checks["clock_skew"]; ok {
+		t.Error("expected clock_skew to be skipped after token failure")
+	}
+}
+
+func TestDoctor_NoToken(t *testing.T) {
+	setupDoctorTest(t)
+
+	mock := NewMockClient()
+	result := SetTestMode(mock)
+	defer ResetTestMode()
+
+	RunTestCommand(func() {
+		doctorCmd.Run(doctorCmd, []string{})
+	})
+
+	if c := doctorChecks(t, result)["token"]; c.Status != checkFail {
+		t.Errorf("expected token fail, got %s", c.Status)
+	}
+}
+
+func TestDoctor_LocalWorkspace(t *testing.T) {
+	setupDoctorTest(t)
+	_ = config.AddAccount("personal", "tok_a", "")
+	dir := t.TempDir()
+	t.Chdir(dir)
+	if err := os.WriteFile(filepath.Join(dir, ".recuerd0.yaml"), []byte("workspace: \"9\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("RECUERD0_WORKSPACE", "5")
+
+	mock := NewMockClient()
+	mock.GetResponse = &client.APIResponse{StatusCode: 200, Data: map[string]interface{}{"id": float64(9)}}
+	result := SetTestMode(mock)
+	defer ResetTestMode()
+
+	RunTestCommand(func() {
+		doctorCmd.Run(doctorCmd, []string{})
+	})
+
+	checks := doctorChecks(t, result)
+	if c := checks["local_workspace"]; c.Status != checkPass || !strings.Contains(c.Message, "workspace 9 from "+filepath.Join(dir, ".recuerd0.yaml")) {
+		t.Errorf("expected the local file's workspace to be checked, got %+v", c)
+	}
+	if c := checks["workspace"]; c.Status != checkPass || !strings.Contains(c.Message, "workspace 5 from") {
+		t.Errorf("expected the overriding workspace to be checked, got %+v", c)
+	}
+}
+
+func TestClockSkewCheck(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name   string
+		offset time.Duration
+		status string
+	}{
+		{"in sync", 0, checkPass},
+		{"slightly off", 2 * time.Minute, checkWarn},
+		{"far off", -10 * time.Minute, checkFail},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &client.APIResponse{Header: http.Header{
+				"Date": []string{now.Add(tt.offset).UTC().Format(http.TimeFormat)},
+			}}
+			if got := clockSkewCheck(resp, now); got.Status != tt.status {
+				t.Errorf("expected %s, got %s (%s)", tt.status, got.Status, got.Message)
+			}
+		})
+	}
+
+	if got := clockSkewCheck(&client.APIResponse{}, now); got.Status != checkWarn {
+		t.Errorf("expected warn without Date header, got %s", got.Status)
+	}
+}
+
+func TestRateLimitCheck_Low(t *testing.T) {
+	resp := &client.APIResponse{Header: http.Header{
+		"Ratelimit-Limit":     []string{"100"},
+		"Ratelimit-Remaining": []string{"3"},
+	}}
+	if got := rateLimitCheck(resp); got.Status != checkWarn {
+		t.Errorf("expected warn, got %s (%s)", got.Status, got.Message)
+	}
+}
@@ -0,0 +1,156 @@
+package commands
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maquina/recuerd0-cli/internal/client"
+	"github.com/maquina/recuerd0-cli/internal/config"
+	"github.com/maquina/recuerd0-cli/internal/response"
+)
+
+// dryRunRequest is a write that --dry-run intercepted instead of sending.
+type dryRunRequest struct {
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+	Body    interface{}       `json:"body,omitempty"`
+}
+
+// dryRunWrite is a change to a local file, such as a key or a trash entry,
+// that --dry-run skipped.
+type dryRunWrite struct {
+	Op   string `json:"op"`
+	What string `json:"what"`
+	File string `json:"file"`
+}
+
+// dryRunRequests and dryRunWrites collect what was intercepted during this
+// run.
+var (
+	dryRunRequests []dryRunRequest
+	dryRunWrites   []dryRunWrite
+	dryRunMu       sync.Mutex
+)
+
+// skipLocalWrite reports whether --dry-run is set, in which case the caller
+// leaves file alone and the write, op being "write" or "remove", is listed
+// in meta.dry_run_local_writes.
+func skipLocalWrite(op, what, file string) bool {
+	if !cfgDryRun {
+		return false
+	}
+	w := dryRunWrite{Op: op, What: what, File: file}
+	dryRunMu.Lock()
+	defer dryRunMu.Unlock()
+	for _, seen := range dryRunWrites {
+		if seen == w {
+			return true
+		}
+	}
+	dryRunWrites = append(dryRunWrites, w)
+	return true
+}
+
+func init() {
+	config.SkipWrites(func(what, path string) bool {
+		return skipLocalWrite("write", what, path)
+	})
+}
+
+// dryRunClient passes reads through so commands can resolve names and
+// inspect current state, and records writes without sending them. Writes
+// return an empty success so multi-step commands carry on and every write
+// they would make is listed.
+type dryRunClient struct {
+	client.API
+}
+
+func (c *dryRunClient) record(method, path string, body interface{}) *client.APIResponse {
+	headers := map[string]string{"Authorization": "Bearer [REDACTED]"}
+	if body != nil {
+		headers["Content-Type"] = "application/json"
+	}
+	url := path
+	if cfg != nil {
+		url = strings.TrimRight(cfg.APIURL, "/") + path
+	}
+	dryRunMu.Lock()
+	defer dryRunMu.Unlock()
+	dryRunRequests = append(dryRunRequests, dryRunRequest{
+		Method:  method,
+		Path:    path,
+		URL:     url,
+		Headers: headers,
+		Body:    body,
+	})
+	status := http.StatusOK
+	switch method {
+	case http.MethodPost:
+		status = http.StatusCreated
+	case http.MethodDelete:
+		status = http.StatusNoContent
+	}
+	return &client.APIResponse{StatusCode: status}
+}
+
+func (c *dryRunClient) Post(path string, body interface{}) (*client.APIResponse, error) {
+	return c.record(http.MethodPost, path, body), nil
+}
+
+func (c *dryRunClient) Patch(path string, body interface{}) (*client.APIResponse, error) {
+	return c.record(http.MethodPatch, path, body), nil
+}
+
+func (c *dryRunClient) Delete(path string) (*client.APIResponse, error) {
+	return c.record(http.MethodDelete, path, nil), nil
+}
+
+// applyDryRun marks resp as a dry run with meta.dry_run and lists the local
+// writes that were skipped in meta.dry_run_local_writes. When requests were intercepted, they replace the
+// command's own data, which would describe results that never happened.
+func applyDryRun(resp *response.Response) {
+	if !cfgDryRun {
+		return
+	}
+	dryRunMu.Lock()
+	defer dryRunMu.Unlock()
+	requests, writes := dryRunRequests, dryRunWrites
+	dryRunRequests, dryRunWrites = nil, nil
+	resp.Meta["dry_run"] = true
+	if len(writes) > 0 {
+		resp.Meta["dry_run_local_writes"] = writes
+	}
+	if !resp.Success {
+		return
+	}
+	switch {
+	case len(requests) > 0:
+		resp.Data = map[string]interface{}{"requests": requests}
+		resp.Pagination = nil
+		resp.Location = ""
+		resp.Summary = fmt.Sprintf("Dry run: %d request(s) not sent", len(requests))
+		resp.Breadcrumbs = []response.Breadcrumb{
+			breadcrumb("apply", commandWithoutDryRun(currentCmd), "Run again without --dry-run to send these requests"),
+		}
+	case len(writes) > 0:
+		resp.Summary = fmt.Sprintf("Dry run: %d local write(s) skipped", len(writes))
+		resp.Breadcrumbs = []response.Breadcrumb{
+			breadcrumb("apply", commandWithoutDryRun(currentCmd), "Run again without --dry-run to make these changes"),
+		}
+	}
+}
+
+// commandWithoutDryRun renders the command being run from its parsed flags
+// and arguments, as auditCommandLine does, minus --dry-run. --token is left
+// out rather than echoed, so the command runs with the configured token.
+func commandWithoutDryRun(cmd *cobra.Command) string {
+	return commandLine(cmd, func(name, value string) (string, bool) {
+		return value, name != "dry-run" && name != "token"
+	})
+}
@@ -0,0 +1,338 @@
+package commands
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/maquina/recuerd0-cli/internal/client"
+	"github.com/maquina/recuerd0-cli/internal/config"
+	"github.com/maquina/recuerd0-cli/internal/crypt"
+	"github.com/maquina/recuerd0-cli/internal/trash"
+)
+
+// skippedWrites returns the local writes listed in
+// meta.dry_run_local_writes.
+func skippedWrites(t *testing.T, result *CommandResult) []dryRunWrite {
+	t.Helper()
+	if result.Response.Meta["dry_run"] != true {
+		t.Fatalf("expected meta.dry_run to be true, got %v", result.Response.Meta)
+	}
+	writes, _ := result.Response.Meta["dry_run_local_writes"].([]dryRunWrite)
+	return writes
+}
+
+func dryRunResult(t *testing.T, result *CommandResult) []dryRunRequest {
+	t.Helper()
+	if result.ExitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d: %+v", result.ExitCode, result.Response.Error)
+	}
+	skippedWrites(t, result)
+	out, _ := json.Marshal(result.Response)
+	if strings.Contains(string(out), "tok_test") {
+		t.Error("dry run output contains the token")
+	}
+	data := result.Response.Data.(map[string]interface{})
+	return data["requests"].([]dryRunRequest)
+}
+
+func TestDryRun_MemoryCreate(t *testing.T) {
+	mock := NewMockClient()
+	result := SetTestMode(mock)
+	SetTestConfigFull("tok_test", "https://api.example.com/", "5")
+	defer ResetTestMode()
+	cfgDryRun = true
+	memoryCreateTitle = "Notes"
+	memoryCreateContent = "Body"
+	defer func() { memoryCreateTitle, memoryCreateContent = "", "" }()
+
+	RunTestCommand(func() {
+		memoryCreateCmd.Run(memoryCreateCmd, []string{})
+	})
+
+	requests := dryRunResult(t, result)
+	if len(mock.PostCalls) != 0 {
+		t.Fatal("expected no POST to be sent")
+	}
+	if len(requests) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(requests))
+	}
+	r := requests[0]
+	if r.Method != "POST" || r.Path != "/workspaces/5/memories" || r.URL != "https://api.example.com/workspaces/5/memories" {
+		t.Errorf("unexpected request: %+v", r)
+	}
+	if r.Headers["Authorization"] != "Bearer [REDACTED]" {
+		t.Errorf("expected redacted authorization header, got %v", r.Headers)
+	}
+	memory := r.Body.(map[string]interface{})["memory"].(map[string]interface{})
+	if memory["title"] != "Notes" || memory["content"] != "Body" {
+		t.Errorf("unexpected body: %v", memory)
+	}
+	if !strings.Contains(result.Response.Summary, "Dry run") {
+		t.Errorf("unexpected summary %q", result.Response.Summary)
+	}
+}
+
+func TestDryRun_DeleteAndArchive(t *testing.T) {
+	mock := NewMockClient()
+	mock.GetResponse = &client.APIResponse{StatusCode: 200, Data: []interface{}{}}
+	result := SetTestMode(mock)
+	SetTestConfigFull("tok_test", "https://api.example.com", "5")
+	defer ResetTestMode()
+	cfgDryRun = true
+
+	RunTestCommand(func() {
+		memoryDeleteCmd.Run(memoryDeleteCmd, []string{"7"})
+	})
+	requests := dryRunResult(t, result)
+	if len(mock.DeleteCalls) != 0 || len(requests) != 1 || requests[0].Method != "DELETE" || requests[0].Body != nil {
+		t.Errorf("unexpected delete dry run: %+v (sent %d)", requests, len(mock.DeleteCalls))
+	}
+	writes := skippedWrites(t, result)
+	if len(writes) != 1 || writes[0].Op != "write" || !strings.HasPrefix(writes[0].File, trash.Dir()) {
+		t.Errorf("expected the trash entry to be listed, got %v", writes)
+	}
+	if entries, _ := trash.List(); len(entries) != 0 {
+		t.Errorf("expected nothing in the trash, got %d entries", len(entries))
+	}
+
+	RunTestCommand(func() {
+		workspaceArchiveCmd.Run(workspaceArchiveCmd, []string{"5"})
+	})
+	requests = dryRunResult(t, result)
+	if len(mock.PostCalls) != 0 || len(requests) != 1 || requests[0].Path != "/workspaces/5/archive" {
+		t.Errorf("unexpected archive dry run: %+v", requests)
+	}
+}
+
+func TestDryRun_ListsEveryWrite(t *testing.T) {
+	mock := NewMockClient()
+	result := SetTestMode(mock)
+	SetTestConfigFull("tok_test", "https://api.example.com", "5")
+	defer ResetTestMode()
+	cfgDryRun = true
+	memoryImportSplit = true
+	orig := stdinReader
+	stdinReader = func() io.Reader {
+		return strings.NewReader(`{"sessionId":"a","role":"user","content":"first question"}
+{"sessionId":"b","role":"user","content":"second question"}`)
+	}
+	defer func() {
+		memoryImportSplit = false
+		stdinReader = orig
+	}()
+
+	RunTestCommand(func() {
+		memoryImportTranscriptCmd.Run(memoryImportTranscriptCmd, []string{"-"})
+	})
+
+	if requests := dryRunResult(t, result); len(requests) != 2 {
+		t.Errorf("expected 2 requests, got %d", len(requests))
+	}
+}
+
+func TestDryRun_ErrorsAreMarked(t *testing.T) {
+	mock := NewMockClient()
+	result := SetTestMode(mock)
+	SetTestConfigFull("tok_test", "https://api.example.com", "5")
+	defer ResetTestMode()
+	cfgDryRun = true
+
+	RunTestCommand(func() {
+		memoryUpdateCmd.Run(memoryUpdateCmd, []string{"7"})
+	})
+
+	if result.ExitCode != 2 {
+		t.Fatalf("expected exit code 2, got %d", result.ExitCode)
+	}
+	if writes := skippedWrites(t, result); len(writes) != 0 {
+		t.Errorf("expected no local writes, got %v", writes)
+	}
+}
+
+func TestDryRun_Off(t *testing.T) {
+	mock := NewMockClient()
+	result := SetTestMode(mock)
+	SetTestConfigFull("tok_test", "https://api.example.com", "5")
+	defer ResetTestMode()
+	memoryDeleteYes = true
+	defer func() { memoryDeleteYes = false }()
+
+	RunTestCommand(func() {
+		memoryDeleteCmd.Run(memoryDeleteCmd, []string{"7"})
+	})
+
+	if len(mock.DeleteCalls) != 1 {
+		t.Error("expected the delete to be sent")
+	}
+	if _, ok := result.Response.Meta["dry_run"]; ok {
+		t.Error("expected no meta.dry_run without --dry-run")
+	}
+}
+
+func TestDryRun_KeyRotateLeavesKeyring(t *testing.T) {
+	mock := NewMockClient()
+	result, _ := setupEncryptedWorkspace(t, mock)
+	cfgDryRun = true
+	file := crypt.KeyringPath(config.KeyDir(), "team")
+	before, _ := os.ReadFile(file)
+
+	RunTestCommand(func() {
+		keyRotateCmd.Run(keyRotateCmd, []string{"team"})
+	})
+
+	if result.ExitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d: %+v", result.ExitCode, result.Response.Error)
+	}
+	if after, _ := os.ReadFile(file); string(after) != string(before) {
+		t.Error("expected the keyring to be left unchanged")
+	}
+	writes := skippedWrites(t, result)
+	if len(writes) != 1 || writes[0].File != file {
+		t.Errorf("expected the keyring write to be listed, got %v", writes)
+	}
+	if !strings.Contains(result.Response.Summary, "1 local write(s) skipped") {
+		t.Errorf("unexpected summary %q", result.Response.Summary)
+	}
+}
+
+func TestDryRun_ApplyInShell(t *testing.T) {
+	mock := NewMockClient()
+	out := runShell(t, mock, ":json\nmemory create --title \"Release notes\" --content Body --dry-run\n")
+
+	if len(mock.PostCalls) != 0 {
+		t.Fatal("expected no POST to be sent")
+	}
+	want := `"cmd": "recuerd0 memory create --content Body --title \"Release notes\""`
+	if !strings.Contains(out, want) {
+		t.Errorf("expected apply breadcrumb %s, got:\n%s", want, out)
+	}
+}
+
+func TestCommandWithoutDryRun(t *testing.T) {
+	SetTestMode(NewMockClient())
+	SetTestConfigFull("tok_test", "https://api.example.com", "5")
+	defer ResetTestMode()
+	defer resetFlags(rootCmd)
+
+	rootCmd.SetArgs([]string{"workspace", "archive", "5", "--token", "tok_live", "--dry-run"})
+	RunTestCommand(func() { _ = rootCmd.Execute() })
+
+	if got, want := commandWithoutDryRun(currentCmd), "recuerd0 workspace archive 5"; got != want {
+		t.Errorf("commandWithoutDryRun() = %q, want %q", got, want)
+	}
+}
+
+func TestDryRun_LeavesLocalFiles(t *testing.T) {
+	workDir := t.TempDir()
+	tests := []struct {
+		name string
+		file func() string
+		run  func()
+	}{
+		{"account add", config.GlobalConfigPath, func() {
+			accountAddToken = "tok_new"
+			defer func() { accountAddToken = "" }()
+			accountAddCmd.Run(accountAddCmd, []string{"extra"})
+		}},
+		{"account select", config.GlobalConfigPath, func() {
+			accountSelectCmd.Run(accountSelectCmd, []string{"work"})
+		}},
+		{"account remove", config.GlobalConfigPath, func() {
+			accountRemoveCmd.Run(accountRemoveCmd, []string{"work"})
+		}},
+		{"config set", config.GlobalConfigPath, func() {
+			configSetCmd.Run(configSetCmd, []string{"accounts.work.timeout", "10s"})
+		}},
+		{"config unset", config.GlobalConfigPath, func() {
+			configUnsetCmd.Run(configUnsetCmd, []string{"accounts.work.api_url"})
+		}},
+		{"config set --local", func() string { return filepath.Join(workDir, ".recuerd0.yaml") }, func() {
+			configSetLocal = true
+			defer func() { configSetLocal = false }()
+			configSetCmd.Run(configSetCmd, []string{"workspace", "9"})
+		}},
+		{"init", func() string { return filepath.Join(workDir, ".recuerd0.yaml") }, func() {
+			initForce, cfgWorkspace = true, "5"
+			defer func() { initForce, cfgWorkspace = false, "" }()
+			initCmd.Run(initCmd, []string{})
+		}},
+		{"skill generate", func() string { return filepath.Join(workDir, "SKILL.md") }, func() {
+			skillGenerateOutput = filepath.Join(workDir, "SKILL.md")
+			defer func() { skillGenerateOutput = "" }()
+			skillGenerateCmd.Run(skillGenerateCmd, nil)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupAccountTest(t)
+			_ = config.AddAccount("personal", "tok_personal", "")
+			_ = config.AddAccount("work", "tok_work", "https://work.example.com")
+			_ = config.SetCurrent("personal")
+			t.Chdir(workDir)
+			if err := os.WriteFile(filepath.Join(workDir, ".recuerd0.yaml"), []byte("workspace: \"5\"\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			os.Remove(filepath.Join(workDir, "SKILL.md"))
+
+			mock := NewMockClient()
+			mock.GetResponse = &client.APIResponse{StatusCode: 200, Data: map[string]interface{}{"id": 5, "name": "Team"}}
+			result := SetTestMode(mock)
+			defer ResetTestMode()
+			cfgDryRun = true
+			file := tt.file()
+			before, _ := os.ReadFile(file)
+
+			RunTestCommand(tt.run)
+
+			if result.ExitCode != 0 {
+				t.Fatalf("expected exit code 0, got %d: %+v", result.ExitCode, result.Response.Error)
+			}
+			if after, _ := os.ReadFile(file); string(after) != string(before) {
+				t.Errorf("expected %s to be left unchanged", file)
+			}
+			writes := skippedWrites(t, result)
+			if len(writes) != 1 || writes[0].File != file {
+				t.Errorf("expected the write to %s to be listed, got %v", file, writes)
+			}
+			if !strings.Contains(result.Response.Summary, "1 local write(s) skipped") {
+				t.Errorf("unexpected summary %q", result.Response.Summary)
+			}
+		})
+	}
+}
+
+func TestDryRun_RecordWritesNoCassette(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"id":7,"title":"Notes"}`)
+	}))
+	defer server.Close()
+
+	result := SetTestMode(nil)
+	defer ResetTestMode()
+	clientFactory = nil
+	SetTestConfigFull("tok_test", server.URL, "5")
+	cfgDryRun = true
+	path := filepath.Join(t.TempDir(), "run.yaml")
+	cfgRecord = path
+
+	RunTestCommand(func() {
+		memoryShowCmd.Run(memoryShowCmd, []string{"7"})
+	})
+
+	if result.ExitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d: %+v", result.ExitCode, result.Response.Error)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected no cassette to be written, got %v", err)
+	}
+	if writes := skippedWrites(t, result); len(writes) != 1 || writes[0].File != path {
+		t.Errorf("expected the cassette write to be listed, got %v", writes)
+	}
+}
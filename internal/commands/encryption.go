@@ -0,0 +1,246 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/maquina/recuerd0-cli/internal/cache"
+	"github.com/maquina/recuerd0-cli/internal/config"
+	"github.com/maquina/recuerd0-cli/internal/crypt"
+	"github.com/maquina/recuerd0-cli/internal/errors"
+)
+
+// encryptionInfo is meta.encryption on commands that encrypt or decrypt
+// content.
+type encryptionInfo struct {
+	Key       string `json:"key,omitempty"`
+	KeyID     string `json:"key_id,omitempty"`
+	Encrypted bool   `json:"encrypted,omitempty"`
+	Decrypted bool   `json:"decrypted,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// workspaceKey returns the keyring content in ws is encrypted with, or nil
+// if the workspace is not configured for encryption.
+func workspaceKey(ws string) (*crypt.Keyring, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	name := cfg.Encryption[ws]
+	if name == "" {
+		return nil, nil
+	}
+	k, err := crypt.LoadKeyring(config.KeyDir(), name)
+	if err != nil {
+		return nil, errors.NewError(fmt.Sprintf("workspace %s is encrypted: %v", ws, err))
+	}
+	return k, nil
+}
+
+// encryptFields replaces fields["content"] with ciphertext when ws is
+// configured for encryption. Titles and tags are left readable so listing
+// and search by title keep working. It returns the plaintext content, or ""
+// if nothing was encrypted.
+func encryptFields(ws string, fields map[string]interface{}) (string, error) {
+	k, err := workspaceKey(ws)
+	if err != nil || k == nil {
+		return "", err
+	}
+	content, ok := fields["content"].(string)
+	if !ok || content == "" {
+		return "", nil
+	}
+	r := k.Current().Recipient()
+	armored, err := crypt.Encrypt([]byte(content), r)
+	if err != nil {
+		return "", errors.NewError(fmt.Sprintf("encrypting content: %v", err))
+	}
+	fields["content"] = armored
+	setMeta("encryption", encryptionInfo{Key: k.Name, KeyID: r.ID(), Encrypted: true})
+	return content, nil
+}
+
+// memoryBody returns the content body of a memory response, which is either
+// a string or an object with a body.
+func memoryBody(m map[string]interface{}) string {
+	if content, ok := m["content"].(map[string]interface{}); ok {
+		body, _ := content["body"].(string)
+		return body
+	}
+	body, _ := m["content"].(string)
+	return body
+}
+
+func setMemoryBody(m map[string]interface{}, body string) {
+	if content, ok := m["content"].(map[string]interface{}); ok {
+		content["body"] = body
+		return
+	}
+	m["content"] = body
+}
+
+// decryptBody opens armored content with any locally stored key.
+func decryptBody(body string) (string, string, error) {
+	ids, err := crypt.AllIdentities(config.KeyDir())
+	if err != nil {
+		return "", "", err
+	}
+	plaintext, err := crypt.Decrypt(body, ids...)
+	if err != nil {
+		return "", "", err
+	}
+	kids, _ := crypt.KeyIDs(body)
+	kid := ""
+	if len(kids) > 0 {
+		kid = kids[0]
+	}
+	return string(plaintext), kid, nil
+}
+
+// decryptMemory replaces encrypted content in a memory response with the
+// plaintext, which it also returns. Failures are reported in
+// meta.encryption rather than failing the command, so the rest of the
+// memory is still shown.
+func decryptMemory(data interface{}) (string, bool) {
+	m, ok := data.(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+	body := memoryBody(m)
+	if !crypt.IsEncrypted(body) {
+		return "", false
+	}
+	plaintext, kid, err := decryptBody(body)
+	if err != nil {
+		setMeta("encryption", encryptionInfo{Encrypted: true, Error: err.Error()})
+		return "", false
+	}
+	setMemoryBody(m, plaintext)
+	setMeta("encryption", encryptionInfo{KeyID: kid, Encrypted: true, Decrypted: true})
+	return plaintext, true
+}
+
+// The offline index holds the words of decrypted memories, since the server
+// can only search their titles. It lives in the cache directory, scoped to
+// the API URL and token like other cache entries.
+
+type indexEntry struct {
+	WorkspaceID string   `json:"workspace_id"`
+	ID          string   `json:"id"`
+	Title       string   `json:"title"`
+	Terms       []string `json:"terms"`
+}
+
+func indexKey() string {
+	return cache.Key("encrypted-index", cfg.APIURL, cfg.Token)
+}
+
+func loadIndex() map[string]indexEntry {
+	index := map[string]indexEntry{}
+	cache.Get(indexKey(), cache.NoExpiry, &index)
+	return index
+}
+
+// indexMemory records the words of a decrypted memory. Errors are ignored:
+// the index is a convenience and can be rebuilt by showing memories again.
+func indexMemory(ws, id, title, plaintext string) {
+	if ws == "" || id == "" {
+		return
+	}
+	if skipLocalWrite("write", "offline search index", cache.Path(indexKey())) {
+		return
+	}
+	seen := map[string]bool{}
+	for _, term := range queryTerms(title + "\n" + plaintext) {
+		seen[term] = true
+	}
+	terms := make([]string, 0, len(seen))
+	for term := range seen {
+		terms = append(terms, term)
+	}
+	sort.Strings(terms)
+
+	index := loadIndex()
+	index[ws+"/"+id] = indexEntry{WorkspaceID: ws, ID: id, Title: title, Terms: terms}
+	_ = cache.Put(indexKey(), index)
+}
+
+// indexMemoryResponse indexes a memory returned by the API, using plaintext
+// as its content.
+func indexMemoryResponse(ws string, data interface{}, plaintext string) {
+	m, ok := data.(map[string]interface{})
+	if !ok {
+		return
+	}
+	title, _ := m["title"].(string)
+	indexMemory(ws, stringID(m["id"]), title, plaintext)
+}
+
+// searchIndex returns the indexed memories containing every query term,
+// optionally limited to one workspace, shaped like API search results.
+func searchIndex(query, ws string) []interface{} {
+	terms := queryTerms(query)
+	index := loadIndex()
+	keys := make([]string, 0, len(index))
+	for key := range index {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	results := []interface{}{}
+	for _, key := range keys {
+		e := index[key]
+		if ws != "" && e.WorkspaceID != ws {
+			continue
+		}
+		if !containsAllTerms(e.Terms, terms) {
+			continue
+		}
+		results = append(results, map[string]interface{}{
+			"id":        e.ID,
+			"title":     e.Title,
+			"workspace": map[string]interface{}{"id": e.WorkspaceID},
+			"source":    "offline",
+		})
+	}
+	return results
+}
+
+func containsAllTerms(have, want []string) bool {
+	for _, w := range want {
+		i := sort.SearchStrings(have, w)
+		if i == len(have) || have[i] != w {
+			return false
+		}
+	}
+	return len(want) > 0
+}
+
+// describeKeyring summarises a keyring for key commands.
+func describeKeyring(k *crypt.Keyring) map[string]interface{} {
+	r := k.Current().Recipient()
+	workspaces := []string{}
+	if cfg != nil {
+		for ws, name := range cfg.Encryption {
+			if name == k.Name {
+				workspaces = append(workspaces, ws)
+			}
+		}
+	}
+	sort.Strings(workspaces)
+	return map[string]interface{}{
+		"name":       k.Name,
+		"public_key": r.String(),
+		"key_id":     r.ID(),
+		"keys":       len(k.Identities),
+		"workspaces": workspaces,
+	}
+}
+
+// keyNameArg validates a key name given on the command line.
+func keyNameArg(name string) error {
+	if !crypt.ValidName(name) {
+		return errors.NewInvalidArgsError(fmt.Sprintf("invalid key name %q: use letters, digits, - and _", name))
+	}
+	return nil
+}
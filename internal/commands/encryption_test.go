@@ -0,0 +1,322 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/maquina/recuerd0-cli/internal/client"
+	"github.com/maquina/recuerd0-cli/internal/config"
+	"github.com/maquina/recuerd0-cli/internal/crypt"
+)
+
+// setupEncryptedWorkspace stores a "team" key in a temporary config dir and
+// marks workspace 5 as encrypted with it.
+func setupEncryptedWorkspace(t *testing.T, mock *MockClient) (*CommandResult, *crypt.Keyring) {
+	t.Helper()
+	dir := t.TempDir()
+	config.SetConfigDir(dir)
+
+	id, err := crypt.GenerateIdentity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	k := &crypt.Keyring{Name: "team", Identities: []*crypt.Identity{id}}
+	if err := crypt.SaveKeyring(config.KeyDir(), k); err != nil {
+		t.Fatal(err)
+	}
+
+	result := SetTestMode(mock)
+	SetTestConfigFull("tok_test", "https://api.example.com", "5")
+	cfg.Encryption = map[string]string{"5": "team"}
+	t.Cleanup(func() {
+		ResetTestMode()
+		config.SetConfigDir("")
+	})
+	return result, k
+}
+
+func TestEncryption_CreateEncryptsContent(t *testing.T) {
+	mock := NewMockClient()
+	mock.PostResponse = &client.APIResponse{StatusCode: 201, Data: map[string]interface{}{"id": 42, "title": "Deploy notes"}}
+	result, k := setupEncryptedWorkspace(t, mock)
+
+	memoryCreateTitle = "Deploy notes"
+	memoryCreateContent = "Rotate the staging database credentials"
+	memoryCreateTags = "ops"
+	t.Cleanup(func() { memoryCreateTitle, memoryCreateContent, memoryCreateTags = "", "", "" })
+
+	RunTestCommand(func() {
+		memoryCreateCmd.Run(memoryCreateCmd, []string{})
+	})
+
+	if result.ExitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", result.ExitCode)
+	}
+	memory := mock.PostCalls[0].Body.(map[string]interface{})["memory"].(map[string]interface{})
+	content := memory["content"].(string)
+	if !crypt.IsEncrypted(content) || strings.Contains(content, "staging") {
+		t.Fatalf("expected ciphertext, got %q", content)
+	}
+	if memory["title"] != "Deploy notes" {
+		t.Errorf("expected title to stay in plaintext, got %v", memory["title"])
+	}
+	if tags := memory["tags"].([]string); len(tags) != 1 || tags[0] != "ops" {
+		t.Errorf("expected tags to stay in plaintext, got %v", tags)
+	}
+	plaintext, err := crypt.Decrypt(content, k.Identities...)
+	if err != nil || string(plaintext) != "Rotate the staging database credentials" {
+		t.Errorf("Decrypt = %q, %v", plaintext, err)
+	}
+
+	info, ok := result.Response.Meta["encryption"].(encryptionInfo)
+	if !ok || info.Key != "team" || info.KeyID != k.Current().Recipient().ID() || !info.Encrypted {
+		t.Errorf("unexpected meta.encryption: %v", result.Response.Meta["encryption"])
+	}
+
+	if results := searchIndex("staging credentials", ""); len(results) != 1 {
+		t.Errorf("expected created memory in the offline index, got %v", results)
+	}
+}
+
+func TestEncryption_PlainWorkspaceUnchanged(t *testing.T) {
+	mock := NewMockClient()
+	result, _ := setupEncryptedWorkspace(t, mock)
+	cfg.Workspace = "6"
+
+	memoryCreateTitle = "Notes"
+	memoryCreateContent = "plain"
+	t.Cleanup(func() { memoryCreateTitle, memoryCreateContent = "", "" })
+
+	RunTestCommand(func() {
+		memoryCreateCmd.Run(memoryCreateCmd, []string{})
+	})
+
+	if got := sentContent(mock); got != "plain" {
+		t.Errorf("expected content unchanged, got %q", got)
+	}
+	if _, ok := result.Response.Meta["encryption"]; ok {
+		t.Error("expected no meta.encryption for an unencrypted workspace")
+	}
+}
+
+func TestEncryption_MissingKey(t *testing.T) {
+	mock := NewMockClient()
+	result, _ := setupEncryptedWorkspace(t, mock)
+	cfg.Encryption["5"] = "lost"
+
+	memoryCreateTitle = "Notes"
+	memoryCreateContent = "secret plans"
+	t.Cleanup(func() { memoryCreateTitle, memoryCreateContent = "", "" })
+
+	RunTestCommand(func() {
+		memoryCreateCmd.Run(memoryCreateCmd, []string{})
+	})
+
+	if result.ExitCode == 0 {
+		t.Fatal("expected failure when the workspace key is missing")
+	}
+	if len(mock.PostCalls) != 0 {
+		t.Error("expected nothing to be sent")
+	}
+}
+
+func TestEncryption_ShowDecrypts(t *testing.T) {
+	mock := NewMockClient()
+	result, k := setupEncryptedWorkspace(t, mock)
+
+	armored, _ := crypt.Encrypt([]byte("Use blue-green deploys"), k.Current().Recipient())
+	mock.GetResponse = &client.APIResponse{StatusCode: 200, Data: map[string]interface{}{
+		"id":      "9",
+		"title":   "Deploys",
+		"content": map[string]interface{}{"body": armored},
+	}}
+
+	RunTestCommand(func() {
+		memoryShowCmd.Run(memoryShowCmd, []string{"9"})
+	})
+
+	data := result.Response.Data.(map[string]interface{})
+	if body := data["content"].(map[string]interface{})["body"]; body != "Use blue-green deploys" {
+		t.Errorf("expected decrypted body, got %v", body)
+	}
+	if info := result.Response.Meta["encryption"].(encryptionInfo); !info.Decrypted {
+		t.Errorf("unexpected meta.encryption: %+v", info)
+	}
+	if results := searchIndex("blue", "5"); len(results) != 1 {
+		t.Errorf("expected shown memory in the offline index, got %v", results)
+	}
+	if results := searchIndex("blue", "6"); len(results) != 0 {
+		t.Errorf("expected workspace filter to apply, got %v", results)
+	}
+}
+
+func TestEncryption_ShowWithoutKey(t *testing.T) {
+	mock := NewMockClient()
+	result, _ := setupEncryptedWorkspace(t, mock)
+
+	other, _ := crypt.GenerateIdentity()
+	armored, _ := crypt.Encrypt([]byte("hidden"), other.Recipient())
+	mock.GetResponse = &client.APIResponse{StatusCode: 200, Data: map[string]interface{}{"id": "9", "content": armored}}
+
+	RunTestCommand(func() {
+		memoryShowCmd.Run(memoryShowCmd, []string{"9"})
+	})
+
+	if result.ExitCode != 0 {
+		t.Fatalf("expected show to succeed, got exit code %d", result.ExitCode)
+	}
+	if data := result.Response.Data.(map[string]interface{}); data["content"] != armored {
+		t.Error("expected ciphertext to be left in place")
+	}
+	if info := result.Response.Meta["encryption"].(encryptionInfo); info.Decrypted || info.Error == "" {
+		t.Errorf("expected decryption error in meta, got %+v", info)
+	}
+}
+
+func TestSearch_Offline(t *testing.T) {
+	mock := NewMockClient()
+	result, _ := setupEncryptedWorkspace(t, mock)
+	indexMemory("5", "1", "Deploys", "blue-green rollout with canaries")
+	indexMemory("5", "2", "Oncall", "pager rotation")
+	resetSearchFlags()
+	searchOffline = true
+	t.Cleanup(resetSearchFlags)
+
+	RunTestCommand(func() {
+		searchCmd.Run(searchCmd, []string{"canaries rollout"})
+	})
+
+	if result.ExitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", result.ExitCode)
+	}
+	if len(mock.GetCalls) != 0 {
+		t.Error("expected no API calls for an offline search")
+	}
+	data := result.Response.Data.(map[string]interface{})
+	results := data["results"].([]interface{})
+	if len(results) != 1 || results[0].(map[string]interface{})["id"] != "1" {
+		t.Errorf("unexpected results: %v", results)
+	}
+}
+
+func TestKey_GenerateAndList(t *testing.T) {
+	mock := NewMockClient()
+	result, _ := setupEncryptedWorkspace(t, mock)
+
+	RunTestCommand(func() {
+		keyGenerateCmd.Run(keyGenerateCmd, []string{"personal"})
+	})
+	if result.ExitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", result.ExitCode)
+	}
+	if !crypt.KeyringExists(config.KeyDir(), "personal") {
+		t.Fatal("expected key file to be written")
+	}
+
+	RunTestCommand(func() {
+		keyGenerateCmd.Run(keyGenerateCmd, []string{"personal"})
+	})
+	if result.ExitCode != 2 {
+		t.Errorf("expected exit code 2 for an existing key, got %d", result.ExitCode)
+	}
+
+	RunTestCommand(func() {
+		keyListCmd.Run(keyListCmd, []string{})
+	})
+	keys := result.Response.Data.([]interface{})
+	if len(keys) != 2 {
+		t.Fatalf("expected 2 keys, got %d", len(keys))
+	}
+	team := keys[1].(map[string]interface{})
+	if team["name"] != "team" || strings.Join(team["workspaces"].([]string), ",") != "5" {
+		t.Errorf("unexpected key entry: %v", team)
+	}
+}
+
+func TestKey_Import(t *testing.T) {
+	mock := NewMockClient()
+	result, k := setupEncryptedWorkspace(t, mock)
+
+	shared, _ := crypt.GenerateIdentity()
+	file := filepath.Join(t.TempDir(), "shared.key")
+	_ = os.WriteFile(file, []byte("# from alice\n"+shared.String()+"\n"+k.Current().String()+"\n"), 0600)
+
+	RunTestCommand(func() {
+		keyImportCmd.Run(keyImportCmd, []string{"team", file})
+	})
+
+	if result.ExitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", result.ExitCode)
+	}
+	loaded, err := crypt.LoadKeyring(config.KeyDir(), "team")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Identities) != 2 || loaded.Current().String() != shared.String() {
+		t.Errorf("expected the new key to be appended once, got %d keys", len(loaded.Identities))
+	}
+}
+
+func TestKey_RotateReencrypt(t *testing.T) {
+	mock := NewMockClient()
+	result, k := setupEncryptedWorkspace(t, mock)
+	old := k.Current()
+
+	armored, _ := crypt.Encrypt([]byte("old secret"), old.Recipient())
+	mock.GetResponses = map[string]*client.APIResponse{
+		"/workspaces/5/memories": {StatusCode: 200, Data: []interface{}{
+			map[string]interface{}{"id": 1}, map[string]interface{}{"id": 2},
+		}},
+		"/workspaces/5/memories/1": {StatusCode: 200, Data: map[string]interface{}{"id": 1, "title": "A", "content": armored}},
+		"/workspaces/5/memories/2": {StatusCode: 200, Data: map[string]interface{}{"id": 2, "title": "B", "content": "plain"}},
+	}
+	keyRotateReencrypt = true
+	t.Cleanup(func() { keyRotateReencrypt = false })
+
+	RunTestCommand(func() {
+		keyRotateCmd.Run(keyRotateCmd, []string{"team"})
+	})
+
+	if result.ExitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d: %v", result.ExitCode, result.Response)
+	}
+	rotated, _ := crypt.LoadKeyring(config.KeyDir(), "team")
+	if len(rotated.Identities) != 2 {
+		t.Fatalf("expected 2 keys after rotation, got %d", len(rotated.Identities))
+	}
+	if len(mock.PatchCalls) != 1 || mock.PatchCalls[0].Path != "/workspaces/5/memories/1" {
+		t.Fatalf("expected one re-encrypted memory, got %v", mock.PatchCalls)
+	}
+	content := mock.PatchCalls[0].Body.(map[string]interface{})["memory"].(map[string]interface{})["content"].(string)
+	if _, err := crypt.Decrypt(content, old); err == nil {
+		t.Error("expected re-encrypted content to be unreadable with the old key")
+	}
+	if got, err := crypt.Decrypt(content, rotated.Current()); err != nil || string(got) != "old secret" {
+		t.Errorf("Decrypt with new key = %q, %v", got, err)
+	}
+	stats := result.Response.Data.(map[string]interface{})["reencrypt"].(*reencryptStats)
+	if stats.Reencrypted != 1 || stats.Plaintext != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestKey_RotateReencryptWrongWorkspace(t *testing.T) {
+	mock := NewMockClient()
+	result, _ := setupEncryptedWorkspace(t, mock)
+	keyRotateReencrypt = true
+	keyRotateWorkspace = "6"
+	t.Cleanup(func() { keyRotateReencrypt, keyRotateWorkspace = false, "" })
+
+	RunTestCommand(func() {
+		keyRotateCmd.Run(keyRotateCmd, []string{"team"})
+	})
+
+	if result.ExitCode != 2 {
+		t.Errorf("expected exit code 2, got %d", result.ExitCode)
+	}
+	if k, _ := crypt.LoadKeyring(config.KeyDir(), "team"); len(k.Identities) != 1 {
+		t.Error("expected no rotation when the workspace does not use the key")
+	}
+}
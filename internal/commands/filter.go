@@ -0,0 +1,347 @@
+package commands
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maquina/recuerd0-cli/internal/client"
+	"github.com/maquina/recuerd0-cli/internal/errors"
+	"github.com/maquina/recuerd0-cli/internal/response"
+)
+
+// listFilterFlags holds the client-side filter and sort flags shared by the
+// list and search commands.
+type listFilterFlags struct {
+	Tags       []string
+	Source     string
+	Since      string
+	Until      string
+	DateField  string
+	TitleMatch string
+	Sort       string
+	Reverse    bool
+}
+
+func addListFilterFlags(cmd *cobra.Command, f *listFilterFlags) {
+	cmd.Flags().StringArrayVar(&f.Tags, "tag", nil, "keep only items with this tag (repeatable)")
+	cmd.Flags().StringVar(&f.Source, "source", "", "keep only items with this source")
+	cmd.Flags().StringVar(&f.Since, "since", "", "keep items dated on or after (RFC3339, YYYY-MM-DD or 7d/12h)")
+	cmd.Flags().StringVar(&f.Until, "until", "", "keep items dated on or before (RFC3339, YYYY-MM-DD or 7d/12h)")
+	cmd.Flags().StringVar(&f.DateField, "date-field", "updated_at", "field used by --since/--until: updated_at or created_at")
+	cmd.Flags().StringVar(&f.TitleMatch, "title-match", "", "keep items whose title (or name) matches REGEX")
+	cmd.Flags().StringVar(&f.Sort, "sort", "", "sort by FIELD (e.g. title, updated_at, created_at, id)")
+	cmd.Flags().BoolVar(&f.Reverse, "reverse", false, "reverse the order")
+}
+
+// listFilter is the compiled form of listFilterFlags.
+type listFilter struct {
+	tags       []string
+	source     string
+	since      time.Time
+	until      time.Time
+	dateField  string
+	titleMatch *regexp.Regexp
+	sortField  string
+	reverse    bool
+}
+
+// compile validates the flags and returns a filter.
+func (f listFilterFlags) compile() (*listFilter, error) {
+	lf := &listFilter{
+		tags:      f.Tags,
+		source:    f.Source,
+		dateField: f.DateField,
+		sortField: f.Sort,
+		reverse:   f.Reverse,
+	}
+	switch lf.dateField {
+	case "":
+		lf.dateField = "updated_at"
+	case "updated_at", "created_at":
+	default:
+		return nil, errors.NewInvalidArgsError(fmt.Sprintf("--date-field must be updated_at or created_at, got %q", f.DateField))
+	}
+
+	var err error
+	if f.Since != "" {
+		if lf.since, err = parseFilterTime(f.Since); err != nil {
+			return nil, errors.NewInvalidArgsError(fmt.Sprintf("invalid --since: %v", err))
+		}
+	}
+	if f.Until != "" {
+		if lf.until, err = parseFilterTime(f.Until); err != nil {
+			return nil, errors.NewInvalidArgsError(fmt.Sprintf("invalid --until: %v", err))
+		}
+		// A bare date includes the whole day.
+		if len(f.Until) == len("2006-01-02") {
+			lf.until = lf.until.Add(24*time.Hour - time.Nanosecond)
+		}
+	}
+	if f.TitleMatch != "" {
+		if lf.titleMatch, err = regexp.Compile(f.TitleMatch); err != nil {
+			return nil, errors.NewInvalidArgsError(fmt.Sprintf("invalid --title-match: %v", err))
+		}
+	}
+	return lf, nil
+}
+
+// parseFilterTime accepts RFC3339, a YYYY-MM-DD date or a relative age such
+// as 7d, 2w or 12h measured back from now.
+func parseFilterTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	if n := len(s); n > 1 {
+		if amount, err := strconv.Atoi(s[:n-1]); err == nil && amount >= 0 {
+			unit := map[byte]time.Duration{'h': time.Hour, 'd': 24 * time.Hour, 'w': 7 * 24 * time.Hour}[s[n-1]]
+			if unit != 0 {
+				return time.Now().Add(-time.Duration(amount) * unit), nil
+			}
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not RFC3339, YYYY-MM-DD or an age like 7d", s)
+}
+
+// filters reports whether any item would be dropped by the filter.
+func (lf *listFilter) filters() bool {
+	return len(lf.tags) > 0 || lf.source != "" || !lf.since.IsZero() || !lf.until.IsZero() || lf.titleMatch != nil
+}
+
+// active reports whether the filter changes the result in any way.
+func (lf *listFilter) active() bool {
+	return lf.filters() || lf.sortField != "" || lf.reverse
+}
+
+func (lf *listFilter) match(item map[string]interface{}) bool {
+	if len(lf.tags) > 0 && !hasAllTags(item["tags"], lf.tags) {
+		return false
+	}
+	if lf.source != "" && !strings.EqualFold(fmt.Sprint(item["source"]), lf.source) {
+		return false
+	}
+	if !lf.since.IsZero() || !lf.until.IsZero() {
+		s, _ := item[lf.dateField].(string)
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return false
+		}
+		if !lf.since.IsZero() && t.Before(lf.since) {
+			return false
+		}
+		if !lf.until.IsZero() && t.After(lf.until) {
+			return false
+		}
+	}
+	if lf.titleMatch != nil {
+		title, ok := item["title"].(string)
+		if !ok {
+			title, _ = item["name"].(string)
+		}
+		if !lf.titleMatch.MatchString(title) {
+			return false
+		}
+	}
+	return true
+}
+
+// apply filters and sorts items. Items that are not objects are dropped when
+// any filter is set.
+func (lf *listFilter) apply(items []interface{}) []interface{} {
+	out := make([]interface{}, 0, len(items))
+	for _, it := range items {
+		m, ok := it.(map[string]interface{})
+		if lf.filters() && (!ok || !lf.match(m)) {
+			continue
+		}
+		out = append(out, it)
+	}
+
+	if lf.sortField != "" {
+		sort.SliceStable(out, func(i, j int) bool {
+			return lessByField(out[i], out[j], lf.sortField)
+		})
+	}
+	if lf.reverse {
+		for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+			out[i], out[j] = out[j], out[i]
+		}
+	}
+	return out
+}
+
+// lessByField orders numbers numerically and everything else as
+// case-insensitive strings. Items missing the field sort last.
+func lessByField(a, b interface{}, field string) bool {
+	av, aok := fieldValue(a, field)
+	bv, bok := fieldValue(b, field)
+	if !aok || !bok {
+		return aok && !bok
+	}
+	if an, ok := av.(float64); ok {
+		if bn, ok := bv.(float64); ok {
+			return an < bn
+		}
+	}
+	return strings.ToLower(fmt.Sprint(av)) < strings.ToLower(fmt.Sprint(bv))
+}
+
+func fieldValue(item interface{}, field string) (interface{}, bool) {
+	m, ok := item.(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	v, ok := m[field]
+	return v, ok && v != nil
+}
+
+// hasAllTags reports whether a tags value carries every wanted tag,
+// case-insensitively.
+func hasAllTags(v interface{}, want []string) bool {
+	have := map[string]bool{}
+	if list, ok := v.([]interface{}); ok {
+		for _, t := range list {
+			have[strings.ToLower(fmt.Sprint(t))] = true
+		}
+	}
+	for _, t := range want {
+		if !have[strings.ToLower(strings.TrimSpace(t))] {
+			return false
+		}
+	}
+	return true
+}
+
+// pageItems extracts the list from a page: either the top-level array or, for
+// search, the results array.
+func pageItems(data interface{}) []interface{} {
+	switch d := data.(type) {
+	case []interface{}:
+		return d
+	case map[string]interface{}:
+		items, _ := d["results"].([]interface{})
+		return items
+	}
+	return nil
+}
+
+// pageIterator follows a listing's next links one page at a time. It stops
+// when a page has no next link, when the server repeats the current link,
+// or after limit pages when limit is positive; NextURL is then the page it
+// did not get, if any.
+type pageIterator struct {
+	get     func(path string) (*client.APIResponse, error)
+	NextURL string
+	Pages   int
+	limit   int
+}
+
+// newPageIterator starts at path, getting each page with get, such as
+// apiClient.GetWithPagination.
+func newPageIterator(get func(path string) (*client.APIResponse, error), path string, limit int) *pageIterator {
+	return &pageIterator{get: get, NextURL: path, limit: limit}
+}
+
+// Next gets the next page, or returns nil when there are no more to get.
+func (it *pageIterator) Next() (*client.APIResponse, error) {
+	if it.NextURL == "" || (it.limit > 0 && it.Pages >= it.limit) {
+		return nil, nil
+	}
+	current := it.NextURL
+	resp, err := it.get(current)
+	if err != nil {
+		return nil, err
+	}
+	it.Pages++
+	it.NextURL = resp.LinkNext
+	if it.NextURL == current {
+		it.NextURL = ""
+	}
+	return resp, nil
+}
+
+// fetchAllPages follows next links from path, up to maxListPages, and
+// returns the items of every page.
+func fetchAllPages(apiClient client.API, path string) ([]interface{}, error) {
+	var items []interface{}
+	pages := newPageIterator(apiClient.GetWithPagination, path, maxListPages)
+	for {
+		resp, err := pages.Next()
+		if err != nil {
+			return nil, err
+		}
+		if resp == nil {
+			return items, nil
+		}
+		items = append(items, pageItems(resp.Data)...)
+	}
+}
+
+// filteredListing is the outcome of fetching and filtering a listing.
+type filteredListing struct {
+	Data       interface{}
+	Fetched    int
+	Matched    int
+	Pagination *response.Pagination
+}
+
+// summary describes a filtered listing, e.g. "3 of 40 memory(ies) match".
+func (l *filteredListing) summary(noun string, lf *listFilter) string {
+	if lf.filters() {
+		return fmt.Sprintf("%d of %d %s match", l.Matched, l.Fetched, noun)
+	}
+	return fmt.Sprintf("%d %s", l.Matched, noun)
+}
+
+// fetchFiltered fetches path and applies lf. With a filter and no explicit
+// page, every page is fetched so the filter sees the whole listing; with an
+// explicit page only that page is filtered and the server's next link is kept.
+func fetchFiltered(apiClient client.API, path string, explicitPage bool, lf *listFilter) (*filteredListing, error) {
+	pages := newPageIterator(apiClient.GetWithPagination, path, maxListPages)
+	resp, err := pages.Next()
+	if err != nil {
+		return nil, err
+	}
+	first := resp.Data
+	items := append([]interface{}{}, pageItems(first)...)
+
+	for !explicitPage {
+		resp, err = pages.Next()
+		if err != nil {
+			return nil, err
+		}
+		if resp == nil {
+			break
+		}
+		items = append(items, pageItems(resp.Data)...)
+	}
+
+	kept := lf.apply(items)
+	out := &filteredListing{
+		Fetched: len(items),
+		Matched: len(kept),
+		Pagination: &response.Pagination{
+			HasNext: pages.NextURL != "",
+			NextURL: pages.NextURL,
+			Fetched: len(items),
+			Matched: len(kept),
+			Pages:   pages.Pages,
+		},
+	}
+	if m, ok := first.(map[string]interface{}); ok {
+		m["results"] = kept
+		m["total_results"] = float64(len(kept))
+		out.Data = m
+	} else {
+		out.Data = kept
+	}
+	return out, nil
+}
@@ -0,0 +1,220 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/maquina/recuerd0-cli/internal/client"
+	"github.com/maquina/recuerd0-cli/internal/errors"
+)
+
+func filterItems() []interface{} {
+	return []interface{}{
+		map[string]interface{}{"id": float64(1), "title": "Design doc", "tags": []interface{}{"design"}, "source": "manual", "updated_at": "2026-03-01T10:00:00Z"},
+		map[string]interface{}{"id": float64(2), "title": "API notes", "tags": []interface{}{"api", "Design"}, "source": "claude", "updated_at": "2026-05-01T10:00:00Z"},
+		map[string]interface{}{"id": float64(10), "title": "Standup", "tags": []interface{}{}, "source": "manual", "updated_at": "2026-06-01T10:00:00Z"},
+	}
+}
+
+func ids(items []interface{}) []float64 {
+	out := make([]float64, 0, len(items))
+	for _, it := range items {
+		out = append(out, it.(map[string]interface{})["id"].(float64))
+	}
+	return out
+}
+
+func TestListFilter_Apply(t *testing.T) {
+	tests := []struct {
+		name  string
+		flags listFilterFlags
+		want  []float64
+	}{
+		{"no filter", listFilterFlags{}, []float64{1, 2, 10}},
+		{"tag", listFilterFlags{Tags: []string{"design"}}, []float64{1, 2}},
+		{"two tags", listFilterFlags{Tags: []string{"design", "api"}}, []float64{2}},
+		{"source", listFilterFlags{Source: "Manual"}, []float64{1, 10}},
+		{"since", listFilterFlags{Since: "2026-04-01"}, []float64{2, 10}},
+		{"until bare date is inclusive", listFilterFlags{Until: "2026-05-01"}, []float64{1, 2}},
+		{"title match", listFilterFlags{TitleMatch: "(?i)^(design|standup)"}, []float64{1, 10}},
+		{"sort by title", listFilterFlags{Sort: "title"}, []float64{2, 1, 10}},
+		{"sort by id numerically", listFilterFlags{Sort: "id", Reverse: true}, []float64{10, 2, 1}},
+		{"reverse only", listFilterFlags{Reverse: true}, []float64{10, 2, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lf, err := tt.flags.compile()
+			if err != nil {
+				t.Fatalf("compile error: %v", err)
+			}
+			got := ids(lf.apply(filterItems()))
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestListFilter_CompileErrors(t *testing.T) {
+	for _, f := range []listFilterFlags{
+		{Since: "yesterday"},
+		{Until: "2026-13-01"},
+		{TitleMatch: "("},
+		{Since: "7d", DateField: "deleted_at"},
+	} {
+		if _, err := f.compile(); err == nil {
+			t.Errorf("expected error for %+v", f)
+		}
+	}
+}
+
+func TestParseFilterTime_Relative(t *testing.T) {
+	got, err := parseFilterTime("2d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(got); d < 47*time.Hour || d > 49*time.Hour {
+		t.Errorf("expected about 48h ago, got %s", d)
+	}
+}
+
+func TestMemoryList_FilterFollowsPages(t *testing.T) {
+	items := filterItems()
+	mock := NewMockClient()
+	mock.GetResponses = map[string]*client.APIResponse{
+		"/workspaces/5/memories":        {StatusCode: 200, Data: items[:2], LinkNext: "/workspaces/5/memories?page=2"},
+		"/workspaces/5/memories?page=2": {StatusCode: 200, Data: items[2:]},
+	}
+	result := SetTestMode(mock)
+	SetTestConfig("tok_test", "https://api.example.com")
+	defer ResetTestMode()
+
+	memoryListWorkspace = "5"
+	memoryListPage = ""
+	memoryListFilter = listFilterFlags{Source: "manual"}
+	defer func() {
+		memoryListWorkspace = ""
+		memoryListFilter = listFilterFlags{}
+	}()
+
+	RunTestCommand(func() {
+		memoryListCmd.Run(memoryListCmd, []string{})
+	})
+
+	if result.ExitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", result.ExitCode)
+	}
+	if len(mock.GetCalls) != 2 {
+		t.Fatalf("expected 2 page requests, got %d", len(mock.GetCalls))
+	}
+	if got := ids(result.Response.Data.([]interface{})); len(got) != 2 || got[1] != 10 {
+		t.Errorf("unexpected items: %v", got)
+	}
+	p := result.Response.Pagination
+	if p.HasNext || p.Pages != 2 || p.Fetched != 3 || p.Matched != 2 {
+		t.Errorf("unexpected pagination: %+v", p)
+	}
+	if result.Response.Summary != "2 of 3 memory(ies) match" {
+		t.Errorf("unexpected summary: %s", result.Response.Summary)
+	}
+}
+
+func TestMemoryList_FilterExplicitPage(t *testing.T) {
+	mock := NewMockClient()
+	mock.GetResponse = &client.APIResponse{StatusCode: 200, Data: filterItems(), LinkNext: "/workspaces/5/memories?page=3"}
+	result := SetTestMode(mock)
+	SetTestConfig("tok_test", "https://api.example.com")
+	defer ResetTestMode()
+
+	memoryListWorkspace = "5"
+	memoryListPage = "2"
+	memoryListFilter = listFilterFlags{Tags: []string{"api"}}
+	defer func() {
+		memoryListWorkspace, memoryListPage = "", ""
+		memoryListFilter = listFilterFlags{}
+	}()
+
+	RunTestCommand(func() {
+		memoryListCmd.Run(memoryListCmd, []string{})
+	})
+
+	if len(mock.GetCalls) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(mock.GetCalls))
+	}
+	p := result.Response.Pagination
+	if !p.HasNext || p.NextURL != "/workspaces/5/memories?page=3" || p.Matched != 1 {
+		t.Errorf("unexpected pagination: %+v", p)
+	}
+}
+
+func TestWorkspaceList_InvalidFilter(t *testing.T) {
+	mock := NewMockClient()
+	result := SetTestMode(mock)
+	SetTestConfig("tok_test", "https://api.example.com")
+	defer ResetTestMode()
+
+	workspaceListFilter = listFilterFlags{TitleMatch: "["}
+	defer func() { workspaceListFilter = listFilterFlags{} }()
+
+	RunTestCommand(func() {
+		workspaceListCmd.Run(workspaceListCmd, []string{})
+	})
+
+	if result.ExitCode != errors.ExitInvalidArgs {
+		t.Errorf("expected exit code %d, got %d", errors.ExitInvalidArgs, result.ExitCode)
+	}
+	if len(mock.GetCalls) != 0 {
+		t.Errorf("expected no API calls, got %d", len(mock.GetCalls))
+	}
+}
+
+func TestPageIterator(t *testing.T) {
+	responses := map[string]*client.APIResponse{
+		"/a":      {LinkNext: "/a?p=2"},
+		"/a?p=2":  {LinkNext: "/a?p=3"},
+		"/a?p=3":  {LinkNext: "/a?p=3"},
+		"/broken": nil,
+	}
+	var got []string
+	get := func(path string) (*client.APIResponse, error) {
+		got = append(got, path)
+		if responses[path] == nil {
+			return nil, errors.NewError("boom")
+		}
+		return responses[path], nil
+	}
+	drain := func(it *pageIterator) error {
+		for {
+			resp, err := it.Next()
+			if err != nil || resp == nil {
+				return err
+			}
+		}
+	}
+
+	it := newPageIterator(get, "/a", 0)
+	if err := drain(it); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 || it.Pages != 3 || it.NextURL != "" {
+		t.Errorf("expected a repeated next link to end the listing, got %v, %+v", got, it)
+	}
+
+	got = nil
+	it = newPageIterator(get, "/a", 2)
+	if err := drain(it); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || it.NextURL != "/a?p=3" {
+		t.Errorf("expected the limit to stop after 2 pages and keep the next link, got %v, %+v", got, it)
+	}
+
+	if err := drain(newPageIterator(get, "/broken", 0)); err == nil {
+		t.Error("expected the error of a failed page")
+	}
+}
@@ -0,0 +1,415 @@
+package commands
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"flag"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maquina/recuerd0-cli/internal/audit"
+	"github.com/maquina/recuerd0-cli/internal/catalog"
+	"github.com/maquina/recuerd0-cli/internal/client"
+	"github.com/maquina/recuerd0-cli/internal/config"
+	"github.com/maquina/recuerd0-cli/internal/crypt"
+	"github.com/maquina/recuerd0-cli/internal/errors"
+	"github.com/maquina/recuerd0-cli/internal/schema"
+	"github.com/maquina/recuerd0-cli/internal/trash"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite testdata/golden from the current output")
+
+// Fixtures shaped like the API reference in docs/API.md.
+var (
+	goldenWorkspace = map[string]interface{}{
+		"id": float64(1), "name": "Project Alpha", "description": "Main project workspace",
+		"memories_count": float64(42), "archived": false,
+		"created_at": "2026-01-15T10:30:00Z", "updated_at": "2026-02-04T14:22:00Z",
+		"url": "https://recuerd0.com/workspaces/1",
+	}
+	goldenMemorySummary = map[string]interface{}{
+		"id": float64(7), "title": "Meeting Notes", "version": float64(1), "source": "manual",
+		"tags":       []interface{}{"meetings", "q1"},
+		"created_at": "2026-01-20T09:00:00Z", "updated_at": "2026-02-03T16:45:00Z",
+		"url": "https://recuerd0.com/workspaces/1/memories/7",
+	}
+	goldenSearch = map[string]interface{}{
+		"query": "architecture", "total_results": float64(1),
+		"results": []interface{}{map[string]interface{}{
+			"id": float64(7), "title": "Design Doc", "version": float64(1), "version_label": "v1",
+			"has_versions": false, "tags": []interface{}{"design"}, "source": "manual",
+			"snippet":    "Initial architecture overview...",
+			"created_at": "2026-01-20T09:00:00Z", "updated_at": "2026-02-03T16:45:00Z",
+			"url":       "https://recuerd0.com/workspaces/1/memories/7",
+			"workspace": map[string]interface{}{"id": float64(1), "name": "Project Alpha", "url": "https://recuerd0.com/workspaces/1"},
+		}},
+	}
+)
+
+func goldenMemory() map[string]interface{} {
+	m := map[string]interface{}{}
+	for k, v := range goldenMemorySummary {
+		m[k] = v
+	}
+	m["content"] = map[string]interface{}{"body": "# Meeting Notes\n\nDiscussed Q1 goals."}
+	m["workspace"] = map[string]interface{}{"id": float64(1), "name": "Project Alpha", "url": "https://recuerd0.com/workspaces/1"}
+	return m
+}
+
+// goldenNow stands in for the clock wherever it reaches the output.
+var goldenNow = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Secret keys for the key commands, which otherwise generate random ones.
+const (
+	goldenSecretKey  = "RECUERD0-SECRET-KEY-1:UYgAKb-npweaEeZr4qAOzkDmRyiWaHgH6SBIJ9QlEOA"
+	goldenRotatedKey = "RECUERD0-SECRET-KEY-1:zLfuiRhgYuRaTHj4mRTmh_wF5zwnU80TbgNcg_-aVH4"
+)
+
+// goldenIdentity makes key generate and key rotate use secret instead of a
+// random key.
+func goldenIdentity(t *testing.T, secret string) {
+	t.Helper()
+	id, err := crypt.ParseIdentity(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	orig := generateIdentity
+	generateIdentity = func() (*crypt.Identity, error) { return id, nil }
+	t.Cleanup(func() { generateIdentity = orig })
+}
+
+// goldenKeyring stores a "team" key holding goldenSecretKey, used to
+// encrypt workspace 1.
+func goldenKeyring(t *testing.T) {
+	t.Helper()
+	id, _ := crypt.ParseIdentity(goldenSecretKey)
+	if err := crypt.SaveKeyring(config.KeyDir(), &crypt.Keyring{Name: "team", Identities: []*crypt.Identity{id}}); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Encryption = map[string]string{"1": "team"}
+}
+
+// goldenAccounts adds the accounts personal, which is current, and work.
+func goldenAccounts(t *testing.T) {
+	t.Helper()
+	for _, name := range []string{"personal", "work"} {
+		if err := config.AddAccount(name, "tok_"+name+"_123456", ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, env := range []string{"RECUERD0_ACCOUNT", "RECUERD0_TOKEN", "RECUERD0_API_URL", "RECUERD0_WORKSPACE"} {
+		t.Setenv(env, "")
+	}
+}
+
+// goldenTrash puts memory 7 of workspace 1 in the trash.
+func goldenTrash(t *testing.T) {
+	t.Helper()
+	if err := trash.Put(&trash.Entry{MemoryID: "7", WorkspaceID: "1", APIURL: "https://recuerd0.com/api/v1", DeletedAt: goldenNow, Memory: goldenMemory()}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// goldenFile writes content to name in the working directory.
+func goldenFile(t *testing.T, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(name, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func goldenTagged(tags ...interface{}) map[string]interface{} {
+	m := goldenMemory()
+	m["tags"] = tags
+	return m
+}
+
+// goldenCase runs args as a command line against mock, after setup has
+// written any local files the command reads.
+type goldenCase struct {
+	name string
+	args []string
+	// data is the schema of data, when it has one; list means an array
+	// of them.
+	data  string
+	list  bool
+	mock  func() *MockClient
+	setup func(t *testing.T)
+}
+
+// goldenExempt lists the commands TestGoldenOutput does not cover and why.
+// Every other command in the catalog needs a case.
+var goldenExempt = map[string]string{
+	"memory cat":     "prints the memory's content, not an envelope",
+	"run-breadcrumb": "prints the output of the command it runs",
+	"schema":         "prints a JSON Schema document, checked in schema_test.go",
+	"shell":          "interactive",
+	"tui":            "interactive",
+}
+
+// TestGoldenOutput runs commands against API fixtures, compares their
+// output with testdata/golden and validates it against the published
+// schemas. Run with -update after an intended change; a change to the
+// shape of the output also needs response.SchemaVersion bumped. A command
+// added to the catalog without a case fails the test.
+func TestGoldenOutput(t *testing.T) {
+	tests := []goldenCase{
+		{name: "workspace_list", args: []string{"workspace", "list"}, data: "workspace", list: true,
+			mock: func() *MockClient {
+				return NewMockClient().WithGetData([]interface{}{goldenWorkspace}).WithGetLinkNext("https://recuerd0.com/api/v1/workspaces?page=2")
+			}},
+		{name: "workspace_show", args: []string{"workspace", "show", "1"}, data: "workspace",
+			mock: func() *MockClient { return NewMockClient().WithGetData(goldenWorkspace) }},
+		{name: "workspace_create", args: []string{"workspace", "create", "--name", "Project Alpha"}, data: "workspace",
+			mock: func() *MockClient { return NewMockClient().WithPostData(goldenWorkspace) }},
+		{name: "workspace_update", args: []string{"workspace", "update", "1", "--description", "Main project workspace"}, data: "workspace",
+			mock: func() *MockClient { return NewMockClient().WithPatchData(goldenWorkspace) }},
+		{name: "workspace_archive", args: []string{"workspace", "archive", "1"}, data: "workspace",
+			mock: func() *MockClient {
+				archived := map[string]interface{}{}
+				for k, v := range goldenWorkspace {
+					archived[k] = v
+				}
+				archived["archived"] = true
+				return NewMockClient().WithPostData(archived)
+			}},
+		{name: "memory_list", args: []string{"memory", "list"}, data: "memory", list: true,
+			mock: func() *MockClient { return NewMockClient().WithGetData([]interface{}{goldenMemorySummary}) }},
+		{name: "memory_show", args: []string{"memory", "show", "7"}, data: "memory",
+			mock: func() *MockClient { return NewMockClient().WithGetData(goldenMemory()) }},
+		{name: "memory_create", args: []string{"memory", "create", "--title", "Meeting Notes", "--content", "# Meeting Notes", "--tags", "meetings,q1"}, data: "memory",
+			mock: func() *MockClient { return NewMockClient().WithPostData(goldenMemory()) }},
+		{name: "memory_update", args: []string{"memory", "update", "7", "--title", "Meeting Notes"}, data: "memory",
+			mock: func() *MockClient { return NewMockClient().WithPatchData(goldenMemory()) }},
+		{name: "memory_version_create", args: []string{"memory", "version", "create", "7", "--content", "# Meeting Notes"}, data: "memory",
+			mock: func() *MockClient { return NewMockClient().WithPostData(goldenMemory()) }},
+		{name: "search", args: []string{"search", "architecture"}, data: "search",
+			mock: func() *MockClient { return NewMockClient().WithGetData(goldenSearch) }},
+		{name: "workspace_unarchive", args: []string{"workspace", "unarchive", "1"}, data: "workspace",
+			mock: func() *MockClient {
+				m := NewMockClient()
+				m.DeleteResponse = &client.APIResponse{StatusCode: 200, Data: goldenWorkspace}
+				return m
+			}},
+		{name: "memory_delete", args: []string{"memory", "delete", "7", "--yes"},
+			mock: func() *MockClient { return NewMockClient().WithGetData(goldenMemory()) },
+			setup: func(t *testing.T) {
+				orig := trashNow
+				trashNow = func() time.Time { return goldenNow }
+				t.Cleanup(func() { trashNow = orig })
+			}},
+		{name: "memory_delete_dry_run", args: []string{"memory", "delete", "7", "--dry-run"},
+			mock: func() *MockClient { return NewMockClient().WithGetData(goldenMemory()) },
+			setup: func(t *testing.T) {
+				orig := trashNow
+				trashNow = func() time.Time { return goldenNow }
+				t.Cleanup(func() { trashNow = orig })
+			}},
+		{name: "memory_import_transcript", args: []string{"memory", "import-transcript", "conversations.json", "--tags", "chat"}, data: "memory",
+			mock: func() *MockClient { return NewMockClient().WithPostData(goldenMemory()) },
+			setup: func(t *testing.T) {
+				goldenFile(t, "conversations.json", `[{"name": "Planning", "chat_messages": [{"sender": "human", "text": "Hello"}, {"sender": "assistant", "text": "Hi"}]}]`)
+			}},
+		{name: "memory_tag_add", args: []string{"memory", "tag", "add", "7", "design"},
+			mock: func() *MockClient {
+				return NewMockClient().WithGetPathData("/workspaces/1/memories/7", goldenMemory()).WithPatchData(goldenTagged("meetings", "q1", "design"))
+			}},
+		{name: "memory_tag_remove", args: []string{"memory", "tag", "remove", "7", "q1"},
+			mock: func() *MockClient {
+				return NewMockClient().WithGetPathData("/workspaces/1/memories/7", goldenMemory()).WithPatchData(goldenTagged("meetings"))
+			}},
+		{name: "tag_list", args: []string{"tag", "list"},
+			mock: func() *MockClient { return NewMockClient().WithGetData([]interface{}{goldenMemorySummary}) }},
+		{name: "tag_rename", args: []string{"tag", "rename", "q1", "2026-q1"},
+			mock: func() *MockClient {
+				return NewMockClient().WithGetData([]interface{}{goldenMemorySummary}).WithPatchData(goldenTagged("meetings", "2026-q1"))
+			}},
+		{name: "trash_list", args: []string{"trash", "list"}, setup: goldenTrash},
+		{name: "trash_restore", args: []string{"trash", "restore", "7"}, data: "memory",
+			mock:  func() *MockClient { return NewMockClient().WithPostData(goldenMemory()) },
+			setup: goldenTrash},
+		{name: "context", args: []string{"context", "architecture"},
+			mock: func() *MockClient {
+				return NewMockClient().WithGetPathData("/search?q=architecture", goldenSearch).WithGetPathData("/workspaces/1/memories/7", goldenMemory())
+			}},
+		{name: "batch", args: []string{"batch"},
+			mock: func() *MockClient { return NewMockClient().WithGetData(goldenMemory()).WithPostData(goldenMemory()) },
+			setup: func(t *testing.T) {
+				origOutput, origReader := batchOutput, stdinReader
+				batchOutput = io.Discard
+				stdinReader = func() io.Reader {
+					return strings.NewReader(`{"op":"memory.create","title":"Meeting Notes","content":"# Meeting Notes"}
+{"op":"memory.show","id":7}
+`)
+				}
+				t.Cleanup(func() { batchOutput, stdinReader = origOutput, origReader })
+			}},
+		{name: "audit_log", args: []string{"audit", "log"},
+			setup: func(t *testing.T) {
+				_ = audit.Append(audit.Record{Time: goldenNow, Account: "personal", Command: "recuerd0 memory update --title [REDACTED] 7",
+					Method: "PATCH", Path: "/workspaces/1/memories/7", Workspace: "1", MemoryID: "7", Status: 200, Result: audit.ResultOK})
+			}},
+		{name: "key_generate", args: []string{"key", "generate", "personal"},
+			setup: func(t *testing.T) { goldenIdentity(t, goldenSecretKey) }},
+		{name: "key_import", args: []string{"key", "import", "team", "shared.key"},
+			setup: func(t *testing.T) { goldenFile(t, "shared.key", "# from a teammate\n"+goldenSecretKey+"\n") }},
+		{name: "key_list", args: []string{"key", "list"}, setup: goldenKeyring},
+		{name: "key_rotate", args: []string{"key", "rotate", "team"},
+			setup: func(t *testing.T) {
+				goldenKeyring(t)
+				goldenIdentity(t, goldenRotatedKey)
+			}},
+		{name: "account_add", args: []string{"account", "add", "personal", "--token", "tok_personal_123456"}},
+		{name: "account_list", args: []string{"account", "list"}, setup: goldenAccounts},
+		{name: "account_select", args: []string{"account", "select", "work"}, setup: goldenAccounts},
+		{name: "account_remove", args: []string{"account", "remove", "work"}, setup: goldenAccounts},
+		{name: "config_explain", args: []string{"config", "explain"}, setup: goldenAccounts},
+		{name: "config_get", args: []string{"config", "get", "accounts.work"}, setup: goldenAccounts},
+		{name: "config_list", args: []string{"config", "list"}, setup: goldenAccounts},
+		{name: "config_set", args: []string{"config", "set", "--local", "workspace", "1"}},
+		{name: "config_unset", args: []string{"config", "unset", "--local", "workspace"},
+			setup: func(t *testing.T) { goldenFile(t, ".recuerd0.yaml", "workspace: \"1\"\n") }},
+		{name: "init", args: []string{"init", "--account", "personal", "--workspace", "1"},
+			mock:  func() *MockClient { return NewMockClient().WithGetData(goldenWorkspace) },
+			setup: goldenAccounts},
+		{name: "doctor", args: []string{"doctor"},
+			mock: func() *MockClient {
+				m := NewMockClient()
+				m.GetResponse = &client.APIResponse{StatusCode: 200, Data: []interface{}{goldenWorkspace}, Header: http.Header{
+					"Date":                  []string{goldenNow.Format(http.TimeFormat)},
+					"X-Ratelimit-Limit":     []string{"100"},
+					"X-Ratelimit-Remaining": []string{"97"},
+				}}
+				return m
+			},
+			setup: func(t *testing.T) {
+				goldenAccounts(t)
+				origLookup, origTLS, origNow := doctorLookupHost, doctorTLSProbe, doctorNow
+				doctorLookupHost = func(string) ([]string, error) { return []string{"127.0.0.1"}, nil }
+				doctorTLSProbe = func(string, *tls.Config) (time.Time, error) { return goldenNow.AddDate(0, 3, 0), nil }
+				doctorNow = func() time.Time { return goldenNow }
+				t.Cleanup(func() { doctorLookupHost, doctorTLSProbe, doctorNow = origLookup, origTLS, origNow })
+			}},
+		{name: "commands", args: []string{"commands"}},
+		{name: "skill_generate", args: []string{"skill", "generate", "--output", "SKILL.md"}},
+		{name: "version", args: []string{"version"}},
+		{name: "memory_update_no_fields", args: []string{"memory", "update", "7"},
+			mock: NewMockClient},
+		{name: "memory_show_not_found", args: []string{"memory", "show", "99"},
+			mock: func() *MockClient {
+				m := NewMockClient()
+				m.GetError = errors.NewNotFoundError("Memory not found")
+				return m
+			}},
+	}
+
+	golden, err := filepath.Abs(filepath.Join("testdata", "golden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	covered := map[string]bool{}
+	envelope, _ := schema.Document("envelope")
+	failure, _ := schema.Document("error")
+	ran := 0
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ran++
+			// Local files go to fresh directories, named in the output by
+			// placeholders so it does not change from run to run.
+			configDir, workDir := t.TempDir(), t.TempDir()
+			config.SetConfigDir(configDir)
+			t.Chdir(workDir)
+			mock := NewMockClient
+			if tt.mock != nil {
+				mock = tt.mock
+			}
+			result := SetTestMode(mock())
+			SetTestConfigFull("tok_test", "https://recuerd0.com/api/v1", "1")
+			defer func() {
+				ResetTestMode()
+				config.SetConfigDir("")
+			}()
+			defer resetFlags(rootCmd)
+			if tt.setup != nil {
+				tt.setup(t)
+			}
+
+			RunTestCommand(func() {
+				rootCmd.SetArgs(tt.args)
+				if err := rootCmd.Execute(); err != nil {
+					t.Errorf("%v: %v", tt.args, err)
+				}
+			})
+			if result.Response == nil {
+				t.Fatal("expected a response")
+			}
+			if currentCmd != nil {
+				covered[strings.TrimPrefix(currentCmd.CommandPath(), rootCmd.Name()+" ")] = true
+			}
+			result.Response.Meta["timestamp"] = "2026-01-01T00:00:00Z"
+			got, err := json.MarshalIndent(result.Response, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, '\n')
+			for dir, name := range map[string]string{configDir: "<config_dir>", workDir: "<work_dir>", testCacheDir: "<cache_dir>"} {
+				quoted, _ := json.Marshal(dir)
+				got = bytes.ReplaceAll(got, bytes.Trim(quoted, `"`), []byte(name))
+			}
+
+			path := filepath.Join(golden, tt.name+".json")
+			if *updateGolden {
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, got, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("%v; run: go test ./internal/commands -run TestGoldenOutput -update", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("output differs from %s:\n%s", path, got)
+			}
+
+			var out map[string]interface{}
+			if err := json.Unmarshal(got, &out); err != nil {
+				t.Fatal(err)
+			}
+			env := envelope
+			if !result.Response.Success {
+				env = failure
+			}
+			if err := schema.Validate(env, out); err != nil {
+				t.Errorf("envelope: %v", err)
+			}
+			if tt.data == "" {
+				return
+			}
+			data, _ := schema.Document(tt.data)
+			if tt.list {
+				data = map[string]interface{}{"type": "array", "items": data}
+			}
+			if err := schema.Validate(data, out["data"]); err != nil {
+				t.Errorf("data: %v", err)
+			}
+		})
+	}
+
+	if ran < len(tests) {
+		return
+	}
+	for _, c := range catalog.Build(rootCmd, "").Commands {
+		if !covered[c.Path] && goldenExempt[c.Path] == "" {
+			t.Errorf("%s has no case in TestGoldenOutput; add one or list it in goldenExempt", c.Path)
+		}
+	}
+}
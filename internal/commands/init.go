@@ -0,0 +1,160 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maquina/recuerd0-cli/internal/config"
+	"github.com/maquina/recuerd0-cli/internal/errors"
+	"github.com/maquina/recuerd0-cli/internal/response"
+)
+
+var initForce bool
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Create .recuerd0.yaml for the current project",
+	Long: `Create .recuerd0.yaml in the current directory, pinning an account and a workspace.
+
+Pass --account and --workspace to run non-interactively. On a terminal, missing
+values are picked from a list.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cwd, err := os.Getwd()
+		if err != nil {
+			exitWithError(errors.NewError(fmt.Sprintf("getting working directory: %v", err)))
+			return
+		}
+		path := filepath.Join(cwd, ".recuerd0.yaml")
+		if _, err := os.Stat(path); err == nil && !initForce {
+			exitWithError(errors.NewInvalidArgsError(fmt.Sprintf("%s already exists; use --force to overwrite", path)))
+			return
+		}
+
+		globalCfg, err := config.ListAccounts()
+		if err != nil {
+			exitWithError(errors.NewError(fmt.Sprintf("listing accounts: %v", err)))
+			return
+		}
+		if len(globalCfg.Accounts) == 0 {
+			exitWithError(errors.NewAuthError("No accounts configured. Run: recuerd0 account add <name> --token TOKEN"))
+			return
+		}
+
+		// Pick the account
+		account := cfgAccount
+		if account == "" {
+			names := make([]string, 0, len(globalCfg.Accounts))
+			for name := range globalCfg.Accounts {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			switch {
+			case len(names) == 1:
+				account = names[0]
+			case stdinIsTerminal():
+				def := sort.SearchStrings(names, globalCfg.Current)
+				if def >= len(names) || names[def] != globalCfg.Current {
+					def = -1
+				}
+				idx, err := promptChoice("Select an account:", names, def)
+				if err != nil {
+					exitWithError(errors.NewInvalidArgsError(err.Error()))
+					return
+				}
+				account = names[idx]
+			default:
+				account = globalCfg.Current
+			}
+		}
+		if _, ok := globalCfg.Accounts[account]; !ok {
+			exitWithError(errors.NewInvalidArgsError(fmt.Sprintf("account %q not found. Run: recuerd0 account list", account)))
+			return
+		}
+
+		resolved, err := config.Resolve(config.ResolvedConfig{Account: account, Token: cfgToken, APIURL: cfgAPIURL})
+		if err != nil {
+			exitWithError(errors.NewError(fmt.Sprintf("loading config: %v", err)))
+			return
+		}
+		cfg = resolved
+		if err := requireAuth(); err != nil {
+			exitWithError(err)
+			return
+		}
+
+		apiClient := getClient()
+
+		// Pick the workspace
+		workspace := cfgWorkspace
+		if workspace == "" {
+			if !stdinIsTerminal() {
+				exitWithError(errors.NewInvalidArgsError("--workspace is required when not running interactively"))
+				return
+			}
+			resp, err := apiClient.Get("/workspaces")
+			if err != nil {
+				exitWithError(err)
+				return
+			}
+			items, _ := resp.Data.([]interface{})
+			if len(items) == 0 {
+				exitWithError(errors.NewNotFoundError("No workspaces found. Run: recuerd0 workspace create --name NAME"))
+				return
+			}
+			ids := make([]string, 0, len(items))
+			labels := make([]string, 0, len(items))
+			for _, item := range items {
+				m, _ := item.(map[string]interface{})
+				ids = append(ids, fmt.Sprint(m["id"]))
+				labels = append(labels, fmt.Sprintf("%v (id %v)", m["name"], m["id"]))
+			}
+			idx, err := promptChoice("Select a workspace:", labels, -1)
+			if err != nil {
+				exitWithError(errors.NewInvalidArgsError(err.Error()))
+				return
+			}
+			workspace = ids[idx]
+		}
+
+		workspace, err = lookupWorkspaceID(workspace)
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		if _, err := apiClient.Get("/workspaces/" + workspace); err != nil {
+			exitWithError(err)
+			return
+		}
+
+		local, err := config.LoadLocal(path)
+		if err != nil {
+			exitWithError(errors.NewError(fmt.Sprintf("loading %s: %v", path, err)))
+			return
+		}
+		local.Account = account
+		local.Workspace = workspace
+		if err := config.SaveLocal(path, local); err != nil {
+			exitWithError(errors.NewError(fmt.Sprintf("writing %s: %v", path, err)))
+			return
+		}
+
+		printSuccessWithBreadcrumbs(
+			map[string]string{"path": path, "account": account, "workspace": workspace},
+			fmt.Sprintf("Created %s", path),
+			[]response.Breadcrumb{
+				breadcrumb("list", "recuerd0 memory list", "List memories in the project workspace"),
+				breadcrumb("explain", "recuerd0 config explain", "Show effective settings and their sources"),
+			},
+		)
+	},
+}
+
+func init() {
+	initCmd.Flags().BoolVar(&initForce, "force", false, "overwrite an existing .recuerd0.yaml")
+	rootCmd.AddCommand(initCmd)
+}
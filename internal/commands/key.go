@@ -0,0 +1,347 @@
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maquina/recuerd0-cli/internal/catalog"
+	"github.com/maquina/recuerd0-cli/internal/config"
+	"github.com/maquina/recuerd0-cli/internal/crypt"
+	"github.com/maquina/recuerd0-cli/internal/errors"
+	"github.com/maquina/recuerd0-cli/internal/response"
+)
+
+var keyCmd = &cobra.Command{
+	Use:   "key",
+	Short: "Manage encryption keys",
+	Long: `Manage the keys used to encrypt memory content in encrypted workspaces.
+
+Keys are stored in the keys directory next to the global config file, one
+file per key name. A key file can hold several secret keys: the last one is
+used to encrypt, older ones are kept to decrypt memories written before a
+rotation. Enable encryption for a workspace by mapping its ID to a key name:
+
+  recuerd0 config set --local encryption.<workspace_id> <key_name>`,
+}
+
+// generateIdentity creates the secret keys of key generate and key rotate,
+// overridable for tests.
+var generateIdentity = crypt.GenerateIdentity
+
+// key generate
+var keyGenerateCmd = &cobra.Command{
+	Use:         "generate <name>",
+	Short:       "Generate a new encryption key",
+	Annotations: map[string]string{catalog.Local: "true"},
+	Args:        cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		if err := keyNameArg(name); err != nil {
+			exitWithError(err)
+			return
+		}
+		dir := config.KeyDir()
+		if crypt.KeyringExists(dir, name) {
+			exitWithError(errors.NewInvalidArgsError(fmt.Sprintf("key %q already exists; use key rotate to replace it", name)))
+			return
+		}
+
+		id, err := generateIdentity()
+		if err != nil {
+			exitWithError(errors.NewError(err.Error()))
+			return
+		}
+		k := &crypt.Keyring{Name: name, Identities: []*crypt.Identity{id}}
+		if err := saveKeyring(dir, k); err != nil {
+			exitWithError(err)
+			return
+		}
+
+		printSuccessWithBreadcrumbs(describeKeyring(k), fmt.Sprintf("Key %q generated; back up %s", name, dir), []response.Breadcrumb{
+			breadcrumb("enable", fmt.Sprintf("recuerd0 config set --local encryption.<workspace_id> %s", name), "Encrypt a workspace with this key"),
+			breadcrumb("list", "recuerd0 key list", "List keys"),
+		})
+	},
+}
+
+// key import
+var keyImportCmd = &cobra.Command{
+	Use:   "import <name> <file>",
+	Short: "Import secret keys into a key",
+	Long: `Import secret keys shared by a teammate or restored from a backup. The file
+holds one RECUERD0-SECRET-KEY-1 line per key; lines starting with # are
+ignored. Imported keys are added to the named key, creating it if needed,
+and the last one becomes the key used to encrypt. Use - to read from stdin.`,
+	Annotations: map[string]string{catalog.Local: "true"},
+	Args:        cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		if err := keyNameArg(name); err != nil {
+			exitWithError(err)
+			return
+		}
+
+		var (
+			data []byte
+			err  error
+		)
+		if args[1] == "-" {
+			data, err = io.ReadAll(stdinReader())
+		} else {
+			data, err = os.ReadFile(args[1])
+		}
+		if err != nil {
+			if os.IsNotExist(err) {
+				exitWithError(errors.NewInvalidArgsError(fmt.Sprintf("key file not found: %s", args[1])))
+				return
+			}
+			exitWithError(errors.NewError(fmt.Sprintf("reading key file: %v", err)))
+			return
+		}
+		imported, err := parseIdentities(data)
+		if err != nil {
+			exitWithError(errors.NewInvalidArgsError(err.Error()))
+			return
+		}
+
+		dir := config.KeyDir()
+		k := &crypt.Keyring{Name: name}
+		if crypt.KeyringExists(dir, name) {
+			if k, err = crypt.LoadKeyring(dir, name); err != nil {
+				exitWithError(errors.NewError(err.Error()))
+				return
+			}
+		}
+		have := map[string]bool{}
+		for _, id := range k.Identities {
+			have[id.String()] = true
+		}
+		added := 0
+		for _, id := range imported {
+			if have[id.String()] {
+				continue
+			}
+			have[id.String()] = true
+			k.Identities = append(k.Identities, id)
+			added++
+		}
+		if err := saveKeyring(dir, k); err != nil {
+			exitWithError(err)
+			return
+		}
+
+		printSuccessWithBreadcrumbs(describeKeyring(k), fmt.Sprintf("Imported %d new secret key(s) into %q", added, name), []response.Breadcrumb{
+			breadcrumb("list", "recuerd0 key list", "List keys"),
+		})
+	},
+}
+
+// parseIdentities reads secret keys, one per line, skipping comments.
+func parseIdentities(data []byte) ([]*crypt.Identity, error) {
+	var ids []*crypt.Identity
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		id, err := crypt.ParseIdentity(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", n, err)
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no secret keys found")
+	}
+	return ids, nil
+}
+
+// key list
+var keyListCmd = &cobra.Command{
+	Use:         "list",
+	Short:       "List encryption keys",
+	Annotations: map[string]string{catalog.Local: "true"},
+	Run: func(cmd *cobra.Command, args []string) {
+		dir := config.KeyDir()
+		names, err := crypt.ListKeyrings(dir)
+		if err != nil {
+			exitWithError(errors.NewError(fmt.Sprintf("listing keys: %v", err)))
+			return
+		}
+		keys := make([]interface{}, 0, len(names))
+		for _, name := range names {
+			k, err := crypt.LoadKeyring(dir, name)
+			if err != nil {
+				exitWithError(errors.NewError(err.Error()))
+				return
+			}
+			keys = append(keys, describeKeyring(k))
+		}
+
+		printSuccessWithBreadcrumbs(keys, fmt.Sprintf("%d key(s)", len(keys)), []response.Breadcrumb{
+			breadcrumb("generate", "recuerd0 key generate <name>", "Generate a key"),
+		})
+	},
+}
+
+// key rotate
+var (
+	keyRotateReencrypt bool
+	keyRotateWorkspace string
+)
+
+var keyRotateCmd = &cobra.Command{
+	Use:   "rotate <name>",
+	Short: "Add a new secret key and encrypt with it from now on",
+	Long: `Add a new secret key to a key. New content is encrypted with it; older
+secret keys are kept so existing memories can still be read.
+
+With --reencrypt, every encrypted memory in the workspace is decrypted and
+encrypted again with the new secret key, after which the old ones can be
+retired.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		if err := keyNameArg(name); err != nil {
+			exitWithError(err)
+			return
+		}
+
+		var ws string
+		if keyRotateReencrypt {
+			if err := requireAuth(); err != nil {
+				exitWithError(err)
+				return
+			}
+			var err error
+			if ws, err = resolveWorkspace(keyRotateWorkspace); err != nil {
+				exitWithError(err)
+				return
+			}
+			if cfg.Encryption[ws] != name {
+				exitWithError(errors.NewInvalidArgsError(fmt.Sprintf("workspace %s is not encrypted with key %q", ws, name)))
+				return
+			}
+		}
+
+		dir := config.KeyDir()
+		k, err := crypt.LoadKeyring(dir, name)
+		if err != nil {
+			exitWithError(errors.NewNotFoundError(err.Error()))
+			return
+		}
+		id, err := generateIdentity()
+		if err != nil {
+			exitWithError(errors.NewError(err.Error()))
+			return
+		}
+		k.Identities = append(k.Identities, id)
+		if err := saveKeyring(dir, k); err != nil {
+			exitWithError(err)
+			return
+		}
+
+		data := describeKeyring(k)
+		summary := fmt.Sprintf("Key %q rotated", name)
+		if keyRotateReencrypt {
+			stats, err := reencryptWorkspace(ws, k)
+			data["reencrypt"] = stats
+			if err != nil {
+				exitWithError(err)
+				return
+			}
+			summary = fmt.Sprintf("Key %q rotated; re-encrypted %d memory(ies)", name, stats.Reencrypted)
+		}
+
+		printSuccessWithBreadcrumbs(data, summary, []response.Breadcrumb{
+			breadcrumb("list", "recuerd0 key list", "List keys"),
+		})
+	},
+}
+
+// saveKeyring writes k to dir, unless --dry-run is set.
+func saveKeyring(dir string, k *crypt.Keyring) error {
+	if skipLocalWrite("write", fmt.Sprintf("key %q", k.Name), crypt.KeyringPath(dir, k.Name)) {
+		return nil
+	}
+	if err := crypt.SaveKeyring(dir, k); err != nil {
+		return errors.NewError(fmt.Sprintf("saving key: %v", err))
+	}
+	return nil
+}
+
+// reencryptStats reports what key rotate --reencrypt did.
+type reencryptStats struct {
+	Workspace   string   `json:"workspace"`
+	Reencrypted int      `json:"reencrypted"`
+	Plaintext   int      `json:"plaintext"`
+	Failed      []string `json:"failed"`
+}
+
+// reencryptWorkspace re-encrypts every encrypted memory in ws with the
+// current key of k. Memories no local key can open are reported as failed.
+func reencryptWorkspace(ws string, k *crypt.Keyring) (*reencryptStats, error) {
+	stats := &reencryptStats{Workspace: ws, Failed: []string{}}
+	r := k.Current().Recipient()
+	apiClient := getClient()
+
+	items, err := fetchAllPages(apiClient, fmt.Sprintf("/workspaces/%s/memories", ws))
+	if err != nil {
+		return stats, err
+	}
+	var ids []string
+	for _, item := range items {
+		if m, ok := item.(map[string]interface{}); ok {
+			ids = append(ids, stringID(m["id"]))
+		}
+	}
+
+	for _, id := range ids {
+		memoryPath := fmt.Sprintf("/workspaces/%s/memories/%s", ws, id)
+		resp, err := apiClient.Get(memoryPath)
+		if err != nil {
+			return stats, err
+		}
+		m, _ := resp.Data.(map[string]interface{})
+		body := memoryBody(m)
+		if !crypt.IsEncrypted(body) {
+			stats.Plaintext++
+			continue
+		}
+		plaintext, _, err := decryptBody(body)
+		if err != nil {
+			stats.Failed = append(stats.Failed, id)
+			continue
+		}
+		armored, err := crypt.Encrypt([]byte(plaintext), r)
+		if err != nil {
+			return stats, errors.NewError(fmt.Sprintf("encrypting content: %v", err))
+		}
+		patch := map[string]interface{}{"memory": map[string]interface{}{"content": armored}}
+		if _, err := apiClient.Patch(memoryPath, patch); err != nil {
+			return stats, err
+		}
+		title, _ := m["title"].(string)
+		indexMemory(ws, id, title, plaintext)
+		stats.Reencrypted++
+	}
+	return stats, nil
+}
+
+func init() {
+	rootCmd.AddCommand(keyCmd)
+	keyCmd.AddCommand(keyGenerateCmd)
+	keyCmd.AddCommand(keyImportCmd)
+	keyCmd.AddCommand(keyListCmd)
+
+	keyRotateCmd.Flags().BoolVar(&keyRotateReencrypt, "reencrypt", false, "re-encrypt existing memories with the new key")
+	keyRotateCmd.Flags().StringVar(&keyRotateWorkspace, "workspace", "", "workspace to re-encrypt (ID, name or alias)")
+	keyCmd.AddCommand(keyRotateCmd)
+}
@@ -5,11 +5,14 @@ import (
 	"io"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/maquina/recuerd0-cli/internal/client"
 	"github.com/maquina/recuerd0-cli/internal/errors"
 	"github.com/maquina/recuerd0-cli/internal/response"
+	"github.com/maquina/recuerd0-cli/internal/trash"
 )
 
 var memoryCmd = &cobra.Command{
@@ -17,28 +20,50 @@ var memoryCmd = &cobra.Command{
 	Short: "Manage memories",
 }
 
-// resolveWorkspace gets workspace from flag or config.
+// resolveWorkspace gets workspace from flag or config and turns names and
+// aliases into an ID.
 func resolveWorkspace(flagVal string) (string, error) {
-	if flagVal != "" {
-		return flagVal, nil
+	ref := flagVal
+	if ref == "" {
+		ws, err := requireWorkspace()
+		if err != nil {
+			return "", err
+		}
+		ref = ws
 	}
-	return requireWorkspace()
+	return lookupWorkspaceID(ref)
 }
 
 // memory list
 var (
 	memoryListWorkspace string
 	memoryListPage      string
+	memoryListFilter    listFilterFlags
+	memoryListStream    bool
 )
 
 var memoryListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List memories in a workspace",
+	Example: `  recuerd0 memory list --workspace 22
+  recuerd0 memory list --workspace 22 --tag design --since 7d --sort updated_at --reverse
+  recuerd0 memory list --workspace 22 --stream > memories.ndjson`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if err := requireAuth(); err != nil {
 			exitWithError(err)
 			return
 		}
+		lf, err := memoryListFilter.compile()
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		if memoryListStream {
+			if err := validStream(lf); err != nil {
+				exitWithError(err)
+				return
+			}
+		}
 		ws, err := resolveWorkspace(memoryListWorkspace)
 		if err != nil {
 			exitWithError(err)
@@ -50,7 +75,31 @@ var memoryListCmd = &cobra.Command{
 			path += "?page=" + memoryListPage
 		}
 
+		bc := []response.Breadcrumb{
+			breadcrumb("show", fmt.Sprintf("recuerd0 memory show --workspace %s <memory_id>", ws), "View memory details"),
+			breadcrumb("create", fmt.Sprintf("recuerd0 memory create --workspace %s --title <title> --content <content>", ws), "Create a memory"),
+		}
+
 		apiClient := getClient()
+		if memoryListStream {
+			listing, err := streamListing(apiClient, path, memoryListPage != "", lf)
+			if err != nil {
+				exitWithError(err)
+				return
+			}
+			printSuccessWithPageAndBreadcrumbs(listing.Data, listing.Pagination, listing.summary("memory(ies)", lf), bc)
+			return
+		}
+		if lf.active() {
+			listing, err := fetchFiltered(apiClient, path, memoryListPage != "", lf)
+			if err != nil {
+				exitWithError(err)
+				return
+			}
+			printSuccessWithPageAndBreadcrumbs(listing.Data, listing.Pagination, listing.summary("memory(ies)", lf), bc)
+			return
+		}
+
 		resp, err := apiClient.GetWithPagination(path)
 		if err != nil {
 			exitWithError(err)
@@ -61,23 +110,33 @@ var memoryListCmd = &cobra.Command{
 		items := countItems(resp.Data)
 		summary := fmt.Sprintf("%d memory(ies)", items)
 
-		bc := []response.Breadcrumb{
-			breadcrumb("show", fmt.Sprintf("recuerd0 memory show --workspace %s <memory_id>", ws), "View memory details"),
-			breadcrumb("create", fmt.Sprintf("recuerd0 memory create --workspace %s --title TITLE --content CONTENT", ws), "Create a memory"),
-		}
-
 		printSuccessWithPaginationAndBreadcrumbs(resp.Data, hasNext, resp.LinkNext, summary, bc)
 	},
 }
 
 // memory show
-var memoryShowWorkspace string
+var (
+	memoryShowWorkspace string
+	memoryShowRender    bool
+)
 
 var memoryShowCmd = &cobra.Command{
 	Use:   "show <memory_id>",
 	Short: "Show memory details",
-	Args:  cobra.ExactArgs(1),
+	Long: `Show memory details.
+
+With --render only the content is printed, as Markdown rendered for the
+terminal, or as plain text when stdout is not a terminal. memory cat
+prints the content without rendering.`,
+	Example: `  recuerd0 memory show --workspace 22 42
+  recuerd0 memory show --workspace 22 42 --render`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeSingleMemoryID,
 	Run: func(cmd *cobra.Command, args []string) {
+		if memoryShowRender {
+			runMemoryBody(memoryShowWorkspace, args[0], true)
+			return
+		}
 		if err := requireAuth(); err != nil {
 			exitWithError(err)
 			return
@@ -96,9 +155,13 @@ var memoryShowCmd = &cobra.Command{
 		}
 
 		bc := []response.Breadcrumb{
-			breadcrumb("update", fmt.Sprintf("recuerd0 memory update --workspace %s %s --title TITLE", ws, args[0]), "Update memory"),
+			breadcrumb("update", fmt.Sprintf("recuerd0 memory update --workspace %s %s --title <title>", ws, args[0]), "Update memory"),
 			breadcrumb("version", fmt.Sprintf("recuerd0 memory version create --workspace %s %s", ws, args[0]), "Create a version"),
-			breadcrumb("delete", fmt.Sprintf("recuerd0 memory delete --workspace %s %s", ws, args[0]), "Delete memory"),
+			breadcrumb("delete", fmt.Sprintf("recuerd0 memory delete --workspace %s %s --yes", ws, args[0]), "Delete memory"),
+		}
+
+		if plaintext, ok := decryptMemory(resp.Data); ok {
+			indexMemoryResponse(ws, resp.Data, plaintext)
 		}
 
 		printSuccessWithBreadcrumbs(resp.Data, "Memory details", bc)
@@ -107,16 +170,20 @@ var memoryShowCmd = &cobra.Command{
 
 // memory create
 var (
-	memoryCreateWorkspace string
-	memoryCreateTitle     string
-	memoryCreateContent   string
-	memoryCreateSource    string
-	memoryCreateTags      string
+	memoryCreateWorkspace    string
+	memoryCreateTitle        string
+	memoryCreateContent      string
+	memoryCreateSource       string
+	memoryCreateTags         string
+	memoryCreateNoDefault    bool
+	memoryCreateAllowSecrets bool
 )
 
 var memoryCreateCmd = &cobra.Command{
 	Use:   "create",
 	Short: "Create a new memory",
+	Example: `  recuerd0 memory create --workspace 22 --title "Redis caching pattern" --content "Use read-through caching" --tags "caching,redis"
+  cat notes.md | recuerd0 memory create --workspace 22 --title "Notes" --content -`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if err := requireAuth(); err != nil {
 			exitWithError(err)
@@ -151,6 +218,20 @@ var memoryCreateCmd = &cobra.Command{
 		if memoryCreateTags != "" {
 			memory["tags"] = parseTags(memoryCreateTags)
 		}
+		if !memoryCreateNoDefault {
+			if applied := applyProjectDefaults(memory, true); applied != nil {
+				setMeta("defaults_applied", applied)
+			}
+		}
+		if err := scanForSecrets(memory, memoryCreateAllowSecrets); err != nil {
+			exitWithError(err)
+			return
+		}
+		plaintext, err := encryptFields(ws, memory)
+		if err != nil {
+			exitWithError(err)
+			return
+		}
 
 		body := map[string]interface{}{"memory": memory}
 
@@ -161,8 +242,12 @@ var memoryCreateCmd = &cobra.Command{
 			return
 		}
 
+		if plaintext != "" {
+			indexMemoryResponse(ws, resp.Data, plaintext)
+		}
+
 		bc := []response.Breadcrumb{
-			breadcrumb("show", fmt.Sprintf("recuerd0 memory show --workspace %s <memory_id>", ws), "View created memory"),
+			breadcrumb("show", fmt.Sprintf("recuerd0 memory show --workspace %s %s", ws, responseID(resp.Data, "<memory_id>")), "View created memory"),
 			breadcrumb("list", fmt.Sprintf("recuerd0 memory list --workspace %s", ws), "List all memories"),
 		}
 
@@ -172,17 +257,21 @@ var memoryCreateCmd = &cobra.Command{
 
 // memory update
 var (
-	memoryUpdateWorkspace string
-	memoryUpdateTitle     string
-	memoryUpdateContent   string
-	memoryUpdateSource    string
-	memoryUpdateTags      string
+	memoryUpdateWorkspace    string
+	memoryUpdateTitle        string
+	memoryUpdateContent      string
+	memoryUpdateSource       string
+	memoryUpdateTags         string
+	memoryUpdateNoDefault    bool
+	memoryUpdateAllowSecrets bool
 )
 
 var memoryUpdateCmd = &cobra.Command{
-	Use:   "update <memory_id>",
-	Short: "Update an existing memory",
-	Args:  cobra.ExactArgs(1),
+	Use:               "update <memory_id>",
+	Short:             "Update an existing memory",
+	Example:           `  recuerd0 memory update --workspace 22 42 --title "Redis caching pattern" --tags "caching,redis"`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeSingleMemoryID,
 	Run: func(cmd *cobra.Command, args []string) {
 		if err := requireAuth(); err != nil {
 			exitWithError(err)
@@ -222,6 +311,20 @@ var memoryUpdateCmd = &cobra.Command{
 			exitWithError(errors.NewInvalidArgsError("at least one field to update is required"))
 			return
 		}
+		if !memoryUpdateNoDefault {
+			if applied := applyProjectDefaults(memory, false); applied != nil {
+				setMeta("defaults_applied", applied)
+			}
+		}
+		if err := scanForSecrets(memory, memoryUpdateAllowSecrets); err != nil {
+			exitWithError(err)
+			return
+		}
+		plaintext, err := encryptFields(ws, memory)
+		if err != nil {
+			exitWithError(err)
+			return
+		}
 
 		body := map[string]interface{}{"memory": memory}
 
@@ -232,6 +335,10 @@ var memoryUpdateCmd = &cobra.Command{
 			return
 		}
 
+		if plaintext != "" {
+			indexMemoryResponse(ws, resp.Data, plaintext)
+		}
+
 		bc := []response.Breadcrumb{
 			breadcrumb("show", fmt.Sprintf("recuerd0 memory show --workspace %s %s", ws, args[0]), "View updated memory"),
 		}
@@ -241,12 +348,22 @@ var memoryUpdateCmd = &cobra.Command{
 }
 
 // memory delete
-var memoryDeleteWorkspace string
+var (
+	memoryDeleteWorkspace string
+	memoryDeleteYes       bool
+)
 
 var memoryDeleteCmd = &cobra.Command{
 	Use:   "delete <memory_id>",
 	Short: "Delete a memory",
-	Args:  cobra.ExactArgs(1),
+	Long: `Delete a memory and all its versions.
+
+The memory is first saved to the local trash, from where trash restore can
+recreate it. Deleting asks for confirmation on a terminal; elsewhere --yes
+is required.`,
+	Example:           `  recuerd0 memory delete --workspace 22 42 --yes`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeSingleMemoryID,
 	Run: func(cmd *cobra.Command, args []string) {
 		if err := requireAuth(); err != nil {
 			exitWithError(err)
@@ -257,26 +374,75 @@ var memoryDeleteCmd = &cobra.Command{
 			exitWithError(err)
 			return
 		}
+		confirm := !memoryDeleteYes && !cfgDryRun
+		if confirm && !stdinIsTerminal() {
+			exitWithError(errors.NewInvalidArgsError("memory delete permanently removes the memory and all its versions; pass --yes to confirm"))
+			return
+		}
 
+		path := fmt.Sprintf("/workspaces/%s/memories/%s", ws, args[0])
 		apiClient := getClient()
-		_, err = apiClient.Delete(fmt.Sprintf("/workspaces/%s/memories/%s", ws, args[0]))
+		resp, err := apiClient.Get(path)
 		if err != nil {
 			exitWithError(err)
 			return
 		}
+		memory, _ := resp.Data.(map[string]interface{})
+		if confirm {
+			title, _ := memory["title"].(string)
+			if !promptConfirm(fmt.Sprintf("Delete memory %s %q and all its versions?", args[0], title)) {
+				exitWithError(errors.NewInvalidArgsError("deletion cancelled"))
+				return
+			}
+		}
 
+		entry, err := trashAndDelete(apiClient, ws, args[0], memory)
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+
+		data := map[string]string{"deleted": args[0]}
 		bc := []response.Breadcrumb{
 			breadcrumb("list", fmt.Sprintf("recuerd0 memory list --workspace %s", ws), "List remaining memories"),
 		}
+		if entry != nil {
+			data["trash_id"] = entry.ID
+			bc = append(bc, breadcrumb("restore", fmt.Sprintf("recuerd0 trash restore %s", entry.ID), "Recreate the deleted memory"))
+		}
 
-		printSuccessWithBreadcrumbs(
-			map[string]string{"deleted": args[0]},
-			fmt.Sprintf("Memory %s deleted", args[0]),
-			bc,
-		)
+		printSuccessWithBreadcrumbs(data, fmt.Sprintf("Memory %s deleted", args[0]), bc)
 	},
 }
 
+// trashNow returns the deletion time of trash entries, overridable for
+// tests.
+var trashNow = time.Now
+
+// trashAndDelete saves memory, fetched from ws, to the trash and then
+// deletes it. In a dry run nothing is saved and the entry is nil.
+func trashAndDelete(apiClient client.API, ws, id string, memory map[string]interface{}) (*trash.Entry, error) {
+	var entry *trash.Entry
+	deletedAt := trashNow().UTC()
+	file := trash.Path(trash.NewID(ws, id, deletedAt))
+	if !skipLocalWrite("write", fmt.Sprintf("trash entry for memory %s", id), file) {
+		entry = &trash.Entry{MemoryID: id, WorkspaceID: ws, APIURL: cfg.APIURL, DeletedAt: deletedAt, Memory: memory}
+		if err := trash.Put(entry); err != nil {
+			return nil, errors.NewError(fmt.Sprintf("saving memory to trash, nothing was deleted: %v", err))
+		}
+	}
+
+	if _, err := apiClient.Delete(fmt.Sprintf("/workspaces/%s/memories/%s", ws, id)); err != nil {
+		// Drop the snapshot only when the server answered: after a
+		// network error the memory may be gone anyway.
+		if cliErr, ok := err.(*errors.CLIError); ok && cliErr.Status != 0 && entry != nil {
+			_ = trash.Remove(entry.ID)
+		}
+		return nil, err
+	}
+	return entry, nil
+}
+
 func parseTags(s string) []string {
 	parts := strings.Split(s, ",")
 	tags := make([]string, 0, len(parts))
@@ -297,27 +463,35 @@ var stdinReader = func() io.Reader {
 func init() {
 	rootCmd.AddCommand(memoryCmd)
 
-	memoryListCmd.Flags().StringVar(&memoryListWorkspace, "workspace", "", "workspace ID")
+	memoryListCmd.Flags().StringVar(&memoryListWorkspace, "workspace", "", "workspace ID, name or alias")
 	memoryListCmd.Flags().StringVar(&memoryListPage, "page", "", "page number")
+	addListFilterFlags(memoryListCmd, &memoryListFilter)
+	addStreamFlag(memoryListCmd, &memoryListStream)
 	memoryCmd.AddCommand(memoryListCmd)
 
-	memoryShowCmd.Flags().StringVar(&memoryShowWorkspace, "workspace", "", "workspace ID")
+	memoryShowCmd.Flags().StringVar(&memoryShowWorkspace, "workspace", "", "workspace ID, name or alias")
+	memoryShowCmd.Flags().BoolVar(&memoryShowRender, "render", false, "print only the content, rendering Markdown on a terminal")
 	memoryCmd.AddCommand(memoryShowCmd)
 
-	memoryCreateCmd.Flags().StringVar(&memoryCreateWorkspace, "workspace", "", "workspace ID")
+	memoryCreateCmd.Flags().StringVar(&memoryCreateWorkspace, "workspace", "", "workspace ID, name or alias")
 	memoryCreateCmd.Flags().StringVar(&memoryCreateTitle, "title", "", "memory title")
 	memoryCreateCmd.Flags().StringVar(&memoryCreateContent, "content", "", "memory content (use - for stdin)")
 	memoryCreateCmd.Flags().StringVar(&memoryCreateSource, "source", "", "source of the memory")
 	memoryCreateCmd.Flags().StringVar(&memoryCreateTags, "tags", "", "comma-separated tags")
+	memoryCreateCmd.Flags().BoolVar(&memoryCreateNoDefault, "no-defaults", false, "ignore defaults from .recuerd0.yaml")
+	memoryCreateCmd.Flags().BoolVar(&memoryCreateAllowSecrets, "allow-secrets", false, "send content even if secrets are detected")
 	memoryCmd.AddCommand(memoryCreateCmd)
 
-	memoryUpdateCmd.Flags().StringVar(&memoryUpdateWorkspace, "workspace", "", "workspace ID")
+	memoryUpdateCmd.Flags().StringVar(&memoryUpdateWorkspace, "workspace", "", "workspace ID, name or alias")
 	memoryUpdateCmd.Flags().StringVar(&memoryUpdateTitle, "title", "", "memory title")
 	memoryUpdateCmd.Flags().StringVar(&memoryUpdateContent, "content", "", "memory content (use - for stdin)")
 	memoryUpdateCmd.Flags().StringVar(&memoryUpdateSource, "source", "", "source of the memory")
 	memoryUpdateCmd.Flags().StringVar(&memoryUpdateTags, "tags", "", "comma-separated tags")
+	memoryUpdateCmd.Flags().BoolVar(&memoryUpdateNoDefault, "no-defaults", false, "ignore defaults from .recuerd0.yaml")
+	memoryUpdateCmd.Flags().BoolVar(&memoryUpdateAllowSecrets, "allow-secrets", false, "send content even if secrets are detected")
 	memoryCmd.AddCommand(memoryUpdateCmd)
 
-	memoryDeleteCmd.Flags().StringVar(&memoryDeleteWorkspace, "workspace", "", "workspace ID")
+	memoryDeleteCmd.Flags().StringVar(&memoryDeleteWorkspace, "workspace", "", "workspace ID, name or alias")
+	memoryDeleteCmd.Flags().BoolVar(&memoryDeleteYes, "yes", false, "delete without asking for confirmation")
 	memoryCmd.AddCommand(memoryDeleteCmd)
 }
@@ -0,0 +1,106 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maquina/recuerd0-cli/internal/crypt"
+	"github.com/maquina/recuerd0-cli/internal/errors"
+	"github.com/maquina/recuerd0-cli/internal/markdown"
+	"github.com/maquina/recuerd0-cli/internal/term"
+)
+
+// bodyOutput receives memory bodies printed by memory cat and
+// memory show --render, overridable for tests.
+var bodyOutput io.Writer = os.Stdout
+
+// stdoutIsTerminal reports whether stdout is an interactive terminal,
+// overridable for tests.
+var stdoutIsTerminal = func() bool {
+	return term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// writeMemoryBody prints only the body of a memory response. With render
+// set and a terminal on stdout, the Markdown is rendered to the terminal
+// width; otherwise the text passes through unchanged for pipes.
+func writeMemoryBody(ws string, data interface{}, render bool) error {
+	m, ok := data.(map[string]interface{})
+	if !ok {
+		return errors.NewError("unexpected memory response")
+	}
+	if plaintext, ok := decryptMemory(m); ok {
+		indexMemoryResponse(ws, m, plaintext)
+	}
+	body := memoryBody(m)
+	if crypt.IsEncrypted(body) {
+		return errors.NewError("memory is encrypted and no local key opens it; see recuerd0 key list")
+	}
+
+	if render && stdoutIsTerminal() {
+		body = markdown.Render(body, markdown.Options{
+			Width: term.Width(int(os.Stdout.Fd())),
+			Color: os.Getenv("NO_COLOR") == "" && os.Getenv("TERM") != "dumb",
+		})
+	}
+	if body != "" && !strings.HasSuffix(body, "\n") {
+		body += "\n"
+	}
+	if _, err := io.WriteString(bodyOutput, body); err != nil {
+		return errors.NewError(fmt.Sprintf("writing memory: %v", err))
+	}
+	return nil
+}
+
+// memory cat
+var (
+	memoryCatWorkspace string
+	memoryCatRender    bool
+)
+
+var memoryCatCmd = &cobra.Command{
+	Use:   "cat <memory_id>",
+	Short: "Print a memory's content",
+	Long: `Print only a memory's content, without the JSON envelope.
+
+With --render the Markdown is rendered for the terminal: headings, lists,
+tables, links and highlighted code blocks, wrapped to its width. When
+stdout is not a terminal the content passes through as plain text, so
+the command works in pipes either way.`,
+	Example: `  recuerd0 memory cat --workspace 22 42 > notes.md
+  recuerd0 memory cat --workspace 22 42 --render`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeSingleMemoryID,
+	Run: func(cmd *cobra.Command, args []string) {
+		runMemoryBody(memoryCatWorkspace, args[0], memoryCatRender)
+	},
+}
+
+func runMemoryBody(workspace, id string, render bool) {
+	if err := requireAuth(); err != nil {
+		exitWithError(err)
+		return
+	}
+	ws, err := resolveWorkspace(workspace)
+	if err != nil {
+		exitWithError(err)
+		return
+	}
+	resp, err := getClient().Get(fmt.Sprintf("/workspaces/%s/memories/%s", ws, id))
+	if err != nil {
+		exitWithError(err)
+		return
+	}
+	if err := writeMemoryBody(ws, resp.Data, render); err != nil {
+		exitWithError(err)
+	}
+}
+
+func init() {
+	memoryCatCmd.Flags().StringVar(&memoryCatWorkspace, "workspace", "", "workspace ID, name or alias")
+	memoryCatCmd.Flags().BoolVar(&memoryCatRender, "render", false, "render Markdown when stdout is a terminal")
+	memoryCmd.AddCommand(memoryCatCmd)
+}
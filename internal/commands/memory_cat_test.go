@@ -0,0 +1,147 @@
+package commands
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/maquina/recuerd0-cli/internal/client"
+	"github.com/maquina/recuerd0-cli/internal/crypt"
+	"github.com/maquina/recuerd0-cli/internal/errors"
+)
+
+const catBody = "# Deploy\n\n| Step | Owner |\n|------|-------|\n| Tag | ops |\n\n```go\nfunc main() {}\n```"
+
+// captureBody sends memory bodies to a buffer and pretends stdout is a
+// terminal when tty is set.
+func captureBody(t *testing.T, tty bool) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	origOut, origTTY := bodyOutput, stdoutIsTerminal
+	bodyOutput = &buf
+	stdoutIsTerminal = func() bool { return tty }
+	t.Setenv("COLUMNS", "60")
+	t.Cleanup(func() {
+		bodyOutput, stdoutIsTerminal = origOut, origTTY
+		memoryCatWorkspace, memoryCatRender = "", false
+		memoryShowWorkspace, memoryShowRender = "", false
+	})
+	return &buf
+}
+
+func catMock() *MockClient {
+	return NewMockClient().WithGetPathData("/workspaces/5/memories/8", map[string]interface{}{
+		"id": float64(8), "title": "Deploy checklist",
+		"content": map[string]interface{}{"body": catBody},
+	})
+}
+
+func TestMemoryCat(t *testing.T) {
+	tests := []struct {
+		name   string
+		tty    bool
+		render bool
+		check  func(t *testing.T, out string)
+	}{
+		{"plain", true, false, func(t *testing.T, out string) {
+			if out != catBody+"\n" {
+				t.Errorf("expected the raw body, got %q", out)
+			}
+		}},
+		{"render on a terminal", true, true, func(t *testing.T, out string) {
+			for _, want := range []string{"Deploy", "┌", "│ Tag  │ ops   │", "func main() {}"} {
+				if !strings.Contains(stripSGR(out), want) {
+					t.Errorf("expected %q in:\n%s", want, out)
+				}
+			}
+			if strings.Contains(out, "# Deploy") || strings.Contains(out, "```") {
+				t.Errorf("expected Markdown to be rendered:\n%s", out)
+			}
+		}},
+		{"render in a pipe", false, true, func(t *testing.T, out string) {
+			if out != catBody+"\n" {
+				t.Errorf("expected the raw body in a pipe, got %q", out)
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := catMock()
+			result := SetTestMode(mock)
+			SetTestConfigFull("tok_test", "https://api.example.com", "5")
+			defer ResetTestMode()
+			buf := captureBody(t, tt.tty)
+
+			memoryCatRender = tt.render
+			RunTestCommand(func() {
+				memoryCatCmd.Run(memoryCatCmd, []string{"8"})
+			})
+
+			if result.ExitCode != 0 || result.Response != nil {
+				t.Fatalf("expected only the body, got exit code %d and %+v", result.ExitCode, result.Response)
+			}
+			tt.check(t, buf.String())
+		})
+	}
+}
+
+func TestMemoryShow_Render(t *testing.T) {
+	mock := catMock()
+	result := SetTestMode(mock)
+	SetTestConfigFull("tok_test", "https://api.example.com", "5")
+	defer ResetTestMode()
+	buf := captureBody(t, true)
+
+	memoryShowRender = true
+	RunTestCommand(func() {
+		memoryShowCmd.Run(memoryShowCmd, []string{"8"})
+	})
+
+	if result.ExitCode != 0 || result.Response != nil {
+		t.Fatalf("expected only the body, got exit code %d and %+v", result.ExitCode, result.Response)
+	}
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if w := len([]rune(stripSGR(line))); w > 60 {
+			t.Errorf("line wider than the terminal: %q", line)
+		}
+	}
+	if !strings.Contains(buf.String(), "\x1b[") {
+		t.Error("expected color on a terminal")
+	}
+}
+
+func TestMemoryCat_EncryptedWithoutKey(t *testing.T) {
+	mock := NewMockClient()
+	result, _ := setupEncryptedWorkspace(t, mock)
+	buf := captureBody(t, false)
+
+	other, _ := crypt.GenerateIdentity()
+	armored, _ := crypt.Encrypt([]byte("hidden"), other.Recipient())
+	mock.GetResponse = &client.APIResponse{StatusCode: 200, Data: map[string]interface{}{"id": "9", "content": armored}}
+
+	RunTestCommand(func() {
+		memoryCatCmd.Run(memoryCatCmd, []string{"9"})
+	})
+
+	if result.ExitCode != errors.ExitError {
+		t.Errorf("expected exit code %d, got %d", errors.ExitError, result.ExitCode)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected no ciphertext on stdout, got %q", buf.String())
+	}
+}
+
+// stripSGR removes color escape codes.
+func stripSGR(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == 0x1b {
+			for i < len(s) && s[i] != 'm' {
+				i++
+			}
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,194 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maquina/recuerd0-cli/internal/errors"
+	"github.com/maquina/recuerd0-cli/internal/response"
+	"github.com/maquina/recuerd0-cli/internal/transcript"
+)
+
+// memory import-transcript
+var (
+	memoryImportWorkspace    string
+	memoryImportFormat       string
+	memoryImportSplit        bool
+	memoryImportTitle        string
+	memoryImportSource       string
+	memoryImportTags         string
+	memoryImportNoDefault    bool
+	memoryImportAllowSecrets bool
+)
+
+var memoryImportTranscriptCmd = &cobra.Command{
+	Use:   "import-transcript <file>",
+	Short: "Import an AI conversation transcript as a memory",
+	Long: `Import a conversation export as Markdown with one heading per speaker.
+
+Supported formats (detected automatically, or set with --format):
+  chatgpt  ChatGPT conversations.json
+  claude   Claude conversations.json export
+  jsonl    JSONL chat logs with role/content turns, including Claude Code
+           session logs, or a JSON array of such turns
+
+An export with several conversations becomes one memory with a section per
+conversation, or one memory per conversation with --split. Use - to read
+from stdin.`,
+	Example: `  recuerd0 memory import-transcript conversations.json --workspace 22 --split
+  recuerd0 memory import-transcript session.jsonl --workspace 22 --tags "debugging"`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := requireAuth(); err != nil {
+			exitWithError(err)
+			return
+		}
+		if memoryImportSplit && memoryImportTitle != "" {
+			exitWithError(errors.NewInvalidArgsError("--title cannot be combined with --split; titles are derived per conversation"))
+			return
+		}
+
+		data, err := readTranscript(args[0])
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		convs, err := transcript.Parse(data, memoryImportFormat)
+		if err != nil {
+			exitWithError(errors.NewInvalidArgsError(err.Error()))
+			return
+		}
+
+		ws, err := resolveWorkspace(memoryImportWorkspace)
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+
+		var memories []map[string]interface{}
+		if memoryImportSplit {
+			for _, c := range convs {
+				memories = append(memories, transcriptMemory(c.DeriveTitle(), c.Source, c.Markdown(2)))
+			}
+		} else {
+			title, content := mergeConversations(convs)
+			if memoryImportTitle != "" {
+				title = memoryImportTitle
+			}
+			memories = append(memories, transcriptMemory(title, convs[0].Source, content))
+		}
+
+		plaintexts := make([]string, len(memories))
+		for i, memory := range memories {
+			if err := scanForSecrets(memory, memoryImportAllowSecrets); err != nil {
+				exitWithError(err)
+				return
+			}
+			if plaintexts[i], err = encryptFields(ws, memory); err != nil {
+				exitWithError(err)
+				return
+			}
+		}
+
+		apiClient := getClient()
+		path := fmt.Sprintf("/workspaces/%s/memories", ws)
+		created := make([]interface{}, 0, len(memories))
+		for i, memory := range memories {
+			resp, err := apiClient.Post(path, map[string]interface{}{"memory": memory})
+			if err != nil {
+				if cliErr, ok := err.(*errors.CLIError); ok && i > 0 {
+					cliErr.Message = fmt.Sprintf("created %d of %d memories, then: %s", i, len(memories), cliErr.Message)
+				}
+				exitWithError(err)
+				return
+			}
+			if plaintexts[i] != "" {
+				indexMemoryResponse(ws, resp.Data, plaintexts[i])
+			}
+			created = append(created, resp.Data)
+		}
+
+		bc := []response.Breadcrumb{
+			breadcrumb("list", fmt.Sprintf("recuerd0 memory list --workspace %s", ws), "List all memories"),
+		}
+		if len(created) == 1 {
+			bc = append([]response.Breadcrumb{
+				breadcrumb("show", fmt.Sprintf("recuerd0 memory show --workspace %s %s", ws, responseID(created[0], "<memory_id>")), "View imported memory"),
+			}, bc...)
+			printSuccessWithBreadcrumbs(created[0], fmt.Sprintf("Imported %d conversation(s) as one memory", len(convs)), bc)
+			return
+		}
+		printSuccessWithBreadcrumbs(created, fmt.Sprintf("Imported %d conversation(s) as %d memories", len(convs), len(created)), bc)
+	},
+}
+
+func readTranscript(name string) ([]byte, error) {
+	var (
+		data []byte
+		err  error
+	)
+	if name == "-" {
+		data, err = io.ReadAll(stdinReader())
+	} else {
+		data, err = os.ReadFile(name)
+	}
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.NewInvalidArgsError(fmt.Sprintf("transcript file not found: %s", name))
+		}
+		return nil, errors.NewError(fmt.Sprintf("reading transcript: %v", err))
+	}
+	return data, nil
+}
+
+// mergeConversations renders every conversation into one document, with a
+// section per conversation when there is more than one.
+func mergeConversations(convs []transcript.Conversation) (string, string) {
+	if len(convs) == 1 {
+		return convs[0].DeriveTitle(), convs[0].Markdown(2)
+	}
+	sections := make([]string, 0, len(convs))
+	for _, c := range convs {
+		sections = append(sections, fmt.Sprintf("## %s\n\n%s", c.DeriveTitle(), c.Markdown(3)))
+	}
+	title := fmt.Sprintf("%d conversations from %s", len(convs), convs[0].Source)
+	return title, strings.Join(sections, "\n")
+}
+
+// transcriptMemory builds the memory fields for one import, applying flag
+// overrides and project defaults.
+func transcriptMemory(title, source, content string) map[string]interface{} {
+	memory := map[string]interface{}{
+		"title":   title,
+		"content": content,
+		"source":  source,
+	}
+	if memoryImportSource != "" {
+		memory["source"] = memoryImportSource
+	}
+	if memoryImportTags != "" {
+		memory["tags"] = parseTags(memoryImportTags)
+	}
+	if !memoryImportNoDefault {
+		if applied := applyProjectDefaults(memory, true); applied != nil {
+			setMeta("defaults_applied", applied)
+		}
+	}
+	return memory
+}
+
+func init() {
+	memoryImportTranscriptCmd.Flags().StringVar(&memoryImportWorkspace, "workspace", "", "workspace ID, name or alias")
+	memoryImportTranscriptCmd.Flags().StringVar(&memoryImportFormat, "format", transcript.FormatAuto, "transcript format: auto, chatgpt, claude or jsonl")
+	memoryImportTranscriptCmd.Flags().BoolVar(&memoryImportSplit, "split", false, "create one memory per conversation")
+	memoryImportTranscriptCmd.Flags().StringVar(&memoryImportTitle, "title", "", "memory title (default: derived from the transcript)")
+	memoryImportTranscriptCmd.Flags().StringVar(&memoryImportSource, "source", "", "source of the memory (default: the originating tool)")
+	memoryImportTranscriptCmd.Flags().StringVar(&memoryImportTags, "tags", "", "comma-separated tags")
+	memoryImportTranscriptCmd.Flags().BoolVar(&memoryImportNoDefault, "no-defaults", false, "ignore defaults from .recuerd0.yaml")
+	memoryImportTranscriptCmd.Flags().BoolVar(&memoryImportAllowSecrets, "allow-secrets", false, "send content even if secrets are detected")
+	memoryCmd.AddCommand(memoryImportTranscriptCmd)
+}
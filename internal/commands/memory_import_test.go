@@ -0,0 +1,145 @@
+package commands
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/maquina/recuerd0-cli/internal/errors"
+)
+
+const importExport = `[
+  {"name": "First chat", "chat_messages": [{"sender": "human", "text": "Hello"}, {"sender": "assistant", "text": "Hi"}]},
+  {"name": "Second chat", "chat_messages": [{"sender": "human", "text": "Bye"}]}
+]`
+
+func resetImportFlags() {
+	memoryImportWorkspace, memoryImportFormat = "", "auto"
+	memoryImportSplit = false
+	memoryImportTitle, memoryImportSource, memoryImportTags = "", "", ""
+	memoryImportNoDefault = false
+}
+
+func writeTranscript(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "conversations.json")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestMemoryImportTranscript_Merged(t *testing.T) {
+	mock := NewMockClient()
+	result := SetTestMode(mock)
+	SetTestConfigFull("tok_test", "https://api.example.com", "5")
+	defer ResetTestMode()
+	resetImportFlags()
+	defer resetImportFlags()
+	memoryImportTags = "chat"
+
+	path := writeTranscript(t, importExport)
+	RunTestCommand(func() {
+		memoryImportTranscriptCmd.Run(memoryImportTranscriptCmd, []string{path})
+	})
+
+	if result.ExitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d: %+v", result.ExitCode, result.Response.Error)
+	}
+	if len(mock.PostCalls) != 1 {
+		t.Fatalf("expected 1 POST, got %d", len(mock.PostCalls))
+	}
+	call := mock.PostCalls[0]
+	if call.Path != "/workspaces/5/memories" {
+		t.Errorf("unexpected path: %s", call.Path)
+	}
+	memory := call.Body.(map[string]interface{})["memory"].(map[string]interface{})
+	if memory["title"] != "2 conversations from claude" || memory["source"] != "claude" {
+		t.Errorf("unexpected memory: %+v", memory)
+	}
+	content := memory["content"].(string)
+	if !strings.Contains(content, "## First chat\n\n### User\n\nHello") || !strings.Contains(content, "## Second chat") {
+		t.Errorf("unexpected content:\n%s", content)
+	}
+	if tags := memory["tags"].([]string); len(tags) != 1 || tags[0] != "chat" {
+		t.Errorf("unexpected tags: %v", tags)
+	}
+}
+
+func TestMemoryImportTranscript_Split(t *testing.T) {
+	mock := NewMockClient()
+	result := SetTestMode(mock)
+	SetTestConfigFull("tok_test", "https://api.example.com", "5")
+	defer ResetTestMode()
+	resetImportFlags()
+	defer resetImportFlags()
+	memoryImportSplit = true
+	memoryImportSource = "claude-desktop"
+
+	path := writeTranscript(t, importExport)
+	RunTestCommand(func() {
+		memoryImportTranscriptCmd.Run(memoryImportTranscriptCmd, []string{path})
+	})
+
+	if result.ExitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", result.ExitCode)
+	}
+	if len(mock.PostCalls) != 2 {
+		t.Fatalf("expected 2 POSTs, got %d", len(mock.PostCalls))
+	}
+	second := mock.PostCalls[1].Body.(map[string]interface{})["memory"].(map[string]interface{})
+	if second["title"] != "Second chat" || second["source"] != "claude-desktop" {
+		t.Errorf("unexpected memory: %+v", second)
+	}
+	if !strings.HasPrefix(second["content"].(string), "## User\n\nBye") {
+		t.Errorf("unexpected content: %q", second["content"])
+	}
+}
+
+func TestMemoryImportTranscript_Stdin(t *testing.T) {
+	mock := NewMockClient()
+	result := SetTestMode(mock)
+	SetTestConfigFull("tok_test", "https://api.example.com", "5")
+	defer ResetTestMode()
+	resetImportFlags()
+	defer resetImportFlags()
+	memoryImportTitle = "Debug session"
+
+	orig := stdinReader
+	stdinReader = func() io.Reader { return strings.NewReader(`{"role":"user","content":"why?"}`) }
+	defer func() { stdinReader = orig }()
+
+	RunTestCommand(func() {
+		memoryImportTranscriptCmd.Run(memoryImportTranscriptCmd, []string{"-"})
+	})
+
+	if result.ExitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", result.ExitCode)
+	}
+	memory := mock.PostCalls[0].Body.(map[string]interface{})["memory"].(map[string]interface{})
+	if memory["title"] != "Debug session" || memory["source"] != "chat-log" {
+		t.Errorf("unexpected memory: %+v", memory)
+	}
+}
+
+func TestMemoryImportTranscript_Invalid(t *testing.T) {
+	mock := NewMockClient()
+	result := SetTestMode(mock)
+	SetTestConfigFull("tok_test", "https://api.example.com", "5")
+	defer ResetTestMode()
+	resetImportFlags()
+
+	path := writeTranscript(t, `[{"chat_messages": []}]`)
+	RunTestCommand(func() {
+		memoryImportTranscriptCmd.Run(memoryImportTranscriptCmd, []string{path})
+	})
+
+	if result.ExitCode != errors.ExitInvalidArgs {
+		t.Errorf("expected exit code %d, got %d", errors.ExitInvalidArgs, result.ExitCode)
+	}
+	if len(mock.PostCalls) != 0 {
+		t.Errorf("expected no POST, got %d", len(mock.PostCalls))
+	}
+}
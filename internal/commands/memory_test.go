@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/maquina/recuerd0-cli/internal/client"
+	"github.com/maquina/recuerd0-cli/internal/config"
 	"github.com/maquina/recuerd0-cli/internal/errors"
 )
 
@@ -266,6 +267,8 @@ func TestMemoryDelete(t *testing.T) {
 	defer ResetTestMode()
 
 	memoryDeleteWorkspace = ""
+	memoryDeleteYes = true
+	defer func() { memoryDeleteYes = false }()
 
 	RunTestCommand(func() {
 		memoryDeleteCmd.Run(memoryDeleteCmd, []string{"42"})
@@ -303,3 +306,95 @@ func TestParseTags(t *testing.T) {
 		}
 	}
 }
+
+func TestMemoryCreate_ProjectDefaults(t *testing.T) {
+	mock := NewMockClient()
+	mock.PostResponse = &client.APIResponse{StatusCode: 201, Data: map[string]interface{}{"id": "100"}}
+
+	result := SetTestMode(mock)
+	SetTestConfigFull("tok_test", "https://api.example.com", "5")
+	cfg.Defaults = config.Defaults{Tags: []string{"proj", "ai"}, Source: "claude-code", TitlePrefix: "[proj] "}
+	defer ResetTestMode()
+
+	memoryCreateTitle = "Caching"
+	memoryCreateTags = "ai,redis"
+	defer func() { memoryCreateTitle = ""; memoryCreateTags = "" }()
+
+	RunTestCommand(func() {
+		memoryCreateCmd.Run(memoryCreateCmd, []string{})
+	})
+
+	if result.ExitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", result.ExitCode)
+	}
+	memory := mock.PostCalls[0].Body.(map[string]interface{})["memory"].(map[string]interface{})
+	if memory["title"] != "[proj] Caching" {
+		t.Errorf("unexpected title: %v", memory["title"])
+	}
+	if memory["source"] != "claude-code" {
+		t.Errorf("unexpected source: %v", memory["source"])
+	}
+	tags := memory["tags"].([]string)
+	if strings.Join(tags, ",") != "ai,redis,proj" {
+		t.Errorf("unexpected tags: %v", tags)
+	}
+
+	applied, ok := result.Response.Meta["defaults_applied"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected defaults_applied in meta, got %v", result.Response.Meta)
+	}
+	if added := applied["tags"].([]string); len(added) != 1 || added[0] != "proj" {
+		t.Errorf("unexpected applied tags: %v", added)
+	}
+}
+
+func TestMemoryCreate_NoDefaults(t *testing.T) {
+	mock := NewMockClient()
+	mock.PostResponse = &client.APIResponse{StatusCode: 201, Data: map[string]interface{}{"id": "100"}}
+
+	result := SetTestMode(mock)
+	SetTestConfigFull("tok_test", "https://api.example.com", "5")
+	cfg.Defaults = config.Defaults{Tags: []string{"proj"}, Source: "claude-code"}
+	defer ResetTestMode()
+
+	memoryCreateTitle = "Caching"
+	memoryCreateNoDefault = true
+	defer func() { memoryCreateTitle = ""; memoryCreateNoDefault = false }()
+
+	RunTestCommand(func() {
+		memoryCreateCmd.Run(memoryCreateCmd, []string{})
+	})
+
+	memory := mock.PostCalls[0].Body.(map[string]interface{})["memory"].(map[string]interface{})
+	if _, ok := memory["tags"]; ok {
+		t.Errorf("expected no tags, got %v", memory["tags"])
+	}
+	if _, ok := memory["source"]; ok {
+		t.Errorf("expected no source, got %v", memory["source"])
+	}
+	if _, ok := result.Response.Meta["defaults_applied"]; ok {
+		t.Error("expected no defaults_applied in meta")
+	}
+}
+
+func TestMemoryUpdate_DefaultsOnlyAugment(t *testing.T) {
+	mock := NewMockClient()
+	mock.PatchResponse = &client.APIResponse{StatusCode: 200, Data: map[string]interface{}{"id": "42"}}
+
+	SetTestMode(mock)
+	SetTestConfigFull("tok_test", "https://api.example.com", "5")
+	cfg.Defaults = config.Defaults{Tags: []string{"proj"}, Source: "claude-code"}
+	defer ResetTestMode()
+
+	memoryUpdateContent = "new body"
+	defer func() { memoryUpdateContent = "" }()
+
+	RunTestCommand(func() {
+		memoryUpdateCmd.Run(memoryUpdateCmd, []string{"42"})
+	})
+
+	memory := mock.PatchCalls[0].Body.(map[string]interface{})["memory"].(map[string]interface{})
+	if len(memory) != 1 {
+		t.Errorf("expected only content to be sent, got %v", memory)
+	}
+}
@@ -1,6 +1,10 @@
 package commands
 
-import "github.com/maquina/recuerd0-cli/internal/client"
+import (
+	"sync"
+
+	"github.com/maquina/recuerd0-cli/internal/client"
+)
 
 // MockCall records a call made to the mock client.
 type MockCall struct {
@@ -15,6 +19,9 @@ type MockClient struct {
 	PatchResponse  *client.APIResponse
 	DeleteResponse *client.APIResponse
 
+	// GetResponses overrides GetResponse for specific paths.
+	GetResponses map[string]*client.APIResponse
+
 	GetError    error
 	PostError   error
 	PatchError  error
@@ -24,6 +31,10 @@ type MockClient struct {
 	PostCalls   []MockCall
 	PatchCalls  []MockCall
 	DeleteCalls []MockCall
+
+	// mu guards the call logs, as bulk commands call the client from
+	// several goroutines.
+	mu sync.Mutex
 }
 
 // NewMockClient creates a mock client with default success responses.
@@ -37,14 +48,21 @@ func NewMockClient() *MockClient {
 }
 
 func (m *MockClient) Get(path string) (*client.APIResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.GetCalls = append(m.GetCalls, MockCall{Path: path})
 	if m.GetError != nil {
 		return nil, m.GetError
 	}
+	if resp, ok := m.GetResponses[path]; ok {
+		return resp, nil
+	}
 	return m.GetResponse, nil
 }
 
 func (m *MockClient) Post(path string, body interface{}) (*client.APIResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.PostCalls = append(m.PostCalls, MockCall{Path: path, Body: body})
 	if m.PostError != nil {
 		return nil, m.PostError
@@ -53,6 +71,8 @@ func (m *MockClient) Post(path string, body interface{}) (*client.APIResponse, e
 }
 
 func (m *MockClient) Patch(path string, body interface{}) (*client.APIResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.PatchCalls = append(m.PatchCalls, MockCall{Path: path, Body: body})
 	if m.PatchError != nil {
 		return nil, m.PatchError
@@ -61,6 +81,8 @@ func (m *MockClient) Patch(path string, body interface{}) (*client.APIResponse,
 }
 
 func (m *MockClient) Delete(path string) (*client.APIResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.DeleteCalls = append(m.DeleteCalls, MockCall{Path: path})
 	if m.DeleteError != nil {
 		return nil, m.DeleteError
@@ -72,6 +94,33 @@ func (m *MockClient) GetWithPagination(path string) (*client.APIResponse, error)
 	return m.Get(path)
 }
 
+// GetEach passes the items of the Get response to fn, as the client does
+// while decoding.
+func (m *MockClient) GetEach(path string, fn func(item interface{}) error) (*client.APIResponse, error) {
+	resp, err := m.Get(path)
+	if err != nil {
+		return nil, err
+	}
+	out := *resp
+	out.Data = nil
+	items := pageItems(resp.Data)
+	if obj, ok := resp.Data.(map[string]interface{}); ok {
+		rest := make(map[string]interface{}, len(obj))
+		for k, v := range obj {
+			if k != "results" {
+				rest[k] = v
+			}
+		}
+		out.Data = rest
+	}
+	for _, item := range items {
+		if err := fn(item); err != nil {
+			return nil, err
+		}
+	}
+	return &out, nil
+}
+
 // WithGetData sets the Data field on the Get response.
 func (m *MockClient) WithGetData(data interface{}) *MockClient {
 	m.GetResponse.Data = data
@@ -90,6 +139,15 @@ func (m *MockClient) WithPatchData(data interface{}) *MockClient {
 	return m
 }
 
+// WithGetPathData sets the Data returned by Get for a specific path.
+func (m *MockClient) WithGetPathData(path string, data interface{}) *MockClient {
+	if m.GetResponses == nil {
+		m.GetResponses = make(map[string]*client.APIResponse)
+	}
+	m.GetResponses[path] = &client.APIResponse{StatusCode: 200, Data: data}
+	return m
+}
+
 // WithPostLocation sets the Location on the Post response.
 func (m *MockClient) WithPostLocation(location string) *MockClient {
 	m.PostResponse.Location = location
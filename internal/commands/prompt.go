@@ -0,0 +1,62 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// stdinIsTerminal reports whether stdin is an interactive terminal,
+// overridable for tests.
+var stdinIsTerminal = func() bool {
+	fi, err := os.Stdin.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// promptWriter is where interactive prompts are written. Prompts go to
+// stderr so stdout stays a single JSON envelope.
+var promptWriter io.Writer = os.Stderr
+
+// promptChoice asks the user to pick one of options by number and returns
+// its index. An empty answer selects def when def >= 0.
+func promptChoice(label string, options []string, def int) (int, error) {
+	fmt.Fprintln(promptWriter, label)
+	for i, opt := range options {
+		marker := " "
+		if i == def {
+			marker = "*"
+		}
+		fmt.Fprintf(promptWriter, "%s %d) %s\n", marker, i+1, opt)
+	}
+
+	reader := bufio.NewReader(stdinReader())
+	for {
+		fmt.Fprint(promptWriter, "> ")
+		line, err := reader.ReadString('\n')
+		answer := strings.TrimSpace(line)
+		if answer == "" && def >= 0 {
+			return def, nil
+		}
+		if n, convErr := strconv.Atoi(answer); convErr == nil && n >= 1 && n <= len(options) {
+			return n - 1, nil
+		}
+		if err != nil {
+			return -1, fmt.Errorf("no selection made")
+		}
+		fmt.Fprintf(promptWriter, "Enter a number between 1 and %d\n", len(options))
+	}
+}
+
+// promptConfirm asks a yes/no question; anything but y or yes is a no.
+func promptConfirm(label string) bool {
+	fmt.Fprintf(promptWriter, "%s [y/N] ", label)
+	line, _ := bufio.NewReader(stdinReader()).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
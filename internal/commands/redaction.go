@@ -0,0 +1,108 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/maquina/recuerd0-cli/internal/errors"
+	"github.com/maquina/recuerd0-cli/internal/redact"
+)
+
+// redactedFields are the memory fields scanned for secrets.
+var redactedFields = []string{"title", "content"}
+
+// redactionFinding is a finding reported in meta.redaction.
+type redactionFinding struct {
+	Field string `json:"field"`
+	redact.Finding
+}
+
+// redactionReport is meta.redaction: the mode, what was done and what was
+// found. Action is "warned", "redacted", "blocked" or "allowed".
+type redactionReport struct {
+	Mode     string             `json:"mode"`
+	Action   string             `json:"action"`
+	Findings []redactionFinding `json:"findings"`
+}
+
+func redactionDetectors() (string, []redact.Detector, error) {
+	mode := redact.DefaultMode
+	var patterns map[string]string
+	if cfg != nil {
+		if cfg.Redaction.Mode != "" {
+			mode = cfg.Redaction.Mode
+		}
+		patterns = cfg.Redaction.Patterns
+	}
+	if !redact.ValidMode(mode) {
+		return "", nil, errors.NewInvalidArgsError(fmt.Sprintf("redaction.mode must be warn, redact or block, got %q", mode))
+	}
+	custom, err := redact.Custom(patterns)
+	if err != nil {
+		return "", nil, errors.NewInvalidArgsError(err.Error())
+	}
+	return mode, append(custom, redact.Builtin()...), nil
+}
+
+// scanForSecrets checks the content-bearing fields of a memory or version
+// request before it is sent. Depending on the configured mode findings are
+// reported, replaced in fields, or cause an error; allow sends the fields
+// unchanged but still reports. Findings accumulate in meta.redaction so
+// commands sending several memories report them all.
+func scanForSecrets(fields map[string]interface{}, allow bool) error {
+	mode, detectors, err := redactionDetectors()
+	if err != nil {
+		return err
+	}
+
+	var found []redactionFinding
+	for _, field := range redactedFields {
+		text, ok := fields[field].(string)
+		if !ok || text == "" {
+			continue
+		}
+		findings := redact.Scan(text, detectors)
+		if len(findings) == 0 {
+			continue
+		}
+		for _, f := range findings {
+			found = append(found, redactionFinding{Field: field, Finding: f})
+		}
+		if mode == redact.ModeRedact && !allow {
+			fields[field] = redact.Apply(text, findings)
+		}
+	}
+	if len(found) == 0 {
+		return nil
+	}
+
+	report, _ := pendingMeta["redaction"].(*redactionReport)
+	if report == nil {
+		report = &redactionReport{Mode: mode}
+		setMeta("redaction", report)
+	}
+	report.Findings = append(report.Findings, found...)
+
+	switch {
+	case allow:
+		report.Action = "allowed"
+	case mode == redact.ModeBlock:
+		report.Action = "blocked"
+		return errors.NewInvalidArgsError(fmt.Sprintf(
+			"refusing to send %d possible secret(s): %s. Remove them, set redaction.mode to warn or redact, or pass --allow-secrets",
+			len(found), describeFindings(found)))
+	case mode == redact.ModeRedact:
+		report.Action = "redacted"
+	default:
+		report.Action = "warned"
+	}
+	return nil
+}
+
+func describeFindings(found []redactionFinding) string {
+	parts := make([]string, 0, len(found))
+	for _, f := range found {
+		parts = append(parts, fmt.Sprintf("%s in %s line %d", f.Detector, f.Field, f.Line))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,154 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/maquina/recuerd0-cli/internal/errors"
+)
+
+// Assembled at runtime so the repository never contains a real-looking token.
+var testSecret = "ghp_" + strings.Repeat("a1B2", 9)
+
+func runCreateWithSecret(t *testing.T, mode string, allow bool) (*MockClient, *CommandResult) {
+	t.Helper()
+	mock := NewMockClient()
+	result := SetTestMode(mock)
+	SetTestConfigFull("tok_test", "https://api.example.com", "5")
+	cfg.Redaction.Mode = mode
+
+	memoryCreateTitle = "Deploy notes"
+	memoryCreateContent = "Run deploy with\nGITHUB_TOKEN=" + testSecret
+	memoryCreateAllowSecrets = allow
+	t.Cleanup(func() {
+		ResetTestMode()
+		memoryCreateTitle, memoryCreateContent = "", ""
+		memoryCreateAllowSecrets = false
+	})
+
+	RunTestCommand(func() {
+		memoryCreateCmd.Run(memoryCreateCmd, []string{})
+	})
+	return mock, result
+}
+
+func redactionMeta(t *testing.T, result *CommandResult) *redactionReport {
+	t.Helper()
+	report, ok := result.Response.Meta["redaction"].(*redactionReport)
+	if !ok {
+		t.Fatalf("expected meta.redaction, got %v", result.Response.Meta)
+	}
+	return report
+}
+
+func sentContent(mock *MockClient) string {
+	return mock.PostCalls[0].Body.(map[string]interface{})["memory"].(map[string]interface{})["content"].(string)
+}
+
+func TestRedaction_Warn(t *testing.T) {
+	mock, result := runCreateWithSecret(t, "warn", false)
+
+	if result.ExitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", result.ExitCode)
+	}
+	if !strings.Contains(sentContent(mock), testSecret) {
+		t.Error("expected warn mode to send content unchanged")
+	}
+	report := redactionMeta(t, result)
+	if report.Action != "warned" || len(report.Findings) != 1 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	f := report.Findings[0]
+	if f.Field != "content" || f.Detector != "github_token" || f.Line != 2 {
+		t.Errorf("unexpected finding: %+v", f)
+	}
+}
+
+func TestRedaction_Redact(t *testing.T) {
+	mock, result := runCreateWithSecret(t, "redact", false)
+
+	content := sentContent(mock)
+	if strings.Contains(content, testSecret) || !strings.Contains(content, "[REDACTED:github_token]") {
+		t.Errorf("expected secret to be replaced, got %q", content)
+	}
+	if report := redactionMeta(t, result); report.Action != "redacted" {
+		t.Errorf("unexpected action %q", report.Action)
+	}
+}
+
+func TestRedaction_Block(t *testing.T) {
+	mock, result := runCreateWithSecret(t, "block", false)
+
+	if result.ExitCode != errors.ExitInvalidArgs {
+		t.Fatalf("expected exit code %d, got %d", errors.ExitInvalidArgs, result.ExitCode)
+	}
+	if len(mock.PostCalls) != 0 {
+		t.Error("expected nothing to be sent")
+	}
+	if !strings.Contains(result.Response.Error.Message, "github_token in content line 2") {
+		t.Errorf("unexpected message: %s", result.Response.Error.Message)
+	}
+	if report := redactionMeta(t, result); report.Action != "blocked" {
+		t.Errorf("unexpected action %q", report.Action)
+	}
+}
+
+func TestRedaction_AllowSecrets(t *testing.T) {
+	mock, result := runCreateWithSecret(t, "block", true)
+
+	if result.ExitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", result.ExitCode)
+	}
+	if !strings.Contains(sentContent(mock), testSecret) {
+		t.Error("expected content to be sent unchanged")
+	}
+	if report := redactionMeta(t, result); report.Action != "allowed" {
+		t.Errorf("unexpected action %q", report.Action)
+	}
+}
+
+func TestRedaction_CustomPatternOnUpdate(t *testing.T) {
+	mock := NewMockClient()
+	result := SetTestMode(mock)
+	SetTestConfigFull("tok_test", "https://api.example.com", "5")
+	defer ResetTestMode()
+	cfg.Redaction.Mode = "redact"
+	cfg.Redaction.Patterns = map[string]string{"ticket": `SEC-\d+`}
+
+	memoryUpdateTitle = "Follow up SEC-4411"
+	defer func() { memoryUpdateTitle = "" }()
+
+	RunTestCommand(func() {
+		memoryUpdateCmd.Run(memoryUpdateCmd, []string{"9"})
+	})
+
+	title := mock.PatchCalls[0].Body.(map[string]interface{})["memory"].(map[string]interface{})["title"]
+	if title != "Follow up [REDACTED:ticket]" {
+		t.Errorf("unexpected title %q", title)
+	}
+	if report := redactionMeta(t, result); report.Findings[0].Field != "title" {
+		t.Errorf("unexpected report: %+v", report)
+	}
+}
+
+func TestRedaction_InvalidMode(t *testing.T) {
+	mock := NewMockClient()
+	result := SetTestMode(mock)
+	SetTestConfigFull("tok_test", "https://api.example.com", "5")
+	defer ResetTestMode()
+	cfg.Redaction.Mode = "off"
+
+	memoryVersionCreateContent = "anything"
+	defer func() { memoryVersionCreateContent = "" }()
+
+	RunTestCommand(func() {
+		memoryVersionCreateCmd.Run(memoryVersionCreateCmd, []string{"9"})
+	})
+
+	if result.ExitCode != errors.ExitInvalidArgs {
+		t.Errorf("expected exit code %d, got %d", errors.ExitInvalidArgs, result.ExitCode)
+	}
+	if len(mock.PostCalls) != 0 {
+		t.Error("expected nothing to be sent")
+	}
+}
@@ -3,14 +3,20 @@ package commands
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/maquina/recuerd0-cli/internal/audit"
+	"github.com/maquina/recuerd0-cli/internal/cache"
+	"github.com/maquina/recuerd0-cli/internal/cassette"
 	"github.com/maquina/recuerd0-cli/internal/client"
 	"github.com/maquina/recuerd0-cli/internal/config"
 	"github.com/maquina/recuerd0-cli/internal/errors"
 	"github.com/maquina/recuerd0-cli/internal/response"
+	"github.com/maquina/recuerd0-cli/internal/trash"
 )
 
 var (
@@ -21,6 +27,7 @@ var (
 	cfgWorkspace string
 	cfgVerbose   bool
 	cfgPretty    bool
+	cfgDryRun    bool
 
 	// Resolved configuration
 	cfg *config.ResolvedConfig
@@ -30,6 +37,9 @@ var (
 
 	// Client factory (overridden in tests)
 	clientFactory func() client.API
+
+	// currentCmd is the command being run, recorded in the audit log.
+	currentCmd *cobra.Command
 )
 
 // SetVersion sets the version string displayed by the version command.
@@ -41,14 +51,19 @@ var rootCmd = &cobra.Command{
 	Use:   "recuerd0",
 	Short: "Recuerd0 CLI — preserve, version, and organize knowledge from AI conversations",
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
-		if testMode {
+		currentCmd = cmd
+
+		// Commands run in-process by tests or the shell keep the config
+		// already set.
+		if captured != nil {
 			return
 		}
 
 		response.SetPrettyPrint(cfgPretty)
 
-		// Skip config resolution for commands that don't need it
-		if cmd.Name() == "version" {
+		// Skip config resolution for commands that don't need it.
+		// doctor resolves config itself so it can report parse errors.
+		if cmd.Name() == "version" || cmd.Name() == "doctor" {
 			return
 		}
 
@@ -63,6 +78,11 @@ var rootCmd = &cobra.Command{
 			exitWithError(errors.NewError(fmt.Sprintf("loading config: %v", err)))
 			return
 		}
+		// A replayed run needs no token; the placeholder is what the
+		// recorder left in the cassette.
+		if replaying() && resolved.Token == "" {
+			resolved.Token = cassette.Marker
+		}
 		cfg = resolved
 	},
 	SilenceUsage:  true,
@@ -73,25 +93,76 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&cfgAccount, "account", "", "account name to use")
 	rootCmd.PersistentFlags().StringVar(&cfgToken, "token", "", "API token (overrides config)")
 	rootCmd.PersistentFlags().StringVar(&cfgAPIURL, "api-url", "", "API base URL (overrides config)")
-	rootCmd.PersistentFlags().StringVar(&cfgWorkspace, "workspace", "", "workspace ID (overrides config)")
+	rootCmd.PersistentFlags().StringVar(&cfgWorkspace, "workspace", "", "workspace ID, name or alias (overrides config)")
 	rootCmd.PersistentFlags().BoolVar(&cfgVerbose, "verbose", false, "show HTTP request/response details")
 	rootCmd.PersistentFlags().BoolVar(&cfgPretty, "pretty", false, "pretty-print JSON output")
+	rootCmd.PersistentFlags().BoolVar(&cfgDryRun, "dry-run", false, "print the write requests a command would send without sending them or changing local files other than caches")
+	rootCmd.PersistentFlags().StringVar(&cfgRecord, "record", "", "record every HTTP interaction, redacted, to a YAML or JSON cassette `FILE`")
+	rootCmd.PersistentFlags().StringVar(&cfgReplay, "replay", "", "answer requests from a cassette `FILE` instead of the API")
 }
 
 // Execute runs the root command.
 func Execute() {
+	registerFlagCompletions(rootCmd)
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 }
 
-// getClient creates an API client from the resolved config.
+// getClient creates an API client from the resolved config. With --dry-run
+// the client records writes instead of sending them.
 func getClient() client.API {
+	c := newClient()
+	if cfgDryRun && c != nil {
+		return &dryRunClient{API: c}
+	}
+	return c
+}
+
+func newClient() client.API {
 	if clientFactory != nil {
 		return clientFactory()
 	}
-	return client.New(cfg.APIURL, cfg.Token, cfgVerbose)
+	opts, err := clientOptions(cfg.Transport)
+	if err != nil {
+		exitWithError(errors.NewError(fmt.Sprintf("configuring HTTP client: %v", err)))
+		return nil
+	}
+	c, err := client.NewWithOptions(cfg.APIURL, cfg.Token, cfgVerbose, opts)
+	if err != nil {
+		exitWithError(errors.NewError(fmt.Sprintf("configuring HTTP client: %v", err)))
+		return nil
+	}
+	if c.HTTPClient.Transport, err = cassetteTransport(c.HTTPClient.Transport); err != nil {
+		exitWithError(err)
+		return nil
+	}
+	// Replayed writes were not sent, so they stay out of the audit log.
+	if !replaying() {
+		c.OnMutation = recordMutation
+	}
+	return c
+}
+
+// clientOptions converts account transport settings into client options.
+func clientOptions(t config.Transport) (client.Options, error) {
+	opts := client.Options{
+		Proxy:              t.Proxy,
+		CACertFile:         t.CACert,
+		ClientCertFile:     t.ClientCert,
+		ClientKeyFile:      t.ClientKey,
+		InsecureSkipVerify: t.InsecureSkipVerify,
+		Headers:            t.Headers,
+	}
+	if t.Timeout != "" {
+		d, err := time.ParseDuration(t.Timeout)
+		if err != nil || d <= 0 {
+			return opts, fmt.Errorf("invalid timeout %q, expected a duration such as 60s", t.Timeout)
+		}
+		opts.Timeout = d
+	}
+	return opts, nil
 }
 
 // requireAuth checks that a token is available.
@@ -121,23 +192,47 @@ func exitWithError(err error) {
 	}
 
 	resp := response.Error(cliErr)
+	applyMeta(resp)
 
-	if testMode {
-		testResult.Response = resp
-		testResult.ExitCode = cliErr.ExitCode
-		panic(testExitSignal{})
+	if captured != nil {
+		captured.Response = resp
+		captured.ExitCode = cliErr.ExitCode
+		panic(exitSignal{})
 	}
 
 	resp.PrintAndExit()
 }
 
+// pendingMeta holds extra meta entries for the next response.
+var pendingMeta map[string]interface{}
+
+// setMeta adds an entry to the meta of the next response printed.
+func setMeta(key string, value interface{}) {
+	if pendingMeta == nil {
+		pendingMeta = make(map[string]interface{})
+	}
+	pendingMeta[key] = value
+}
+
+// applyMeta moves pending meta entries onto resp, and keeps its
+// breadcrumbs for run-breadcrumb.
+func applyMeta(resp *response.Response) {
+	for k, v := range pendingMeta {
+		resp.Meta[k] = v
+	}
+	pendingMeta = nil
+	applyDryRun(resp)
+	rememberBreadcrumbs(resp)
+}
+
 // printSuccess outputs a success response.
 func printSuccess(data interface{}) {
 	resp := response.Success(data)
-	if testMode {
-		testResult.Response = resp
-		testResult.ExitCode = 0
-		panic(testExitSignal{})
+	applyMeta(resp)
+	if captured != nil {
+		captured.Response = resp
+		captured.ExitCode = 0
+		panic(exitSignal{})
 	}
 	resp.Print()
 }
@@ -145,10 +240,11 @@ func printSuccess(data interface{}) {
 // printSuccessWithLocation outputs a success response with location.
 func printSuccessWithLocation(data interface{}, location string) {
 	resp := response.SuccessWithLocation(data, location)
-	if testMode {
-		testResult.Response = resp
-		testResult.ExitCode = 0
-		panic(testExitSignal{})
+	applyMeta(resp)
+	if captured != nil {
+		captured.Response = resp
+		captured.ExitCode = 0
+		panic(exitSignal{})
 	}
 	resp.Print()
 }
@@ -156,10 +252,11 @@ func printSuccessWithLocation(data interface{}, location string) {
 // printSuccessWithBreadcrumbs outputs a success response with summary and breadcrumbs.
 func printSuccessWithBreadcrumbs(data interface{}, summary string, breadcrumbs []response.Breadcrumb) {
 	resp := response.SuccessWithBreadcrumbs(data, summary, breadcrumbs)
-	if testMode {
-		testResult.Response = resp
-		testResult.ExitCode = 0
-		panic(testExitSignal{})
+	applyMeta(resp)
+	if captured != nil {
+		captured.Response = resp
+		captured.ExitCode = 0
+		panic(exitSignal{})
 	}
 	resp.Print()
 }
@@ -167,42 +264,77 @@ func printSuccessWithBreadcrumbs(data interface{}, summary string, breadcrumbs [
 // printSuccessWithPaginationAndBreadcrumbs outputs the full response.
 func printSuccessWithPaginationAndBreadcrumbs(data interface{}, hasNext bool, nextURL string, summary string, breadcrumbs []response.Breadcrumb) {
 	resp := response.SuccessWithPaginationAndBreadcrumbs(data, hasNext, nextURL, summary, breadcrumbs)
-	if testMode {
-		testResult.Response = resp
-		testResult.ExitCode = 0
-		panic(testExitSignal{})
+	applyMeta(resp)
+	if captured != nil {
+		captured.Response = resp
+		captured.ExitCode = 0
+		panic(exitSignal{})
+	}
+	resp.Print()
+}
+
+// printSuccessWithPageAndBreadcrumbs outputs the full response with prepared
+// pagination info.
+func printSuccessWithPageAndBreadcrumbs(data interface{}, page *response.Pagination, summary string, breadcrumbs []response.Breadcrumb) {
+	resp := response.SuccessWithPageAndBreadcrumbs(data, page, summary, breadcrumbs)
+	applyMeta(resp)
+	if captured != nil {
+		captured.Response = resp
+		captured.ExitCode = 0
+		panic(exitSignal{})
 	}
 	resp.Print()
 }
 
 // breadcrumb is a helper to create a Breadcrumb.
 func breadcrumb(action, cmd, description string) response.Breadcrumb {
-	return response.Breadcrumb{Action: action, Cmd: cmd, Description: description}
+	return response.NewBreadcrumb(action, cmd, description)
+}
+
+// responseID returns the ID in a response's data, or placeholder when
+// there is none, as with --dry-run.
+func responseID(data interface{}, placeholder string) string {
+	if m, ok := data.(map[string]interface{}); ok {
+		if id := stringID(m["id"]); id != "" {
+			return id
+		}
+	}
+	return placeholder
 }
 
-// --- Test infrastructure ---
+// --- In-process execution and test infrastructure ---
 
-type testExitSignal struct{}
+// exitSignal unwinds a command after its response was captured.
+type exitSignal struct{}
 
-// CommandResult captures the result of a command in test mode.
+// CommandResult captures the result of a command run in-process.
 type CommandResult struct {
 	Response *response.Response
 	ExitCode int
 }
 
+// captured, when set, receives the next response instead of stdout, and
+// exitWithError no longer exits. Tests and the shell run commands this way.
+var captured *CommandResult
+
 var (
-	testMode   bool
-	testResult *CommandResult
-	testMu     sync.Mutex
+	testMu       sync.Mutex
+	testCacheDir string
 )
 
 // SetTestMode enables test mode with a mock client.
 func SetTestMode(mockClient client.API) *CommandResult {
 	testMu.Lock()
-	testMode = true
-	testResult = &CommandResult{}
+	captured = &CommandResult{}
+	pendingMeta = nil
+	dryRunRequests = nil
+	dryRunWrites = nil
 	clientFactory = func() client.API { return mockClient }
-	return testResult
+	testCacheDir, _ = os.MkdirTemp("", "recuerd0-test-cache")
+	cache.SetDir(testCacheDir)
+	trash.SetDir(filepath.Join(testCacheDir, "trash"))
+	audit.SetPath(filepath.Join(testCacheDir, "audit.jsonl"))
+	return captured
 }
 
 // SetTestConfig sets the resolved config for tests.
@@ -224,21 +356,32 @@ func SetTestConfigFull(token, apiURL, workspace string) {
 
 // ResetTestMode disables test mode and cleans up.
 func ResetTestMode() {
-	testMode = false
-	testResult = nil
+	captured = nil
 	clientFactory = nil
 	cfg = nil
+	cfgDryRun = false
+	cfgRecord, cfgReplay, cassetteRT = "", "", nil
+	currentCmd = nil
+	cache.SetDir("")
+	trash.SetDir("")
+	audit.SetPath("")
+	os.RemoveAll(testCacheDir)
 	testMu.Unlock()
 }
 
-// RunTestCommand runs a command function and captures the exit signal.
-func RunTestCommand(fn func()) {
+// runCaptured runs fn, stopping where its response was captured.
+func runCaptured(fn func()) {
 	defer func() {
 		if r := recover(); r != nil {
-			if _, ok := r.(testExitSignal); !ok {
+			if _, ok := r.(exitSignal); !ok {
 				panic(r)
 			}
 		}
 	}()
 	fn()
 }
+
+// RunTestCommand runs a command function and captures the exit signal.
+func RunTestCommand(fn func()) {
+	runCaptured(fn)
+}
@@ -0,0 +1,102 @@
+package commands
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maquina/recuerd0-cli/internal/cache"
+	"github.com/maquina/recuerd0-cli/internal/errors"
+	"github.com/maquina/recuerd0-cli/internal/response"
+)
+
+// lastResult holds the breadcrumbs of the last successful response, which
+// run-breadcrumb picks from.
+type lastResult struct {
+	Summary     string                `json:"summary"`
+	Breadcrumbs []response.Breadcrumb `json:"breadcrumbs"`
+}
+
+func lastResultKey() string {
+	return cache.Key("last-breadcrumbs")
+}
+
+// rememberBreadcrumbs saves resp's breadcrumbs for run-breadcrumb. Failed
+// responses are skipped, so a mistyped command does not lose them.
+func rememberBreadcrumbs(resp *response.Response) {
+	if !resp.Success {
+		return
+	}
+	_ = cache.Put(lastResultKey(), lastResult{Summary: resp.Summary, Breadcrumbs: resp.Breadcrumbs})
+}
+
+var runBreadcrumbCmd = &cobra.Command{
+	Use:   "run-breadcrumb <action> [name=value...]",
+	Short: "Run a breadcrumb from the previous result",
+	Long: `Run the breadcrumb with the given action from the last command that
+succeeded, as if its argv had been typed. Values for the placeholders listed
+in its requires field are passed as name=value:
+
+  recuerd0 memory list --workspace 22
+  recuerd0 run-breadcrumb show memory_id=42
+
+Global flags given here, such as --account or --pretty, apply to the
+command that runs.`,
+	Example: `  recuerd0 run-breadcrumb show
+  recuerd0 run-breadcrumb show memory_id=42`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var last lastResult
+		if !cache.Get(lastResultKey(), cache.NoExpiry, &last) || len(last.Breadcrumbs) == 0 {
+			exitWithError(errors.NewInvalidArgsError("the previous result has no breadcrumbs"))
+			return
+		}
+
+		var b *response.Breadcrumb
+		actions := make([]string, len(last.Breadcrumbs))
+		for i := range last.Breadcrumbs {
+			actions[i] = last.Breadcrumbs[i].Action
+			if b == nil && last.Breadcrumbs[i].Action == args[0] {
+				b = &last.Breadcrumbs[i]
+			}
+		}
+		if b == nil {
+			exitWithError(errors.NewInvalidArgsError(fmt.Sprintf("no %q breadcrumb in the previous result; available: %s", args[0], strings.Join(actions, ", "))))
+			return
+		}
+
+		values := map[string]string{}
+		for _, arg := range args[1:] {
+			name, value, ok := strings.Cut(arg, "=")
+			if !ok {
+				exitWithError(errors.NewInvalidArgsError(fmt.Sprintf("expected name=value, got %q", arg)))
+				return
+			}
+			if !slices.Contains(b.Requires, name) {
+				exitWithError(errors.NewInvalidArgsError(fmt.Sprintf("breadcrumb %q has no <%s> to fill", b.Action, name)))
+				return
+			}
+			values[name] = value
+		}
+		argv, missing := b.Fill(values)
+		if len(missing) > 0 {
+			exitWithError(errors.NewInvalidArgsError(fmt.Sprintf("breadcrumb %q requires %s; pass them as name=value", b.Action, strings.Join(missing, ", "))))
+			return
+		}
+		if len(argv) < 2 || argv[0] != rootCmd.Name() || argv[1] == cmd.Name() {
+			exitWithError(errors.NewInvalidArgsError(fmt.Sprintf("cannot run breadcrumb %q: %s", b.Action, b.Cmd)))
+			return
+		}
+
+		rootCmd.SetArgs(argv[1:])
+		if err := rootCmd.Execute(); err != nil {
+			exitWithError(errors.NewInvalidArgsError(err.Error()))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(runBreadcrumbCmd)
+}
@@ -0,0 +1,108 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/maquina/recuerd0-cli/internal/errors"
+)
+
+func TestRunBreadcrumb(t *testing.T) {
+	mock := NewMockClient().
+		WithPostData(map[string]interface{}{"id": float64(7), "title": "Notes"}).
+		WithGetData(map[string]interface{}{"id": float64(7), "title": "Notes"})
+	result := SetTestMode(mock)
+	SetTestConfigFull("tok_test", "https://api.example.com", "5")
+	defer ResetTestMode()
+	defer resetFlags(rootCmd)
+
+	run := func(args ...string) {
+		t.Helper()
+		RunTestCommand(func() {
+			runBreadcrumbCmd.Run(runBreadcrumbCmd, args)
+		})
+	}
+
+	memoryCreateTitle, memoryCreateContent = "Notes", "Body"
+	RunTestCommand(func() {
+		memoryCreateCmd.Run(memoryCreateCmd, nil)
+	})
+	memoryCreateTitle, memoryCreateContent = "", ""
+	if show := result.Response.Breadcrumbs[0]; show.Cmd != "recuerd0 memory show --workspace 5 7" || show.Requires != nil {
+		t.Fatalf("expected the created ID in the breadcrumb, got %+v", show)
+	}
+
+	run("show")
+	if result.ExitCode != 0 || result.Response.Summary != "Memory details" {
+		t.Fatalf("expected memory show to run, got %d %+v", result.ExitCode, result.Response)
+	}
+	if got := mock.GetCalls[len(mock.GetCalls)-1].Path; got != "/workspaces/5/memories/7" {
+		t.Errorf("unexpected path %s", got)
+	}
+
+	// The breadcrumbs of memory show are now the previous result.
+	run("show")
+	if result.ExitCode != errors.ExitInvalidArgs || !strings.Contains(result.Response.Error.Message, "available: update, version, delete") {
+		t.Errorf("expected the available actions, got %+v", result.Response.Error)
+	}
+
+	// The failure kept the breadcrumbs of memory show.
+	run("update", "title=Renamed")
+	if result.ExitCode != 0 || len(mock.PatchCalls) != 1 || mock.PatchCalls[0].Path != "/workspaces/5/memories/7" {
+		t.Fatalf("expected memory update to run, got %d %+v", result.ExitCode, result.Response)
+	}
+	body := mock.PatchCalls[0].Body.(map[string]interface{})["memory"].(map[string]interface{})
+	if body["title"] != "Renamed" {
+		t.Errorf("expected the filled-in title, got %v", body["title"])
+	}
+
+	// delete confirms with --yes, as nothing can answer a prompt.
+	RunTestCommand(func() {
+		memoryShowCmd.Run(memoryShowCmd, []string{"7"})
+	})
+	run("delete")
+	if result.ExitCode != 0 || len(mock.DeleteCalls) != 1 {
+		t.Fatalf("expected memory delete to run, got %d %+v", result.ExitCode, result.Response.Error)
+	}
+}
+
+func TestRunBreadcrumb_Placeholders(t *testing.T) {
+	mock := NewMockClient().WithGetData([]interface{}{map[string]interface{}{"id": float64(7), "title": "Notes"}})
+	result := SetTestMode(mock)
+	SetTestConfigFull("tok_test", "https://api.example.com", "5")
+	defer ResetTestMode()
+	defer resetFlags(rootCmd)
+
+	RunTestCommand(func() {
+		memoryListCmd.Run(memoryListCmd, nil)
+	})
+	if show := result.Response.Breadcrumbs[0]; strings.Join(show.Requires, ",") != "memory_id" {
+		t.Fatalf("expected show to require memory_id, got %+v", show)
+	}
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"show"}, `breadcrumb "show" requires memory_id`},
+		{[]string{"show", "memory_id"}, "expected name=value"},
+		{[]string{"show", "workspace_id=3"}, "has no <workspace_id>"},
+		{[]string{"nope"}, `no "nope" breadcrumb`},
+	}
+	for _, tt := range tests {
+		RunTestCommand(func() {
+			runBreadcrumbCmd.Run(runBreadcrumbCmd, tt.args)
+		})
+		if result.ExitCode != errors.ExitInvalidArgs || !strings.Contains(result.Response.Error.Message, tt.want) {
+			t.Errorf("%v: expected %q, got %+v", tt.args, tt.want, result.Response.Error)
+		}
+	}
+
+	mock.WithGetData(map[string]interface{}{"id": float64(9), "title": "Other"})
+	RunTestCommand(func() {
+		runBreadcrumbCmd.Run(runBreadcrumbCmd, []string{"show", "memory_id=9"})
+	})
+	if result.ExitCode != 0 || mock.GetCalls[len(mock.GetCalls)-1].Path != "/workspaces/5/memories/9" {
+		t.Errorf("expected memory 9 to be shown, got %d %+v", result.ExitCode, result.Response)
+	}
+}
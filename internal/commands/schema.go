@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/maquina/recuerd0-cli/internal/catalog"
+	"github.com/maquina/recuerd0-cli/internal/errors"
+	"github.com/maquina/recuerd0-cli/internal/schema"
+)
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema [name]",
+	Short: "Print the JSON Schema of the output",
+	Long: `Print a JSON Schema (draft 2020-12) generated from the types the CLI
+prints, without the envelope:
+
+  envelope    the envelope every command prints (the default)
+  error       the envelope of a failed command
+  workspace   a workspace; workspace list returns an array of them
+  memory      a memory; memory list returns an array of them
+  search      the data of search
+
+meta.schema_version in every response names the version of these
+schemas; it changes when a field is removed, renamed or retyped.`,
+	Example: `  recuerd0 schema
+  recuerd0 schema memory`,
+	Args:        cobra.MaximumNArgs(1),
+	ValidArgs:   schema.Names,
+	Annotations: map[string]string{catalog.Local: "true"},
+	Run: func(cmd *cobra.Command, args []string) {
+		name := "envelope"
+		if len(args) > 0 {
+			name = args[0]
+		}
+		doc, err := schema.Document(name)
+		if err != nil {
+			exitWithError(errors.NewInvalidArgsError(err.Error()))
+			return
+		}
+		if err := writeIndentedJSON(catalogOutput, doc); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+}
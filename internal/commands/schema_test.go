@@ -0,0 +1,54 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/maquina/recuerd0-cli/internal/errors"
+)
+
+func TestSchema(t *testing.T) {
+	tests := []struct {
+		args  []string
+		title string
+	}{
+		{nil, "recuerd0 envelope"},
+		{[]string{"memory"}, "recuerd0 memory"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			result := SetTestMode(NewMockClient())
+			defer ResetTestMode()
+			var buf bytes.Buffer
+			orig := catalogOutput
+			catalogOutput = &buf
+			defer func() { catalogOutput = orig }()
+
+			RunTestCommand(func() {
+				schemaCmd.Run(schemaCmd, tt.args)
+			})
+
+			if result.Response != nil {
+				t.Fatalf("expected the bare schema, got an envelope %+v", result.Response)
+			}
+			var doc map[string]interface{}
+			if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+				t.Fatal(err)
+			}
+			if doc["title"] != tt.title {
+				t.Errorf("expected %q, got %v", tt.title, doc["title"])
+			}
+		})
+	}
+	t.Run("unknown", func(t *testing.T) {
+		result := SetTestMode(NewMockClient())
+		defer ResetTestMode()
+		RunTestCommand(func() {
+			schemaCmd.Run(schemaCmd, []string{"tag"})
+		})
+		if result.ExitCode != errors.ExitInvalidArgs {
+			t.Errorf("expected exit code %d, got %d", errors.ExitInvalidArgs, result.ExitCode)
+		}
+	})
+}
@@ -2,6 +2,7 @@ package commands
 
 import (
 	"fmt"
+	"net/url"
 
 	"github.com/spf13/cobra"
 
@@ -12,33 +13,150 @@ import (
 var (
 	searchWorkspace string
 	searchPage      string
+	searchTitle     []string
+	searchBody      []string
+	searchPhrase    []string
+	searchAny       []string
+	searchAll       []string
+	searchNot       []string
+	searchFilter    listFilterFlags
+	searchOffline   bool
+	searchStream    bool
 )
 
 var searchCmd = &cobra.Command{
-	Use:   "search <query>",
+	Use:   "search [query]",
 	Short: "Search memories",
-	Args:  cobra.ExactArgs(1),
+	Long: `Search memories using FTS5 syntax, either written directly or composed with
+flags. Flags are combined with AND:
+
+  --title WORD      title:WORD
+  --body WORD       body:WORD
+  --phrase TEXT     "TEXT"
+  --all WORD        WORD (repeat for more required terms)
+  --any WORD        (A OR B) across all --any values
+  --not WORD        NOT WORD
+
+Results can also be filtered and sorted locally with --tag, --source,
+--since/--until, --title-match, --sort and --reverse. When any of these are
+set, all result pages are fetched unless --page is given.
+
+--stream writes each result as one JSON line as pages arrive, without
+holding them in memory, and then a summary envelope. It takes the filters
+but not --sort or --reverse.
+
+The server cannot search the content of encrypted workspaces. --offline
+searches a local index of encrypted memories instead, built as they are
+created, updated or shown on this machine; every query word must match.`,
+	Example: `  recuerd0 search "architecture AND design"
+  recuerd0 search --title architecture --any meeting --any standup --not draft --workspace 22`,
+	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		if err := requireAuth(); err != nil {
 			exitWithError(err)
 			return
 		}
 
-		query := args[0]
-		if query == "" {
-			exitWithError(errors.NewInvalidArgsError("search query is required"))
+		lf, err := searchFilter.compile()
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		if searchStream {
+			if err := validStream(lf); err != nil {
+				exitWithError(err)
+				return
+			}
+		}
+
+		parts := searchQueryParts{
+			Title:  searchTitle,
+			Body:   searchBody,
+			Phrase: searchPhrase,
+			Any:    searchAny,
+			All:    searchAll,
+			Not:    searchNot,
+		}
+		if len(args) > 0 {
+			parts.Raw = args[0]
+		}
+		query, err := buildSearchQuery(parts)
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		if err := validateSearchQuery(query); err != nil {
+			exitWithError(err)
 			return
 		}
 
-		path := "/search?q=" + query
+		if searchOffline {
+			if lf.active() || searchPage != "" || searchStream {
+				exitWithError(errors.NewInvalidArgsError("--offline cannot be combined with --page, --stream or result filters"))
+				return
+			}
+			ws := ""
+			if searchWorkspace != "" {
+				if ws, err = lookupWorkspaceID(searchWorkspace); err != nil {
+					exitWithError(err)
+					return
+				}
+			}
+			results := searchIndex(query, ws)
+			data := map[string]interface{}{
+				"query":         query,
+				"results":       results,
+				"total_results": len(results),
+				"offline":       true,
+			}
+			bc := []response.Breadcrumb{
+				breadcrumb("show", fmt.Sprintf("recuerd0 memory show --workspace %s <memory_id>", searchResultWorkspace(ws)), "View and decrypt memory"),
+			}
+			printSuccessWithBreadcrumbs(data, fmt.Sprintf("%d offline result(s) for %q", len(results), query), bc)
+			return
+		}
+
+		params := url.Values{}
+		params.Set("q", query)
+		ws := ""
 		if searchWorkspace != "" {
-			path += "&workspace_id=" + searchWorkspace
+			if ws, err = lookupWorkspaceID(searchWorkspace); err != nil {
+				exitWithError(err)
+				return
+			}
+			params.Set("workspace_id", ws)
 		}
 		if searchPage != "" {
-			path += "&page=" + searchPage
+			params.Set("page", searchPage)
+		}
+
+		path := "/search?" + params.Encode()
+		bc := []response.Breadcrumb{
+			breadcrumb("show", fmt.Sprintf("recuerd0 memory show --workspace %s <memory_id>", searchResultWorkspace(ws)), "View memory details"),
 		}
 
 		apiClient := getClient()
+		if searchStream {
+			listing, err := streamListing(apiClient, path, searchPage != "", lf)
+			if err != nil {
+				exitWithError(err)
+				return
+			}
+			summary := listing.summary(fmt.Sprintf("result(s) for %q", query), lf)
+			printSuccessWithPageAndBreadcrumbs(listing.Data, listing.Pagination, summary, bc)
+			return
+		}
+		if lf.active() {
+			listing, err := fetchFiltered(apiClient, path, searchPage != "", lf)
+			if err != nil {
+				exitWithError(err)
+				return
+			}
+			summary := listing.summary(fmt.Sprintf("result(s) for %q", query), lf)
+			printSuccessWithPageAndBreadcrumbs(listing.Data, listing.Pagination, summary, bc)
+			return
+		}
+
 		resp, err := apiClient.GetWithPagination(path)
 		if err != nil {
 			exitWithError(err)
@@ -49,16 +167,30 @@ var searchCmd = &cobra.Command{
 		items := countSearchResults(resp.Data)
 		summary := fmt.Sprintf("%d result(s) for %q", items, query)
 
-		bc := []response.Breadcrumb{
-			breadcrumb("show", "recuerd0 memory show --workspace <id> <memory_id>", "View memory details"),
-		}
-
 		printSuccessWithPaginationAndBreadcrumbs(resp.Data, hasNext, resp.LinkNext, summary, bc)
 	},
 }
 
+// searchResultWorkspace is the workspace of a result: the one searched, or
+// a placeholder when results can come from any workspace.
+func searchResultWorkspace(ws string) string {
+	if ws == "" {
+		return "<workspace_id>"
+	}
+	return ws
+}
+
 func init() {
-	searchCmd.Flags().StringVar(&searchWorkspace, "workspace", "", "limit search to workspace")
+	searchCmd.Flags().StringVar(&searchWorkspace, "workspace", "", "limit search to workspace (ID, name or alias)")
 	searchCmd.Flags().StringVar(&searchPage, "page", "", "page number")
+	searchCmd.Flags().StringArrayVar(&searchTitle, "title", nil, "match in title (repeatable)")
+	searchCmd.Flags().StringArrayVar(&searchBody, "body", nil, "match in body (repeatable)")
+	searchCmd.Flags().StringArrayVar(&searchPhrase, "phrase", nil, "match exact phrase (repeatable)")
+	searchCmd.Flags().StringArrayVar(&searchAny, "any", nil, "match at least one of these terms (repeatable)")
+	searchCmd.Flags().StringArrayVar(&searchAll, "all", nil, "require term (repeatable)")
+	searchCmd.Flags().StringArrayVar(&searchNot, "not", nil, "exclude term (repeatable)")
+	searchCmd.Flags().BoolVar(&searchOffline, "offline", false, "search the local index of encrypted memories")
+	addListFilterFlags(searchCmd, &searchFilter)
+	addStreamFlag(searchCmd, &searchStream)
 	rootCmd.AddCommand(searchCmd)
 }
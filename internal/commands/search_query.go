@@ -0,0 +1,150 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/maquina/recuerd0-cli/internal/errors"
+)
+
+// Query length limits enforced by the search API.
+const (
+	minSearchQueryLen = 3
+	maxSearchQueryLen = 100
+)
+
+// searchQueryParts holds the structured search flags before they are
+// compiled into an FTS5 query.
+type searchQueryParts struct {
+	Raw    string
+	Title  []string
+	Body   []string
+	Phrase []string
+	Any    []string
+	All    []string
+	Not    []string
+}
+
+// buildSearchQuery compiles the structured flags into FTS5 syntax. The raw
+// query is kept as-is (grouped when combined with other parts or --not);
+// every other value is quoted when needed so it is matched literally.
+func buildSearchQuery(p searchQueryParts) (string, error) {
+	var required []string
+	if raw := strings.TrimSpace(p.Raw); raw != "" {
+		required = append(required, raw)
+	}
+	for _, v := range p.Title {
+		required = append(required, "title:"+ftsTerm(v))
+	}
+	for _, v := range p.Body {
+		required = append(required, "body:"+ftsTerm(v))
+	}
+	for _, v := range p.Phrase {
+		required = append(required, ftsPhrase(v))
+	}
+	for _, v := range p.All {
+		required = append(required, ftsTerm(v))
+	}
+	if len(p.Any) > 0 {
+		alts := make([]string, 0, len(p.Any))
+		for _, v := range p.Any {
+			alts = append(alts, ftsTerm(v))
+		}
+		anyOf := strings.Join(alts, " OR ")
+		if len(alts) > 1 {
+			anyOf = "(" + anyOf + ")"
+		}
+		required = append(required, anyOf)
+	}
+
+	if len(required) == 0 {
+		if len(p.Not) > 0 {
+			return "", errors.NewInvalidArgsError("--not needs something to exclude from; add a query or another search flag")
+		}
+		return "", errors.NewInvalidArgsError("search query is required; pass a query or use --title, --body, --phrase, --any or --all")
+	}
+
+	// FTS5 binds NOT tighter than AND and OR, so the raw query is grouped
+	// whenever anything is added to it, or "a OR b NOT c" would only
+	// exclude c from b.
+	if strings.TrimSpace(p.Raw) != "" && (len(required) > 1 || len(p.Not) > 0) {
+		required[0] = "(" + required[0] + ")"
+	}
+	query := strings.Join(required, " AND ")
+	for _, v := range p.Not {
+		query += " NOT " + ftsTerm(v)
+	}
+	return query, nil
+}
+
+// ftsTerm returns v as a single FTS5 term, quoting it when it contains
+// anything other than letters, digits and underscores or is an operator.
+func ftsTerm(v string) string {
+	v = strings.TrimSpace(v)
+	switch v {
+	case "AND", "OR", "NOT", "NEAR":
+		return ftsPhrase(v)
+	}
+	for _, r := range v {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+			return ftsPhrase(v)
+		}
+	}
+	return v
+}
+
+// ftsPhrase wraps v in double quotes, doubling any embedded quotes as FTS5
+// expects.
+func ftsPhrase(v string) string {
+	return `"` + strings.ReplaceAll(strings.TrimSpace(v), `"`, `""`) + `"`
+}
+
+// validateSearchQuery checks what the API would reject so bad queries fail
+// locally with a precise message instead of a generic syntax error.
+func validateSearchQuery(q string) error {
+	n := utf8.RuneCountInString(q)
+	if n < minSearchQueryLen {
+		return errors.NewInvalidArgsError(fmt.Sprintf("search query must be at least %d characters (got %d)", minSearchQueryLen, n))
+	}
+	if n > maxSearchQueryLen {
+		return errors.NewInvalidArgsError(fmt.Sprintf("search query must be at most %d characters (got %d)", maxSearchQueryLen, n))
+	}
+
+	var open []int
+	quoteAt := 0
+	runes := []rune(q)
+	for i := 0; i < len(runes); i++ {
+		pos := i + 1
+		r := runes[i]
+		if quoteAt > 0 {
+			if r == '"' {
+				if i+1 < len(runes) && runes[i+1] == '"' {
+					i++ // escaped quote
+					continue
+				}
+				quoteAt = 0
+			}
+			continue
+		}
+		switch r {
+		case '"':
+			quoteAt = pos
+		case '(':
+			open = append(open, pos)
+		case ')':
+			if len(open) == 0 {
+				return errors.NewInvalidArgsError(fmt.Sprintf("search query has unmatched ')' at position %d", pos))
+			}
+			open = open[:len(open)-1]
+		}
+	}
+	if quoteAt > 0 {
+		return errors.NewInvalidArgsError(fmt.Sprintf("search query has unclosed '\"' opened at position %d", quoteAt))
+	}
+	if len(open) > 0 {
+		return errors.NewInvalidArgsError(fmt.Sprintf("search query has unclosed '(' opened at position %d", open[len(open)-1]))
+	}
+	return nil
+}
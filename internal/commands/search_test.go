@@ -1,11 +1,21 @@
 package commands
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/maquina/recuerd0-cli/internal/client"
+	"github.com/maquina/recuerd0-cli/internal/errors"
 )
 
+func resetSearchFlags() {
+	searchWorkspace, searchPage = "", ""
+	searchTitle, searchBody, searchPhrase = nil, nil, nil
+	searchAny, searchAll, searchNot = nil, nil, nil
+	searchFilter = listFilterFlags{}
+	searchOffline = false
+}
+
 func TestSearch(t *testing.T) {
 	mock := NewMockClient()
 	mock.GetResponse = &client.APIResponse{
@@ -17,8 +27,7 @@ func TestSearch(t *testing.T) {
 	SetTestConfig("tok_test", "https://api.example.com")
 	defer ResetTestMode()
 
-	searchWorkspace = ""
-	searchPage = ""
+	resetSearchFlags()
 
 	RunTestCommand(func() {
 		searchCmd.Run(searchCmd, []string{"golang patterns"})
@@ -30,7 +39,7 @@ func TestSearch(t *testing.T) {
 	if !result.Response.Success {
 		t.Error("expected success response")
 	}
-	if mock.GetCalls[0].Path != "/search?q=golang patterns" {
+	if mock.GetCalls[0].Path != "/search?q=golang+patterns" {
 		t.Errorf("unexpected path: %s", mock.GetCalls[0].Path)
 	}
 }
@@ -46,9 +55,9 @@ func TestSearch_WithWorkspace(t *testing.T) {
 	SetTestConfig("tok_test", "https://api.example.com")
 	defer ResetTestMode()
 
+	resetSearchFlags()
 	searchWorkspace = "5"
-	searchPage = ""
-	defer func() { searchWorkspace = "" }()
+	defer resetSearchFlags()
 
 	RunTestCommand(func() {
 		searchCmd.Run(searchCmd, []string{"test"})
@@ -73,9 +82,9 @@ func TestSearch_WithPage(t *testing.T) {
 	SetTestConfig("tok_test", "https://api.example.com")
 	defer ResetTestMode()
 
-	searchWorkspace = ""
+	resetSearchFlags()
 	searchPage = "3"
-	defer func() { searchPage = "" }()
+	defer resetSearchFlags()
 
 	RunTestCommand(func() {
 		searchCmd.Run(searchCmd, []string{"query"})
@@ -84,7 +93,7 @@ func TestSearch_WithPage(t *testing.T) {
 	if result.ExitCode != 0 {
 		t.Errorf("expected exit code 0, got %d", result.ExitCode)
 	}
-	if mock.GetCalls[0].Path != "/search?q=query&page=3" {
+	if mock.GetCalls[0].Path != "/search?page=3&q=query" {
 		t.Errorf("unexpected path: %s", mock.GetCalls[0].Path)
 	}
 }
@@ -103,3 +112,167 @@ func TestSearch_NoAuth(t *testing.T) {
 		t.Error("expected error response")
 	}
 }
+
+func TestSearch_EncodesSpecialCharacters(t *testing.T) {
+	mock := NewMockClient()
+	mock.GetResponse = &client.APIResponse{StatusCode: 200, Data: map[string]interface{}{}}
+
+	result := SetTestMode(mock)
+	SetTestConfig("tok_test", "https://api.example.com")
+	defer ResetTestMode()
+	resetSearchFlags()
+
+	RunTestCommand(func() {
+		searchCmd.Run(searchCmd, []string{`"Q&A" #notes`})
+	})
+
+	if result.ExitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", result.ExitCode)
+	}
+	if got := mock.GetCalls[0].Path; got != "/search?q=%22Q%26A%22+%23notes" {
+		t.Errorf("unexpected path: %s", got)
+	}
+}
+
+func TestSearch_BuilderFlags(t *testing.T) {
+	mock := NewMockClient()
+	mock.GetResponse = &client.APIResponse{StatusCode: 200, Data: map[string]interface{}{}}
+
+	result := SetTestMode(mock)
+	SetTestConfig("tok_test", "https://api.example.com")
+	defer ResetTestMode()
+	resetSearchFlags()
+	defer resetSearchFlags()
+	searchTitle = []string{"architecture"}
+	searchNot = []string{"draft"}
+
+	RunTestCommand(func() {
+		searchCmd.Run(searchCmd, []string{})
+	})
+
+	if result.ExitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", result.ExitCode)
+	}
+	if got := mock.GetCalls[0].Path; got != "/search?q=title%3Aarchitecture+NOT+draft" {
+		t.Errorf("unexpected path: %s", got)
+	}
+}
+
+func TestSearch_InvalidQueryMakesNoRequest(t *testing.T) {
+	mock := NewMockClient()
+	result := SetTestMode(mock)
+	SetTestConfig("tok_test", "https://api.example.com")
+	defer ResetTestMode()
+	resetSearchFlags()
+
+	RunTestCommand(func() {
+		searchCmd.Run(searchCmd, []string{"(golang"})
+	})
+
+	if result.ExitCode != errors.ExitInvalidArgs {
+		t.Errorf("expected exit code %d, got %d", errors.ExitInvalidArgs, result.ExitCode)
+	}
+	if len(mock.GetCalls) != 0 {
+		t.Errorf("expected no API calls, got %d", len(mock.GetCalls))
+	}
+}
+
+func TestSearch_TagFilter(t *testing.T) {
+	mock := NewMockClient()
+	mock.GetResponse = &client.APIResponse{
+		StatusCode: 200,
+		Data: map[string]interface{}{
+			"total_results": float64(2),
+			"results": []interface{}{
+				map[string]interface{}{"id": float64(1), "tags": []interface{}{"Design"}},
+				map[string]interface{}{"id": float64(2), "tags": []interface{}{"ops"}},
+			},
+		},
+	}
+
+	result := SetTestMode(mock)
+	SetTestConfig("tok_test", "https://api.example.com")
+	defer ResetTestMode()
+	resetSearchFlags()
+	defer resetSearchFlags()
+	searchFilter.Tags = []string{"design"}
+
+	RunTestCommand(func() {
+		searchCmd.Run(searchCmd, []string{"architecture"})
+	})
+
+	data := result.Response.Data.(map[string]interface{})
+	if results := data["results"].([]interface{}); len(results) != 1 {
+		t.Errorf("expected 1 result, got %d", len(results))
+	}
+	if !strings.HasPrefix(result.Response.Summary, "1 of 2 result(s)") {
+		t.Errorf("unexpected summary: %s", result.Response.Summary)
+	}
+}
+
+func TestBuildSearchQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		parts searchQueryParts
+		want  string
+	}{
+		{"raw only", searchQueryParts{Raw: "meeting OR standup"}, "meeting OR standup"},
+		{"title and body", searchQueryParts{Title: []string{"design"}, Body: []string{"rails"}}, "title:design AND body:rails"},
+		{"phrase", searchQueryParts{Phrase: []string{`say "hi" now`}}, `"say ""hi"" now"`},
+		{"any", searchQueryParts{Any: []string{"meeting", "standup"}}, "(meeting OR standup)"},
+		{"single any", searchQueryParts{Any: []string{"meeting"}}, "meeting"},
+		{"all with quoting", searchQueryParts{All: []string{"c++", "OR"}}, `"c++" AND "OR"`},
+		{"raw grouped", searchQueryParts{Raw: "a OR b", All: []string{"notes"}}, "(a OR b) AND notes"},
+		{"not", searchQueryParts{All: []string{"design"}, Not: []string{"draft", "old"}}, "design NOT draft NOT old"},
+		{"raw with not", searchQueryParts{Raw: "redis OR memcached", Not: []string{"draft"}}, "(redis OR memcached) NOT draft"},
+		{"raw, all and not", searchQueryParts{Raw: "redis OR memcached", All: []string{"cache"}, Not: []string{"draft"}}, "(redis OR memcached) AND cache NOT draft"},
+		{"multiword title", searchQueryParts{Title: []string{"project plan"}}, `title:"project plan"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := buildSearchQuery(tt.parts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := buildSearchQuery(searchQueryParts{Not: []string{"draft"}}); err == nil {
+		t.Error("expected error for --not alone")
+	}
+	if _, err := buildSearchQuery(searchQueryParts{}); err == nil {
+		t.Error("expected error for empty query")
+	}
+}
+
+func TestValidateSearchQuery(t *testing.T) {
+	tests := []struct {
+		query   string
+		wantErr string
+	}{
+		{"golang", ""},
+		{`"say ""hi"""`, ""},
+		{"(a OR b) AND c", ""},
+		{"ab", "at least 3 characters (got 2)"},
+		{strings.Repeat("x", 101), "at most 100 characters (got 101)"},
+		{"(a OR b", "unclosed '(' opened at position 1"},
+		{"a OR b)", "unmatched ')' at position 7"},
+		{`say "hello`, `unclosed '"' opened at position 5`},
+		{`"(not a group"`, ""},
+	}
+	for _, tt := range tests {
+		err := validateSearchQuery(tt.query)
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("%q: unexpected error: %v", tt.query, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%q: expected error containing %q, got %v", tt.query, tt.wantErr, err)
+		}
+	}
+}
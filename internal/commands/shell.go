@@ -0,0 +1,564 @@
+package commands
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/maquina/recuerd0-cli/internal/audit"
+	"github.com/maquina/recuerd0-cli/internal/catalog"
+	"github.com/maquina/recuerd0-cli/internal/client"
+	"github.com/maquina/recuerd0-cli/internal/errors"
+	"github.com/maquina/recuerd0-cli/internal/lineedit"
+	"github.com/maquina/recuerd0-cli/internal/response"
+	"github.com/maquina/recuerd0-cli/internal/shellwords"
+)
+
+// shellHelp is the shell command's help, also printed by help inside it.
+const shellHelp = `Start an interactive session. The config is resolved once and one HTTP
+connection is kept for every command. Type any recuerd0 command without the
+leading "recuerd0"; output is shown as tables unless :json is on.
+
+Session commands:
+  use [workspace]   set or show the current workspace
+  show N            show result N of the last list or search
+  open TITLE        show the memory with this title in the current workspace
+  :json / :table    switch between JSON envelopes and tables
+  help              this text
+  exit, quit        leave (Ctrl-D works too)
+
+Tab completes commands, flags, workspace names and memory titles. History
+is kept in shell_history next to the audit log.`
+
+var shellCmd = &cobra.Command{
+	Use:         "shell",
+	Short:       "Interactive session with history, completion and tables",
+	Long:        shellHelp,
+	Annotations: map[string]string{catalog.Interactive: "true"},
+	Args:        cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := requireAuth(); err != nil {
+			exitWithError(err)
+			return
+		}
+		s := newShellSession(os.Stdin, os.Stdout, int(os.Stdin.Fd()))
+		s.loop()
+	},
+}
+
+// shellItem is one entry of the last result set, which show N refers to.
+type shellItem struct {
+	Kind      string
+	ID        string
+	Workspace string
+	Title     string
+}
+
+// shellSession holds the state kept between commands.
+type shellSession struct {
+	editor  *lineedit.Editor
+	out     io.Writer
+	json    bool
+	results []shellItem
+	// titles caches the current workspace's memories for completion and
+	// open; it is dropped after every command.
+	titles []shellItem
+}
+
+func newShellSession(in io.Reader, out io.Writer, fd int) *shellSession {
+	s := &shellSession{out: out}
+	s.editor = lineedit.New(in, out, fd)
+	s.editor.Complete = s.complete
+	s.editor.History = loadShellHistory(s.editor.MaxHistory)
+	return s
+}
+
+// shellHistoryPath keeps the history beside the audit log, in the XDG
+// state directory.
+func shellHistoryPath() string {
+	return filepath.Join(filepath.Dir(audit.Path()), "shell_history")
+}
+
+func loadShellHistory(max int) []string {
+	f, err := os.Open(shellHistoryPath())
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if max > 0 && len(lines) > max {
+		lines = lines[len(lines)-max:]
+	}
+	return lines
+}
+
+func appendShellHistory(line string) {
+	path := shellHistoryPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	fmt.Fprintln(f, line)
+}
+
+// loop reads and runs commands until exit or end of input. The client is
+// created once and shared by every command.
+func (s *shellSession) loop() {
+	shared := newClient()
+	prevFactory := clientFactory
+	clientFactory = func() client.API { return shared }
+	defer func() { clientFactory = prevFactory }()
+
+	for {
+		s.editor.Prompt = s.prompt()
+		line, err := s.editor.ReadLine()
+		if err == lineedit.ErrInterrupt {
+			continue
+		}
+		if err != nil {
+			return
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		s.editor.AddHistory(line)
+		if !s.exec(line) {
+			return
+		}
+	}
+}
+
+func (s *shellSession) prompt() string {
+	ws := ""
+	if cfg != nil {
+		ws = cfg.Workspace
+	}
+	if ws == "" {
+		return "recuerd0> "
+	}
+	if refs, _, err := listWorkspaceRefs(false); err == nil {
+		for _, ref := range refs {
+			if ref.ID == ws {
+				return fmt.Sprintf("recuerd0 [%s]> ", ref.Name)
+			}
+		}
+	}
+	return fmt.Sprintf("recuerd0 [%s]> ", ws)
+}
+
+// shellBuiltins are the session commands, completed alongside the CLI's.
+var shellBuiltins = []string{"use", "show", "open", ":json", ":table", "help", "exit", "quit"}
+
+// shellConfigFlags would need the config resolved again, which the shell
+// does only at start.
+var shellConfigFlags = []string{"--account", "--token", "--api-url"}
+
+// exec runs one line and reports whether the session should go on.
+func (s *shellSession) exec(line string) bool {
+	words, err := shellwords.Split(line)
+	if err != nil {
+		s.printError(errors.NewInvalidArgsError(err.Error()))
+		return true
+	}
+	if words[0] == "recuerd0" {
+		words = words[1:]
+		if len(words) == 0 {
+			return true
+		}
+	}
+
+	for _, w := range words {
+		for _, f := range shellConfigFlags {
+			if w == f || strings.HasPrefix(w, f+"=") {
+				s.printError(errors.NewInvalidArgsError(fmt.Sprintf("%s cannot change inside the shell; restart it with %s", f, f)))
+				return true
+			}
+		}
+	}
+	if words[0] == "shell" {
+		s.printError(errors.NewInvalidArgsError("already in the shell"))
+		return true
+	}
+	// Only lines that run are saved, with flag values such as --content
+	// scrubbed as in the audit log.
+	appendShellHistory(shellwords.Join(scrubArgs(words)))
+
+	switch words[0] {
+	case "exit", "quit":
+		return false
+	case "help", ":help":
+		if len(words) == 1 {
+			fmt.Fprintln(s.out, shellHelp)
+			return true
+		}
+	case ":json":
+		s.json = true
+		fmt.Fprintln(s.out, "Output: JSON envelopes")
+		return true
+	case ":table":
+		s.json = false
+		fmt.Fprintln(s.out, "Output: tables")
+		return true
+	case "use":
+		s.use(words[1:])
+		return true
+	case "show":
+		s.showResult(words[1:])
+		return true
+	case "open":
+		s.open(strings.Join(words[1:], " "))
+		return true
+	}
+	s.runAndShow(words)
+	return true
+}
+
+// run executes a CLI command in-process and returns its response. Flags
+// are reset afterwards so they do not carry over to the next command.
+func (s *shellSession) run(args []string) *CommandResult {
+	result := &CommandResult{}
+	prev := captured
+	captured = result
+	defer func() {
+		captured = prev
+		resetFlags(rootCmd)
+		s.titles = nil
+	}()
+
+	rootCmd.SetArgs(args)
+	runCaptured(func() {
+		if err := rootCmd.Execute(); err != nil {
+			result.Response = response.Error(errors.NewInvalidArgsError(err.Error()))
+			result.ExitCode = errors.ExitInvalidArgs
+		}
+	})
+	return result
+}
+
+func (s *shellSession) runAndShow(args []string) {
+	result := s.run(args)
+	if result.Response == nil {
+		return
+	}
+	if result.Response.Success {
+		s.remember(args, result.Response.Data)
+	}
+	s.print(result.Response)
+}
+
+func (s *shellSession) print(resp *response.Response) {
+	if s.json {
+		enc := json.NewEncoder(s.out)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(resp)
+		return
+	}
+	writeHuman(s.out, resp)
+}
+
+func (s *shellSession) printError(err *errors.CLIError) {
+	resp := response.Error(err)
+	s.print(resp)
+}
+
+// remember keeps list and search results so show N can refer to them.
+func (s *shellSession) remember(args []string, data interface{}) {
+	items := listItems(normalizeData(data))
+	if items == nil {
+		return
+	}
+	kind := "memory"
+	if args[0] == "workspace" {
+		kind = "workspace"
+	}
+	ws := ""
+	if cfg != nil {
+		ws = cfg.Workspace
+	}
+	for i, a := range args {
+		if a == "--workspace" && i+1 < len(args) {
+			if id, err := lookupWorkspaceID(args[i+1]); err == nil {
+				ws = id
+			}
+		} else if v, ok := strings.CutPrefix(a, "--workspace="); ok {
+			if id, err := lookupWorkspaceID(v); err == nil {
+				ws = id
+			}
+		}
+	}
+
+	results := []shellItem{}
+	for _, item := range items {
+		m, ok := item.(map[string]interface{})
+		if !ok || m["id"] == nil {
+			return
+		}
+		entry := shellItem{Kind: kind, ID: stringID(m["id"]), Workspace: ws}
+		entry.Title, _ = m["title"].(string)
+		if kind == "workspace" {
+			entry.Title, _ = m["name"].(string)
+		}
+		if w, ok := m["workspace"].(map[string]interface{}); ok && w["id"] != nil {
+			entry.Workspace = stringID(w["id"])
+		} else if m["workspace_id"] != nil {
+			entry.Workspace = stringID(m["workspace_id"])
+		}
+		results = append(results, entry)
+	}
+	s.results = results
+}
+
+func (s *shellSession) use(args []string) {
+	if len(args) == 0 {
+		if cfg.Workspace == "" {
+			fmt.Fprintln(s.out, "No current workspace; use <workspace> to set one")
+		} else {
+			fmt.Fprintf(s.out, "Current workspace: %s\n", cfg.Workspace)
+		}
+		return
+	}
+	id, err := lookupWorkspaceID(strings.Join(args, " "))
+	if err != nil {
+		s.printError(asCLIError(err))
+		return
+	}
+	cfg.Workspace = id
+	s.titles = nil
+	fmt.Fprintf(s.out, "Using workspace %s\n", id)
+}
+
+func (s *shellSession) showResult(args []string) {
+	n := 0
+	if len(args) == 1 {
+		n, _ = strconv.Atoi(args[0])
+	}
+	if n < 1 || n > len(s.results) {
+		if len(s.results) == 0 {
+			s.printError(errors.NewInvalidArgsError("no results yet; run a list or search first"))
+		} else {
+			s.printError(errors.NewInvalidArgsError(fmt.Sprintf("show takes a result number from 1 to %d", len(s.results))))
+		}
+		return
+	}
+	item := s.results[n-1]
+	if item.Kind == "workspace" {
+		s.runAndShow([]string{"workspace", "show", item.ID})
+		return
+	}
+	args = []string{"memory", "show", item.ID}
+	if item.Workspace != "" {
+		args = append(args, "--workspace", item.Workspace)
+	}
+	s.runAndShow(args)
+}
+
+func (s *shellSession) open(title string) {
+	if title == "" {
+		s.printError(errors.NewInvalidArgsError("open takes a memory title"))
+		return
+	}
+	memories, err := s.memoryTitles()
+	if err != nil {
+		s.printError(asCLIError(err))
+		return
+	}
+	var prefixed []shellItem
+	for _, m := range memories {
+		if strings.EqualFold(m.Title, title) {
+			prefixed = []shellItem{m}
+			break
+		}
+		if strings.HasPrefix(strings.ToLower(m.Title), strings.ToLower(title)) {
+			prefixed = append(prefixed, m)
+		}
+	}
+	switch len(prefixed) {
+	case 0:
+		s.printError(errors.NewNotFoundError(fmt.Sprintf("no memory titled %q in workspace %s", title, cfg.Workspace)))
+	case 1:
+		s.runAndShow([]string{"memory", "show", prefixed[0].ID, "--workspace", prefixed[0].Workspace})
+	default:
+		s.printError(errors.NewInvalidArgsError(fmt.Sprintf("%d memories start with %q; type more of the title", len(prefixed), title)))
+	}
+}
+
+// memoryTitles lists the current workspace's memories, cached until the
+// next command.
+func (s *shellSession) memoryTitles() ([]shellItem, error) {
+	if s.titles != nil {
+		return s.titles, nil
+	}
+	ws, err := requireWorkspace()
+	if err != nil {
+		return nil, err
+	}
+	memories, err := workspaceMemories(getClient(), ws)
+	if err != nil {
+		return nil, err
+	}
+	s.titles = []shellItem{}
+	for _, m := range memories {
+		title, _ := m["title"].(string)
+		s.titles = append(s.titles, shellItem{Kind: "memory", ID: stringID(m["id"]), Workspace: ws, Title: title})
+	}
+	return s.titles, nil
+}
+
+func asCLIError(err error) *errors.CLIError {
+	if cliErr, ok := err.(*errors.CLIError); ok {
+		return cliErr
+	}
+	return errors.NewError(err.Error())
+}
+
+// complete suggests commands, subcommands and flags from the command tree,
+// workspace names after --workspace and use, and memory titles after open
+// and search.
+func (s *shellSession) complete(line string) (int, []string) {
+	prev, start, word := splitPartialWords(line)
+	if len(prev) > 0 && prev[0] == "recuerd0" {
+		prev = prev[1:]
+	}
+
+	var pool []string
+	switch {
+	case len(prev) == 0:
+		pool = append([]string{}, shellBuiltins...)
+		for _, c := range rootCmd.Commands() {
+			if c.IsAvailableCommand() && c.Name() != "shell" {
+				pool = append(pool, c.Name())
+			}
+		}
+	case prev[len(prev)-1] == "--workspace" || (prev[0] == "use" && len(prev) == 1):
+		if refs, _, err := listWorkspaceRefs(false); err == nil {
+			for _, ref := range refs {
+				pool = append(pool, ref.Name)
+			}
+		}
+	case (prev[0] == "open" || prev[0] == "search") && !strings.HasPrefix(word, "-"):
+		if memories, err := s.memoryTitles(); err == nil {
+			for _, m := range memories {
+				pool = append(pool, m.Title)
+			}
+		}
+	default:
+		cmd, rest, err := rootCmd.Find(prev)
+		if err != nil {
+			return start, nil
+		}
+		if strings.HasPrefix(word, "-") {
+			add := func(f *pflag.Flag) {
+				if !f.Hidden {
+					pool = append(pool, "--"+f.Name)
+				}
+			}
+			cmd.NonInheritedFlags().VisitAll(add)
+			cmd.InheritedFlags().VisitAll(add)
+		} else if len(rest) == 0 {
+			for _, c := range cmd.Commands() {
+				if c.IsAvailableCommand() {
+					pool = append(pool, c.Name())
+				}
+			}
+		}
+	}
+
+	var out []string
+	seen := map[string]bool{}
+	for _, c := range pool {
+		if c == "" || seen[c] || !strings.HasPrefix(strings.ToLower(c), strings.ToLower(word)) {
+			continue
+		}
+		seen[c] = true
+		out = append(out, shellwords.Join([]string{c}))
+	}
+	return start, out
+}
+
+// splitPartialWords splits the text before the cursor into the finished
+// words and the word being typed, whose start offset it also returns.
+// Unterminated quotes are allowed.
+func splitPartialWords(line string) ([]string, int, string) {
+	var words []string
+	var cur strings.Builder
+	var quote rune
+	inWord := false
+	start := len(line)
+	for i, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			if !inWord {
+				inWord, start = true, i
+			}
+			quote = r
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, cur.String())
+				cur.Reset()
+				inWord = false
+			}
+		default:
+			if !inWord {
+				inWord, start = true, i
+			}
+			cur.WriteRune(r)
+		}
+	}
+	if !inWord {
+		start = len(line)
+	}
+	return words, start, cur.String()
+}
+
+// resetFlags returns every flag in the tree to its default.
+func resetFlags(cmd *cobra.Command) {
+	reset := func(f *pflag.Flag) {
+		if !f.Changed {
+			return
+		}
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			var def []string
+			if d := strings.Trim(f.DefValue, "[]"); d != "" {
+				def = strings.Split(d, ",")
+			}
+			_ = sv.Replace(def)
+		} else {
+			_ = f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	}
+	cmd.Flags().VisitAll(reset)
+	cmd.PersistentFlags().VisitAll(reset)
+	for _, c := range cmd.Commands() {
+		resetFlags(c)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(shellCmd)
+}
@@ -0,0 +1,193 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/maquina/recuerd0-cli/internal/response"
+)
+
+// tableColumns are the fields shown in tables, in this order, when the
+// items have them.
+var tableColumns = []string{"id", "name", "title", "workspace", "tags", "source", "version", "result", "count", "updated_at"}
+
+// maxCellWidth cuts long table cells.
+const maxCellWidth = 48
+
+// writeHuman renders an envelope as tables and text: lists as a numbered
+// table, single objects as fields followed by their content.
+func writeHuman(w io.Writer, resp *response.Response) {
+	if !resp.Success {
+		if resp.Error != nil {
+			fmt.Fprintf(w, "Error: %s (%s)\n", resp.Error.Message, resp.Error.Code)
+		}
+		return
+	}
+
+	data := normalizeData(resp.Data)
+	if items := listItems(data); items != nil {
+		writeTable(w, items)
+	} else if m, ok := data.(map[string]interface{}); ok {
+		writeRecord(w, m)
+	} else if data != nil {
+		out, _ := json.MarshalIndent(data, "", "  ")
+		fmt.Fprintln(w, string(out))
+	}
+
+	if resp.Summary != "" {
+		fmt.Fprintln(w, resp.Summary)
+	}
+	if resp.Pagination != nil && resp.Pagination.HasNext {
+		fmt.Fprintln(w, "More results: run again with --page")
+	}
+}
+
+// normalizeData turns typed data into the generic JSON shapes the API
+// returns, so every response renders the same way.
+func normalizeData(data interface{}) interface{} {
+	switch data.(type) {
+	case nil, map[string]interface{}, []interface{}, string, float64, bool:
+		return data
+	}
+	b, err := json.Marshal(data)
+	if err != nil {
+		return data
+	}
+	var out interface{}
+	if err := json.Unmarshal(b, &out); err != nil {
+		return data
+	}
+	return out
+}
+
+// listItems returns the items of a list or search response, or nil when
+// data is not a list.
+func listItems(data interface{}) []interface{} {
+	switch d := data.(type) {
+	case []interface{}:
+		return d
+	case map[string]interface{}:
+		if results, ok := d["results"].([]interface{}); ok {
+			return results
+		}
+	}
+	return nil
+}
+
+func writeTable(w io.Writer, items []interface{}) {
+	if len(items) == 0 {
+		fmt.Fprintln(w, "(no results)")
+		return
+	}
+	present := map[string]bool{}
+	for _, item := range items {
+		if m, ok := item.(map[string]interface{}); ok {
+			for k := range m {
+				present[k] = true
+			}
+		}
+	}
+	var cols []string
+	for _, c := range tableColumns {
+		if present[c] {
+			cols = append(cols, c)
+		}
+	}
+	if len(cols) == 0 {
+		for k := range present {
+			cols = append(cols, k)
+		}
+		sort.Strings(cols)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "#\t"+strings.ToUpper(strings.Join(cols, "\t")))
+	for i, item := range items {
+		m, _ := item.(map[string]interface{})
+		cells := make([]string, len(cols))
+		for j, c := range cols {
+			cells[j] = cellText(c, m[c], maxCellWidth)
+		}
+		fmt.Fprintf(tw, "%d\t%s\n", i+1, strings.Join(cells, "\t"))
+	}
+	tw.Flush()
+}
+
+func writeRecord(w io.Writer, m map[string]interface{}) {
+	var keys []string
+	for _, c := range tableColumns {
+		if _, ok := m[c]; ok {
+			keys = append(keys, c)
+		}
+	}
+	var rest []string
+	for k := range m {
+		if k != "content" && !slices.Contains(keys, k) {
+			rest = append(rest, k)
+		}
+	}
+	sort.Strings(rest)
+	keys = append(keys, rest...)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, k := range keys {
+		fmt.Fprintf(tw, "%s:\t%s\n", k, cellText(k, m[k], 0))
+	}
+	tw.Flush()
+
+	if body := memoryBody(m); body != "" {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, strings.TrimRight(body, "\n"))
+		fmt.Fprintln(w)
+	}
+}
+
+// cellText renders one value on a single line, cut to max runes when max
+// is positive.
+func cellText(key string, v interface{}, max int) string {
+	var s string
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		s = v
+		if strings.HasSuffix(key, "_at") {
+			if t, err := time.Parse(time.RFC3339, v); err == nil {
+				s = t.Local().Format("2006-01-02 15:04")
+			}
+		}
+	case float64, bool:
+		s = stringID(v)
+	case []interface{}:
+		parts := make([]string, len(v))
+		for i, p := range v {
+			parts[i] = cellText("", p, 0)
+		}
+		s = strings.Join(parts, ", ")
+	case map[string]interface{}:
+		switch {
+		case v["name"] != nil:
+			s = cellText("", v["name"], 0)
+		case v["title"] != nil:
+			s = cellText("", v["title"], 0)
+		case v["id"] != nil:
+			s = stringID(v["id"])
+		default:
+			b, _ := json.Marshal(v)
+			s = string(b)
+		}
+	default:
+		s = fmt.Sprint(v)
+	}
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); max > 0 && len(r) > max {
+		s = string(r[:max-1]) + "…"
+	}
+	return s
+}
@@ -0,0 +1,144 @@
+package commands
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func shellMock() *MockClient {
+	return NewMockClient().
+		WithGetPathData("/workspaces", []interface{}{
+			map[string]interface{}{"id": float64(5), "name": "Notes"},
+			map[string]interface{}{"id": float64(6), "name": "Ops runbooks"},
+		}).
+		WithGetPathData("/workspaces/5/memories", []interface{}{
+			map[string]interface{}{"id": float64(7), "title": "Caching strategy", "tags": []interface{}{"redis"}},
+			map[string]interface{}{"id": float64(8), "title": "Deploy checklist", "tags": []interface{}{"ops"}},
+		}).
+		WithGetPathData("/workspaces/5/memories/8", map[string]interface{}{
+			"id": float64(8), "title": "Deploy checklist",
+			"content": map[string]interface{}{"body": "# Deploy\n\n1. Tag the release"},
+		})
+}
+
+func runShell(t *testing.T, mock *MockClient, input string) string {
+	t.Helper()
+	SetTestMode(mock)
+	SetTestConfigFull("tok_test", "https://api.example.com", "5")
+	defer ResetTestMode()
+
+	var out bytes.Buffer
+	s := newShellSession(strings.NewReader(input), &out, -1)
+	s.loop()
+	return out.String()
+}
+
+func TestShell_ListThenShowByNumber(t *testing.T) {
+	mock := shellMock()
+	out := runShell(t, mock, "memory list\nshow 2\nexit\nmemory list\n")
+
+	if !strings.Contains(out, "recuerd0 [Notes]> ") {
+		t.Errorf("expected the workspace name in the prompt, got %q", out)
+	}
+	for _, want := range []string{"#  ID  TITLE", "2  8   Deploy checklist  ops", "2 memory(ies)", "1. Tag the release"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+	if last := mock.GetCalls[len(mock.GetCalls)-1].Path; last != "/workspaces/5/memories/8" {
+		t.Errorf("expected show 2 to fetch memory 8, got %s", last)
+	}
+	if strings.Count(out, "2 memory(ies)") != 1 {
+		t.Error("expected input after exit to be ignored")
+	}
+}
+
+func TestShell_JSONAndErrors(t *testing.T) {
+	out := runShell(t, shellMock(), "show 1\n:json\nmemory show 8\n--token x memory list\n")
+
+	if !strings.Contains(out, "Error: no results yet; run a list or search first (INVALID_ARGS)") {
+		t.Errorf("expected show without results to fail, got:\n%s", out)
+	}
+	if !strings.Contains(out, `"success": true`) || !strings.Contains(out, `"title": "Deploy checklist"`) {
+		t.Errorf("expected a JSON envelope after :json, got:\n%s", out)
+	}
+	if !strings.Contains(out, "--token cannot change inside the shell") {
+		t.Errorf("expected config flags to be refused, got:\n%s", out)
+	}
+}
+
+func TestShell_FlagsDoNotCarryOver(t *testing.T) {
+	out := runShell(t, shellMock(), "memory list --tag ops\nmemory list\n")
+
+	if !strings.Contains(out, "1 of 2 memory(ies) match") || !strings.Contains(out, "\n2 memory(ies)\n") {
+		t.Errorf("expected the second list to be unfiltered, got:\n%s", out)
+	}
+}
+
+func TestShell_UseAndHistory(t *testing.T) {
+	mock := shellMock()
+	SetTestMode(mock)
+	SetTestConfigFull("tok_test", "https://api.example.com", "5")
+	defer ResetTestMode()
+
+	var out bytes.Buffer
+	s := newShellSession(strings.NewReader("use Ops\nuse\n"), &out, -1)
+	s.loop()
+	if cfg.Workspace != "6" || !strings.Contains(out.String(), "Current workspace: 6") {
+		t.Errorf("expected use to switch to workspace 6, got %q (%s)", cfg.Workspace, out.String())
+	}
+
+	history, err := os.ReadFile(shellHistoryPath())
+	if err != nil || string(history) != "use Ops\nuse\n" {
+		t.Errorf("expected history to be saved, got %q (%v)", history, err)
+	}
+	if s := newShellSession(strings.NewReader(""), &out, -1); len(s.editor.History) != 2 {
+		t.Errorf("expected history to be loaded, got %q", s.editor.History)
+	}
+}
+
+func TestShell_HistoryScrubbed(t *testing.T) {
+	SetTestMode(shellMock().WithPostData(map[string]interface{}{"id": float64(9), "title": "T"}))
+	SetTestConfigFull("tok_test", "https://api.example.com", "5")
+	defer ResetTestMode()
+
+	var out bytes.Buffer
+	newShellSession(strings.NewReader("memory list --token tok_live\nmemory create --title T --content sk_live_123 --tags a,b\n"), &out, -1).loop()
+
+	history, err := os.ReadFile(shellHistoryPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "memory create --title [REDACTED] --content [REDACTED] --tags [REDACTED]\n"; string(history) != want {
+		t.Errorf("expected only the line that ran, scrubbed, got %q", history)
+	}
+}
+
+func TestShell_Complete(t *testing.T) {
+	SetTestMode(shellMock())
+	SetTestConfigFull("tok_test", "https://api.example.com", "5")
+	defer ResetTestMode()
+	s := newShellSession(strings.NewReader(""), &bytes.Buffer{}, -1)
+
+	tests := []struct {
+		line  string
+		start int
+		want  []string
+	}{
+		{"mem", 0, []string{"memory"}},
+		{"memory li", 7, []string{"list"}},
+		{"memory list --wo", 12, []string{"--workspace"}},
+		{"memory list --workspace op", 24, []string{`"Ops runbooks"`}},
+		{"use \"ops r", 4, []string{`"Ops runbooks"`}},
+		{"open dep", 5, []string{`"Deploy checklist"`}},
+	}
+	for _, tt := range tests {
+		start, got := s.complete(tt.line)
+		if start != tt.start || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("complete(%q) = %d %v, want %d %v", tt.line, start, got, tt.start, tt.want)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maquina/recuerd0-cli/internal/client"
+	"github.com/maquina/recuerd0-cli/internal/errors"
+	"github.com/maquina/recuerd0-cli/internal/response"
+)
+
+// streamOutput receives the per-item records of --stream, overridable for
+// tests.
+var streamOutput io.Writer = os.Stdout
+
+func addStreamFlag(cmd *cobra.Command, stream *bool) {
+	cmd.Flags().BoolVar(stream, "stream", false, "write one NDJSON line per item as pages arrive, then a summary envelope")
+}
+
+// validStream rejects the flags --stream cannot honour: sorting needs every
+// item before the first can be written.
+func validStream(lf *listFilter) error {
+	if lf.sortField != "" || lf.reverse {
+		return errors.NewInvalidArgsError("--stream cannot be combined with --sort or --reverse")
+	}
+	return nil
+}
+
+// streamListing gets path and, without an explicit page, every page after
+// it, writing each item that passes lf's filters to streamOutput as one
+// JSON line while the page is decoded. Items are not kept, so unlike
+// fetchFiltered it is not bounded by maxListPages. The listing's Data holds
+// the first page's other fields, such as the query of a search, and the
+// number of items written.
+func streamListing(apiClient client.API, path string, explicitPage bool, lf *listFilter) (*filteredListing, error) {
+	enc := json.NewEncoder(streamOutput)
+	data := map[string]interface{}{}
+	out := &filteredListing{Data: data}
+	write := func(item interface{}) error {
+		out.Fetched++
+		if m, ok := item.(map[string]interface{}); lf.filters() && (!ok || !lf.match(m)) {
+			return nil
+		}
+		out.Matched++
+		if err := enc.Encode(item); err != nil {
+			return errors.NewError("writing output: " + err.Error())
+		}
+		return nil
+	}
+
+	pages := newPageIterator(func(path string) (*client.APIResponse, error) {
+		return apiClient.GetEach(path, write)
+	}, path, 0)
+	for {
+		resp, err := pages.Next()
+		if err != nil {
+			return nil, err
+		}
+		if resp == nil {
+			break
+		}
+		if pages.Pages == 1 {
+			if rest, ok := resp.Data.(map[string]interface{}); ok {
+				for k, v := range rest {
+					data[k] = v
+				}
+			}
+		}
+		if explicitPage {
+			break
+		}
+	}
+
+	data["streamed"] = out.Matched
+	if _, ok := data["total_results"]; ok && lf.filters() {
+		data["total_results"] = out.Matched
+	}
+	out.Pagination = &response.Pagination{
+		HasNext: pages.NextURL != "",
+		NextURL: pages.NextURL,
+		Pages:   pages.Pages,
+	}
+	if lf.filters() {
+		out.Pagination.Fetched = out.Fetched
+		out.Pagination.Matched = out.Matched
+	}
+	return out, nil
+}
@@ -0,0 +1,135 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/maquina/recuerd0-cli/internal/errors"
+)
+
+// streamLines decodes the NDJSON records written by --stream.
+func streamLines(t *testing.T, out *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var lines []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var m map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			t.Fatalf("expected one JSON object per line, got %q: %v", line, err)
+		}
+		lines = append(lines, m)
+	}
+	out.Reset()
+	return lines
+}
+
+func TestStream(t *testing.T) {
+	mock := NewMockClient()
+	mock.WithGetPathData("/workspaces/5/memories", []interface{}{
+		map[string]interface{}{"id": float64(1), "title": "One", "tags": []interface{}{"a"}},
+		map[string]interface{}{"id": float64(2), "title": "Two"},
+	})
+	mock.GetResponses["/workspaces/5/memories"].LinkNext = "/workspaces/5/memories?page=2"
+	mock.WithGetPathData("/workspaces/5/memories?page=2", []interface{}{
+		map[string]interface{}{"id": float64(3), "title": "Three", "tags": []interface{}{"a"}},
+	})
+	mock.WithGetPathData("/search?q=notes", map[string]interface{}{
+		"query": "notes", "total_results": float64(1),
+		"results": []interface{}{map[string]interface{}{"id": float64(4), "title": "Notes"}},
+	})
+	result := SetTestMode(mock)
+	SetTestConfigFull("tok_test", "https://api.example.com", "5")
+	defer ResetTestMode()
+
+	var out bytes.Buffer
+	origOutput := streamOutput
+	streamOutput = &out
+	defer func() { streamOutput = origOutput }()
+	defer resetFlags(rootCmd)
+
+	t.Run("memory list", func(t *testing.T) {
+		memoryListStream = true
+		defer func() { memoryListStream = false }()
+		RunTestCommand(func() {
+			memoryListCmd.Run(memoryListCmd, nil)
+		})
+		if result.ExitCode != 0 {
+			t.Fatalf("expected success, got %+v", result.Response)
+		}
+		lines := streamLines(t, &out)
+		if len(lines) != 3 || lines[2]["title"] != "Three" {
+			t.Fatalf("expected an item per line across pages, got %v", lines)
+		}
+		data := result.Response.Data.(map[string]interface{})
+		if data["streamed"] != 3 || result.Response.Pagination.Pages != 2 || result.Response.Pagination.HasNext {
+			t.Errorf("unexpected summary %+v %+v", data, result.Response.Pagination)
+		}
+		if result.Response.Summary != "3 memory(ies)" {
+			t.Errorf("unexpected summary %q", result.Response.Summary)
+		}
+	})
+
+	t.Run("filtered page", func(t *testing.T) {
+		memoryListStream, memoryListPage = true, "1"
+		memoryListFilter.Tags = []string{"a"}
+		defer func() {
+			memoryListStream, memoryListPage = false, ""
+			memoryListFilter.Tags = nil
+		}()
+		mock.WithGetPathData("/workspaces/5/memories?page=1", mock.GetResponses["/workspaces/5/memories"].Data)
+		mock.GetResponses["/workspaces/5/memories?page=1"].LinkNext = "/workspaces/5/memories?page=2"
+		RunTestCommand(func() {
+			memoryListCmd.Run(memoryListCmd, nil)
+		})
+		lines := streamLines(t, &out)
+		if len(lines) != 1 || lines[0]["title"] != "One" {
+			t.Fatalf("expected the matching item of one page, got %v", lines)
+		}
+		page := result.Response.Pagination
+		if !page.HasNext || page.Pages != 1 || page.Fetched != 2 || page.Matched != 1 {
+			t.Errorf("unexpected pagination %+v", page)
+		}
+		if result.Response.Summary != "1 of 2 memory(ies) match" {
+			t.Errorf("unexpected summary %q", result.Response.Summary)
+		}
+	})
+
+	t.Run("search", func(t *testing.T) {
+		searchStream = true
+		defer func() { searchStream = false }()
+		RunTestCommand(func() {
+			searchCmd.Run(searchCmd, []string{"notes"})
+		})
+		lines := streamLines(t, &out)
+		if len(lines) != 1 || lines[0]["title"] != "Notes" {
+			t.Fatalf("expected the result lines, got %v", lines)
+		}
+		data := result.Response.Data.(map[string]interface{})
+		if data["query"] != "notes" || data["streamed"] != 1 || data["results"] != nil {
+			t.Errorf("expected the search fields without results, got %v", data)
+		}
+	})
+
+	t.Run("sort refused", func(t *testing.T) {
+		workspaceListStream = true
+		workspaceListFilter.Sort = "name"
+		defer func() {
+			workspaceListStream = false
+			workspaceListFilter.Sort = ""
+		}()
+		calls := len(mock.GetCalls)
+		RunTestCommand(func() {
+			workspaceListCmd.Run(workspaceListCmd, nil)
+		})
+		if result.ExitCode != errors.ExitInvalidArgs || !strings.Contains(result.Response.Error.Message, "--sort") {
+			t.Errorf("expected --sort to be refused, got %+v", result.Response)
+		}
+		if len(mock.GetCalls) != calls || out.Len() != 0 {
+			t.Error("expected nothing to be fetched or written")
+		}
+	})
+}
@@ -0,0 +1,475 @@
+package commands
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maquina/recuerd0-cli/internal/client"
+	"github.com/maquina/recuerd0-cli/internal/errors"
+	"github.com/maquina/recuerd0-cli/internal/response"
+	"github.com/maquina/recuerd0-cli/internal/shellwords"
+)
+
+// defaultConcurrency is how many requests bulk commands keep in flight.
+const defaultConcurrency = 4
+
+// forEachConcurrent calls fn for each index below n, running at most limit
+// calls at once, and returns when all have finished.
+func forEachConcurrent(n, limit int, fn func(i int)) {
+	if limit < 1 {
+		limit = 1
+	}
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(i)
+		}(i)
+	}
+	wg.Wait()
+}
+
+// validConcurrency rejects a --concurrency below one.
+func validConcurrency(n int) error {
+	if n < 1 {
+		return errors.NewInvalidArgsError(fmt.Sprintf("--concurrency must be at least 1, got %d", n))
+	}
+	return nil
+}
+
+// Per-memory outcomes of a bulk tag change.
+const (
+	tagUpdated   = "updated"
+	tagUnchanged = "unchanged"
+	tagFailed    = "failed"
+)
+
+// tagResult is the outcome of a tag change on one memory.
+type tagResult struct {
+	ID     string   `json:"id"`
+	Result string   `json:"result"`
+	Tags   []string `json:"tags,omitempty"`
+	Code   string   `json:"code,omitempty"`
+	Error  string   `json:"error,omitempty"`
+
+	err error
+}
+
+// tagTarget is a memory to retag. Tags are fetched when not already known
+// from a listing.
+type tagTarget struct {
+	ID   string
+	Tags []string
+	Have bool
+}
+
+// memoryTags returns the tags of a memory as strings.
+func memoryTags(m map[string]interface{}) []string {
+	raw, _ := m["tags"].([]interface{})
+	tags := make([]string, 0, len(raw))
+	for _, t := range raw {
+		if s, ok := t.(string); ok {
+			tags = append(tags, s)
+		}
+	}
+	return tags
+}
+
+func sameTags(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// addTags appends the tags in add that are missing from tags, comparing
+// without case so an existing tag keeps its spelling.
+func addTags(tags, add []string) []string {
+	out := append([]string{}, tags...)
+	for _, t := range add {
+		if !hasTag(out, t) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// removeTags drops every tag in remove from tags, in any case.
+func removeTags(tags, remove []string) []string {
+	out := []string{}
+	for _, t := range tags {
+		if !hasTag(remove, t) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// renameTag replaces from, in any case, with to, keeping its position and
+// dropping the duplicate when the memory already has to.
+func renameTag(tags []string, from, to string) []string {
+	out := []string{}
+	for _, t := range tags {
+		if strings.EqualFold(t, from) {
+			t = to
+		}
+		if !hasTag(out, t) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// hasTag reports whether tags holds tag. Tags are compared without case, as
+// mergeTags and the --tag filter do.
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// retag applies edit to the tags of each target in ws and PATCHes the
+// memories whose tags change, running up to concurrency requests at once.
+// Results are in target order.
+func retag(apiClient client.API, ws string, targets []tagTarget, concurrency int, edit func([]string) []string) []tagResult {
+	results := make([]tagResult, len(targets))
+	forEachConcurrent(len(targets), concurrency, func(i int) {
+		target := targets[i]
+		path := fmt.Sprintf("/workspaces/%s/memories/%s", ws, target.ID)
+		r := tagResult{ID: target.ID}
+		defer func() { results[i] = r }()
+
+		current := target.Tags
+		if !target.Have {
+			resp, err := apiClient.Get(path)
+			if err != nil {
+				r.fail(err)
+				return
+			}
+			m, _ := resp.Data.(map[string]interface{})
+			current = memoryTags(m)
+		}
+
+		tags := edit(current)
+		r.Tags = tags
+		if sameTags(current, tags) {
+			r.Result = tagUnchanged
+			return
+		}
+		patch := map[string]interface{}{"memory": map[string]interface{}{"tags": tags}}
+		if _, err := apiClient.Patch(path, patch); err != nil {
+			r.fail(err)
+			return
+		}
+		r.Result = tagUpdated
+	})
+	return results
+}
+
+func (r *tagResult) fail(err error) {
+	r.Result = tagFailed
+	r.Tags = nil
+	r.Error = err.Error()
+	r.err = err
+	if e, ok := err.(*errors.CLIError); ok {
+		r.Code = e.Code
+		r.Error = e.Message
+	}
+}
+
+// tagChange is the data of a bulk tag change.
+type tagChange struct {
+	Workspace string      `json:"workspace"`
+	Updated   int         `json:"updated"`
+	Unchanged int         `json:"unchanged"`
+	Failed    int         `json:"failed"`
+	Results   []tagResult `json:"results"`
+}
+
+// printTagChange prints the per-memory results. When every memory failed,
+// the first error is returned instead; partial failures are listed in the
+// results with a breadcrumb to retry them.
+func printTagChange(ws string, results []tagResult, retry func(ids []string) string) {
+	change := tagChange{Workspace: ws, Results: results}
+	var failed []string
+	var firstErr error
+	for _, r := range results {
+		switch r.Result {
+		case tagUpdated:
+			change.Updated++
+		case tagUnchanged:
+			change.Unchanged++
+		case tagFailed:
+			change.Failed++
+			failed = append(failed, r.ID)
+			if firstErr == nil {
+				firstErr = r.err
+			}
+		}
+	}
+	if len(results) > 0 && change.Failed == len(results) {
+		exitWithError(firstErr)
+		return
+	}
+
+	bc := []response.Breadcrumb{
+		breadcrumb("tags", fmt.Sprintf("recuerd0 tag list --workspace %s", ws), "Show tag usage"),
+	}
+	if len(failed) > 0 {
+		bc = append(bc, breadcrumb("retry", retry(failed), "Retry the memories that failed"))
+	}
+	summary := fmt.Sprintf("%d updated, %d unchanged, %d failed", change.Updated, change.Unchanged, change.Failed)
+	printSuccessWithBreadcrumbs(change, summary, bc)
+}
+
+// uniqueArgs drops repeated IDs, keeping the first occurrence.
+func uniqueArgs(args []string) []string {
+	out := []string{}
+	for _, a := range args {
+		if !slices.Contains(out, a) {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+var memoryTagCmd = &cobra.Command{
+	Use:   "tag",
+	Short: "Add or remove tags without replacing the others",
+	Long: `Add or remove tags on one or more memories. Each memory's current tags are
+read and the merged set is written back, so other tags are kept; memories
+whose tags would not change are left alone.`,
+}
+
+// memory tag add / memory tag remove
+var (
+	memoryTagWorkspace   string
+	memoryTagConcurrency int
+)
+
+func runMemoryTag(verb string, edit func(current, tags []string) []string) func(*cobra.Command, []string) {
+	return func(cmd *cobra.Command, args []string) {
+		if err := requireAuth(); err != nil {
+			exitWithError(err)
+			return
+		}
+		if err := validConcurrency(memoryTagConcurrency); err != nil {
+			exitWithError(err)
+			return
+		}
+		tags := parseTags(args[len(args)-1])
+		if len(tags) == 0 {
+			exitWithError(errors.NewInvalidArgsError("at least one tag is required"))
+			return
+		}
+		ws, err := resolveWorkspace(memoryTagWorkspace)
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+
+		ids := uniqueArgs(args[:len(args)-1])
+		targets := make([]tagTarget, len(ids))
+		for i, id := range ids {
+			targets[i] = tagTarget{ID: id}
+		}
+		results := retag(getClient(), ws, targets, memoryTagConcurrency, func(current []string) []string {
+			return edit(current, tags)
+		})
+		printTagChange(ws, results, func(failed []string) string {
+			args := append([]string{"recuerd0", "memory", "tag", verb, "--workspace", ws}, failed...)
+			return shellwords.Join(append(args, strings.Join(tags, ",")))
+		})
+	}
+}
+
+var memoryTagAddCmd = &cobra.Command{
+	Use:   "add <memory_id...> <tags>",
+	Short: "Add tags to memories",
+	Long: `Add tags to memories, keeping the tags they already have.
+
+<tags> is a comma-separated list, e.g. "design,q3".`,
+	Example:           `  recuerd0 memory tag add --workspace 22 42 43 "design,q3"`,
+	Args:              cobra.MinimumNArgs(2),
+	ValidArgsFunction: completeMemoryIDs,
+	Run:               runMemoryTag("add", addTags),
+}
+
+var memoryTagRemoveCmd = &cobra.Command{
+	Use:   "remove <memory_id...> <tags>",
+	Short: "Remove tags from memories",
+	Long: `Remove tags from memories, keeping their other tags.
+
+<tags> is a comma-separated list, e.g. "draft,wip".`,
+	Args:              cobra.MinimumNArgs(2),
+	ValidArgsFunction: completeMemoryIDs,
+	Run:               runMemoryTag("remove", removeTags),
+}
+
+var tagCmd = &cobra.Command{
+	Use:   "tag",
+	Short: "Manage tags across a workspace",
+}
+
+// workspaceMemories lists every memory in ws.
+func workspaceMemories(apiClient client.API, ws string) ([]map[string]interface{}, error) {
+	items, err := fetchAllPages(apiClient, fmt.Sprintf("/workspaces/%s/memories", ws))
+	if err != nil {
+		return nil, err
+	}
+	memories := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		if m, ok := item.(map[string]interface{}); ok {
+			memories = append(memories, m)
+		}
+	}
+	return memories, nil
+}
+
+// tagCount is one tag list item.
+type tagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// tag list
+var tagListWorkspace string
+
+var tagListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the tags used in a workspace with how many memories have each",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := requireAuth(); err != nil {
+			exitWithError(err)
+			return
+		}
+		ws, err := resolveWorkspace(tagListWorkspace)
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+
+		memories, err := workspaceMemories(getClient(), ws)
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		counts := map[string]int{}
+		for _, m := range memories {
+			for _, t := range uniqueArgs(memoryTags(m)) {
+				counts[t]++
+			}
+		}
+		items := make([]tagCount, 0, len(counts))
+		for t, n := range counts {
+			items = append(items, tagCount{Tag: t, Count: n})
+		}
+		sort.Slice(items, func(i, j int) bool {
+			if items[i].Count != items[j].Count {
+				return items[i].Count > items[j].Count
+			}
+			return items[i].Tag < items[j].Tag
+		})
+
+		bc := []response.Breadcrumb{
+			breadcrumb("memories", fmt.Sprintf("recuerd0 memory list --workspace %s --tag <tag>", ws), "List memories with a tag"),
+			breadcrumb("rename", fmt.Sprintf("recuerd0 tag rename <old> <new> --workspace %s", ws), "Rename a tag"),
+		}
+		printSuccessWithBreadcrumbs(items, fmt.Sprintf("%d tag(s) across %d memory(ies)", len(items), len(memories)), bc)
+	},
+}
+
+// tag rename
+var (
+	tagRenameWorkspace   string
+	tagRenameConcurrency int
+)
+
+var tagRenameCmd = &cobra.Command{
+	Use:   "rename <old> <new>",
+	Short: "Rename a tag on every memory in a workspace",
+	Long: `Rename a tag on every memory in a workspace that has it. A memory that
+already has the new tag keeps a single copy.`,
+	Example:           `  recuerd0 tag rename wip draft --workspace 22`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeTagArg,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := requireAuth(); err != nil {
+			exitWithError(err)
+			return
+		}
+		if err := validConcurrency(tagRenameConcurrency); err != nil {
+			exitWithError(err)
+			return
+		}
+		from, to := strings.TrimSpace(args[0]), strings.TrimSpace(args[1])
+		if from == "" || to == "" || strings.Contains(to, ",") {
+			exitWithError(errors.NewInvalidArgsError("tags must be non-empty and cannot contain commas"))
+			return
+		}
+		if from == to {
+			exitWithError(errors.NewInvalidArgsError("old and new tag are the same"))
+			return
+		}
+		ws, err := resolveWorkspace(tagRenameWorkspace)
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+
+		apiClient := getClient()
+		memories, err := workspaceMemories(apiClient, ws)
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		var targets []tagTarget
+		for _, m := range memories {
+			if tags := memoryTags(m); hasTag(tags, from) {
+				targets = append(targets, tagTarget{ID: stringID(m["id"]), Tags: tags, Have: true})
+			}
+		}
+
+		results := retag(apiClient, ws, targets, tagRenameConcurrency, func(current []string) []string {
+			return renameTag(current, from, to)
+		})
+		printTagChange(ws, results, func([]string) string {
+			return shellwords.Join([]string{"recuerd0", "tag", "rename", from, to, "--workspace", ws})
+		})
+	},
+}
+
+func init() {
+	for _, c := range []*cobra.Command{memoryTagAddCmd, memoryTagRemoveCmd} {
+		c.Flags().StringVar(&memoryTagWorkspace, "workspace", "", "workspace ID, name or alias")
+		c.Flags().IntVar(&memoryTagConcurrency, "concurrency", defaultConcurrency, "maximum requests in flight")
+		memoryTagCmd.AddCommand(c)
+	}
+	memoryCmd.AddCommand(memoryTagCmd)
+
+	rootCmd.AddCommand(tagCmd)
+
+	tagListCmd.Flags().StringVar(&tagListWorkspace, "workspace", "", "workspace ID, name or alias")
+	tagCmd.AddCommand(tagListCmd)
+
+	tagRenameCmd.Flags().StringVar(&tagRenameWorkspace, "workspace", "", "workspace ID, name or alias")
+	tagRenameCmd.Flags().IntVar(&tagRenameConcurrency, "concurrency", defaultConcurrency, "maximum requests in flight")
+	tagCmd.AddCommand(tagRenameCmd)
+}
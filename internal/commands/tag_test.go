@@ -0,0 +1,302 @@
+package commands
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/maquina/recuerd0-cli/internal/client"
+	"github.com/maquina/recuerd0-cli/internal/errors"
+)
+
+func taggedMemory(id int, tags ...interface{}) *client.APIResponse {
+	return &client.APIResponse{StatusCode: 200, Data: map[string]interface{}{"id": float64(id), "tags": tags}}
+}
+
+func patchedTags(t *testing.T, mock *MockClient) map[string][]string {
+	t.Helper()
+	out := map[string][]string{}
+	for _, call := range mock.PatchCalls {
+		memory := call.Body.(map[string]interface{})["memory"].(map[string]interface{})
+		out[call.Path] = memory["tags"].([]string)
+	}
+	return out
+}
+
+func TestTagEdits(t *testing.T) {
+	tests := []struct {
+		name string
+		got  []string
+		want []string
+	}{
+		{"add keeps existing", addTags([]string{"a", "b"}, []string{"b", "c"}), []string{"a", "b", "c"}},
+		{"remove", removeTags([]string{"a", "b", "c"}, []string{"b", "z"}), []string{"a", "c"}},
+		{"rename in place", renameTag([]string{"a", "old", "c"}, "old", "new"), []string{"a", "new", "c"}},
+		{"rename merges", renameTag([]string{"new", "old"}, "old", "new"), []string{"new"}},
+		{"add ignores case", addTags([]string{"design"}, []string{"Design", "Q3"}), []string{"design", "Q3"}},
+		{"remove ignores case", removeTags([]string{"Draft", "ops"}, []string{"draft"}), []string{"ops"}},
+		{"rename ignores case", renameTag([]string{"Old", "c"}, "old", "new"), []string{"new", "c"}},
+		{"rename merges any case", renameTag([]string{"New", "old"}, "old", "new"), []string{"New"}},
+		{"rename fixes case", renameTag([]string{"design", "ops"}, "design", "Design"), []string{"Design", "ops"}},
+	}
+	for _, tt := range tests {
+		if !reflect.DeepEqual(tt.got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestForEachConcurrent_Limit(t *testing.T) {
+	var mu sync.Mutex
+	running, peak := 0, 0
+	seen := make([]bool, 20)
+	forEachConcurrent(len(seen), 3, func(i int) {
+		mu.Lock()
+		running++
+		if running > peak {
+			peak = running
+		}
+		seen[i] = true
+		mu.Unlock()
+		time.Sleep(time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+	})
+	if peak > 3 {
+		t.Errorf("expected at most 3 calls at once, got %d", peak)
+	}
+	for i, ok := range seen {
+		if !ok {
+			t.Errorf("index %d was not visited", i)
+		}
+	}
+}
+
+func TestMemoryTagAdd(t *testing.T) {
+	mock := NewMockClient()
+	mock.GetResponses = map[string]*client.APIResponse{
+		"/workspaces/5/memories/1": taggedMemory(1, "ops"),
+		"/workspaces/5/memories/2": taggedMemory(2, "ops", "design"),
+	}
+	result := SetTestMode(mock)
+	SetTestConfigFull("tok_test", "https://api.example.com", "5")
+	defer ResetTestMode()
+
+	RunTestCommand(func() {
+		memoryTagAddCmd.Run(memoryTagAddCmd, []string{"1", "2", "1", "design, q3"})
+	})
+
+	if result.ExitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", result.ExitCode)
+	}
+	want := map[string][]string{
+		"/workspaces/5/memories/1": {"ops", "design", "q3"},
+		"/workspaces/5/memories/2": {"ops", "design", "q3"},
+	}
+	if got := patchedTags(t, mock); !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected patches: %v", got)
+	}
+	change := result.Response.Data.(tagChange)
+	if change.Updated != 2 || len(change.Results) != 2 || change.Results[0].ID != "1" || change.Results[1].ID != "2" {
+		t.Errorf("unexpected results: %+v", change)
+	}
+}
+
+func TestMemoryTagRemove_Unchanged(t *testing.T) {
+	mock := NewMockClient()
+	mock.GetResponses = map[string]*client.APIResponse{
+		"/workspaces/5/memories/1": taggedMemory(1, "ops", "draft"),
+		"/workspaces/5/memories/2": taggedMemory(2, "ops"),
+	}
+	result := SetTestMode(mock)
+	SetTestConfigFull("tok_test", "https://api.example.com", "5")
+	defer ResetTestMode()
+
+	RunTestCommand(func() {
+		memoryTagRemoveCmd.Run(memoryTagRemoveCmd, []string{"1", "2", "draft"})
+	})
+
+	want := map[string][]string{"/workspaces/5/memories/1": {"ops"}}
+	if got := patchedTags(t, mock); !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected patches: %v", got)
+	}
+	change := result.Response.Data.(tagChange)
+	if change.Updated != 1 || change.Unchanged != 1 || change.Results[1].Result != tagUnchanged {
+		t.Errorf("unexpected results: %+v", change)
+	}
+}
+
+func TestMemoryTag_MixedCase(t *testing.T) {
+	mock := NewMockClient()
+	mock.GetResponses = map[string]*client.APIResponse{
+		"/workspaces/5/memories/1": taggedMemory(1, "design", "Draft"),
+	}
+	result := SetTestMode(mock)
+	SetTestConfigFull("tok_test", "https://api.example.com", "5")
+	defer ResetTestMode()
+
+	RunTestCommand(func() {
+		memoryTagAddCmd.Run(memoryTagAddCmd, []string{"1", "Design"})
+	})
+	if len(mock.PatchCalls) != 0 || result.Response.Data.(tagChange).Unchanged != 1 {
+		t.Fatalf("expected Design to match design, got %v", patchedTags(t, mock))
+	}
+
+	RunTestCommand(func() {
+		memoryTagRemoveCmd.Run(memoryTagRemoveCmd, []string{"1", "draft"})
+	})
+	want := map[string][]string{"/workspaces/5/memories/1": {"design"}}
+	if got := patchedTags(t, mock); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected draft to remove Draft, got %v", got)
+	}
+}
+
+func TestMemoryTag_Failures(t *testing.T) {
+	t.Run("partial", func(t *testing.T) {
+		mock := NewMockClient()
+		mock.GetResponses = map[string]*client.APIResponse{"/workspaces/5/memories/1": taggedMemory(1)}
+		result := SetTestMode(&missingMemoryClient{MockClient: mock, missing: "/workspaces/5/memories/2"})
+		SetTestConfigFull("tok_test", "https://api.example.com", "5")
+		defer ResetTestMode()
+
+		RunTestCommand(func() {
+			memoryTagAddCmd.Run(memoryTagAddCmd, []string{"1", "2", "ops"})
+		})
+		if result.ExitCode != 0 {
+			t.Fatalf("expected exit code 0 on partial failure, got %d", result.ExitCode)
+		}
+		change := result.Response.Data.(tagChange)
+		if change.Updated != 1 || change.Failed != 1 || change.Results[1].Code != "NOT_FOUND" {
+			t.Errorf("unexpected results: %+v", change)
+		}
+		var retry string
+		for _, b := range result.Response.Breadcrumbs {
+			if b.Action == "retry" {
+				retry = b.Cmd
+			}
+		}
+		if retry != "recuerd0 memory tag add --workspace 5 2 ops" {
+			t.Errorf("unexpected retry breadcrumb %q", retry)
+		}
+	})
+	t.Run("all", func(t *testing.T) {
+		mock := NewMockClient()
+		mock.GetError = errors.NewNotFoundError("memory not found")
+		result := SetTestMode(mock)
+		SetTestConfigFull("tok_test", "https://api.example.com", "5")
+		defer ResetTestMode()
+
+		RunTestCommand(func() {
+			memoryTagAddCmd.Run(memoryTagAddCmd, []string{"1", "ops"})
+		})
+		if result.ExitCode != errors.ExitNotFound {
+			t.Errorf("expected exit code %d, got %d", errors.ExitNotFound, result.ExitCode)
+		}
+	})
+}
+
+// missingMemoryClient answers not found for one path.
+type missingMemoryClient struct {
+	*MockClient
+	missing string
+}
+
+func (c *missingMemoryClient) Get(path string) (*client.APIResponse, error) {
+	if path == c.missing {
+		return nil, errors.NewNotFoundError("memory not found")
+	}
+	return c.MockClient.Get(path)
+}
+
+func TestMemoryTag_InvalidConcurrency(t *testing.T) {
+	result := SetTestMode(NewMockClient())
+	SetTestConfigFull("tok_test", "https://api.example.com", "5")
+	defer ResetTestMode()
+	memoryTagConcurrency = 0
+	defer func() { memoryTagConcurrency = defaultConcurrency }()
+
+	RunTestCommand(func() {
+		memoryTagAddCmd.Run(memoryTagAddCmd, []string{"1", "ops"})
+	})
+	if result.ExitCode != errors.ExitInvalidArgs {
+		t.Errorf("expected exit code %d, got %d", errors.ExitInvalidArgs, result.ExitCode)
+	}
+}
+
+func taggedListing() *MockClient {
+	return NewMockClient().WithGetData([]interface{}{
+		map[string]interface{}{"id": float64(1), "tags": []interface{}{"design", "q3"}},
+		map[string]interface{}{"id": float64(2), "tags": []interface{}{"design"}},
+		map[string]interface{}{"id": float64(3), "tags": []interface{}{"design-doc", "ops"}},
+		map[string]interface{}{"id": float64(4)},
+	})
+}
+
+func TestTagList(t *testing.T) {
+	mock := taggedListing()
+	result := SetTestMode(mock)
+	SetTestConfigFull("tok_test", "https://api.example.com", "5")
+	defer ResetTestMode()
+
+	RunTestCommand(func() {
+		tagListCmd.Run(tagListCmd, []string{})
+	})
+
+	want := []tagCount{{"design", 2}, {"design-doc", 1}, {"ops", 1}, {"q3", 1}}
+	if got := result.Response.Data.([]tagCount); !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected counts: %v", got)
+	}
+	if mock.GetCalls[0].Path != "/workspaces/5/memories" {
+		t.Errorf("unexpected path %s", mock.GetCalls[0].Path)
+	}
+}
+
+func TestTagRename(t *testing.T) {
+	mock := taggedListing()
+	result := SetTestMode(mock)
+	SetTestConfigFull("tok_test", "https://api.example.com", "5")
+	defer ResetTestMode()
+
+	RunTestCommand(func() {
+		tagRenameCmd.Run(tagRenameCmd, []string{"design", "architecture"})
+	})
+
+	if result.ExitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", result.ExitCode)
+	}
+	want := map[string][]string{
+		"/workspaces/5/memories/1": {"architecture", "q3"},
+		"/workspaces/5/memories/2": {"architecture"},
+	}
+	if got := patchedTags(t, mock); !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected patches: %v", got)
+	}
+	if len(mock.GetCalls) != 1 {
+		t.Errorf("expected tags to come from the listing, got %d GETs", len(mock.GetCalls))
+	}
+	var ids []string
+	for _, r := range result.Response.Data.(tagChange).Results {
+		ids = append(ids, r.ID)
+	}
+	sort.Strings(ids)
+	if !reflect.DeepEqual(ids, []string{"1", "2"}) {
+		t.Errorf("unexpected results for %v", ids)
+	}
+}
+
+func TestTagRename_SameTag(t *testing.T) {
+	mock := taggedListing()
+	result := SetTestMode(mock)
+	SetTestConfigFull("tok_test", "https://api.example.com", "5")
+	defer ResetTestMode()
+
+	RunTestCommand(func() {
+		tagRenameCmd.Run(tagRenameCmd, []string{"design", "design"})
+	})
+	if result.ExitCode != errors.ExitInvalidArgs || len(mock.GetCalls) != 0 {
+		t.Errorf("expected INVALID_ARGS without API calls, got exit %d", result.ExitCode)
+	}
+}
@@ -0,0 +1,176 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maquina/recuerd0-cli/internal/errors"
+	"github.com/maquina/recuerd0-cli/internal/response"
+	"github.com/maquina/recuerd0-cli/internal/trash"
+)
+
+var trashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "List and restore deleted memories",
+	Long: `memory delete saves a snapshot of each memory, metadata and content, to a
+local trash directory before deleting it. Restoring creates a new memory
+from the snapshot; the server assigns it a new ID and version history
+starts again.`,
+}
+
+// trashSummary is one trash list item: the snapshot without its content.
+type trashSummary struct {
+	ID          string      `json:"id"`
+	MemoryID    string      `json:"memory_id"`
+	WorkspaceID string      `json:"workspace_id"`
+	Title       string      `json:"title"`
+	Tags        interface{} `json:"tags,omitempty"`
+	DeletedAt   string      `json:"deleted_at"`
+}
+
+// trashEntries returns the entries deleted from the current API.
+func trashEntries() ([]*trash.Entry, error) {
+	all, err := trash.List()
+	if err != nil {
+		return nil, errors.NewError(err.Error())
+	}
+	entries := []*trash.Entry{}
+	for _, e := range all {
+		if e.APIURL == cfg.APIURL {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+// trash list
+var trashListWorkspace string
+
+var trashListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List deleted memories, most recent first",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := requireAuth(); err != nil {
+			exitWithError(err)
+			return
+		}
+		ws := ""
+		if trashListWorkspace != "" {
+			var err error
+			if ws, err = lookupWorkspaceID(trashListWorkspace); err != nil {
+				exitWithError(err)
+				return
+			}
+		}
+
+		entries, err := trashEntries()
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		items := []trashSummary{}
+		for _, e := range entries {
+			if ws != "" && e.WorkspaceID != ws {
+				continue
+			}
+			title, _ := e.Memory["title"].(string)
+			items = append(items, trashSummary{
+				ID:          e.ID,
+				MemoryID:    e.MemoryID,
+				WorkspaceID: e.WorkspaceID,
+				Title:       title,
+				Tags:        e.Memory["tags"],
+				DeletedAt:   e.DeletedAt.Format(time.RFC3339),
+			})
+		}
+
+		bc := []response.Breadcrumb{
+			breadcrumb("restore", "recuerd0 trash restore <trash_id>", "Recreate a deleted memory"),
+		}
+		printSuccessWithBreadcrumbs(items, fmt.Sprintf("%d deleted memory(ies)", len(items)), bc)
+	},
+}
+
+// trash restore
+var trashRestoreWorkspace string
+
+var trashRestoreCmd = &cobra.Command{
+	Use:   "restore <id>",
+	Short: "Recreate a deleted memory from the trash",
+	Long: `Recreate a deleted memory from its snapshot. <id> is a trash ID from
+trash list, or the deleted memory's ID to restore its most recent snapshot.
+The memory is recreated in its original workspace unless --workspace is
+given. Encrypted content is restored as is.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := requireAuth(); err != nil {
+			exitWithError(err)
+			return
+		}
+
+		entries, err := trashEntries()
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		var entry *trash.Entry
+		for _, e := range entries {
+			if e.ID == args[0] || e.MemoryID == args[0] {
+				entry = e
+				break
+			}
+		}
+		if entry == nil {
+			exitWithError(errors.NewNotFoundError(fmt.Sprintf("no deleted memory %q in the trash", args[0])))
+			return
+		}
+
+		ws := entry.WorkspaceID
+		if trashRestoreWorkspace != "" {
+			if ws, err = lookupWorkspaceID(trashRestoreWorkspace); err != nil {
+				exitWithError(err)
+				return
+			}
+		}
+
+		memory := map[string]interface{}{}
+		for _, field := range []string{"title", "source", "tags"} {
+			if v, ok := entry.Memory[field]; ok && v != nil {
+				memory[field] = v
+			}
+		}
+		if body := memoryBody(entry.Memory); body != "" {
+			memory["content"] = body
+		}
+
+		apiClient := getClient()
+		resp, err := apiClient.Post(fmt.Sprintf("/workspaces/%s/memories", ws), map[string]interface{}{"memory": memory})
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		if !skipLocalWrite("remove", fmt.Sprintf("trash entry %s", entry.ID), trash.Path(entry.ID)) {
+			if err := trash.Remove(entry.ID); err != nil {
+				setMeta("trash_warning", err.Error())
+			}
+		}
+
+		bc := []response.Breadcrumb{
+			breadcrumb("show", fmt.Sprintf("recuerd0 memory show --workspace %s %s", ws, responseID(resp.Data, "<memory_id>")), "View restored memory"),
+			breadcrumb("list", fmt.Sprintf("recuerd0 memory list --workspace %s", ws), "List memories"),
+		}
+		printSuccessWithBreadcrumbs(resp.Data, fmt.Sprintf("Memory %s restored from trash", entry.MemoryID), bc)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(trashCmd)
+
+	trashListCmd.Flags().StringVar(&trashListWorkspace, "workspace", "", "only show memories deleted from this workspace")
+	trashCmd.AddCommand(trashListCmd)
+
+	trashRestoreCmd.Flags().StringVar(&trashRestoreWorkspace, "workspace", "", "workspace to restore into (default: the original)")
+	trashCmd.AddCommand(trashRestoreCmd)
+}
@@ -0,0 +1,160 @@
+package commands
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/maquina/recuerd0-cli/internal/client"
+	"github.com/maquina/recuerd0-cli/internal/errors"
+	"github.com/maquina/recuerd0-cli/internal/trash"
+)
+
+func deletableMemory() *MockClient {
+	mock := NewMockClient()
+	mock.GetResponse = &client.APIResponse{StatusCode: 200, Data: map[string]interface{}{
+		"id":      42,
+		"title":   "Runbook",
+		"source":  "manual",
+		"tags":    []interface{}{"ops"},
+		"content": map[string]interface{}{"body": "# Runbook\n\nRestart the workers."},
+	}}
+	return mock
+}
+
+func runDelete(t *testing.T, mock *MockClient, yes, tty bool, answer string) *CommandResult {
+	t.Helper()
+	result := SetTestMode(mock)
+	SetTestConfigFull("tok_test", "https://api.example.com", "5")
+	t.Cleanup(ResetTestMode)
+
+	memoryDeleteYes = yes
+	origTerminal, origReader, origWriter := stdinIsTerminal, stdinReader, promptWriter
+	stdinIsTerminal = func() bool { return tty }
+	stdinReader = func() io.Reader { return strings.NewReader(answer) }
+	promptWriter = io.Discard
+	t.Cleanup(func() {
+		memoryDeleteYes = false
+		stdinIsTerminal, stdinReader, promptWriter = origTerminal, origReader, origWriter
+	})
+
+	RunTestCommand(func() {
+		memoryDeleteCmd.Run(memoryDeleteCmd, []string{"42"})
+	})
+	return result
+}
+
+func TestMemoryDelete_SnapshotsToTrash(t *testing.T) {
+	mock := deletableMemory()
+	result := runDelete(t, mock, true, false, "")
+
+	if result.ExitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", result.ExitCode)
+	}
+	entries, _ := trash.List()
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 trash entry, got %d", len(entries))
+	}
+	e := entries[0]
+	if e.MemoryID != "42" || e.WorkspaceID != "5" || e.APIURL != "https://api.example.com" || e.Memory["title"] != "Runbook" {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+	if data := result.Response.Data.(map[string]string); data["trash_id"] != e.ID {
+		t.Errorf("expected trash_id %s, got %v", e.ID, data)
+	}
+}
+
+func TestMemoryDelete_RequiresYesWhenNotInteractive(t *testing.T) {
+	mock := deletableMemory()
+	result := runDelete(t, mock, false, false, "")
+
+	if result.ExitCode != errors.ExitInvalidArgs {
+		t.Fatalf("expected exit code %d, got %d", errors.ExitInvalidArgs, result.ExitCode)
+	}
+	if len(mock.GetCalls) != 0 || len(mock.DeleteCalls) != 0 {
+		t.Error("expected no API calls without --yes")
+	}
+}
+
+func TestMemoryDelete_Prompt(t *testing.T) {
+	t.Run("confirmed", func(t *testing.T) {
+		mock := deletableMemory()
+		result := runDelete(t, mock, false, true, "y\n")
+		if result.ExitCode != 0 || len(mock.DeleteCalls) != 1 {
+			t.Fatalf("expected confirmed delete, got exit %d and %d calls", result.ExitCode, len(mock.DeleteCalls))
+		}
+	})
+	t.Run("declined", func(t *testing.T) {
+		mock := deletableMemory()
+		result := runDelete(t, mock, false, true, "\n")
+		if result.ExitCode != errors.ExitInvalidArgs || len(mock.DeleteCalls) != 0 {
+			t.Errorf("expected declined delete, got exit %d and %d calls", result.ExitCode, len(mock.DeleteCalls))
+		}
+		if entries, _ := trash.List(); len(entries) != 0 {
+			t.Error("expected no snapshot when deletion is declined")
+		}
+	})
+}
+
+func TestMemoryDelete_ServerRejectsKeepsNoSnapshot(t *testing.T) {
+	mock := deletableMemory()
+	mock.DeleteError = errors.NewForbiddenError("read-only token")
+	mock.DeleteError.(*errors.CLIError).Status = 403
+	result := runDelete(t, mock, true, false, "")
+
+	if result.ExitCode != errors.ExitForbidden {
+		t.Fatalf("expected exit code %d, got %d", errors.ExitForbidden, result.ExitCode)
+	}
+	if entries, _ := trash.List(); len(entries) != 0 {
+		t.Error("expected the snapshot to be dropped when the server refused the delete")
+	}
+}
+
+func TestTrashListAndRestore(t *testing.T) {
+	mock := NewMockClient()
+	mock.PostResponse = &client.APIResponse{StatusCode: 201, Data: map[string]interface{}{"id": 99}}
+	result := SetTestMode(mock)
+	SetTestConfigFull("tok_test", "https://api.example.com", "5")
+	defer ResetTestMode()
+
+	_ = trash.Put(&trash.Entry{MemoryID: "42", WorkspaceID: "5", APIURL: "https://api.example.com", Memory: map[string]interface{}{
+		"id": 42, "title": "Runbook", "tags": []interface{}{"ops"}, "source": "manual",
+		"content": map[string]interface{}{"body": "Restart the workers."},
+	}})
+	_ = trash.Put(&trash.Entry{MemoryID: "43", WorkspaceID: "5", APIURL: "https://other.example.com", Memory: map[string]interface{}{"title": "Elsewhere"}})
+
+	RunTestCommand(func() {
+		trashListCmd.Run(trashListCmd, []string{})
+	})
+	items := result.Response.Data.([]trashSummary)
+	if len(items) != 1 || items[0].MemoryID != "42" || items[0].Title != "Runbook" {
+		t.Fatalf("unexpected trash list: %+v", items)
+	}
+
+	RunTestCommand(func() {
+		trashRestoreCmd.Run(trashRestoreCmd, []string{"42"})
+	})
+	if result.ExitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", result.ExitCode)
+	}
+	if mock.PostCalls[0].Path != "/workspaces/5/memories" {
+		t.Errorf("unexpected path %s", mock.PostCalls[0].Path)
+	}
+	memory := mock.PostCalls[0].Body.(map[string]interface{})["memory"].(map[string]interface{})
+	if memory["title"] != "Runbook" || memory["content"] != "Restart the workers." || memory["source"] != "manual" {
+		t.Errorf("unexpected restore body: %v", memory)
+	}
+	if _, ok := memory["id"]; ok {
+		t.Error("expected the old ID not to be sent")
+	}
+	if entries, _ := trash.List(); len(entries) != 1 || entries[0].MemoryID != "43" {
+		t.Errorf("expected the restored entry to leave the trash, got %+v", entries)
+	}
+
+	RunTestCommand(func() {
+		trashRestoreCmd.Run(trashRestoreCmd, []string{"43"})
+	})
+	if result.ExitCode != errors.ExitNotFound {
+		t.Errorf("expected entries from another API to be ignored, got exit code %d", result.ExitCode)
+	}
+}
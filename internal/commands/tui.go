@@ -0,0 +1,850 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+
+	"github.com/maquina/recuerd0-cli/internal/catalog"
+	"github.com/maquina/recuerd0-cli/internal/client"
+	"github.com/maquina/recuerd0-cli/internal/crypt"
+	"github.com/maquina/recuerd0-cli/internal/errors"
+	"github.com/maquina/recuerd0-cli/internal/term"
+	"github.com/maquina/recuerd0-cli/internal/tui"
+)
+
+// tuiKeys is the key reference shown by ? inside the interface.
+const tuiKeys = `Tab, ←/→      switch pane
+↑/↓, j/k      move; scroll in the preview
+PgUp/PgDn     scroll the preview
+Enter         open workspace / focus preview
+/             filter the list as you type
+s             search the workspace as you type
+Esc           clear the filter, leave search or versions
+e             edit the memory in $EDITOR
+t             set the memory's tags
+d             delete the memory (kept in the local trash)
+v             list the memory's versions
+a             archive or unarchive the selected workspace
+r             reload
+q             quit`
+
+var tuiWorkspace string
+
+var tuiCmd = &cobra.Command{
+	Use:   "tui",
+	Short: "Browse and edit memories in a full-screen terminal interface",
+	Long: `Browse workspaces and memories with the keyboard: a workspace pane, a
+memory list with incremental filtering and a rendered Markdown preview.
+Typing after s searches the workspace as you type.
+
+Keys:
+` + tuiKeys,
+	Annotations: map[string]string{catalog.Interactive: "true"},
+	Args:        cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := requireAuth(); err != nil {
+			exitWithError(err)
+			return
+		}
+		fd := int(os.Stdin.Fd())
+		if !term.IsTerminal(fd) || !term.IsTerminal(int(os.Stdout.Fd())) {
+			exitWithError(errors.NewInvalidArgsError("tui needs an interactive terminal; use memory list and memory show instead"))
+			return
+		}
+		ws := cfg.Workspace
+		if tuiWorkspace != "" {
+			id, err := lookupWorkspaceID(tuiWorkspace)
+			if err != nil {
+				exitWithError(err)
+				return
+			}
+			ws = id
+		}
+
+		a := newTUIApp(getClient())
+		if err := a.run(fd, ws); err != nil {
+			exitWithError(err)
+		}
+	},
+}
+
+type tuiPane int
+
+const (
+	tuiWorkspaces tuiPane = iota
+	tuiMemories
+	tuiPreview
+)
+
+type tuiMode int
+
+const (
+	tuiBrowse tuiMode = iota
+	tuiFilter
+	tuiSearch
+	tuiPrompt
+	tuiConfirm
+)
+
+// tuiList is what the memory pane holds.
+type tuiList int
+
+const (
+	tuiListMemories tuiList = iota
+	tuiListSearch
+	tuiListVersions
+)
+
+// tuiDetail is a fetched memory.
+type tuiDetail struct {
+	// memory is the API response, saved to the trash on delete.
+	memory map[string]interface{}
+	// body is the content, decrypted when a local key opens it.
+	body string
+	// locked is set when the content is encrypted and no key opens it.
+	locked string
+}
+
+// tuiApp is the state of the terminal interface. Key presses go through
+// handle and settle; view draws it. Neither touches the terminal, so the
+// whole interface runs against a MockClient in tests.
+type tuiApp struct {
+	api           client.API
+	width, height int
+	focus         tuiPane
+	mode          tuiMode
+	help          bool
+	quit          bool
+
+	workspaces []map[string]interface{}
+	wsCursor   int
+	// ws is the workspace whose memories are listed.
+	ws string
+
+	list    tuiList
+	items   []map[string]interface{}
+	heading string
+	filter  string
+	query   string
+	// shown indexes the items that pass the filter.
+	shown  []int
+	cursor int
+
+	details map[string]*tuiDetail
+	scroll  int
+
+	input   string
+	prompt  string
+	submit  func(string)
+	confirm func()
+	status  string
+
+	searchDue bool
+
+	// editText opens text in an editor and returns the edited text.
+	editText func(string) (string, error)
+}
+
+func newTUIApp(api client.API) *tuiApp {
+	return &tuiApp{
+		api:      api,
+		width:    term.DefaultWidth,
+		height:   24,
+		details:  map[string]*tuiDetail{},
+		editText: editInEditor,
+	}
+}
+
+// run takes over the terminal on fd until the user quits. Keys are read
+// one at a time; slow work (search, previews) waits until no more input
+// is buffered, so a burst of typing costs one request.
+func (a *tuiApp) run(fd int, ws string) error {
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return errors.NewError(fmt.Sprintf("switching the terminal to raw mode: %v", err))
+	}
+	defer func() { _ = term.Restore(fd, state) }()
+	screen := tui.NewScreen(os.Stdout)
+	defer func() { screen.Close() }()
+
+	edit := a.editText
+	a.editText = func(text string) (string, error) {
+		screen.Close()
+		_ = term.Restore(fd, state)
+		defer func() {
+			_, _ = term.MakeRaw(fd)
+			screen = tui.NewScreen(os.Stdout)
+		}()
+		return edit(text)
+	}
+
+	a.resize(term.Size(fd))
+	a.start(ws)
+	in := bufio.NewReader(os.Stdin)
+	for !a.quit {
+		a.resize(term.Size(fd))
+		screen.Draw(a.view())
+		k, err := tui.ReadKey(in)
+		if err != nil {
+			return nil
+		}
+		a.handle(k)
+		if in.Buffered() == 0 {
+			a.settle()
+		}
+	}
+	return nil
+}
+
+func (a *tuiApp) resize(w, h int) {
+	if w > 0 && h > 0 {
+		a.width, a.height = w, h
+	}
+}
+
+// start loads the workspaces and opens ws, or the first workspace.
+func (a *tuiApp) start(ws string) {
+	items, err := fetchAllPages(a.api, "/workspaces")
+	if err != nil {
+		a.fail(err)
+		return
+	}
+	a.workspaces = toMaps(items)
+	if len(a.workspaces) == 0 {
+		a.status = "No workspaces yet; create one with recuerd0 workspace create"
+		return
+	}
+	for i, w := range a.workspaces {
+		if stringID(w["id"]) == ws {
+			a.wsCursor = i
+		}
+	}
+	a.openWorkspace()
+	if ws == "" {
+		a.focus = tuiWorkspaces
+	}
+	a.settle()
+}
+
+func toMaps(items []interface{}) []map[string]interface{} {
+	out := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		if m, ok := item.(map[string]interface{}); ok {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// handle applies one key press.
+func (a *tuiApp) handle(k tui.Key) {
+	if k == tui.Ctrl('c') {
+		a.quit = true
+		return
+	}
+	switch a.mode {
+	case tuiFilter, tuiSearch, tuiPrompt:
+		a.handleInput(k)
+		return
+	case tuiConfirm:
+		a.mode = tuiBrowse
+		if k == tui.Rune('y') || k == tui.Rune('Y') {
+			a.confirm()
+		} else {
+			a.status = "Cancelled"
+		}
+		return
+	}
+
+	a.status = ""
+	if a.help {
+		a.help = false
+		return
+	}
+	page := max(a.height-4, 1)
+	switch {
+	case k == tui.Rune('q'):
+		a.quit = true
+	case k.Code == tui.KeyTab:
+		a.focus = (a.focus + 1) % 3
+	case k.Code == tui.KeyBackTab:
+		a.focus = (a.focus + 2) % 3
+	case k.Code == tui.KeyLeft, k == tui.Rune('h'):
+		if a.focus > tuiWorkspaces {
+			a.focus--
+		}
+	case k.Code == tui.KeyRight, k == tui.Rune('l'):
+		if a.focus == tuiWorkspaces {
+			a.openWorkspace()
+		} else if a.focus == tuiMemories {
+			a.focus = tuiPreview
+		}
+	case k.Code == tui.KeyDown, k == tui.Rune('j'):
+		a.move(1)
+	case k.Code == tui.KeyUp, k == tui.Rune('k'):
+		a.move(-1)
+	case k.Code == tui.KeyHome, k == tui.Rune('g'):
+		a.jump(false)
+	case k.Code == tui.KeyEnd, k == tui.Rune('G'):
+		a.jump(true)
+	case k.Code == tui.KeyPageDown, k == tui.Ctrl('d'), k == tui.Rune(' '):
+		a.scroll += page
+	case k.Code == tui.KeyPageUp, k == tui.Ctrl('u'):
+		a.scroll = max(a.scroll-page, 0)
+	case k.Code == tui.KeyEnter:
+		if a.focus == tuiWorkspaces {
+			a.openWorkspace()
+		} else {
+			a.focus = tuiPreview
+		}
+	case k.Code == tui.KeyEscape:
+		if a.list != tuiListMemories {
+			a.loadMemories()
+		} else if a.filter != "" {
+			a.setFilter("")
+		}
+	case k == tui.Rune('/'):
+		a.startInput(tuiFilter, "Filter: ", a.filter)
+	case k == tui.Rune('s'):
+		if a.ws == "" {
+			a.status = "Open a workspace to search it"
+			return
+		}
+		a.startInput(tuiSearch, "Search: ", a.query)
+	case k == tui.Rune('r'):
+		a.reload()
+	case k == tui.Rune('e'):
+		a.edit()
+	case k == tui.Rune('t'):
+		a.tag()
+	case k == tui.Rune('d'):
+		a.delete()
+	case k == tui.Rune('v'):
+		a.versions()
+	case k == tui.Rune('a'):
+		a.archive()
+	case k == tui.Rune('?'):
+		a.help = true
+	}
+}
+
+func (a *tuiApp) startInput(mode tuiMode, prompt, value string) {
+	a.mode, a.prompt, a.input = mode, prompt, value
+}
+
+func (a *tuiApp) handleInput(k tui.Key) {
+	switch k.Code {
+	case tui.KeyRune:
+		a.input += string(k.Rune)
+	case tui.KeyBackspace:
+		if _, size := utf8.DecodeLastRuneInString(a.input); size > 0 {
+			a.input = a.input[:len(a.input)-size]
+		}
+	case tui.KeyCtrl:
+		if k.Rune == 'u' {
+			a.input = ""
+		}
+	case tui.KeyEnter:
+		mode := a.mode
+		a.mode = tuiBrowse
+		switch mode {
+		case tuiSearch:
+			a.settle()
+			a.focus = tuiMemories
+		case tuiPrompt:
+			a.submit(a.input)
+		}
+		return
+	case tui.KeyEscape:
+		mode := a.mode
+		a.mode = tuiBrowse
+		switch mode {
+		case tuiFilter:
+			a.setFilter("")
+		case tuiSearch:
+			a.query, a.searchDue = "", false
+			if a.list == tuiListSearch {
+				a.loadMemories()
+			}
+		}
+		return
+	default:
+		return
+	}
+
+	switch a.mode {
+	case tuiFilter:
+		a.setFilter(a.input)
+	case tuiSearch:
+		a.query, a.searchDue = a.input, true
+	}
+}
+
+// settle does the work deferred while keys were arriving: the pending
+// search and loading the selected memory for the preview.
+func (a *tuiApp) settle() {
+	if a.searchDue {
+		a.searchDue = false
+		a.search()
+	}
+	if m := a.current(); m != nil {
+		if _, err := a.detail(m); err != nil {
+			a.fail(err)
+		}
+	}
+}
+
+func (a *tuiApp) move(delta int) {
+	switch a.focus {
+	case tuiWorkspaces:
+		a.wsCursor = clamp(a.wsCursor+delta, len(a.workspaces))
+	case tuiMemories:
+		a.cursor = clamp(a.cursor+delta, len(a.shown))
+		a.scroll = 0
+	case tuiPreview:
+		a.scroll = max(a.scroll+delta, 0)
+	}
+}
+
+// jump moves to the first or last entry of the focused pane.
+func (a *tuiApp) jump(last bool) {
+	switch a.focus {
+	case tuiWorkspaces:
+		a.wsCursor = 0
+		if last {
+			a.wsCursor = max(len(a.workspaces)-1, 0)
+		}
+	case tuiMemories:
+		a.cursor, a.scroll = 0, 0
+		if last {
+			a.cursor = max(len(a.shown)-1, 0)
+		}
+	case tuiPreview:
+		a.scroll = 0
+		if last {
+			a.scroll = len(a.previewLines(a.previewWidth())) - 1
+		}
+	}
+}
+
+func clamp(i, n int) int {
+	return max(min(i, n-1), 0)
+}
+
+func (a *tuiApp) fail(err error) {
+	a.status = "Error: " + asCLIError(err).Message
+}
+
+func (a *tuiApp) openWorkspace() {
+	if len(a.workspaces) == 0 {
+		return
+	}
+	a.ws = stringID(a.workspaces[a.wsCursor]["id"])
+	a.loadMemories()
+	a.focus = tuiMemories
+}
+
+// loadMemories lists the current workspace, replacing search results or
+// versions.
+func (a *tuiApp) loadMemories() {
+	memories, err := workspaceMemories(a.api, a.ws)
+	if err != nil {
+		a.fail(err)
+		return
+	}
+	a.setItems(tuiListMemories, memories, "")
+	a.query = ""
+}
+
+func (a *tuiApp) setItems(list tuiList, items []map[string]interface{}, heading string) {
+	a.list, a.items, a.heading = list, items, heading
+	a.cursor, a.scroll = 0, 0
+	a.setFilter("")
+}
+
+// setFilter keeps the items whose title or tags contain f, ignoring case.
+func (a *tuiApp) setFilter(f string) {
+	a.filter = f
+	needle := strings.ToLower(strings.TrimSpace(f))
+	a.shown = a.shown[:0]
+	for i, m := range a.items {
+		if needle == "" || strings.Contains(strings.ToLower(tuiTitle(m)+" "+strings.Join(memoryTags(m), " ")), needle) {
+			a.shown = append(a.shown, i)
+		}
+	}
+	a.cursor = clamp(a.cursor, len(a.shown))
+	a.scroll = 0
+}
+
+func (a *tuiApp) reload() {
+	current := a.current()
+	a.details = map[string]*tuiDetail{}
+	if items, err := fetchAllPages(a.api, "/workspaces"); err == nil {
+		a.workspaces = toMaps(items)
+		a.wsCursor = clamp(a.wsCursor, len(a.workspaces))
+	} else {
+		a.fail(err)
+		return
+	}
+	switch {
+	case a.ws == "":
+	case a.list == tuiListSearch:
+		a.searchDue = true
+	case a.list == tuiListVersions && current != nil:
+		a.versionsOf(current)
+	default:
+		a.loadMemories()
+	}
+	a.status = "Reloaded"
+}
+
+// current returns the selected memory, if any.
+func (a *tuiApp) current() map[string]interface{} {
+	if a.cursor >= len(a.shown) {
+		return nil
+	}
+	return a.items[a.shown[a.cursor]]
+}
+
+func tuiTitle(m map[string]interface{}) string {
+	if title, _ := m["title"].(string); title != "" {
+		return title
+	}
+	return "(untitled)"
+}
+
+// memoryWorkspace returns the workspace of a listed memory: search
+// results name theirs, memory listings are of the current one.
+func (a *tuiApp) memoryWorkspace(m map[string]interface{}) string {
+	if w, ok := m["workspace"].(map[string]interface{}); ok && w["id"] != nil {
+		return stringID(w["id"])
+	}
+	return a.ws
+}
+
+func (a *tuiApp) memoryPath(m map[string]interface{}) string {
+	return fmt.Sprintf("/workspaces/%s/memories/%s", a.memoryWorkspace(m), stringID(m["id"]))
+}
+
+// detail fetches a memory with its content, once.
+func (a *tuiApp) detail(m map[string]interface{}) (*tuiDetail, error) {
+	path := a.memoryPath(m)
+	if d, ok := a.details[path]; ok {
+		return d, nil
+	}
+	resp, err := a.api.Get(path)
+	if err != nil {
+		return nil, err
+	}
+	memory, _ := resp.Data.(map[string]interface{})
+	d := &tuiDetail{memory: memory, body: memoryBody(memory)}
+	if crypt.IsEncrypted(d.body) {
+		plaintext, _, err := decryptBody(d.body)
+		if err != nil {
+			d.locked = err.Error()
+		} else {
+			d.body = plaintext
+		}
+	}
+	a.details[path] = d
+	return d, nil
+}
+
+// search runs the query against /search in the current workspace. The
+// last word matches as a prefix so results follow the typing.
+func (a *tuiApp) search() {
+	q := strings.TrimSpace(a.query)
+	if q == "" {
+		if a.list == tuiListSearch {
+			a.loadMemories()
+		}
+		return
+	}
+	if n := utf8.RuneCountInString(q); n < minSearchQueryLen {
+		a.status = fmt.Sprintf("Type at least %d characters to search", minSearchQueryLen)
+		return
+	}
+	query := tuiSearchQuery(q)
+	if err := validateSearchQuery(query); err != nil {
+		a.status = asCLIError(err).Message
+		return
+	}
+	params := url.Values{"q": {query}, "workspace_id": {a.ws}}
+	resp, err := a.api.GetWithPagination("/search?" + params.Encode())
+	if err != nil {
+		a.fail(err)
+		return
+	}
+	a.setItems(tuiListSearch, toMaps(pageItems(resp.Data)), fmt.Sprintf("Search %q", q))
+	a.query = q
+}
+
+// tuiSearchQuery turns typed words into an FTS5 query that requires every
+// word, the last one as a prefix.
+func tuiSearchQuery(q string) string {
+	words := strings.Fields(q)
+	for i, w := range words {
+		words[i] = ftsTerm(w)
+	}
+	if last := words[len(words)-1]; !strings.HasPrefix(last, `"`) {
+		words[len(words)-1] = last + "*"
+	}
+	return strings.Join(words, " ")
+}
+
+// versions lists the selected memory's versions. The API has no version
+// listing, so this searches the memory's title and keeps exact matches,
+// newest version first.
+func (a *tuiApp) versions() {
+	if m := a.current(); m != nil {
+		a.versionsOf(m)
+		a.focus = tuiMemories
+	}
+}
+
+func (a *tuiApp) versionsOf(m map[string]interface{}) {
+	title := tuiTitle(m)
+	ws := a.memoryWorkspace(m)
+	params := url.Values{"q": {"title:" + ftsPhrase(title)}, "workspace_id": {ws}}
+	items, err := fetchAllPages(a.api, "/search?"+params.Encode())
+	if err != nil {
+		a.fail(err)
+		return
+	}
+	var versions []map[string]interface{}
+	for _, v := range toMaps(items) {
+		if v["title"] == m["title"] && a.memoryWorkspace(v) == ws {
+			versions = append(versions, v)
+		}
+	}
+	if len(versions) == 0 {
+		versions = append(versions, m)
+	}
+	sort.SliceStable(versions, func(i, j int) bool {
+		vi, _ := versions[i]["version"].(float64)
+		vj, _ := versions[j]["version"].(float64)
+		return vi > vj
+	})
+	a.setItems(tuiListVersions, versions, fmt.Sprintf("Versions of %q", title))
+}
+
+// edit opens the selected memory's content in $EDITOR and saves it when it
+// changed, with the same secret scan and encryption as memory update.
+func (a *tuiApp) edit() {
+	m := a.current()
+	if m == nil {
+		return
+	}
+	d, err := a.detail(m)
+	if err != nil {
+		a.fail(err)
+		return
+	}
+	if d.locked != "" {
+		a.status = "Cannot edit: the content is encrypted and no local key opens it"
+		return
+	}
+	text, err := a.editText(d.body)
+	if err != nil {
+		a.status = "Editor failed: " + err.Error()
+		return
+	}
+	if text == d.body {
+		a.status = "No changes"
+		return
+	}
+
+	ws := a.memoryWorkspace(m)
+	fields := map[string]interface{}{"content": text}
+	var scanErr error
+	meta := captureMeta(func() { scanErr = scanForSecrets(fields, false) })
+	if scanErr != nil {
+		a.fail(scanErr)
+		return
+	}
+	plaintext, err := encryptFields(ws, fields)
+	if err != nil {
+		a.fail(err)
+		return
+	}
+	resp, err := a.api.Patch(a.memoryPath(m), map[string]interface{}{"memory": fields})
+	if err != nil {
+		a.fail(err)
+		return
+	}
+	if plaintext != "" {
+		indexMemoryResponse(ws, resp.Data, plaintext)
+	}
+	a.updated(m, resp.Data)
+	a.status = "Saved"
+	if report, ok := meta["redaction"].(*redactionReport); ok {
+		a.status += fmt.Sprintf("; %d possible secret(s) %s", len(report.Findings), report.Action)
+	}
+}
+
+// updated refreshes a listed memory from a write's response and drops its
+// cached content.
+func (a *tuiApp) updated(m map[string]interface{}, data interface{}) {
+	delete(a.details, a.memoryPath(m))
+	if fresh, ok := data.(map[string]interface{}); ok {
+		for _, k := range []string{"title", "tags", "version", "source", "updated_at"} {
+			if v, ok := fresh[k]; ok {
+				m[k] = v
+			}
+		}
+	}
+}
+
+// tag asks for the selected memory's tags, comma-separated.
+func (a *tuiApp) tag() {
+	m := a.current()
+	if m == nil {
+		return
+	}
+	a.startInput(tuiPrompt, "Tags: ", strings.Join(memoryTags(m), ", "))
+	a.submit = func(input string) {
+		tags := parseTags(input)
+		target := tagTarget{ID: stringID(m["id"]), Tags: memoryTags(m), Have: true}
+		r := retag(a.api, a.memoryWorkspace(m), []tagTarget{target}, 1, func([]string) []string { return tags })[0]
+		switch r.Result {
+		case tagFailed:
+			a.fail(r.err)
+		case tagUnchanged:
+			a.status = "Tags unchanged"
+		default:
+			a.updated(m, map[string]interface{}{"tags": toInterfaces(r.Tags)})
+			a.status = "Tags saved"
+		}
+	}
+}
+
+func toInterfaces(s []string) []interface{} {
+	out := make([]interface{}, len(s))
+	for i, v := range s {
+		out[i] = v
+	}
+	return out
+}
+
+// delete asks to confirm, then deletes the selected memory after saving
+// it to the local trash.
+func (a *tuiApp) delete() {
+	m := a.current()
+	if m == nil {
+		return
+	}
+	a.mode = tuiConfirm
+	a.prompt = fmt.Sprintf("Delete %q and all its versions? [y/N]", tuiTitle(m))
+	a.confirm = func() {
+		d, err := a.detail(m)
+		if err != nil {
+			a.fail(err)
+			return
+		}
+		entry, err := trashAndDelete(a.api, a.memoryWorkspace(m), stringID(m["id"]), d.memory)
+		if err != nil {
+			a.fail(err)
+			return
+		}
+		delete(a.details, a.memoryPath(m))
+		for i, item := range a.items {
+			if stringID(item["id"]) == stringID(m["id"]) {
+				a.items = append(a.items[:i], a.items[i+1:]...)
+				break
+			}
+		}
+		a.setFilter(a.filter)
+		a.status = "Deleted"
+		if entry != nil {
+			a.status += "; restore with recuerd0 trash restore " + entry.ID
+		}
+	}
+}
+
+// archive toggles the selected workspace's archived state. Memories have
+// no archive of their own.
+func (a *tuiApp) archive() {
+	if a.focus != tuiWorkspaces || len(a.workspaces) == 0 {
+		a.status = "Archive works on workspaces; select one in the workspace pane"
+		return
+	}
+	w := a.workspaces[a.wsCursor]
+	id := stringID(w["id"])
+	var resp *client.APIResponse
+	var err error
+	archived, _ := w["archived"].(bool)
+	if archived {
+		resp, err = a.api.Delete("/workspaces/" + id + "/archive")
+	} else {
+		resp, err = a.api.Post("/workspaces/"+id+"/archive", nil)
+	}
+	if err != nil {
+		a.fail(err)
+		return
+	}
+	invalidateWorkspaceCache()
+	w["archived"] = !archived
+	if fresh, ok := resp.Data.(map[string]interface{}); ok {
+		for k, v := range fresh {
+			w[k] = v
+		}
+	}
+	if archived {
+		a.status = "Workspace unarchived"
+	} else {
+		a.status = "Workspace archived"
+	}
+}
+
+// editInEditor writes text to a temporary Markdown file, opens it in
+// $VISUAL or $EDITOR and returns the saved contents.
+func editInEditor(text string) (string, error) {
+	f, err := os.CreateTemp("", "recuerd0-*.md")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(text); err != nil {
+		f.Close()
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		return "", err
+	}
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+	parts := strings.Fields(editor)
+	cmd := exec.Command(parts[0], append(parts[1:], f.Name())...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", err
+	}
+	out, err := os.ReadFile(f.Name())
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+func init() {
+	tuiCmd.Flags().StringVar(&tuiWorkspace, "workspace", "", "workspace to open (ID, name or alias)")
+	rootCmd.AddCommand(tuiCmd)
+}
@@ -0,0 +1,287 @@
+package commands
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/maquina/recuerd0-cli/internal/term"
+	"github.com/maquina/recuerd0-cli/internal/tui"
+)
+
+func tuiMock() *MockClient {
+	return shellMock().
+		WithGetPathData("/workspaces/5/memories/7", map[string]interface{}{
+			"id": float64(7), "title": "Caching strategy", "version": float64(2), "tags": []interface{}{"redis"},
+			"content": map[string]interface{}{"body": "## Redis\n\nUse **short** TTLs."},
+		}).
+		WithGetPathData(tuiSearchPath("dep*"), map[string]interface{}{
+			"results": []interface{}{
+				map[string]interface{}{"id": float64(8), "title": "Deploy checklist", "workspace": map[string]interface{}{"id": float64(5)}},
+			},
+		}).
+		WithGetPathData(tuiSearchPath(`title:"Caching strategy"`), map[string]interface{}{
+			"results": []interface{}{
+				map[string]interface{}{"id": float64(3), "title": "Caching strategy", "version": float64(1)},
+				map[string]interface{}{"id": float64(7), "title": "Caching strategy", "version": float64(2)},
+				map[string]interface{}{"id": float64(9), "title": "Caching strategy notes", "version": float64(1)},
+			},
+		})
+}
+
+func tuiSearchPath(q string) string {
+	return "/search?" + url.Values{"q": {q}, "workspace_id": {"5"}}.Encode()
+}
+
+// startTUI opens workspace 5 in a 100x20 interface.
+func startTUI(t *testing.T, mock *MockClient) *tuiApp {
+	t.Helper()
+	SetTestMode(mock)
+	SetTestConfigFull("tok_test", "https://api.example.com", "5")
+	t.Cleanup(ResetTestMode)
+
+	a := newTUIApp(mock)
+	a.width, a.height = 100, 20
+	a.editText = func(string) (string, error) {
+		t.Fatal("unexpected editor")
+		return "", nil
+	}
+	a.start("5")
+	return a
+}
+
+func press(a *tuiApp, keys ...tui.Key) {
+	for _, k := range keys {
+		a.handle(k)
+	}
+	a.settle()
+}
+
+func typeText(a *tuiApp, s string) {
+	for _, r := range s {
+		a.handle(tui.Rune(r))
+	}
+	a.settle()
+}
+
+func screen(a *tuiApp) string {
+	return strings.Join(a.view(), "\n")
+}
+
+func TestTUI_BrowseAndPreview(t *testing.T) {
+	a := startTUI(t, tuiMock())
+
+	lines := a.view()
+	if len(lines) != 20 {
+		t.Fatalf("expected 20 rows, got %d", len(lines))
+	}
+	for i, l := range lines {
+		if w := term.StringWidth(l); w != 100 {
+			t.Errorf("row %d is %d columns wide: %q", i, w, l)
+		}
+	}
+	out := screen(a)
+	for _, want := range []string{"Notes", "Ops runbooks", "Memories (2)", "Caching strategy  #redis", "v2 · #redis", "Redis", "TTLs."} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q on screen:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "## Redis") || strings.Contains(out, "**short**") {
+		t.Error("expected the preview to render Markdown")
+	}
+
+	press(a, tui.Rune('j'))
+	if !strings.Contains(screen(a), "1. Tag the release") {
+		t.Errorf("expected moving down to preview memory 8:\n%s", screen(a))
+	}
+	press(a, tui.Rune('q'))
+	if !a.quit {
+		t.Error("expected q to quit")
+	}
+}
+
+func TestTUI_Filter(t *testing.T) {
+	a := startTUI(t, tuiMock())
+
+	press(a, tui.Rune('/'))
+	typeText(a, "OPS")
+	if len(a.shown) != 1 || tuiTitle(a.current()) != "Deploy checklist" {
+		t.Fatalf("expected the tag filter to keep one memory, got %v", a.shown)
+	}
+	if !strings.Contains(screen(a), "Memories 1/2") || !strings.Contains(screen(a), "Filter: OPS▏") {
+		t.Errorf("unexpected screen:\n%s", screen(a))
+	}
+	press(a, tui.Key{Code: tui.KeyEscape})
+	if a.filter != "" || len(a.shown) != 2 {
+		t.Errorf("expected Escape to clear the filter, got %q %v", a.filter, a.shown)
+	}
+}
+
+func TestTUI_SearchAsYouType(t *testing.T) {
+	mock := tuiMock()
+	a := startTUI(t, mock)
+
+	press(a, tui.Rune('s'))
+	for _, r := range "dep" {
+		a.handle(tui.Rune(r))
+	}
+	searches := func() int {
+		n := 0
+		for _, c := range mock.GetCalls {
+			if strings.HasPrefix(c.Path, "/search") {
+				n++
+			}
+		}
+		return n
+	}
+	if searches() != 0 {
+		t.Fatal("expected no search before the input settles")
+	}
+	a.settle()
+	if searches() != 1 || a.list != tuiListSearch || len(a.items) != 1 {
+		t.Fatalf("expected one search with one result, got %d searches and %d items", searches(), len(a.items))
+	}
+	if !strings.Contains(screen(a), `Search "dep" (1)`) {
+		t.Errorf("unexpected screen:\n%s", screen(a))
+	}
+
+	press(a, tui.Key{Code: tui.KeyEnter}, tui.Key{Code: tui.KeyEscape})
+	if a.list != tuiListMemories || len(a.items) != 2 {
+		t.Errorf("expected Escape to return to the memory list, got %d items", len(a.items))
+	}
+
+	press(a, tui.Rune('s'))
+	typeText(a, "de")
+	if !strings.Contains(a.status, "at least 3 characters") {
+		t.Errorf("expected a short query to wait, got status %q", a.status)
+	}
+}
+
+func TestTUI_Edit(t *testing.T) {
+	t.Run("saves changes", func(t *testing.T) {
+		mock := tuiMock().WithPatchData(map[string]interface{}{"id": float64(7), "title": "Caching strategy", "version": float64(2)})
+		a := startTUI(t, mock)
+		a.editText = func(text string) (string, error) {
+			if !strings.HasPrefix(text, "## Redis") {
+				t.Errorf("expected the editor to get the content, got %q", text)
+			}
+			return text + "\nMore.", nil
+		}
+		press(a, tui.Rune('e'))
+
+		if len(mock.PatchCalls) != 1 || mock.PatchCalls[0].Path != "/workspaces/5/memories/7" {
+			t.Fatalf("unexpected patches: %+v", mock.PatchCalls)
+		}
+		sent := mock.PatchCalls[0].Body.(map[string]interface{})["memory"].(map[string]interface{})
+		if sent["content"] != "## Redis\n\nUse **short** TTLs.\nMore." || a.status != "Saved" {
+			t.Errorf("unexpected update %v, status %q", sent, a.status)
+		}
+	})
+	t.Run("unchanged", func(t *testing.T) {
+		mock := tuiMock()
+		a := startTUI(t, mock)
+		a.editText = func(text string) (string, error) { return text, nil }
+		press(a, tui.Rune('e'))
+		if len(mock.PatchCalls) != 0 || a.status != "No changes" {
+			t.Errorf("expected no update, got %d patches and status %q", len(mock.PatchCalls), a.status)
+		}
+	})
+}
+
+func TestTUI_Tag(t *testing.T) {
+	mock := tuiMock()
+	a := startTUI(t, mock)
+
+	press(a, tui.Rune('t'))
+	if a.input != "redis" {
+		t.Errorf("expected the prompt to hold the current tags, got %q", a.input)
+	}
+	press(a, tui.Ctrl('u'))
+	typeText(a, "cache, ops")
+	press(a, tui.Key{Code: tui.KeyEnter})
+
+	if len(mock.PatchCalls) != 1 {
+		t.Fatalf("expected one patch, got %d", len(mock.PatchCalls))
+	}
+	tags := mock.PatchCalls[0].Body.(map[string]interface{})["memory"].(map[string]interface{})["tags"].([]string)
+	if strings.Join(tags, ",") != "cache,ops" || a.status != "Tags saved" {
+		t.Errorf("unexpected tags %v, status %q", tags, a.status)
+	}
+	if !strings.Contains(screen(a), "Caching strategy  #cache #ops") {
+		t.Errorf("expected the list to show the new tags:\n%s", screen(a))
+	}
+}
+
+func TestTUI_Delete(t *testing.T) {
+	mock := tuiMock()
+	a := startTUI(t, mock)
+
+	press(a, tui.Rune('d'))
+	if !strings.Contains(screen(a), `Delete "Caching strategy" and all its versions? [y/N]`) {
+		t.Errorf("expected a confirmation:\n%s", screen(a))
+	}
+	press(a, tui.Rune('n'))
+	if len(mock.DeleteCalls) != 0 {
+		t.Fatal("expected no delete after declining")
+	}
+
+	press(a, tui.Rune('d'), tui.Rune('y'))
+	if len(mock.DeleteCalls) != 1 || mock.DeleteCalls[0].Path != "/workspaces/5/memories/7" {
+		t.Fatalf("unexpected deletes: %+v", mock.DeleteCalls)
+	}
+	if len(a.items) != 1 || !strings.Contains(a.status, "recuerd0 trash restore ") {
+		t.Errorf("expected the memory to be removed with a restore hint, got %d items, status %q", len(a.items), a.status)
+	}
+}
+
+func TestTUI_Versions(t *testing.T) {
+	a := startTUI(t, tuiMock())
+
+	press(a, tui.Rune('v'))
+	if a.list != tuiListVersions || len(a.items) != 2 {
+		t.Fatalf("expected two versions, got %d", len(a.items))
+	}
+	rows := a.memoryRows(30, 10)
+	if a.heading != `Versions of "Caching strategy"` || !strings.Contains(rows[0], "v2") || !strings.Contains(rows[1], "v1") {
+		t.Errorf("expected versions newest first, got %q %q", a.heading, rows)
+	}
+	press(a, tui.Key{Code: tui.KeyEscape})
+	if a.list != tuiListMemories {
+		t.Error("expected Escape to leave the versions")
+	}
+}
+
+func TestTUI_Archive(t *testing.T) {
+	mock := tuiMock()
+	a := startTUI(t, mock)
+
+	press(a, tui.Rune('a'))
+	if len(mock.PostCalls) != 0 || !strings.Contains(a.status, "workspace pane") {
+		t.Errorf("expected archive to need the workspace pane, got status %q", a.status)
+	}
+
+	press(a, tui.Key{Code: tui.KeyLeft}, tui.Rune('a'))
+	if len(mock.PostCalls) != 1 || mock.PostCalls[0].Path != "/workspaces/5/archive" {
+		t.Fatalf("unexpected posts: %+v", mock.PostCalls)
+	}
+	if !strings.Contains(screen(a), "Notes (archived)") {
+		t.Errorf("expected the workspace to show as archived:\n%s", screen(a))
+	}
+	press(a, tui.Rune('a'))
+	if len(mock.DeleteCalls) != 1 || mock.DeleteCalls[0].Path != "/workspaces/5/archive" || a.status != "Workspace unarchived" {
+		t.Errorf("expected a second press to unarchive, got %+v, status %q", mock.DeleteCalls, a.status)
+	}
+}
+
+func TestTUI_NarrowLayout(t *testing.T) {
+	a := startTUI(t, tuiMock())
+	a.width = 40
+	if out := screen(a); strings.Contains(out, "Workspaces") || !strings.Contains(out, "Memories") {
+		t.Errorf("expected only the focused list on a narrow screen:\n%s", out)
+	}
+	for _, l := range a.view() {
+		if term.StringWidth(l) != 40 {
+			t.Errorf("row is %d columns wide: %q", term.StringWidth(l), l)
+		}
+	}
+}
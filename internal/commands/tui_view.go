@@ -0,0 +1,241 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/maquina/recuerd0-cli/internal/markdown"
+	"github.com/maquina/recuerd0-cli/internal/term"
+)
+
+// ANSI styles used by the terminal interface.
+const (
+	tuiBold    = "\x1b[1m"
+	tuiDim     = "\x1b[2m"
+	tuiInverse = "\x1b[7m"
+	tuiReset   = "\x1b[0m"
+)
+
+// view returns the screen as one string per row, each fitted to the width.
+func (a *tuiApp) view() []string {
+	body := max(a.height-3, 1)
+	wsW, memW, prevW := a.columns()
+
+	type column struct {
+		width int
+		title string
+		rows  []string
+		focus bool
+	}
+	var cols []column
+	if wsW > 0 {
+		cols = append(cols, column{wsW, "Workspaces", a.workspaceRows(wsW, body), a.focus == tuiWorkspaces})
+	}
+	if memW > 0 {
+		cols = append(cols, column{memW, a.listTitle(), a.memoryRows(memW, body), a.focus == tuiMemories})
+	}
+	if prevW > 0 {
+		cols = append(cols, column{prevW, "Preview", a.previewRows(prevW, body), a.focus == tuiPreview})
+	}
+
+	lines := []string{tuiInverse + term.Fit(a.titleBar(), a.width) + tuiReset}
+	var header []string
+	for _, c := range cols {
+		style := tuiDim
+		if c.focus {
+			style = tuiBold
+		}
+		header = append(header, style+term.Fit(" "+c.title, c.width)+tuiReset)
+	}
+	lines = append(lines, strings.Join(header, tuiDim+"│"+tuiReset))
+	for r := 0; r < body; r++ {
+		cells := make([]string, len(cols))
+		for i, c := range cols {
+			cell := ""
+			if r < len(c.rows) {
+				cell = c.rows[r]
+			}
+			cells[i] = term.Fit(cell, c.width)
+		}
+		lines = append(lines, strings.Join(cells, tuiDim+"│"+tuiReset))
+	}
+	return append(lines, term.Fit(a.statusBar(), a.width))
+}
+
+// columns returns the pane widths. Narrow terminals show the focused list
+// beside the preview, or only the focused pane.
+func (a *tuiApp) columns() (int, int, int) {
+	w := a.width
+	switch {
+	case w >= 90:
+		ws := min(24, w/5)
+		mem := (w - ws - 2) * 2 / 5
+		return ws, mem, w - ws - mem - 2
+	case w >= 50:
+		list := (w - 1) * 2 / 5
+		if a.focus == tuiWorkspaces {
+			return list, 0, w - list - 1
+		}
+		return 0, list, w - list - 1
+	}
+	switch a.focus {
+	case tuiWorkspaces:
+		return w, 0, 0
+	case tuiMemories:
+		return 0, w, 0
+	}
+	return 0, 0, w
+}
+
+func (a *tuiApp) titleBar() string {
+	title := " recuerd0"
+	if cfg != nil && cfg.Account != "" {
+		title += " · " + cfg.Account
+	}
+	if name := a.workspaceName(a.ws); name != "" {
+		title += " · " + name
+	}
+	return title
+}
+
+func (a *tuiApp) workspaceName(id string) string {
+	for _, w := range a.workspaces {
+		if stringID(w["id"]) == id {
+			return cellText("name", w["name"], 0)
+		}
+	}
+	return ""
+}
+
+func (a *tuiApp) listTitle() string {
+	title := "Memories"
+	if a.heading != "" {
+		title = a.heading
+	}
+	if a.filter != "" {
+		return fmt.Sprintf("%s %d/%d", title, len(a.shown), len(a.items))
+	}
+	return fmt.Sprintf("%s (%d)", title, len(a.items))
+}
+
+// window returns the first row to show so that cursor stays visible.
+func window(cursor, rows int) int {
+	return max(cursor-rows+1, 0)
+}
+
+// row renders one list entry, highlighted when selected.
+func row(text string, width int, selected, focused bool) string {
+	switch {
+	case selected && focused:
+		return tuiInverse + term.Fit(" "+text, width) + tuiReset
+	case selected:
+		return tuiBold + "›" + text + tuiReset
+	}
+	return " " + text
+}
+
+func (a *tuiApp) workspaceRows(width, rows int) []string {
+	var out []string
+	for i := window(a.wsCursor, rows); i < len(a.workspaces) && len(out) < rows; i++ {
+		w := a.workspaces[i]
+		text := cellText("name", w["name"], 0)
+		if archived, _ := w["archived"].(bool); archived {
+			text += " (archived)"
+		}
+		out = append(out, row(text, width, i == a.wsCursor, a.focus == tuiWorkspaces))
+	}
+	return out
+}
+
+func (a *tuiApp) memoryRows(width, rows int) []string {
+	if len(a.shown) == 0 {
+		if a.ws == "" {
+			return []string{tuiDim + " Open a workspace" + tuiReset}
+		}
+		return []string{tuiDim + " (no memories)" + tuiReset}
+	}
+	var out []string
+	for i := window(a.cursor, rows); i < len(a.shown) && len(out) < rows; i++ {
+		m := a.items[a.shown[i]]
+		text := tuiTitle(m)
+		if a.list == tuiListVersions {
+			text = fmt.Sprintf("v%s  %s", stringID(m["version"]), cellText("updated_at", m["updated_at"], 0))
+		} else if tags := memoryTags(m); len(tags) > 0 {
+			text += "  #" + strings.Join(tags, " #")
+		}
+		out = append(out, row(text, width, i == a.cursor, a.focus == tuiMemories))
+	}
+	return out
+}
+
+func (a *tuiApp) previewWidth() int {
+	_, _, w := a.columns()
+	if w == 0 {
+		w = a.width
+	}
+	return w
+}
+
+// previewLines renders the selected memory: its title, a line of details
+// and the Markdown content.
+func (a *tuiApp) previewLines(width int) []string {
+	if a.help {
+		return strings.Split(tuiKeys, "\n")
+	}
+	m := a.current()
+	if m == nil {
+		return nil
+	}
+	d, ok := a.details[a.memoryPath(m)]
+	if !ok {
+		return []string{tuiDim + "Loading…" + tuiReset}
+	}
+
+	lines := []string{tuiBold + tuiTitle(d.memory) + tuiReset}
+	var facts []string
+	if v := stringID(d.memory["version"]); v != "" {
+		facts = append(facts, "v"+v)
+	}
+	if tags := memoryTags(d.memory); len(tags) > 0 {
+		facts = append(facts, "#"+strings.Join(tags, " #"))
+	}
+	if src, _ := d.memory["source"].(string); src != "" {
+		facts = append(facts, src)
+	}
+	if at := cellText("updated_at", d.memory["updated_at"], 0); at != "" {
+		facts = append(facts, "updated "+at)
+	}
+	lines = append(lines, tuiDim+strings.Join(facts, " · ")+tuiReset, "")
+	if d.locked != "" {
+		return append(lines, "Encrypted; no local key opens it: "+d.locked)
+	}
+	rendered := markdown.Render(d.body, markdown.Options{Width: width - 1, Color: true})
+	return append(lines, strings.Split(rendered, "\n")...)
+}
+
+func (a *tuiApp) previewRows(width, rows int) []string {
+	lines := a.previewLines(width)
+	a.scroll = min(a.scroll, max(len(lines)-rows, 0))
+	lines = lines[a.scroll:]
+	if len(lines) > rows {
+		lines = lines[:rows]
+	}
+	out := make([]string, len(lines))
+	for i, l := range lines {
+		out[i] = " " + l
+	}
+	return out
+}
+
+func (a *tuiApp) statusBar() string {
+	switch a.mode {
+	case tuiFilter, tuiSearch, tuiPrompt:
+		return a.prompt + a.input + "▏"
+	case tuiConfirm:
+		return a.prompt
+	}
+	if a.status != "" {
+		return a.status
+	}
+	return tuiDim + "? help  / filter  s search  e edit  t tags  d delete  v versions  a archive  q quit" + tuiReset
+}
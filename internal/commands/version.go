@@ -1,10 +1,15 @@
 package commands
 
-import "github.com/spf13/cobra"
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/maquina/recuerd0-cli/internal/catalog"
+)
 
 var versionCmd = &cobra.Command{
-	Use:   "version",
-	Short: "Print the CLI version",
+	Use:         "version",
+	Short:       "Print the CLI version",
+	Annotations: map[string]string{catalog.Local: "true"},
 	Run: func(cmd *cobra.Command, args []string) {
 		printSuccess(map[string]string{
 			"version": version,
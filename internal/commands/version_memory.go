@@ -18,17 +18,21 @@ var memoryVersionCmd = &cobra.Command{
 
 // memory version create
 var (
-	memoryVersionCreateWorkspace string
-	memoryVersionCreateTitle     string
-	memoryVersionCreateContent   string
-	memoryVersionCreateSource    string
-	memoryVersionCreateTags      string
+	memoryVersionCreateWorkspace    string
+	memoryVersionCreateTitle        string
+	memoryVersionCreateContent      string
+	memoryVersionCreateSource       string
+	memoryVersionCreateTags         string
+	memoryVersionCreateNoDefault    bool
+	memoryVersionCreateAllowSecrets bool
 )
 
 var memoryVersionCreateCmd = &cobra.Command{
-	Use:   "create <memory_id>",
-	Short: "Create a new version of a memory",
-	Args:  cobra.ExactArgs(1),
+	Use:               "create <memory_id>",
+	Short:             "Create a new version of a memory",
+	Example:           `  recuerd0 memory version create 42 --workspace 22 --content "Now using write-behind caching"`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeSingleMemoryID,
 	Run: func(cmd *cobra.Command, args []string) {
 		if err := requireAuth(); err != nil {
 			exitWithError(err)
@@ -72,6 +76,21 @@ var memoryVersionCreateCmd = &cobra.Command{
 			version["tags"] = trimmed
 		}
 
+		if !memoryVersionCreateNoDefault {
+			if applied := applyProjectDefaults(version, false); applied != nil {
+				setMeta("defaults_applied", applied)
+			}
+		}
+		if err := scanForSecrets(version, memoryVersionCreateAllowSecrets); err != nil {
+			exitWithError(err)
+			return
+		}
+		plaintext, err := encryptFields(ws, version)
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+
 		body := map[string]interface{}{"version": version}
 
 		apiClient := getClient()
@@ -81,6 +100,10 @@ var memoryVersionCreateCmd = &cobra.Command{
 			return
 		}
 
+		if plaintext != "" {
+			indexMemoryResponse(ws, resp.Data, plaintext)
+		}
+
 		bc := []response.Breadcrumb{
 			breadcrumb("show", fmt.Sprintf("recuerd0 memory show --workspace %s %s", ws, args[0]), "View memory"),
 			breadcrumb("list", fmt.Sprintf("recuerd0 memory list --workspace %s", ws), "List memories"),
@@ -93,10 +116,12 @@ var memoryVersionCreateCmd = &cobra.Command{
 func init() {
 	memoryCmd.AddCommand(memoryVersionCmd)
 
-	memoryVersionCreateCmd.Flags().StringVar(&memoryVersionCreateWorkspace, "workspace", "", "workspace ID")
+	memoryVersionCreateCmd.Flags().StringVar(&memoryVersionCreateWorkspace, "workspace", "", "workspace ID, name or alias")
 	memoryVersionCreateCmd.Flags().StringVar(&memoryVersionCreateTitle, "title", "", "version title")
 	memoryVersionCreateCmd.Flags().StringVar(&memoryVersionCreateContent, "content", "", "version content (use - for stdin)")
 	memoryVersionCreateCmd.Flags().StringVar(&memoryVersionCreateSource, "source", "", "source")
 	memoryVersionCreateCmd.Flags().StringVar(&memoryVersionCreateTags, "tags", "", "comma-separated tags")
+	memoryVersionCreateCmd.Flags().BoolVar(&memoryVersionCreateNoDefault, "no-defaults", false, "ignore defaults from .recuerd0.yaml")
+	memoryVersionCreateCmd.Flags().BoolVar(&memoryVersionCreateAllowSecrets, "allow-secrets", false, "send content even if secrets are detected")
 	memoryVersionCmd.AddCommand(memoryVersionCreateCmd)
 }
@@ -15,7 +15,11 @@ var workspaceCmd = &cobra.Command{
 }
 
 // workspace list
-var workspaceListPage string
+var (
+	workspaceListPage   string
+	workspaceListFilter listFilterFlags
+	workspaceListStream bool
+)
 
 var workspaceListCmd = &cobra.Command{
 	Use:   "list",
@@ -26,12 +30,48 @@ var workspaceListCmd = &cobra.Command{
 			return
 		}
 
+		lf, err := workspaceListFilter.compile()
+		if err != nil {
+			exitWithError(err)
+			return
+		}
+		if workspaceListStream {
+			if err := validStream(lf); err != nil {
+				exitWithError(err)
+				return
+			}
+		}
+
 		path := "/workspaces"
 		if workspaceListPage != "" {
 			path += "?page=" + workspaceListPage
 		}
 
+		bc := []response.Breadcrumb{
+			breadcrumb("show", "recuerd0 workspace show <workspace_id>", "View workspace details"),
+			breadcrumb("create", "recuerd0 workspace create --name <name>", "Create a workspace"),
+		}
+
 		apiClient := getClient()
+		if workspaceListStream {
+			listing, err := streamListing(apiClient, path, workspaceListPage != "", lf)
+			if err != nil {
+				exitWithError(err)
+				return
+			}
+			printSuccessWithPageAndBreadcrumbs(listing.Data, listing.Pagination, listing.summary("workspace(s)", lf), bc)
+			return
+		}
+		if lf.active() {
+			listing, err := fetchFiltered(apiClient, path, workspaceListPage != "", lf)
+			if err != nil {
+				exitWithError(err)
+				return
+			}
+			printSuccessWithPageAndBreadcrumbs(listing.Data, listing.Pagination, listing.summary("workspace(s)", lf), bc)
+			return
+		}
+
 		resp, err := apiClient.GetWithPagination(path)
 		if err != nil {
 			exitWithError(err)
@@ -42,37 +82,38 @@ var workspaceListCmd = &cobra.Command{
 		items := countItems(resp.Data)
 		summary := fmt.Sprintf("%d workspace(s)", items)
 
-		bc := []response.Breadcrumb{
-			breadcrumb("show", "recuerd0 workspace show <id>", "View workspace details"),
-			breadcrumb("create", "recuerd0 workspace create --name NAME", "Create a workspace"),
-		}
-
 		printSuccessWithPaginationAndBreadcrumbs(resp.Data, hasNext, resp.LinkNext, summary, bc)
 	},
 }
 
 // workspace show
 var workspaceShowCmd = &cobra.Command{
-	Use:   "show <id>",
-	Short: "Show workspace details",
-	Args:  cobra.ExactArgs(1),
+	Use:               "show <id>",
+	Short:             "Show workspace details",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeWorkspaceArg,
 	Run: func(cmd *cobra.Command, args []string) {
 		if err := requireAuth(); err != nil {
 			exitWithError(err)
 			return
 		}
+		id, err := lookupWorkspaceID(args[0])
+		if err != nil {
+			exitWithError(err)
+			return
+		}
 
 		apiClient := getClient()
-		resp, err := apiClient.Get("/workspaces/" + args[0])
+		resp, err := apiClient.Get("/workspaces/" + id)
 		if err != nil {
 			exitWithError(err)
 			return
 		}
 
 		bc := []response.Breadcrumb{
-			breadcrumb("list-memories", fmt.Sprintf("recuerd0 memory list --workspace %s", args[0]), "List memories in workspace"),
-			breadcrumb("update", fmt.Sprintf("recuerd0 workspace update %s --name NAME", args[0]), "Update workspace"),
-			breadcrumb("archive", fmt.Sprintf("recuerd0 workspace archive %s", args[0]), "Archive workspace"),
+			breadcrumb("list-memories", fmt.Sprintf("recuerd0 memory list --workspace %s", id), "List memories in workspace"),
+			breadcrumb("update", fmt.Sprintf("recuerd0 workspace update %s --name <name>", id), "Update workspace"),
+			breadcrumb("archive", fmt.Sprintf("recuerd0 workspace archive %s", id), "Archive workspace"),
 		}
 
 		printSuccessWithBreadcrumbs(resp.Data, "Workspace details", bc)
@@ -86,8 +127,9 @@ var (
 )
 
 var workspaceCreateCmd = &cobra.Command{
-	Use:   "create",
-	Short: "Create a new workspace",
+	Use:     "create",
+	Short:   "Create a new workspace",
+	Example: `  recuerd0 workspace create --name "my-rails-app" --description "Architecture decisions"`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if err := requireAuth(); err != nil {
 			exitWithError(err)
@@ -111,9 +153,10 @@ var workspaceCreateCmd = &cobra.Command{
 			exitWithError(err)
 			return
 		}
+		invalidateWorkspaceCache()
 
 		bc := []response.Breadcrumb{
-			breadcrumb("show", "recuerd0 workspace show <id>", "View created workspace"),
+			breadcrumb("show", "recuerd0 workspace show "+responseID(resp.Data, "<workspace_id>"), "View created workspace"),
 			breadcrumb("list", "recuerd0 workspace list", "List all workspaces"),
 		}
 
@@ -128,14 +171,20 @@ var (
 )
 
 var workspaceUpdateCmd = &cobra.Command{
-	Use:   "update <id>",
-	Short: "Update a workspace",
-	Args:  cobra.ExactArgs(1),
+	Use:               "update <id>",
+	Short:             "Update a workspace",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeWorkspaceArg,
 	Run: func(cmd *cobra.Command, args []string) {
 		if err := requireAuth(); err != nil {
 			exitWithError(err)
 			return
 		}
+		id, err := lookupWorkspaceID(args[0])
+		if err != nil {
+			exitWithError(err)
+			return
+		}
 
 		workspace := map[string]interface{}{}
 		if workspaceUpdateName != "" {
@@ -152,14 +201,15 @@ var workspaceUpdateCmd = &cobra.Command{
 		body := map[string]interface{}{"workspace": workspace}
 
 		apiClient := getClient()
-		resp, err := apiClient.Patch("/workspaces/"+args[0], body)
+		resp, err := apiClient.Patch("/workspaces/"+id, body)
 		if err != nil {
 			exitWithError(err)
 			return
 		}
+		invalidateWorkspaceCache()
 
 		bc := []response.Breadcrumb{
-			breadcrumb("show", fmt.Sprintf("recuerd0 workspace show %s", args[0]), "View updated workspace"),
+			breadcrumb("show", fmt.Sprintf("recuerd0 workspace show %s", id), "View updated workspace"),
 		}
 
 		printSuccessWithBreadcrumbs(resp.Data, "Workspace updated", bc)
@@ -189,6 +239,8 @@ func init() {
 	rootCmd.AddCommand(workspaceCmd)
 
 	workspaceListCmd.Flags().StringVar(&workspaceListPage, "page", "", "page number")
+	addListFilterFlags(workspaceListCmd, &workspaceListFilter)
+	addStreamFlag(workspaceListCmd, &workspaceListStream)
 	workspaceCmd.AddCommand(workspaceListCmd)
 
 	workspaceCmd.AddCommand(workspaceShowCmd)
@@ -9,24 +9,31 @@ import (
 )
 
 var workspaceArchiveCmd = &cobra.Command{
-	Use:   "archive <id>",
-	Short: "Archive a workspace",
-	Args:  cobra.ExactArgs(1),
+	Use:               "archive <id>",
+	Short:             "Archive a workspace",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeWorkspaceArg,
 	Run: func(cmd *cobra.Command, args []string) {
 		if err := requireAuth(); err != nil {
 			exitWithError(err)
 			return
 		}
+		id, err := lookupWorkspaceID(args[0])
+		if err != nil {
+			exitWithError(err)
+			return
+		}
 
 		apiClient := getClient()
-		resp, err := apiClient.Post("/workspaces/"+args[0]+"/archive", nil)
+		resp, err := apiClient.Post("/workspaces/"+id+"/archive", nil)
 		if err != nil {
 			exitWithError(err)
 			return
 		}
+		invalidateWorkspaceCache()
 
 		bc := []response.Breadcrumb{
-			breadcrumb("unarchive", fmt.Sprintf("recuerd0 workspace unarchive %s", args[0]), "Unarchive workspace"),
+			breadcrumb("unarchive", fmt.Sprintf("recuerd0 workspace unarchive %s", id), "Unarchive workspace"),
 			breadcrumb("list", "recuerd0 workspace list", "List workspaces"),
 		}
 
@@ -35,24 +42,31 @@ var workspaceArchiveCmd = &cobra.Command{
 }
 
 var workspaceUnarchiveCmd = &cobra.Command{
-	Use:   "unarchive <id>",
-	Short: "Unarchive a workspace",
-	Args:  cobra.ExactArgs(1),
+	Use:               "unarchive <id>",
+	Short:             "Unarchive a workspace",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeWorkspaceArg,
 	Run: func(cmd *cobra.Command, args []string) {
 		if err := requireAuth(); err != nil {
 			exitWithError(err)
 			return
 		}
+		id, err := lookupWorkspaceID(args[0])
+		if err != nil {
+			exitWithError(err)
+			return
+		}
 
 		apiClient := getClient()
-		resp, err := apiClient.Delete("/workspaces/" + args[0] + "/archive")
+		resp, err := apiClient.Delete("/workspaces/" + id + "/archive")
 		if err != nil {
 			exitWithError(err)
 			return
 		}
+		invalidateWorkspaceCache()
 
 		bc := []response.Breadcrumb{
-			breadcrumb("show", fmt.Sprintf("recuerd0 workspace show %s", args[0]), "View workspace"),
+			breadcrumb("show", fmt.Sprintf("recuerd0 workspace show %s", id), "View workspace"),
 			breadcrumb("list", "recuerd0 workspace list", "List workspaces"),
 		}
 
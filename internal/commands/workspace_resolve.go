@@ -0,0 +1,147 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/maquina/recuerd0-cli/internal/cache"
+	"github.com/maquina/recuerd0-cli/internal/errors"
+)
+
+// workspaceCacheTTL is how long a workspace listing is reused for name lookups.
+const workspaceCacheTTL = 5 * time.Minute
+
+// maxListPages bounds how many pages are followed when listing everything.
+const maxListPages = 100
+
+// workspaceRef is the subset of a workspace needed to resolve names.
+type workspaceRef struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func workspaceCacheKey() string {
+	return cache.Key("workspaces", cfg.APIURL, cfg.Token)
+}
+
+// invalidateWorkspaceCache drops the cached listing after workspaces change.
+func invalidateWorkspaceCache() {
+	if cfg != nil {
+		cache.Delete(workspaceCacheKey())
+	}
+}
+
+// listWorkspaceRefs returns every workspace, following pagination. A fresh
+// cached listing is used unless refresh is set. The second return value
+// reports whether the result came from the cache.
+func listWorkspaceRefs(refresh bool) ([]workspaceRef, bool, error) {
+	key := workspaceCacheKey()
+	var refs []workspaceRef
+	if !refresh && cache.Get(key, workspaceCacheTTL, &refs) {
+		return refs, true, nil
+	}
+
+	items, err := fetchAllPages(getClient(), "/workspaces")
+	if err != nil {
+		return nil, false, err
+	}
+	refs = []workspaceRef{}
+	for _, item := range items {
+		if m, ok := item.(map[string]interface{}); ok {
+			refs = append(refs, workspaceRef{ID: stringID(m["id"]), Name: fmt.Sprint(m["name"])})
+		}
+	}
+
+	_ = cache.Put(key, refs)
+	return refs, false, nil
+}
+
+// stringID formats a JSON ID (decoded as float64 or string) without a
+// trailing ".0".
+func stringID(v interface{}) string {
+	switch id := v.(type) {
+	case float64:
+		return fmt.Sprintf("%.0f", id)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(id)
+	}
+}
+
+func isNumericID(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// lookupWorkspaceID turns a workspace reference into an ID. A reference can
+// be a numeric ID, an alias from the config, an exact name or a unique name
+// prefix. Names are matched case-insensitively.
+func lookupWorkspaceID(ref string) (string, error) {
+	ref = strings.TrimSpace(ref)
+	if isNumericID(ref) {
+		return ref, nil
+	}
+	if cfg != nil {
+		if target, ok := cfg.Aliases[ref]; ok {
+			if isNumericID(target) {
+				return target, nil
+			}
+			ref = target
+		}
+	}
+
+	refs, cached, err := listWorkspaceRefs(false)
+	if err != nil {
+		return "", err
+	}
+	id, err := matchWorkspace(ref, refs)
+	if cliErr, ok := err.(*errors.CLIError); ok && cliErr.Code == errors.CodeNotFound && cached {
+		// The cached listing may predate a new or renamed workspace.
+		if refs, _, err = listWorkspaceRefs(true); err != nil {
+			return "", err
+		}
+		return matchWorkspace(ref, refs)
+	}
+	return id, err
+}
+
+func matchWorkspace(ref string, refs []workspaceRef) (string, error) {
+	needle := strings.ToLower(ref)
+
+	var exact, prefix []workspaceRef
+	for _, w := range refs {
+		name := strings.ToLower(w.Name)
+		switch {
+		case name == needle:
+			exact = append(exact, w)
+		case strings.HasPrefix(name, needle):
+			prefix = append(prefix, w)
+		}
+	}
+
+	candidates := exact
+	if len(candidates) == 0 {
+		candidates = prefix
+	}
+	switch len(candidates) {
+	case 0:
+		return "", errors.NewNotFoundError(fmt.Sprintf("No workspace matches %q. Run: recuerd0 workspace list", ref))
+	case 1:
+		return candidates[0].ID, nil
+	default:
+		names := make([]string, 0, len(candidates))
+		for _, w := range candidates {
+			names = append(names, fmt.Sprintf("%s (id %s)", w.Name, w.ID))
+		}
+		return "", errors.NewInvalidArgsError(fmt.Sprintf("Workspace %q is ambiguous; candidates: %s", ref, strings.Join(names, ", ")))
+	}
+}
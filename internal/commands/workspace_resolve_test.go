@@ -0,0 +1,116 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/maquina/recuerd0-cli/internal/client"
+	"github.com/maquina/recuerd0-cli/internal/errors"
+)
+
+func workspaceListing() []interface{} {
+	return []interface{}{
+		map[string]interface{}{"id": float64(1), "name": "Project Alpha"},
+		map[string]interface{}{"id": float64(4), "name": "Prototypes"},
+		map[string]interface{}{"id": float64(7), "name": "Notes"},
+	}
+}
+
+func TestLookupWorkspaceID(t *testing.T) {
+	tests := []struct {
+		ref  string
+		want string
+	}{
+		{"12", "12"},
+		{"Notes", "7"},
+		{"notes", "7"},
+		{"proj", "1"},
+		{"prot", "4"},
+		{"team", "4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			mock := NewMockClient().WithGetPathData("/workspaces", workspaceListing())
+			SetTestMode(mock)
+			SetTestConfig("tok_test", "https://api.example.com")
+			cfg.Aliases = map[string]string{"team": "Prototypes"}
+			defer ResetTestMode()
+
+			got, err := lookupWorkspaceID(tt.ref)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestLookupWorkspaceID_Ambiguous(t *testing.T) {
+	mock := NewMockClient().WithGetPathData("/workspaces", workspaceListing())
+	SetTestMode(mock)
+	SetTestConfig("tok_test", "https://api.example.com")
+	defer ResetTestMode()
+
+	_, err := lookupWorkspaceID("pro")
+	cliErr, ok := err.(*errors.CLIError)
+	if !ok || cliErr.Code != errors.CodeInvalidArgs {
+		t.Fatalf("expected INVALID_ARGS, got %v", err)
+	}
+	if !strings.Contains(cliErr.Message, "Project Alpha (id 1)") || !strings.Contains(cliErr.Message, "Prototypes (id 4)") {
+		t.Errorf("expected candidates in message, got %q", cliErr.Message)
+	}
+}
+
+func TestLookupWorkspaceID_NotFound(t *testing.T) {
+	mock := NewMockClient().WithGetPathData("/workspaces", workspaceListing())
+	SetTestMode(mock)
+	SetTestConfig("tok_test", "https://api.example.com")
+	defer ResetTestMode()
+
+	_, err := lookupWorkspaceID("missing")
+	cliErr, ok := err.(*errors.CLIError)
+	if !ok || cliErr.Code != errors.CodeNotFound {
+		t.Fatalf("expected NOT_FOUND, got %v", err)
+	}
+}
+
+func TestLookupWorkspaceID_UsesCache(t *testing.T) {
+	mock := NewMockClient().WithGetPathData("/workspaces", workspaceListing())
+	SetTestMode(mock)
+	SetTestConfig("tok_test", "https://api.example.com")
+	defer ResetTestMode()
+
+	_, _ = lookupWorkspaceID("notes")
+	_, _ = lookupWorkspaceID("alpha project")
+	_, _ = lookupWorkspaceID("Project Alpha")
+
+	// First lookup fills the cache, the miss refreshes it once, the last hits the cache.
+	if len(mock.GetCalls) != 2 {
+		t.Errorf("expected 2 listing calls, got %d", len(mock.GetCalls))
+	}
+}
+
+func TestMemoryList_WorkspaceByName(t *testing.T) {
+	mock := NewMockClient().WithGetPathData("/workspaces", workspaceListing())
+	mock.GetResponse = &client.APIResponse{StatusCode: 200, Data: []interface{}{}}
+
+	result := SetTestMode(mock)
+	SetTestConfigFull("tok_test", "https://api.example.com", "notes")
+	defer ResetTestMode()
+
+	memoryListWorkspace = ""
+	RunTestCommand(func() {
+		memoryListCmd.Run(memoryListCmd, []string{})
+	})
+
+	if result.ExitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", result.ExitCode)
+	}
+	last := mock.GetCalls[len(mock.GetCalls)-1]
+	if last.Path != "/workspaces/7/memories" {
+		t.Errorf("unexpected path: %s", last.Path)
+	}
+}
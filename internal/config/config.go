@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
 
 	"gopkg.in/yaml.v3"
 )
@@ -16,22 +17,69 @@ const (
 	localFileName  = ".recuerd0.yaml"
 )
 
+// Transport holds per-account HTTP settings. Empty values keep the client
+// defaults: a 30s timeout, proxies from HTTPS_PROXY/HTTP_PROXY and the
+// system CA pool.
+type Transport struct {
+	Timeout            string            `yaml:"timeout,omitempty"`
+	Proxy              string            `yaml:"proxy,omitempty"`
+	CACert             string            `yaml:"ca_cert,omitempty"`
+	ClientCert         string            `yaml:"client_cert,omitempty"`
+	ClientKey          string            `yaml:"client_key,omitempty"`
+	InsecureSkipVerify bool              `yaml:"insecure_skip_verify,omitempty"`
+	Headers            map[string]string `yaml:"headers,omitempty"`
+}
+
 // AccountConfig holds credentials for a single named account.
 type AccountConfig struct {
-	Token  string `yaml:"token"`
-	APIURL string `yaml:"api_url"`
+	Token      string            `yaml:"token"`
+	APIURL     string            `yaml:"api_url"`
+	Aliases    map[string]string `yaml:"aliases,omitempty"`
+	Encryption map[string]string `yaml:"encryption,omitempty"`
+	Transport  `yaml:",inline"`
+}
+
+// Redaction configures the secret scan run on memory content before it is
+// sent. Patterns maps a detector name to a regular expression; a single
+// capture group marks the secret part of the match.
+type Redaction struct {
+	Mode     string            `yaml:"mode,omitempty"`
+	Patterns map[string]string `yaml:"patterns,omitempty"`
+}
+
+// Cassette configures what --record replaces in a cassette, on top of the
+// token, the Authorization header and a few built-in names. Fields are JSON
+// keys in request and response bodies; Headers are header names.
+type Cassette struct {
+	Fields  []string `yaml:"fields,omitempty"`
+	Headers []string `yaml:"headers,omitempty"`
 }
 
 // GlobalConfig is the top-level config stored at ~/.config/recuerd0/config.yaml.
 type GlobalConfig struct {
-	Current  string                   `yaml:"current"`
-	Accounts map[string]AccountConfig `yaml:"accounts"`
+	Current   string                   `yaml:"current"`
+	Accounts  map[string]AccountConfig `yaml:"accounts"`
+	Redaction Redaction                `yaml:"redaction,omitempty"`
+	Cassette  Cassette                 `yaml:"cassette,omitempty"`
+}
+
+// Defaults are per-project values merged into memory create, memory update
+// and memory version create.
+type Defaults struct {
+	Tags        []string `yaml:"tags,omitempty"`
+	Source      string   `yaml:"source,omitempty"`
+	TitlePrefix string   `yaml:"title_prefix,omitempty"`
 }
 
 // LocalConfig is an optional per-project override at .recuerd0.yaml.
 type LocalConfig struct {
-	Account   string `yaml:"account"`
-	Workspace string `yaml:"workspace"`
+	Account    string            `yaml:"account"`
+	Workspace  string            `yaml:"workspace"`
+	Aliases    map[string]string `yaml:"aliases,omitempty"`
+	Defaults   Defaults          `yaml:"defaults,omitempty"`
+	Redaction  Redaction         `yaml:"redaction,omitempty"`
+	Encryption map[string]string `yaml:"encryption,omitempty"`
+	Cassette   Cassette          `yaml:"cassette,omitempty"`
 }
 
 // ResolvedConfig is the final merged configuration used by commands.
@@ -40,6 +88,21 @@ type ResolvedConfig struct {
 	APIURL    string
 	Account   string
 	Workspace string
+	// Aliases maps user-defined workspace aliases to an ID or name. Local
+	// aliases take precedence over the account's aliases.
+	Aliases map[string]string
+	// Defaults come from the local config only.
+	Defaults Defaults
+	// Transport comes from the selected account.
+	Transport Transport
+	// Redaction mode comes from the local config, then the global config;
+	// patterns from both are combined, local ones winning on name clashes.
+	Redaction Redaction
+	// Encryption maps workspace IDs to the key their content is encrypted
+	// with. Local entries take precedence over the account's.
+	Encryption map[string]string
+	// Cassette fields and headers from both files are combined.
+	Cassette Cassette
 }
 
 // globalConfigPath returns the path to the global config file.
@@ -125,9 +188,22 @@ func LoadGlobal() (*GlobalConfig, error) {
 	return &cfg, nil
 }
 
+// skipWrite, when set, is asked before a config file is written and
+// returns true to leave the file unchanged.
+var skipWrite func(what, path string) bool
+
+// SkipWrites sets the function asked before each config file write, so a
+// dry run can validate a change without saving it.
+func SkipWrites(fn func(what, path string) bool) {
+	skipWrite = fn
+}
+
 // SaveGlobal writes the global config to disk.
 func SaveGlobal(cfg *GlobalConfig) error {
 	path := globalConfigPath()
+	if skipWrite != nil && skipWrite("global config", path) {
+		return nil
+	}
 	dir := filepath.Dir(path)
 	if err := os.MkdirAll(dir, 0700); err != nil {
 		return fmt.Errorf("creating config directory: %w", err)
@@ -139,18 +215,25 @@ func SaveGlobal(cfg *GlobalConfig) error {
 	return os.WriteFile(path, data, 0600)
 }
 
-// FindLocal walks up from startDir looking for .recuerd0.yaml.
-func FindLocal(startDir string) (*LocalConfig, error) {
+// KeyDir returns the directory encryption keys are stored in, next to the
+// global config file.
+func KeyDir() string {
+	return filepath.Join(filepath.Dir(globalConfigPath()), "keys")
+}
+
+// GlobalConfigPath returns the location of the global config file.
+func GlobalConfigPath() string {
+	return globalConfigPath()
+}
+
+// FindLocalPath walks up from startDir and returns the path of the first
+// .recuerd0.yaml found, or "" if there is none.
+func FindLocalPath(startDir string) string {
 	dir := startDir
 	for {
 		path := filepath.Join(dir, localFileName)
-		data, err := os.ReadFile(path)
-		if err == nil {
-			var cfg LocalConfig
-			if err := yaml.Unmarshal(data, &cfg); err != nil {
-				return nil, fmt.Errorf("parsing local config: %w", err)
-			}
-			return &cfg, nil
+		if _, err := os.Stat(path); err == nil {
+			return path
 		}
 		parent := filepath.Dir(dir)
 		if parent == dir {
@@ -158,72 +241,226 @@ func FindLocal(startDir string) (*LocalConfig, error) {
 		}
 		dir = parent
 	}
-	return nil, nil
+	return ""
 }
 
-// Resolve merges all config layers into a ResolvedConfig.
-// Priority: flags > env > local config > global config.
-func Resolve(flags ResolvedConfig) (*ResolvedConfig, error) {
-	global, err := LoadGlobal()
+// FindLocal walks up from startDir looking for .recuerd0.yaml.
+func FindLocal(startDir string) (*LocalConfig, error) {
+	path := FindLocalPath(startDir)
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("reading local config: %w", err)
+	}
+	var cfg LocalConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing local config: %w", err)
 	}
+	return &cfg, nil
+}
 
-	cwd, _ := os.Getwd()
-	local, _ := FindLocal(cwd)
+// Sources a resolved setting can come from, in precedence order.
+const (
+	SourceFlag    = "flag"
+	SourceEnv     = "env"
+	SourceLocal   = "local"
+	SourceGlobal  = "global"
+	SourceDefault = "default"
+)
 
-	resolved := &ResolvedConfig{}
+// Layer is one candidate value for a setting.
+type Layer struct {
+	Source string
+	Origin string // flag name, env var or file path
+	Value  string
+}
 
-	// Start with global config (current account)
-	accountName := global.Current
-	if local != nil && local.Account != "" {
-		accountName = local.Account
-	}
-	if env := os.Getenv("RECUERD0_ACCOUNT"); env != "" {
-		accountName = env
-	}
-	if flags.Account != "" {
-		accountName = flags.Account
+// Setting describes how a single key was resolved. Layers lists every
+// source that provided a value, highest precedence first; Value and Source
+// come from the first layer. Note explains an order that differs from the
+// usual precedence.
+type Setting struct {
+	Key    string
+	Value  string
+	Source string
+	Layers []Layer
+	Note   string
+}
+
+// Explain resolves every setting and records which layers contributed.
+// Precedence: flags > env > local config > global config > defaults.
+// Unlike Resolve, it fails when a .recuerd0.yaml is found but cannot be
+// read, rather than reporting values as if the file were absent.
+func Explain(flags ResolvedConfig) ([]Setting, error) {
+	cwd, _ := os.Getwd()
+	if _, err := FindLocal(cwd); err != nil {
+		return nil, fmt.Errorf("%s: %w", FindLocalPath(cwd), err)
 	}
-	resolved.Account = accountName
+	settings, _, _, err := explain(flags)
+	return settings, err
+}
 
-	if acct, ok := global.Accounts[accountName]; ok {
-		resolved.Token = acct.Token
-		resolved.APIURL = acct.APIURL
+func explain(flags ResolvedConfig) ([]Setting, *GlobalConfig, *LocalConfig, error) {
+	global, err := LoadGlobal()
+	if err != nil {
+		return nil, nil, nil, err
 	}
 
-	// Workspace from local config
-	if local != nil && local.Workspace != "" {
-		resolved.Workspace = local.Workspace
+	cwd, _ := os.Getwd()
+	localPath := FindLocalPath(cwd)
+	local, _ := FindLocal(cwd)
+	globalPath := globalConfigPath()
+
+	collect := func(key string, candidates ...Layer) Setting {
+		s := Setting{Key: key}
+		for _, c := range candidates {
+			if c.Value != "" {
+				s.Layers = append(s.Layers, c)
+			}
+		}
+		if len(s.Layers) > 0 {
+			s.Value = s.Layers[0].Value
+			s.Source = s.Layers[0].Source
+		}
+		return s
+	}
+
+	var localAccount, localWorkspace, localRedaction string
+	if local != nil {
+		localAccount = local.Account
+		localWorkspace = local.Workspace
+		localRedaction = local.Redaction.Mode
+	}
+
+	account := collect("account",
+		Layer{SourceFlag, "--account", flags.Account},
+		Layer{SourceEnv, "RECUERD0_ACCOUNT", os.Getenv("RECUERD0_ACCOUNT")},
+		Layer{SourceLocal, localPath, localAccount},
+		Layer{SourceGlobal, globalPath + " (current)", global.Current},
+	)
+
+	acct := global.Accounts[account.Value]
+	acctOrigin := fmt.Sprintf("%s (accounts.%s)", globalPath, account.Value)
+
+	token := collect("token",
+		Layer{SourceFlag, "--token", flags.Token},
+		Layer{SourceEnv, "RECUERD0_TOKEN", os.Getenv("RECUERD0_TOKEN")},
+		Layer{SourceGlobal, acctOrigin, acct.Token},
+	)
+	apiURL := collect("api_url",
+		Layer{SourceFlag, "--api-url", flags.APIURL},
+		Layer{SourceEnv, "RECUERD0_API_URL", os.Getenv("RECUERD0_API_URL")},
+		Layer{SourceGlobal, acctOrigin, acct.APIURL},
+		Layer{SourceDefault, "built-in", DefaultAPIURL},
+	)
+	workspace := collect("workspace",
+		Layer{SourceFlag, "--workspace", flags.Workspace},
+		Layer{SourceEnv, "RECUERD0_WORKSPACE", os.Getenv("RECUERD0_WORKSPACE")},
+		Layer{SourceLocal, localPath, localWorkspace},
+	)
+
+	redactionMode := collect("redaction.mode",
+		Layer{SourceLocal, localPath, localRedaction},
+		Layer{SourceGlobal, globalPath, global.Redaction.Mode},
+		Layer{SourceDefault, "built-in", "warn"},
+	)
+
+	settings := []Setting{account, token, apiURL, workspace, redactionMode}
+	// The account's proxy is more specific than the machine-wide
+	// environment, so it wins over HTTPS_PROXY and HTTP_PROXY.
+	proxy := collect("proxy",
+		Layer{SourceGlobal, acctOrigin, acct.Proxy},
+		Layer{SourceEnv, "HTTPS_PROXY", os.Getenv("HTTPS_PROXY")},
+		Layer{SourceEnv, "HTTP_PROXY", os.Getenv("HTTP_PROXY")},
+	)
+	proxy.Note = "the account's proxy takes precedence over HTTPS_PROXY and HTTP_PROXY"
+	transport := []Setting{
+		collect("timeout",
+			Layer{SourceGlobal, acctOrigin, acct.Timeout},
+			Layer{SourceDefault, "built-in", "30s"},
+		),
+		proxy,
+		collect("ca_cert", Layer{SourceGlobal, acctOrigin, acct.CACert}),
+		collect("client_cert", Layer{SourceGlobal, acctOrigin, acct.ClientCert}),
+		collect("client_key", Layer{SourceGlobal, acctOrigin, acct.ClientKey}),
+	}
+	if acct.InsecureSkipVerify {
+		transport = append(transport, collect("insecure_skip_verify", Layer{SourceGlobal, acctOrigin, "true"}))
+	}
+	for _, name := range sortedKeys(acct.Headers) {
+		transport = append(transport, collect("headers."+name, Layer{SourceGlobal, acctOrigin, acct.Headers[name]}))
+	}
+	for _, s := range transport {
+		if s.Value != "" {
+			settings = append(settings, s)
+		}
 	}
+	return settings, global, local, nil
+}
 
-	// Env overrides
-	if env := os.Getenv("RECUERD0_TOKEN"); env != "" {
-		resolved.Token = env
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
 	}
-	if env := os.Getenv("RECUERD0_API_URL"); env != "" {
-		resolved.APIURL = env
+	sort.Strings(keys)
+	return keys
+}
+
+// Resolve merges all config layers into a ResolvedConfig.
+// Priority: flags > env > local config > global config.
+func Resolve(flags ResolvedConfig) (*ResolvedConfig, error) {
+	settings, global, local, err := explain(flags)
+	if err != nil {
+		return nil, err
 	}
-	if env := os.Getenv("RECUERD0_WORKSPACE"); env != "" {
-		resolved.Workspace = env
+
+	resolved := &ResolvedConfig{Aliases: make(map[string]string)}
+	for _, s := range settings {
+		switch s.Key {
+		case "account":
+			resolved.Account = s.Value
+		case "token":
+			resolved.Token = s.Value
+		case "api_url":
+			resolved.APIURL = s.Value
+		case "workspace":
+			resolved.Workspace = s.Value
+		case "redaction.mode":
+			resolved.Redaction.Mode = s.Value
+		}
 	}
 
-	// Flag overrides
-	if flags.Token != "" {
-		resolved.Token = flags.Token
+	resolved.Redaction.Patterns = make(map[string]string)
+	for name, pattern := range global.Redaction.Patterns {
+		resolved.Redaction.Patterns[name] = pattern
 	}
-	if flags.APIURL != "" {
-		resolved.APIURL = flags.APIURL
+
+	resolved.Cassette = global.Cassette
+	resolved.Transport = global.Accounts[resolved.Account].Transport
+	resolved.Encryption = make(map[string]string)
+	for ws, key := range global.Accounts[resolved.Account].Encryption {
+		resolved.Encryption[ws] = key
 	}
-	if flags.Workspace != "" {
-		resolved.Workspace = flags.Workspace
+	for alias, target := range global.Accounts[resolved.Account].Aliases {
+		resolved.Aliases[alias] = target
 	}
-
-	// Default API URL
-	if resolved.APIURL == "" {
-		resolved.APIURL = DefaultAPIURL
+	if local != nil {
+		for alias, target := range local.Aliases {
+			resolved.Aliases[alias] = target
+		}
+		resolved.Defaults = local.Defaults
+		for name, pattern := range local.Redaction.Patterns {
+			resolved.Redaction.Patterns[name] = pattern
+		}
+		for ws, key := range local.Encryption {
+			resolved.Encryption[ws] = key
+		}
+		resolved.Cassette.Fields = append(append([]string{}, resolved.Cassette.Fields...), local.Cassette.Fields...)
+		resolved.Cassette.Headers = append(append([]string{}, resolved.Cassette.Headers...), local.Cassette.Headers...)
 	}
-
 	return resolved, nil
 }
 
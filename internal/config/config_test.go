@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -243,3 +244,115 @@ func TestResolve_DefaultAPIURL(t *testing.T) {
 		t.Errorf("expected default API URL %q, got %q", DefaultAPIURL, resolved.APIURL)
 	}
 }
+
+func TestResolve_Aliases(t *testing.T) {
+	setupTestDir(t)
+	t.Setenv("RECUERD0_ACCOUNT", "")
+	t.Chdir(t.TempDir())
+
+	_ = AddAccount("work", "tok", "")
+	_ = SetValue(ScopeGlobal, "accounts.work.aliases.kb", "12")
+	_ = SetValue(ScopeGlobal, "accounts.work.aliases.notes", "Team Notes")
+	_ = SetValue(ScopeLocal, "aliases.notes", "7")
+
+	resolved, err := Resolve(ResolvedConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved.Aliases["kb"] != "12" {
+		t.Errorf("expected alias kb -> 12, got %v", resolved.Aliases)
+	}
+	if resolved.Aliases["notes"] != "7" {
+		t.Errorf("expected local alias to win, got %v", resolved.Aliases)
+	}
+}
+
+func TestResolve_Redaction(t *testing.T) {
+	setupTestDir(t)
+	t.Setenv("RECUERD0_ACCOUNT", "")
+
+	global := &GlobalConfig{
+		Accounts:  map[string]AccountConfig{},
+		Redaction: Redaction{Mode: "warn", Patterns: map[string]string{"ticket": `T-\d+`, "emp": `E\d+`}},
+	}
+	if err := SaveGlobal(global); err != nil {
+		t.Fatal(err)
+	}
+	project := t.TempDir()
+	local := "redaction:\n  mode: block\n  patterns:\n    emp: 'EMP-\\d+'\n"
+	if err := os.WriteFile(filepath.Join(project, ".recuerd0.yaml"), []byte(local), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(project)
+
+	resolved, err := Resolve(ResolvedConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved.Redaction.Mode != "block" {
+		t.Errorf("expected local mode to win, got %q", resolved.Redaction.Mode)
+	}
+	if resolved.Redaction.Patterns["emp"] != `EMP-\d+` || resolved.Redaction.Patterns["ticket"] != `T-\d+` {
+		t.Errorf("unexpected patterns: %v", resolved.Redaction.Patterns)
+	}
+}
+
+func TestResolve_Cassette(t *testing.T) {
+	setupTestDir(t)
+	t.Setenv("RECUERD0_ACCOUNT", "")
+
+	global := &GlobalConfig{
+		Accounts: map[string]AccountConfig{},
+		Cassette: Cassette{Fields: []string{"email"}, Headers: []string{"X-Api-Key"}},
+	}
+	if err := SaveGlobal(global); err != nil {
+		t.Fatal(err)
+	}
+	project := t.TempDir()
+	if err := os.WriteFile(filepath.Join(project, ".recuerd0.yaml"), []byte("cassette:\n  fields: [customer_id]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(project)
+
+	resolved, err := Resolve(ResolvedConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(resolved.Cassette.Fields, ","); got != "email,customer_id" {
+		t.Errorf("expected fields from both files, got %s", got)
+	}
+	if got := strings.Join(resolved.Cassette.Headers, ","); got != "X-Api-Key" {
+		t.Errorf("unexpected headers %s", got)
+	}
+}
+
+func TestResolve_Encryption(t *testing.T) {
+	setupTestDir(t)
+	t.Setenv("RECUERD0_ACCOUNT", "")
+
+	global := &GlobalConfig{
+		Current: "work",
+		Accounts: map[string]AccountConfig{
+			"work": {Token: "tok", Encryption: map[string]string{"5": "work", "6": "work"}},
+		},
+	}
+	if err := SaveGlobal(global); err != nil {
+		t.Fatal(err)
+	}
+	project := t.TempDir()
+	if err := os.WriteFile(filepath.Join(project, ".recuerd0.yaml"), []byte("encryption:\n  \"6\": team\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(project)
+
+	resolved, err := Resolve(ResolvedConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved.Encryption["5"] != "work" || resolved.Encryption["6"] != "team" {
+		t.Errorf("expected local mapping to win, got %v", resolved.Encryption)
+	}
+	if got := KeyDir(); got != filepath.Join(filepath.Dir(globalConfigPath()), "keys") {
+		t.Errorf("KeyDir = %q", got)
+	}
+}
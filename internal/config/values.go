@@ -0,0 +1,322 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Scopes for reading and writing individual settings.
+const (
+	ScopeGlobal = "global"
+	ScopeLocal  = "local"
+)
+
+var (
+	errUnknownKey = errors.New("unknown config key")
+	errNotSet     = errors.New("config key is not set")
+)
+
+// IsUnknownKey reports whether err was caused by a key that does not exist
+// in the config schema.
+func IsUnknownKey(err error) bool {
+	return errors.Is(err, errUnknownKey)
+}
+
+// IsNotSet reports whether err was caused by reading a key with no value.
+func IsNotSet(err error) bool {
+	return errors.Is(err, errNotSet)
+}
+
+// LocalConfigPath returns the .recuerd0.yaml that applies to dir, or the
+// path where a new one would be created if none exists.
+func LocalConfigPath(dir string) string {
+	if path := FindLocalPath(dir); path != "" {
+		return path
+	}
+	return filepath.Join(dir, localFileName)
+}
+
+// LoadLocal reads a local config file from path.
+func LoadLocal(path string) (*LocalConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &LocalConfig{}, nil
+		}
+		return nil, fmt.Errorf("reading local config: %w", err)
+	}
+	var cfg LocalConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parsing local config: %w", err)
+	}
+	return &cfg, nil
+}
+
+// SaveLocal writes a local config file to path. Local config is meant to be
+// committed alongside the project, so it is written world-readable.
+func SaveLocal(path string, cfg *LocalConfig) error {
+	if skipWrite != nil && skipWrite("local config", path) {
+		return nil
+	}
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("marshaling local config: %w", err)
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// GetValue returns the value stored at a dotted key such as
+// "accounts.work.api_url". Scalars are returned as strings, lists as
+// []string.
+func GetValue(scope, key string) (interface{}, error) {
+	root, err := loadScopeNode(scope)
+	if err != nil {
+		return nil, err
+	}
+	node := lookupNode(root, splitKey(key))
+	if node == nil || (node.Kind == yaml.ScalarNode && node.Value == "") {
+		return nil, fmt.Errorf("%w: %s", errNotSet, key)
+	}
+	return nodeValue(node), nil
+}
+
+// SetValue stores value at a dotted key. Values starting with "[" are parsed
+// as a YAML flow sequence; everything else is stored as a scalar.
+func SetValue(scope, key, value string) error {
+	parts := splitKey(key)
+	if len(parts) == 0 {
+		return fmt.Errorf("%w: %q", errUnknownKey, key)
+	}
+	root, err := loadScopeNode(scope)
+	if err != nil {
+		return err
+	}
+
+	leaf := &yaml.Node{Kind: yaml.ScalarNode, Value: value}
+	if strings.HasPrefix(strings.TrimSpace(value), "[") {
+		var doc yaml.Node
+		if err := yaml.Unmarshal([]byte(value), &doc); err != nil {
+			return fmt.Errorf("parsing list value: %w", err)
+		}
+		if len(doc.Content) > 0 {
+			leaf = doc.Content[0]
+		}
+	}
+
+	setNode(root, parts, leaf)
+	return saveScopeNode(scope, key, root)
+}
+
+// UnsetValue removes a dotted key.
+func UnsetValue(scope, key string) error {
+	parts := splitKey(key)
+	root, err := loadScopeNode(scope)
+	if err != nil {
+		return err
+	}
+	if !removeNode(root, parts) {
+		return fmt.Errorf("%w: %s", errNotSet, key)
+	}
+	return saveScopeNode(scope, key, root)
+}
+
+// ListValues returns every non-empty setting in scope, keyed by dotted path.
+func ListValues(scope string) (map[string]interface{}, error) {
+	root, err := loadScopeNode(scope)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]interface{})
+	flattenNode(root, "", values)
+	return values, nil
+}
+
+func splitKey(key string) []string {
+	var parts []string
+	for _, p := range strings.Split(key, ".") {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return parts
+}
+
+// loadScopeNode loads the config for scope and re-encodes it as a YAML node
+// tree so keys can be addressed generically.
+func loadScopeNode(scope string) (*yaml.Node, error) {
+	var v interface{}
+	switch scope {
+	case ScopeGlobal:
+		cfg, err := LoadGlobal()
+		if err != nil {
+			return nil, err
+		}
+		v = cfg
+	case ScopeLocal:
+		cwd, _ := os.Getwd()
+		cfg, err := LoadLocal(LocalConfigPath(cwd))
+		if err != nil {
+			return nil, err
+		}
+		v = cfg
+	default:
+		return nil, fmt.Errorf("unknown scope %q", scope)
+	}
+
+	var root yaml.Node
+	if err := root.Encode(v); err != nil {
+		return nil, fmt.Errorf("encoding config: %w", err)
+	}
+	return &root, nil
+}
+
+// saveScopeNode decodes the node tree back into the typed config, rejecting
+// keys that are not part of the schema, and writes it to disk.
+func saveScopeNode(scope, key string, root *yaml.Node) error {
+	data, err := yaml.Marshal(root)
+	if err != nil {
+		return fmt.Errorf("marshaling config: %w", err)
+	}
+	decode := func(out interface{}) error {
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(out); err != nil && err != io.EOF {
+			if strings.Contains(err.Error(), "not found in type") {
+				return fmt.Errorf("%w: %s", errUnknownKey, key)
+			}
+			return fmt.Errorf("invalid value for %s: %w", key, err)
+		}
+		return nil
+	}
+
+	switch scope {
+	case ScopeGlobal:
+		var cfg GlobalConfig
+		if err := decode(&cfg); err != nil {
+			return err
+		}
+		if cfg.Accounts == nil {
+			cfg.Accounts = make(map[string]AccountConfig)
+		}
+		if _, ok := cfg.Accounts[cfg.Current]; cfg.Current != "" && !ok {
+			return fmt.Errorf("account %q not found", cfg.Current)
+		}
+		return SaveGlobal(&cfg)
+	case ScopeLocal:
+		var cfg LocalConfig
+		if err := decode(&cfg); err != nil {
+			return err
+		}
+		cwd, _ := os.Getwd()
+		return SaveLocal(LocalConfigPath(cwd), &cfg)
+	default:
+		return fmt.Errorf("unknown scope %q", scope)
+	}
+}
+
+func lookupNode(node *yaml.Node, parts []string) *yaml.Node {
+	for _, part := range parts {
+		if node == nil || node.Kind != yaml.MappingNode {
+			return nil
+		}
+		var next *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == part {
+				next = node.Content[i+1]
+				break
+			}
+		}
+		node = next
+	}
+	return node
+}
+
+func setNode(node *yaml.Node, parts []string, leaf *yaml.Node) {
+	for i, part := range parts {
+		if node.Kind != yaml.MappingNode {
+			*node = yaml.Node{Kind: yaml.MappingNode}
+		}
+		var next *yaml.Node
+		for j := 0; j+1 < len(node.Content); j += 2 {
+			if node.Content[j].Value == part {
+				next = node.Content[j+1]
+				break
+			}
+		}
+		if i == len(parts)-1 {
+			if next != nil {
+				*next = *leaf
+			} else {
+				node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: part}, leaf)
+			}
+			return
+		}
+		if next == nil {
+			next = &yaml.Node{Kind: yaml.MappingNode}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: part}, next)
+		}
+		node = next
+	}
+}
+
+func removeNode(node *yaml.Node, parts []string) bool {
+	if len(parts) == 0 {
+		return false
+	}
+	parent := lookupNode(node, parts[:len(parts)-1])
+	if parent == nil || parent.Kind != yaml.MappingNode {
+		return false
+	}
+	last := parts[len(parts)-1]
+	for i := 0; i+1 < len(parent.Content); i += 2 {
+		if parent.Content[i].Value == last {
+			parent.Content = append(parent.Content[:i], parent.Content[i+2:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func nodeValue(node *yaml.Node) interface{} {
+	switch node.Kind {
+	case yaml.SequenceNode:
+		items := make([]string, 0, len(node.Content))
+		for _, c := range node.Content {
+			items = append(items, c.Value)
+		}
+		return items
+	case yaml.MappingNode:
+		values := make(map[string]interface{})
+		flattenNode(node, "", values)
+		return values
+	default:
+		return node.Value
+	}
+}
+
+func flattenNode(node *yaml.Node, prefix string, out map[string]interface{}) {
+	if node.Kind != yaml.MappingNode {
+		if v := nodeValue(node); v != "" {
+			out[prefix] = v
+		}
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i].Value
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		child := node.Content[i+1]
+		if child.Kind == yaml.SequenceNode && len(child.Content) == 0 {
+			continue
+		}
+		flattenNode(child, key, out)
+	}
+}
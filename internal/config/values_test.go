@@ -0,0 +1,193 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetValue_Global(t *testing.T) {
+	setupTestDir(t)
+	_ = AddAccount("work", "tok_123", "")
+
+	if err := SetValue(ScopeGlobal, "accounts.work.api_url", "https://work.example.com"); err != nil {
+		t.Fatalf("set error: %v", err)
+	}
+
+	cfg, _ := LoadGlobal()
+	if cfg.Accounts["work"].APIURL != "https://work.example.com" {
+		t.Errorf("unexpected api_url: %s", cfg.Accounts["work"].APIURL)
+	}
+	if cfg.Accounts["work"].Token != "tok_123" {
+		t.Errorf("expected token preserved, got %q", cfg.Accounts["work"].Token)
+	}
+}
+
+func TestSetValue_UnknownKey(t *testing.T) {
+	setupTestDir(t)
+
+	err := SetValue(ScopeGlobal, "accounts.work.bogus", "x")
+	if !IsUnknownKey(err) {
+		t.Fatalf("expected unknown key error, got %v", err)
+	}
+	err = SetValue(ScopeGlobal, "nope", "x")
+	if !IsUnknownKey(err) {
+		t.Fatalf("expected unknown key error, got %v", err)
+	}
+}
+
+func TestSetValue_CurrentMustExist(t *testing.T) {
+	setupTestDir(t)
+
+	if err := SetValue(ScopeGlobal, "current", "ghost"); err == nil {
+		t.Error("expected error for unknown account")
+	}
+}
+
+func TestLocalValues(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	if err := SetValue(ScopeLocal, "workspace", "0042"); err != nil {
+		t.Fatalf("set error: %v", err)
+	}
+	if err := SetValue(ScopeLocal, "account", "work"); err != nil {
+		t.Fatalf("set error: %v", err)
+	}
+
+	cfg, err := LoadLocal(filepath.Join(dir, localFileName))
+	if err != nil {
+		t.Fatalf("load error: %v", err)
+	}
+	if cfg.Workspace != "0042" {
+		t.Errorf("expected workspace '0042', got %q", cfg.Workspace)
+	}
+
+	v, err := GetValue(ScopeLocal, "account")
+	if err != nil || v != "work" {
+		t.Errorf("expected 'work', got %v (%v)", v, err)
+	}
+
+	if err := UnsetValue(ScopeLocal, "account"); err != nil {
+		t.Fatalf("unset error: %v", err)
+	}
+	if _, err := GetValue(ScopeLocal, "account"); !IsNotSet(err) {
+		t.Errorf("expected not set error, got %v", err)
+	}
+
+	values, _ := ListValues(ScopeLocal)
+	if len(values) != 1 || values["workspace"] != "0042" {
+		t.Errorf("unexpected values: %v", values)
+	}
+}
+
+func TestExplain_Sources(t *testing.T) {
+	setupTestDir(t)
+	_ = AddAccount("personal", "tok_a", "")
+	_ = AddAccount("work", "tok_b", "https://work.example.com")
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, localFileName), []byte("account: work\nworkspace: \"5\"\n"), 0644)
+	t.Chdir(dir)
+	t.Setenv("RECUERD0_ACCOUNT", "")
+	t.Setenv("RECUERD0_TOKEN", "")
+	t.Setenv("RECUERD0_API_URL", "")
+	t.Setenv("RECUERD0_WORKSPACE", "9")
+
+	settings, err := Explain(ResolvedConfig{})
+	if err != nil {
+		t.Fatalf("explain error: %v", err)
+	}
+	byKey := make(map[string]Setting)
+	for _, s := range settings {
+		byKey[s.Key] = s
+	}
+
+	if s := byKey["account"]; s.Value != "work" || s.Source != SourceLocal || len(s.Layers) != 2 {
+		t.Errorf("unexpected account setting: %+v", s)
+	}
+	if s := byKey["api_url"]; s.Value != "https://work.example.com" || s.Source != SourceGlobal {
+		t.Errorf("unexpected api_url setting: %+v", s)
+	}
+	if s := byKey["workspace"]; s.Value != "9" || s.Source != SourceEnv || len(s.Layers) != 2 {
+		t.Errorf("unexpected workspace setting: %+v", s)
+	}
+}
+
+func TestExplain_BrokenLocal(t *testing.T) {
+	setupTestDir(t)
+	_ = AddAccount("personal", "tok_a", "")
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, localFileName), []byte("workspace: [unclosed"), 0644)
+	t.Chdir(dir)
+
+	_, err := Explain(ResolvedConfig{})
+	if err == nil || !strings.Contains(err.Error(), filepath.Join(dir, localFileName)) {
+		t.Errorf("expected the parse error with the file's path, got %v", err)
+	}
+}
+
+func TestExplain_ProxyNote(t *testing.T) {
+	setupTestDir(t)
+	t.Setenv("RECUERD0_ACCOUNT", "")
+	_ = AddAccount("work", "tok_b", "")
+	if err := SetValue(ScopeGlobal, "accounts.work.proxy", "http://proxy.corp.example:3128"); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(t.TempDir())
+	t.Setenv("HTTPS_PROXY", "http://env.example:8080")
+
+	settings, err := Explain(ResolvedConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range settings {
+		if s.Key != "proxy" {
+			continue
+		}
+		if s.Value != "http://proxy.corp.example:3128" || len(s.Layers) != 2 || s.Note == "" {
+			t.Errorf("expected the account proxy first with a note, got %+v", s)
+		}
+		return
+	}
+	t.Error("expected a proxy setting")
+}
+
+func TestSetValue_LocalDefaultsList(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	if err := SetValue(ScopeLocal, "defaults.tags", "[proj, notes]"); err != nil {
+		t.Fatalf("set error: %v", err)
+	}
+	if err := SetValue(ScopeLocal, "defaults.source", "claude-code"); err != nil {
+		t.Fatalf("set error: %v", err)
+	}
+
+	cfg, _ := LoadLocal(filepath.Join(dir, localFileName))
+	if len(cfg.Defaults.Tags) != 2 || cfg.Defaults.Tags[1] != "notes" {
+		t.Errorf("unexpected tags: %v", cfg.Defaults.Tags)
+	}
+	v, _ := GetValue(ScopeLocal, "defaults.tags")
+	if tags, ok := v.([]string); !ok || len(tags) != 2 {
+		t.Errorf("expected []string from get, got %#v", v)
+	}
+}
+
+func TestSetValue_EncryptionMapping(t *testing.T) {
+	setupTestDir(t)
+	project := t.TempDir()
+	t.Chdir(project)
+
+	if err := SetValue(ScopeLocal, "encryption.22", "team"); err != nil {
+		t.Fatalf("SetValue: %v", err)
+	}
+	local, err := LoadLocal(filepath.Join(project, ".recuerd0.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if local.Encryption["22"] != "team" {
+		t.Errorf("expected encryption mapping, got %v", local.Encryption)
+	}
+}
@@ -0,0 +1,313 @@
+// Package crypt encrypts memory content on the client so the server only
+// stores ciphertext.
+//
+// Each message gets a random content key. The content is sealed with
+// AES-256-GCM and the content key is wrapped for every recipient with an
+// ephemeral X25519 exchange and HKDF-SHA256, the same construction age uses
+// with its X25519 recipients, built only on the standard library.
+package crypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	secretPrefix = "RECUERD0-SECRET-KEY-1:"
+	publicPrefix = "recuerd0-pub-1:"
+
+	armorBegin = "-----BEGIN RECUERD0 ENCRYPTED MEMORY-----"
+	armorEnd   = "-----END RECUERD0 ENCRYPTED MEMORY-----"
+	armorWidth = 64
+
+	wrapInfo = "recuerd0 content key v1"
+	version  = 1
+)
+
+// ErrNoIdentity is returned when none of the given keys can open a message.
+var ErrNoIdentity = errors.New("no matching key to decrypt this memory")
+
+// Identity is an X25519 private key.
+type Identity struct {
+	key *ecdh.PrivateKey
+}
+
+// Recipient is an X25519 public key that content can be encrypted to.
+type Recipient struct {
+	key *ecdh.PublicKey
+}
+
+// GenerateIdentity creates a new random key.
+func GenerateIdentity() (*Identity, error) {
+	key, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("generating key: %w", err)
+	}
+	return &Identity{key: key}, nil
+}
+
+// ParseIdentity reads a key in the form produced by Identity.String.
+func ParseIdentity(s string) (*Identity, error) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, secretPrefix) {
+		return nil, fmt.Errorf("not a recuerd0 secret key (expected %s prefix)", secretPrefix)
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(s, secretPrefix))
+	if err != nil {
+		return nil, fmt.Errorf("decoding secret key: %w", err)
+	}
+	key, err := ecdh.X25519().NewPrivateKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid secret key: %w", err)
+	}
+	return &Identity{key: key}, nil
+}
+
+// String encodes the private key. Treat it like a password.
+func (i *Identity) String() string {
+	return secretPrefix + base64.RawURLEncoding.EncodeToString(i.key.Bytes())
+}
+
+// Recipient returns the public half of the key.
+func (i *Identity) Recipient() *Recipient {
+	return &Recipient{key: i.key.PublicKey()}
+}
+
+// ParseRecipient reads a public key in the form produced by
+// Recipient.String.
+func ParseRecipient(s string) (*Recipient, error) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, publicPrefix) {
+		return nil, fmt.Errorf("not a recuerd0 public key (expected %s prefix)", publicPrefix)
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(s, publicPrefix))
+	if err != nil {
+		return nil, fmt.Errorf("decoding public key: %w", err)
+	}
+	key, err := ecdh.X25519().NewPublicKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %w", err)
+	}
+	return &Recipient{key: key}, nil
+}
+
+func (r *Recipient) String() string {
+	return publicPrefix + base64.RawURLEncoding.EncodeToString(r.key.Bytes())
+}
+
+// ID is a short fingerprint of the public key, stored with each message so
+// the right key can be picked and reported.
+func (r *Recipient) ID() string {
+	sum := sha256.Sum256(r.key.Bytes())
+	return hex.EncodeToString(sum[:8])
+}
+
+type stanza struct {
+	KeyID        string `json:"kid"`
+	EphemeralKey []byte `json:"epk"`
+	WrappedKey   []byte `json:"key"`
+}
+
+type envelope struct {
+	Version    int      `json:"v"`
+	Recipients []stanza `json:"recipients"`
+	Nonce      []byte   `json:"nonce"`
+	Ciphertext []byte   `json:"ct"`
+}
+
+// Encrypt seals plaintext for the recipients and returns armored text that
+// can be stored in place of the content.
+func Encrypt(plaintext []byte, recipients ...*Recipient) (string, error) {
+	if len(recipients) == 0 {
+		return "", errors.New("no recipients to encrypt to")
+	}
+
+	contentKey := make([]byte, 32)
+	if _, err := rand.Read(contentKey); err != nil {
+		return "", err
+	}
+	env := envelope{Version: version}
+	for _, r := range recipients {
+		s, err := wrap(contentKey, r)
+		if err != nil {
+			return "", err
+		}
+		env.Recipients = append(env.Recipients, s)
+	}
+
+	aead, err := newGCM(contentKey)
+	if err != nil {
+		return "", err
+	}
+	env.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(env.Nonce); err != nil {
+		return "", err
+	}
+	env.Ciphertext = aead.Seal(nil, env.Nonce, plaintext, nil)
+
+	data, err := json.Marshal(env)
+	if err != nil {
+		return "", err
+	}
+	return armor(data), nil
+}
+
+// Decrypt opens armored text with whichever identity it was encrypted to.
+func Decrypt(armored string, identities ...*Identity) ([]byte, error) {
+	data, err := dearmor(armored)
+	if err != nil {
+		return nil, err
+	}
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, fmt.Errorf("parsing encrypted memory: %w", err)
+	}
+	if env.Version != version {
+		return nil, fmt.Errorf("unsupported encryption version %d", env.Version)
+	}
+
+	for _, id := range identities {
+		kid := id.Recipient().ID()
+		for _, s := range env.Recipients {
+			if s.KeyID != kid {
+				continue
+			}
+			contentKey, err := unwrap(s, id)
+			if err != nil {
+				return nil, err
+			}
+			aead, err := newGCM(contentKey)
+			if err != nil {
+				return nil, err
+			}
+			plaintext, err := aead.Open(nil, env.Nonce, env.Ciphertext, nil)
+			if err != nil {
+				return nil, errors.New("encrypted memory is corrupt or was tampered with")
+			}
+			return plaintext, nil
+		}
+	}
+	return nil, ErrNoIdentity
+}
+
+// KeyIDs lists the fingerprints of the keys armored text was encrypted to.
+func KeyIDs(armored string) ([]string, error) {
+	data, err := dearmor(armored)
+	if err != nil {
+		return nil, err
+	}
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, fmt.Errorf("parsing encrypted memory: %w", err)
+	}
+	ids := make([]string, 0, len(env.Recipients))
+	for _, s := range env.Recipients {
+		ids = append(ids, s.KeyID)
+	}
+	return ids, nil
+}
+
+// IsEncrypted reports whether s is armored ciphertext from Encrypt.
+func IsEncrypted(s string) bool {
+	return strings.HasPrefix(strings.TrimSpace(s), armorBegin)
+}
+
+func wrap(contentKey []byte, r *Recipient) (stanza, error) {
+	eph, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return stanza{}, err
+	}
+	shared, err := eph.ECDH(r.key)
+	if err != nil {
+		return stanza{}, err
+	}
+	wrapKey, err := wrapKeyFor(shared, eph.PublicKey().Bytes(), r.key.Bytes())
+	if err != nil {
+		return stanza{}, err
+	}
+	aead, err := newGCM(wrapKey)
+	if err != nil {
+		return stanza{}, err
+	}
+	// The wrap key is unique to this ephemeral key, so a zero nonce is safe.
+	nonce := make([]byte, aead.NonceSize())
+	return stanza{
+		KeyID:        r.ID(),
+		EphemeralKey: eph.PublicKey().Bytes(),
+		WrappedKey:   aead.Seal(nil, nonce, contentKey, nil),
+	}, nil
+}
+
+func unwrap(s stanza, id *Identity) ([]byte, error) {
+	ephPub, err := ecdh.X25519().NewPublicKey(s.EphemeralKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ephemeral key: %w", err)
+	}
+	shared, err := id.key.ECDH(ephPub)
+	if err != nil {
+		return nil, err
+	}
+	wrapKey, err := wrapKeyFor(shared, s.EphemeralKey, id.key.PublicKey().Bytes())
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newGCM(wrapKey)
+	if err != nil {
+		return nil, err
+	}
+	contentKey, err := aead.Open(nil, make([]byte, aead.NonceSize()), s.WrappedKey, nil)
+	if err != nil {
+		return nil, errors.New("could not unwrap content key")
+	}
+	return contentKey, nil
+}
+
+func wrapKeyFor(shared, ephPub, recipientPub []byte) ([]byte, error) {
+	salt := append(append([]byte{}, ephPub...), recipientPub...)
+	return hkdf.Key(sha256.New, shared, salt, wrapInfo, 32)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func armor(data []byte) string {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	var sb strings.Builder
+	sb.WriteString(armorBegin + "\n")
+	for len(encoded) > armorWidth {
+		sb.WriteString(encoded[:armorWidth] + "\n")
+		encoded = encoded[armorWidth:]
+	}
+	sb.WriteString(encoded + "\n")
+	sb.WriteString(armorEnd + "\n")
+	return sb.String()
+}
+
+func dearmor(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, armorBegin) || !strings.HasSuffix(s, armorEnd) {
+		return nil, errors.New("not an encrypted memory")
+	}
+	body := strings.TrimSuffix(strings.TrimPrefix(s, armorBegin), armorEnd)
+	body = strings.Join(strings.Fields(body), "")
+	data, err := base64.StdEncoding.DecodeString(body)
+	if err != nil {
+		return nil, fmt.Errorf("decoding encrypted memory: %w", err)
+	}
+	return data, nil
+}
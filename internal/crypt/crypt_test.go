@@ -0,0 +1,125 @@
+package crypt
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func mustIdentity(t *testing.T) *Identity {
+	t.Helper()
+	id, err := GenerateIdentity()
+	if err != nil {
+		t.Fatalf("GenerateIdentity: %v", err)
+	}
+	return id
+}
+
+func TestEncryptDecrypt_RoundTrip(t *testing.T) {
+	id := mustIdentity(t)
+	armored, err := Encrypt([]byte("the launch code is in the drawer"), id.Recipient())
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	if !IsEncrypted(armored) {
+		t.Errorf("IsEncrypted = false for %q", armored)
+	}
+	if strings.Contains(armored, "launch") {
+		t.Error("armored output contains plaintext")
+	}
+	for _, line := range strings.Split(strings.TrimSpace(armored), "\n") {
+		if len(line) > armorWidth && !strings.HasPrefix(line, "-----") {
+			t.Errorf("line longer than %d columns: %q", armorWidth, line)
+		}
+	}
+
+	plaintext, err := Decrypt(armored, id)
+	if err != nil {
+		t.Fatalf("Decrypt: %v", err)
+	}
+	if string(plaintext) != "the launch code is in the drawer" {
+		t.Errorf("plaintext = %q", plaintext)
+	}
+}
+
+func TestDecrypt_WrongKey(t *testing.T) {
+	armored, _ := Encrypt([]byte("secret"), mustIdentity(t).Recipient())
+	if _, err := Decrypt(armored, mustIdentity(t)); !errors.Is(err, ErrNoIdentity) {
+		t.Errorf("err = %v, want ErrNoIdentity", err)
+	}
+}
+
+func TestEncrypt_MultipleRecipients(t *testing.T) {
+	alice, bob := mustIdentity(t), mustIdentity(t)
+	armored, err := Encrypt([]byte("shared"), alice.Recipient(), bob.Recipient())
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	for _, id := range []*Identity{alice, bob} {
+		if got, err := Decrypt(armored, id); err != nil || string(got) != "shared" {
+			t.Errorf("Decrypt = %q, %v", got, err)
+		}
+	}
+	kids, err := KeyIDs(armored)
+	if err != nil {
+		t.Fatalf("KeyIDs: %v", err)
+	}
+	if len(kids) != 2 || kids[0] != alice.Recipient().ID() || kids[1] != bob.Recipient().ID() {
+		t.Errorf("KeyIDs = %v", kids)
+	}
+}
+
+func TestDecrypt_Tampered(t *testing.T) {
+	id := mustIdentity(t)
+	armored, _ := Encrypt([]byte("original"), id.Recipient())
+	data, _ := dearmor(armored)
+	data[len(data)-4] ^= 0x01
+	if _, err := Decrypt(armor(data), id); err == nil {
+		t.Error("expected error for tampered ciphertext")
+	}
+}
+
+func TestEncrypt_NoRecipients(t *testing.T) {
+	if _, err := Encrypt([]byte("x")); err == nil {
+		t.Error("expected error without recipients")
+	}
+}
+
+func TestParseIdentity_RoundTrip(t *testing.T) {
+	id := mustIdentity(t)
+	parsed, err := ParseIdentity(id.String())
+	if err != nil {
+		t.Fatalf("ParseIdentity: %v", err)
+	}
+	if parsed.Recipient().String() != id.Recipient().String() {
+		t.Error("parsed identity has a different public key")
+	}
+
+	r, err := ParseRecipient(id.Recipient().String())
+	if err != nil {
+		t.Fatalf("ParseRecipient: %v", err)
+	}
+	if r.ID() != id.Recipient().ID() {
+		t.Errorf("ID = %s, want %s", r.ID(), id.Recipient().ID())
+	}
+}
+
+func TestParseIdentity_Invalid(t *testing.T) {
+	for _, s := range []string{"", "AGE-SECRET-KEY-1ABC", secretPrefix + "!!", secretPrefix + "c2hvcnQ"} {
+		if _, err := ParseIdentity(s); err == nil {
+			t.Errorf("ParseIdentity(%q) succeeded", s)
+		}
+	}
+	if _, err := ParseRecipient(secretPrefix + "abc"); err == nil {
+		t.Error("ParseRecipient accepted a secret key")
+	}
+}
+
+func TestIsEncrypted(t *testing.T) {
+	if IsEncrypted("# Notes\n\nplain text") {
+		t.Error("plain text reported as encrypted")
+	}
+	if _, err := Decrypt("plain text", mustIdentity(t)); err == nil {
+		t.Error("expected error decrypting plain text")
+	}
+}
@@ -0,0 +1,135 @@
+package crypt
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const keyringExt = ".key"
+
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+// Keyring is a named list of identities stored in one file. The last
+// identity is current: new content is encrypted to it, while older ones are
+// kept so memories encrypted before a rotation can still be read.
+type Keyring struct {
+	Name       string
+	Identities []*Identity
+}
+
+// Current returns the identity new content is encrypted to.
+func (k *Keyring) Current() *Identity {
+	return k.Identities[len(k.Identities)-1]
+}
+
+// ValidName reports whether name can be used for a keyring file.
+func ValidName(name string) bool {
+	return validName.MatchString(name)
+}
+
+// KeyringPath returns the file the keyring called name is stored in.
+func KeyringPath(dir, name string) string {
+	return filepath.Join(dir, name+keyringExt)
+}
+
+// LoadKeyring reads the keyring called name from dir.
+func LoadKeyring(dir, name string) (*Keyring, error) {
+	if !ValidName(name) {
+		return nil, fmt.Errorf("invalid key name %q", name)
+	}
+	f, err := os.Open(KeyringPath(dir, name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("key %q not found; run: recuerd0 key generate %s", name, name)
+		}
+		return nil, fmt.Errorf("reading key %q: %w", name, err)
+	}
+	defer f.Close()
+
+	k := &Keyring{Name: name}
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		id, err := ParseIdentity(line)
+		if err != nil {
+			return nil, fmt.Errorf("key %q line %d: %w", name, n, err)
+		}
+		k.Identities = append(k.Identities, id)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading key %q: %w", name, err)
+	}
+	if len(k.Identities) == 0 {
+		return nil, fmt.Errorf("key %q has no secret keys", name)
+	}
+	return k, nil
+}
+
+// SaveKeyring writes k to dir, readable only by the owner.
+func SaveKeyring(dir string, k *Keyring) error {
+	if !ValidName(k.Name) {
+		return fmt.Errorf("invalid key name %q", k.Name)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("creating key directory: %w", err)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# recuerd0 key %q. The last key is current; keep older ones to read old memories.\n", k.Name)
+	for _, id := range k.Identities {
+		r := id.Recipient()
+		fmt.Fprintf(&sb, "# public key: %s (id %s)\n%s\n", r, r.ID(), id)
+	}
+	return os.WriteFile(KeyringPath(dir, k.Name), []byte(sb.String()), 0600)
+}
+
+// KeyringExists reports whether a keyring called name is stored in dir.
+func KeyringExists(dir, name string) bool {
+	_, err := os.Stat(KeyringPath(dir, name))
+	return err == nil
+}
+
+// ListKeyrings returns the names of the keyrings in dir, sorted.
+func ListKeyrings(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []string{}, nil
+		}
+		return nil, err
+	}
+	names := []string{}
+	for _, e := range entries {
+		if name, ok := strings.CutSuffix(e.Name(), keyringExt); ok && !e.IsDir() && ValidName(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// AllIdentities loads every identity from every keyring in dir, so content
+// can be decrypted regardless of which keyring it was written with.
+func AllIdentities(dir string) ([]*Identity, error) {
+	names, err := ListKeyrings(dir)
+	if err != nil {
+		return nil, err
+	}
+	var ids []*Identity
+	for _, name := range names {
+		k, err := LoadKeyring(dir, name)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, k.Identities...)
+	}
+	return ids, nil
+}
@@ -0,0 +1,90 @@
+package crypt
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestKeyring_SaveLoad(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "keys")
+	first, second := mustIdentity(t), mustIdentity(t)
+	k := &Keyring{Name: "team", Identities: []*Identity{first, second}}
+	if err := SaveKeyring(dir, k); err != nil {
+		t.Fatalf("SaveKeyring: %v", err)
+	}
+
+	info, err := os.Stat(filepath.Join(dir, "team.key"))
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, "team.key"))
+	if !strings.Contains(string(data), second.Recipient().String()) {
+		t.Error("key file does not document the public key")
+	}
+
+	loaded, err := LoadKeyring(dir, "team")
+	if err != nil {
+		t.Fatalf("LoadKeyring: %v", err)
+	}
+	if len(loaded.Identities) != 2 {
+		t.Fatalf("identities = %d, want 2", len(loaded.Identities))
+	}
+	if loaded.Current().String() != second.String() {
+		t.Error("current identity should be the last one")
+	}
+	if !KeyringExists(dir, "team") || KeyringExists(dir, "other") {
+		t.Error("KeyringExists mismatch")
+	}
+}
+
+func TestLoadKeyring_Errors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := LoadKeyring(dir, "missing"); err == nil || !strings.Contains(err.Error(), "key generate") {
+		t.Errorf("err = %v, want hint to generate", err)
+	}
+	if _, err := LoadKeyring(dir, "../escape"); err == nil {
+		t.Error("expected error for invalid name")
+	}
+	_ = os.WriteFile(filepath.Join(dir, "empty.key"), []byte("# nothing\n"), 0600)
+	if _, err := LoadKeyring(dir, "empty"); err == nil {
+		t.Error("expected error for key file without keys")
+	}
+	_ = os.WriteFile(filepath.Join(dir, "bad.key"), []byte("not a key\n"), 0600)
+	if _, err := LoadKeyring(dir, "bad"); err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("err = %v, want line number", err)
+	}
+}
+
+func TestListKeyrings_AllIdentities(t *testing.T) {
+	dir := t.TempDir()
+	if names, err := ListKeyrings(filepath.Join(dir, "none")); err != nil || len(names) != 0 {
+		t.Errorf("ListKeyrings(missing) = %v, %v", names, err)
+	}
+
+	a, b := mustIdentity(t), mustIdentity(t)
+	_ = SaveKeyring(dir, &Keyring{Name: "work", Identities: []*Identity{a}})
+	_ = SaveKeyring(dir, &Keyring{Name: "personal", Identities: []*Identity{b}})
+	_ = os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0600)
+
+	names, err := ListKeyrings(dir)
+	if err != nil {
+		t.Fatalf("ListKeyrings: %v", err)
+	}
+	if strings.Join(names, ",") != "personal,work" {
+		t.Errorf("names = %v", names)
+	}
+
+	ids, err := AllIdentities(dir)
+	if err != nil {
+		t.Fatalf("AllIdentities: %v", err)
+	}
+	armored, _ := Encrypt([]byte("hi"), a.Recipient())
+	if got, err := Decrypt(armored, ids...); err != nil || string(got) != "hi" {
+		t.Errorf("Decrypt with all identities = %q, %v", got, err)
+	}
+}
@@ -27,6 +27,26 @@ const (
 	CodeRateLimited = "RATE_LIMITED"
 )
 
+// ExitCodeInfo describes one exit code and the error code reported with it.
+type ExitCodeInfo struct {
+	ExitCode    int    `json:"exit_code"`
+	ErrorCode   string `json:"error_code,omitempty"`
+	Description string `json:"description"`
+}
+
+// ExitCodes lists every exit code the CLI uses, in order.
+var ExitCodes = []ExitCodeInfo{
+	{ExitSuccess, "", "Success"},
+	{ExitError, CodeError, "General error"},
+	{ExitInvalidArgs, CodeInvalidArgs, "Invalid arguments"},
+	{ExitAuthFailure, CodeAuth, "Authentication error"},
+	{ExitForbidden, CodeForbidden, "Forbidden"},
+	{ExitNotFound, CodeNotFound, "Not found"},
+	{ExitValidation, CodeValidation, "Validation error"},
+	{ExitNetwork, CodeNetwork, "Network error"},
+	{ExitRateLimited, CodeRateLimited, "Rate limited"},
+}
+
 // CLIError represents a typed CLI error with exit code and HTTP status.
 type CLIError struct {
 	Code     string `json:"code"`
@@ -0,0 +1,355 @@
+// Package lineedit reads lines from a terminal with editing keys, history
+// and tab completion, in the spirit of readline. When input is not a
+// terminal, or raw mode is unsupported, lines are read as plain text.
+package lineedit
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/maquina/recuerd0-cli/internal/term"
+)
+
+// ErrInterrupt is returned when the user presses Ctrl-C.
+var ErrInterrupt = errors.New("interrupted")
+
+// CompleteFunc returns completions for the text before the cursor: the
+// byte offset where the word being completed starts, and the candidates
+// that may replace it.
+type CompleteFunc func(line string) (start int, candidates []string)
+
+// Editor reads lines from In, echoing to Out.
+type Editor struct {
+	Prompt   string
+	Complete CompleteFunc
+	// History holds previous lines, oldest first.
+	History    []string
+	MaxHistory int
+
+	// Fd is the terminal file descriptor of In, or -1 to read plain lines.
+	Fd  int
+	In  io.Reader
+	Out io.Writer
+
+	reader *bufio.Reader
+
+	buf  []rune
+	pos  int
+	hist int
+	// saved keeps the line being typed while browsing history.
+	saved   []rune
+	lastTab bool
+}
+
+// New returns an editor on the given input and output. fd is the terminal
+// descriptor of in, or -1 when in is not a terminal.
+func New(in io.Reader, out io.Writer, fd int) *Editor {
+	return &Editor{In: in, Out: out, Fd: fd, MaxHistory: 1000}
+}
+
+// ReadLine shows the prompt and returns the line entered, without the
+// newline. It returns io.EOF at end of input or on Ctrl-D at an empty
+// line, and ErrInterrupt on Ctrl-C.
+func (e *Editor) ReadLine() (string, error) {
+	if e.reader == nil {
+		e.reader = bufio.NewReader(e.In)
+	}
+	if e.Fd < 0 || !term.IsTerminal(e.Fd) {
+		return e.readPlain()
+	}
+	state, err := term.MakeRaw(e.Fd)
+	if err != nil {
+		return e.readPlain()
+	}
+	defer term.Restore(e.Fd, state)
+	return e.edit()
+}
+
+// AddHistory appends line to the history, skipping blanks and repeats.
+func (e *Editor) AddHistory(line string) {
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	if n := len(e.History); n > 0 && e.History[n-1] == line {
+		return
+	}
+	e.History = append(e.History, line)
+	if e.MaxHistory > 0 && len(e.History) > e.MaxHistory {
+		e.History = e.History[len(e.History)-e.MaxHistory:]
+	}
+}
+
+func (e *Editor) readPlain() (string, error) {
+	fmt.Fprint(e.Out, e.Prompt)
+	line, err := e.reader.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+const (
+	keyCtrlA     = 1
+	keyCtrlB     = 2
+	keyCtrlC     = 3
+	keyCtrlD     = 4
+	keyCtrlE     = 5
+	keyCtrlF     = 6
+	keyBackspace = 8
+	keyTab       = 9
+	keyLF        = 10
+	keyCtrlK     = 11
+	keyCtrlL     = 12
+	keyCR        = 13
+	keyCtrlN     = 14
+	keyCtrlP     = 16
+	keyCtrlU     = 21
+	keyCtrlW     = 23
+	keyEscape    = 27
+	keyDelete    = 127
+)
+
+// edit runs the raw-mode key loop.
+func (e *Editor) edit() (string, error) {
+	e.buf, e.pos, e.hist, e.saved, e.lastTab = nil, 0, len(e.History), nil, false
+	e.refresh()
+	for {
+		b, err := e.reader.ReadByte()
+		if err != nil {
+			e.write("\r\n")
+			if err == io.EOF && len(e.buf) > 0 {
+				return string(e.buf), nil
+			}
+			return "", err
+		}
+		tab := false
+		switch b {
+		case keyCR, keyLF:
+			e.write("\r\n")
+			return string(e.buf), nil
+		case keyCtrlC:
+			e.write("^C\r\n")
+			return "", ErrInterrupt
+		case keyCtrlD:
+			if len(e.buf) == 0 {
+				e.write("\r\n")
+				return "", io.EOF
+			}
+			e.deleteAt(e.pos)
+		case keyBackspace, keyDelete:
+			if e.pos > 0 {
+				e.pos--
+				e.deleteAt(e.pos)
+			}
+		case keyCtrlA:
+			e.pos = 0
+		case keyCtrlE:
+			e.pos = len(e.buf)
+		case keyCtrlB:
+			e.left()
+		case keyCtrlF:
+			e.right()
+		case keyCtrlK:
+			e.buf = e.buf[:e.pos]
+		case keyCtrlU:
+			e.buf = append([]rune{}, e.buf[e.pos:]...)
+			e.pos = 0
+		case keyCtrlW:
+			start := e.pos
+			for start > 0 && e.buf[start-1] == ' ' {
+				start--
+			}
+			for start > 0 && e.buf[start-1] != ' ' {
+				start--
+			}
+			e.buf = append(e.buf[:start], e.buf[e.pos:]...)
+			e.pos = start
+		case keyCtrlL:
+			e.write("\x1b[H\x1b[2J")
+		case keyCtrlP:
+			e.historyMove(-1)
+		case keyCtrlN:
+			e.historyMove(1)
+		case keyTab:
+			tab = true
+			e.complete()
+		case keyEscape:
+			e.escape()
+		default:
+			if b < 32 {
+				break
+			}
+			r, err := e.readRune(b)
+			if err != nil {
+				return "", err
+			}
+			e.insert(r)
+		}
+		e.lastTab = tab
+		e.refresh()
+	}
+}
+
+// readRune completes a UTF-8 sequence that starts with b.
+func (e *Editor) readRune(b byte) (rune, error) {
+	if b < utf8.RuneSelf {
+		return rune(b), nil
+	}
+	seq := []byte{b}
+	for !utf8.FullRune(seq) && len(seq) < utf8.UTFMax {
+		next, err := e.reader.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		seq = append(seq, next)
+	}
+	r, _ := utf8.DecodeRune(seq)
+	return r, nil
+}
+
+// escape handles arrow, Home, End and Delete key sequences.
+func (e *Editor) escape() {
+	b, err := e.reader.ReadByte()
+	if err != nil || (b != '[' && b != 'O') {
+		return
+	}
+	b, err = e.reader.ReadByte()
+	if err != nil {
+		return
+	}
+	switch b {
+	case 'A':
+		e.historyMove(-1)
+	case 'B':
+		e.historyMove(1)
+	case 'C':
+		e.right()
+	case 'D':
+		e.left()
+	case 'H':
+		e.pos = 0
+	case 'F':
+		e.pos = len(e.buf)
+	default:
+		if b < '0' || b > '9' {
+			return
+		}
+		code := []byte{b}
+		for {
+			b, err = e.reader.ReadByte()
+			if err != nil || b == '~' {
+				break
+			}
+			code = append(code, b)
+		}
+		switch string(code) {
+		case "1", "7":
+			e.pos = 0
+		case "4", "8":
+			e.pos = len(e.buf)
+		case "3":
+			e.deleteAt(e.pos)
+		}
+	}
+}
+
+func (e *Editor) left() {
+	if e.pos > 0 {
+		e.pos--
+	}
+}
+
+func (e *Editor) right() {
+	if e.pos < len(e.buf) {
+		e.pos++
+	}
+}
+
+func (e *Editor) insert(r ...rune) {
+	tail := append([]rune{}, e.buf[e.pos:]...)
+	e.buf = append(append(e.buf[:e.pos], r...), tail...)
+	e.pos += len(r)
+}
+
+func (e *Editor) deleteAt(i int) {
+	if i < len(e.buf) {
+		e.buf = append(e.buf[:i], e.buf[i+1:]...)
+	}
+}
+
+func (e *Editor) historyMove(delta int) {
+	next := e.hist + delta
+	if next < 0 || next > len(e.History) {
+		return
+	}
+	if e.hist == len(e.History) {
+		e.saved = append([]rune{}, e.buf...)
+	}
+	e.hist = next
+	if next == len(e.History) {
+		e.buf = append([]rune{}, e.saved...)
+	} else {
+		e.buf = []rune(e.History[next])
+	}
+	e.pos = len(e.buf)
+}
+
+// complete replaces the word before the cursor with its single completion
+// or the candidates' common prefix. A second Tab without progress lists
+// the candidates.
+func (e *Editor) complete() {
+	if e.Complete == nil {
+		return
+	}
+	before := string(e.buf[:e.pos])
+	start, candidates := e.Complete(before)
+	if len(candidates) == 0 || start < 0 || start > len(before) {
+		return
+	}
+	word := before[start:]
+	replacement := candidates[0]
+	if len(candidates) == 1 {
+		replacement += " "
+	} else {
+		replacement = commonPrefix(candidates)
+	}
+	if len(replacement) > len(word) || len(candidates) == 1 {
+		e.buf = append([]rune(before[:start]+replacement), e.buf[e.pos:]...)
+		e.pos = utf8.RuneCountInString(before[:start] + replacement)
+		return
+	}
+	if e.lastTab {
+		sorted := append([]string{}, candidates...)
+		sort.Strings(sorted)
+		e.write("\r\n" + strings.Join(sorted, "  ") + "\r\n")
+	}
+}
+
+func commonPrefix(words []string) string {
+	prefix := words[0]
+	for _, w := range words[1:] {
+		for !strings.HasPrefix(w, prefix) {
+			_, size := utf8.DecodeLastRuneInString(prefix)
+			prefix = prefix[:len(prefix)-size]
+		}
+	}
+	return prefix
+}
+
+// refresh redraws the prompt and line and places the cursor.
+func (e *Editor) refresh() {
+	s := "\r" + e.Prompt + string(e.buf) + "\x1b[K"
+	if back := len(e.buf) - e.pos; back > 0 {
+		s += fmt.Sprintf("\x1b[%dD", back)
+	}
+	e.write(s)
+}
+
+func (e *Editor) write(s string) {
+	_, _ = io.WriteString(e.Out, s)
+}
@@ -0,0 +1,115 @@
+package lineedit
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+// editKeys runs the raw-mode key loop on keys, as if typed.
+func editKeys(e *Editor, keys string) (string, error) {
+	e.reader = bufio.NewReader(strings.NewReader(keys))
+	if e.Out == nil {
+		e.Out = io.Discard
+	}
+	return e.edit()
+}
+
+func TestEdit_Keys(t *testing.T) {
+	tests := []struct {
+		name, keys, want string
+	}{
+		{"plain", "memory list\r", "memory list"},
+		{"backspace", "memx\x7fory\r", "memory"},
+		{"arrows insert", "mory\x1b[D\x1b[D\x1b[D\x1b[Dme\r", "memory"},
+		{"home and end", "emor\x01m\x05y\r", "memory"},
+		{"delete key", "memoryx\x1b[D\x1b[3~\r", "memory"},
+		{"kill to end", "memory list\x01\x1b[C\x1b[C\x1b[C\x1b[C\x1b[C\x1b[C\x0b\r", "memory"},
+		{"delete word", "memory list\x17\r", "memory "},
+		{"utf-8", "caf\xc3\xa9\x1b[Dx\r", "cafxé"},
+	}
+	for _, tt := range tests {
+		got, err := editKeys(New(nil, nil, -1), tt.keys)
+		if err != nil || got != tt.want {
+			t.Errorf("%s: got %q (%v), want %q", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func TestEdit_EndOfInput(t *testing.T) {
+	if _, err := editKeys(New(nil, nil, -1), "\x04"); err != io.EOF {
+		t.Errorf("expected io.EOF on Ctrl-D, got %v", err)
+	}
+	if _, err := editKeys(New(nil, nil, -1), "abc\x03"); err != ErrInterrupt {
+		t.Errorf("expected ErrInterrupt on Ctrl-C, got %v", err)
+	}
+}
+
+func TestEdit_History(t *testing.T) {
+	e := New(nil, nil, -1)
+	e.AddHistory("first")
+	e.AddHistory("second")
+	e.AddHistory("second")
+	e.AddHistory("  ")
+	if len(e.History) != 2 {
+		t.Fatalf("expected blanks and repeats to be skipped, got %q", e.History)
+	}
+
+	got, _ := editKeys(e, "\x1b[A\x1b[A\r")
+	if got != "first" {
+		t.Errorf("expected two ups to reach the oldest line, got %q", got)
+	}
+	got, _ = editKeys(e, "draft\x1b[A\x1b[B\r")
+	if got != "draft" {
+		t.Errorf("expected down to restore the line being typed, got %q", got)
+	}
+}
+
+func TestEdit_Complete(t *testing.T) {
+	words := []string{"memory", "memories", "search"}
+	complete := func(line string) (int, []string) {
+		start := strings.LastIndex(line, " ") + 1
+		var out []string
+		for _, w := range words {
+			if strings.HasPrefix(w, line[start:]) {
+				out = append(out, w)
+			}
+		}
+		return start, out
+	}
+
+	e := New(nil, nil, -1)
+	e.Complete = complete
+	if got, _ := editKeys(e, "se\t\r"); got != "search " {
+		t.Errorf("expected a single completion with a space, got %q", got)
+	}
+	if got, _ := editKeys(e, "x me\t\r"); got != "x memor" {
+		t.Errorf("expected the common prefix, got %q", got)
+	}
+
+	var out bytes.Buffer
+	e.Out = &out
+	editKeys(e, "memor\t\t\r")
+	if !strings.Contains(out.String(), "memories  memory") {
+		t.Errorf("expected a second Tab to list candidates, got %q", out.String())
+	}
+}
+
+func TestReadLine_Plain(t *testing.T) {
+	var out bytes.Buffer
+	e := New(strings.NewReader("one\r\ntwo"), &out, -1)
+	e.Prompt = "> "
+	for _, want := range []string{"one", "two"} {
+		if got, err := e.ReadLine(); err != nil || got != want {
+			t.Errorf("got %q (%v), want %q", got, err, want)
+		}
+	}
+	if _, err := e.ReadLine(); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+	if out.String() != "> > > " {
+		t.Errorf("expected a prompt per line, got %q", out.String())
+	}
+}
@@ -0,0 +1,203 @@
+package markdown
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// language describes what the highlighter colors in one language.
+type language struct {
+	keywords     []string
+	lineComments []string
+	blockComment [2]string
+	quotes       string
+	ignoreCase   bool
+}
+
+var (
+	cLike = language{lineComments: []string{"//"}, blockComment: [2]string{"/*", "*/"}, quotes: `"'`}
+	hash  = language{lineComments: []string{"#"}, quotes: `"'`}
+)
+
+var languages = map[string]language{
+	"go":     withKeywords(cLike, "break case chan const continue default defer else fallthrough for func go goto if import interface map package range return select struct switch type var nil true false", "`"),
+	"js":     withKeywords(cLike, "async await break case catch class const continue default delete do else export extends false finally for from function if import in instanceof let new null of return static super switch this throw true try typeof undefined var void while yield", "`"),
+	"ts":     withKeywords(cLike, "abstract any as async await boolean break case catch class const continue declare default do else enum export extends false finally for from function if implements import in interface let new null number private protected public readonly return static string super switch this throw true try type typeof undefined var void while", "`"),
+	"rust":   withKeywords(cLike, "as async await break const continue crate else enum extern false fn for if impl in let loop match mod move mut pub ref return self Self static struct super trait true type unsafe use where while", ""),
+	"java":   withKeywords(cLike, "abstract boolean break byte case catch char class continue default do double else enum extends false final finally float for if implements import instanceof int interface long new null package private protected public return short static super switch this throw throws true try void while", ""),
+	"c":      withKeywords(cLike, "auto break case char const continue default do double else enum extern float for goto if int long return short signed sizeof static struct switch typedef union unsigned void while NULL", ""),
+	"python": withKeywords(hash, "and as assert async await break class continue def del elif else except False finally for from global if import in is lambda None nonlocal not or pass raise return True try while with yield", ""),
+	"ruby":   withKeywords(hash, "alias and begin break case class def do else elsif end ensure false for if in module next nil not or redo rescue retry return self super then true undef unless until when while yield", ""),
+	"sh":     withKeywords(hash, "case do done elif else esac export fi for function if in local return then until while", ""),
+	"yaml":   withKeywords(hash, "true false null yes no", ""),
+	"sql": withKeywords(language{lineComments: []string{"--"}, blockComment: [2]string{"/*", "*/"}, quotes: `'"`, ignoreCase: true},
+		"add all alter and as asc begin between by case commit create delete desc distinct drop else end exists from group having in index insert into is join key left like limit not null on or order primary references right rollback select set table then union unique update values when where with", ""),
+	"json": {quotes: `"`, keywords: []string{"true", "false", "null"}},
+}
+
+var languageAliases = map[string]string{
+	"golang": "go", "javascript": "js", "jsx": "js", "typescript": "ts", "tsx": "ts", "rs": "rust",
+	"kotlin": "java", "cpp": "c", "c++": "c", "h": "c", "cs": "java", "csharp": "java",
+	"py": "python", "rb": "ruby", "bash": "sh", "shell": "sh", "zsh": "sh", "console": "sh",
+	"yml": "yaml", "toml": "yaml", "postgres": "sql", "postgresql": "sql", "mysql": "sql", "sqlite": "sql",
+}
+
+func withKeywords(l language, keywords, extraQuotes string) language {
+	l.keywords = strings.Fields(keywords)
+	l.quotes += extraQuotes
+	return l
+}
+
+// highlighter colors code of one language, remembering open block
+// comments across lines.
+type highlighter struct {
+	lang      *language
+	inComment bool
+}
+
+// newHighlighter returns a highlighter for a fence's info string, with no
+// language when it is unknown.
+func newHighlighter(info string) *highlighter {
+	name := strings.ToLower(strings.TrimSpace(info))
+	if i := strings.IndexAny(name, " {,"); i >= 0 {
+		name = name[:i]
+	}
+	if alias, ok := languageAliases[name]; ok {
+		name = alias
+	}
+	if l, ok := languages[name]; ok {
+		return &highlighter{lang: &l}
+	}
+	return &highlighter{}
+}
+
+// highlight styles one line of code. Without color, or for an unknown
+// language, the line is returned as it is, or all in the code style.
+func (r *renderer) highlight(h *highlighter, line string) string {
+	if !r.opts.Color || line == "" {
+		return line
+	}
+	if h.lang == nil {
+		return r.style(line, styleCode)
+	}
+	var b strings.Builder
+	for _, tok := range h.tokens(line) {
+		b.WriteString(r.style(tok.text, tok.style))
+	}
+	return b.String()
+}
+
+// tokens splits a line into comments, strings, numbers, keywords and
+// plain text.
+func (h *highlighter) tokens(line string) []span {
+	l := h.lang
+	var out []span
+	plain := 0
+	emit := func(start, end int, s style) {
+		if plain < start {
+			out = append(out, span{text: line[plain:start]})
+		}
+		out = append(out, span{text: line[start:end], style: s})
+		plain = end
+	}
+
+	i := 0
+	if h.inComment {
+		end := strings.Index(line, l.blockComment[1])
+		if end < 0 {
+			return []span{{text: line, style: styleComment}}
+		}
+		h.inComment = false
+		i = end + len(l.blockComment[1])
+		emit(0, i, styleComment)
+	}
+	for i < len(line) {
+		rest := line[i:]
+		if l.blockComment[0] != "" && strings.HasPrefix(rest, l.blockComment[0]) {
+			end := strings.Index(rest[len(l.blockComment[0]):], l.blockComment[1])
+			if end < 0 {
+				h.inComment = true
+				emit(i, len(line), styleComment)
+				break
+			}
+			n := len(l.blockComment[0]) + end + len(l.blockComment[1])
+			emit(i, i+n, styleComment)
+			i += n
+			continue
+		}
+		if isLineComment(l, line, i) {
+			emit(i, len(line), styleComment)
+			break
+		}
+		c := line[i]
+		if strings.IndexByte(l.quotes, c) >= 0 {
+			j := i + 1
+			for j < len(line) && line[j] != c {
+				if line[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			j = min(j+1, len(line))
+			emit(i, j, styleString)
+			i = j
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(rest)
+		switch {
+		case unicode.IsDigit(r):
+			j := i + 1
+			for j < len(line) && (isWordByte(line[j]) || line[j] == '.') {
+				j++
+			}
+			emit(i, j, styleNumber)
+			i = j
+		case unicode.IsLetter(r) || r == '_':
+			j := i + size
+			for j < len(line) {
+				r, size := utf8.DecodeRuneInString(line[j:])
+				if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+					break
+				}
+				j += size
+			}
+			if h.isKeyword(line[i:j]) {
+				emit(i, j, styleKeyword)
+			}
+			i = j
+		default:
+			i += size
+		}
+	}
+	if plain < len(line) {
+		out = append(out, span{text: line[plain:]})
+	}
+	return out
+}
+
+func isLineComment(l *language, line string, i int) bool {
+	for _, p := range l.lineComments {
+		if strings.HasPrefix(line[i:], p) {
+			// A # inside a word, as in a URL fragment, is not a comment.
+			return p != "#" || i == 0 || line[i-1] == ' ' || line[i-1] == '\t'
+		}
+	}
+	return false
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func (h *highlighter) isKeyword(word string) bool {
+	if h.lang.ignoreCase {
+		word = strings.ToLower(word)
+	}
+	for _, k := range h.lang.keywords {
+		if k == word {
+			return true
+		}
+	}
+	return false
+}
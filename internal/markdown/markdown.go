@@ -0,0 +1,612 @@
+// Package markdown renders Markdown memories as text for a terminal:
+// headings, lists, quotes, code blocks and inline emphasis, wrapped to a
+// width and optionally styled with ANSI escape codes.
+package markdown
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/maquina/recuerd0-cli/internal/term"
+)
+
+// Options control rendering.
+type Options struct {
+	// Width is the column to wrap at; zero or less disables wrapping.
+	Width int
+	// Color styles the output with ANSI escape codes. Without it, markup
+	// is dropped and headings are underlined with = and -.
+	Color bool
+}
+
+// Render returns src rendered as terminal text, one line per "\n".
+func Render(src string, opts Options) string {
+	r := &renderer{opts: opts}
+	r.blocks(splitLines(src), "", "")
+	for len(r.out) > 0 && isBlank(r.out[len(r.out)-1]) {
+		r.out = r.out[:len(r.out)-1]
+	}
+	return strings.Join(r.out, "\n")
+}
+
+func splitLines(src string) []string {
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	src = strings.ReplaceAll(src, "\t", "    ")
+	return strings.Split(src, "\n")
+}
+
+type renderer struct {
+	opts Options
+	out  []string
+	// indent prefixes lines inside the current list item or quote.
+	indent string
+}
+
+// emit appends a rendered line.
+func (r *renderer) emit(line string) {
+	r.out = append(r.out, line)
+}
+
+// blank separates blocks with a single empty line, keeping the indent
+// of the enclosing quote.
+func (r *renderer) blank() {
+	if n := len(r.out); n > 0 && !isBlank(r.out[n-1]) {
+		r.out = append(r.out, strings.TrimRight(r.indent, " "))
+	}
+}
+
+// isBlank reports whether a rendered line holds nothing but indent and
+// quote bars.
+func isBlank(line string) bool {
+	return strings.Trim(term.StripANSI(line), " │") == ""
+}
+
+// blocks renders lines as a sequence of blocks. first prefixes the first
+// output line and rest every following one, which is how list items and
+// quotes indent their contents.
+func (r *renderer) blocks(lines []string, first, rest string) {
+	saved := r.indent
+	r.indent = rest
+	defer func() { r.indent = saved }()
+	prefix := func() string {
+		p := first
+		first = rest
+		return p
+	}
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			i++
+			if i < len(lines) {
+				r.blank()
+			}
+		case isFence(trimmed):
+			i = r.code(lines, i, prefix)
+		case headingLevel(trimmed) > 0:
+			r.heading(trimmed, prefix())
+			i++
+		case isRule(trimmed):
+			r.rule(prefix())
+			i++
+		case strings.HasPrefix(trimmed, ">"):
+			i = r.quote(lines, i, prefix(), rest)
+		case listMarker(line) != nil:
+			i = r.list(lines, i, prefix, rest)
+		case isTableStart(lines, i):
+			i = r.table(lines, i, prefix)
+		default:
+			i = r.paragraph(lines, i, prefix(), rest)
+		}
+	}
+}
+
+func isFence(s string) bool {
+	return strings.HasPrefix(s, "```") || strings.HasPrefix(s, "~~~")
+}
+
+func headingLevel(s string) int {
+	n := 0
+	for n < len(s) && s[n] == '#' {
+		n++
+	}
+	if n == 0 || n > 6 || (n < len(s) && s[n] != ' ') {
+		return 0
+	}
+	return n
+}
+
+func isRule(s string) bool {
+	s = strings.ReplaceAll(s, " ", "")
+	if len(s) < 3 {
+		return false
+	}
+	return strings.Trim(s, "-") == "" || strings.Trim(s, "*") == "" || strings.Trim(s, "_") == ""
+}
+
+func (r *renderer) code(lines []string, i int, prefix func() string) int {
+	info := strings.TrimSpace(lines[i])
+	fence := info[:3]
+	hl := newHighlighter(strings.TrimLeft(info, fence[:1]))
+	indent := len(lines[i]) - len(strings.TrimLeft(lines[i], " "))
+	r.blank()
+	i++
+	for ; i < len(lines); i++ {
+		if strings.HasPrefix(strings.TrimSpace(lines[i]), fence) {
+			i++
+			break
+		}
+		line := lines[i]
+		if n := len(line) - len(strings.TrimLeft(line, " ")); n > 0 {
+			line = line[min(n, indent):]
+		}
+		r.emit(prefix() + "  " + r.highlight(hl, line))
+	}
+	r.blank()
+	return i
+}
+
+func (r *renderer) heading(s string, prefix string) {
+	level := headingLevel(s)
+	text := strings.TrimSpace(strings.TrimRight(strings.TrimSpace(s[level:]), "#"))
+	r.blank()
+	spans := parseInline(text)
+	if !r.opts.Color {
+		lines := r.wrap(spans, prefix, prefix)
+		for _, l := range lines {
+			r.emit(l)
+		}
+		if level <= 2 {
+			mark := "="
+			if level == 2 {
+				mark = "-"
+			}
+			width := 0
+			for _, l := range lines {
+				width = max(width, term.StringWidth(l)-term.StringWidth(prefix))
+			}
+			r.emit(prefix + strings.Repeat(mark, width))
+		}
+	} else {
+		for j := range spans {
+			spans[j].style |= styleHeading
+			if level == 1 {
+				spans[j].style |= styleUnderline
+			}
+		}
+		for _, l := range r.wrap(spans, prefix, prefix) {
+			r.emit(l)
+		}
+	}
+	r.blank()
+}
+
+func (r *renderer) rule(prefix string) {
+	width := 40
+	if r.opts.Width > 0 {
+		width = max(r.opts.Width-term.StringWidth(prefix), 3)
+	}
+	r.blank()
+	r.emit(prefix + r.style(strings.Repeat("─", width), styleDim))
+	r.blank()
+}
+
+func (r *renderer) quote(lines []string, i int, prefix, rest string) int {
+	var inner []string
+	for ; i < len(lines); i++ {
+		t := strings.TrimSpace(lines[i])
+		if !strings.HasPrefix(t, ">") {
+			break
+		}
+		t = strings.TrimPrefix(t, ">")
+		inner = append(inner, strings.TrimPrefix(t, " "))
+	}
+	bar := r.style("│", styleDim) + " "
+	r.blank()
+	r.blocks(inner, prefix+bar, rest+bar)
+	r.blank()
+	return i
+}
+
+// marker describes a list item's bullet.
+type marker struct {
+	indent  int // spaces before the marker
+	width   int // marker and the space after it
+	ordered bool
+	label   string
+}
+
+func listMarker(line string) *marker {
+	indent := len(line) - len(strings.TrimLeft(line, " "))
+	s := line[indent:]
+	if len(s) >= 2 && strings.ContainsRune("-*+", rune(s[0])) && s[1] == ' ' {
+		return &marker{indent: indent, width: 2, label: "•"}
+	}
+	n := 0
+	for n < len(s) && n < 9 && s[n] >= '0' && s[n] <= '9' {
+		n++
+	}
+	if n > 0 && n+1 < len(s) && (s[n] == '.' || s[n] == ')') && s[n+1] == ' ' {
+		return &marker{indent: indent, width: n + 2, ordered: true, label: s[:n+1]}
+	}
+	return nil
+}
+
+// list renders consecutive items at the first item's indent. Lines
+// indented past the marker belong to the item and are rendered as nested
+// blocks.
+func (r *renderer) list(lines []string, i int, prefix func() string, rest string) int {
+	base := listMarker(lines[i]).indent
+	for i < len(lines) {
+		m := listMarker(lines[i])
+		if m == nil || m.indent != base {
+			break
+		}
+		body := []string{lines[i][m.indent+m.width:]}
+		i++
+		for ; i < len(lines); i++ {
+			line := lines[i]
+			t := strings.TrimSpace(line)
+			if t == "" {
+				// A blank line continues the item only if indented text follows.
+				if i+1 < len(lines) && indentOf(lines[i+1]) > base && strings.TrimSpace(lines[i+1]) != "" {
+					body = append(body, "")
+					continue
+				}
+				break
+			}
+			if next := listMarker(line); next != nil && next.indent <= base {
+				break
+			}
+			if indentOf(line) <= base && (isFence(t) || headingLevel(t) > 0 || strings.HasPrefix(t, ">")) {
+				break
+			}
+			body = append(body, strings.TrimPrefix(line, strings.Repeat(" ", min(indentOf(line), m.indent+m.width))))
+		}
+
+		label := m.label
+		if !m.ordered {
+			switch {
+			case strings.HasPrefix(body[0], "[ ] "):
+				label, body[0] = "☐", body[0][4:]
+			case strings.HasPrefix(body[0], "[x] "), strings.HasPrefix(body[0], "[X] "):
+				label, body[0] = "☑", body[0][4:]
+			}
+		}
+		bullet := label + " "
+		if !m.ordered {
+			bullet = r.style(label, styleBullet) + " "
+		}
+		pad := strings.Repeat(" ", term.StringWidth(label)+1)
+		r.blocks(body, prefix()+bullet, rest+pad)
+	}
+	return i
+}
+
+func indentOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// paragraph joins lines up to the next blank line or block and wraps
+// them. A line ending in two spaces or a backslash forces a break.
+func (r *renderer) paragraph(lines []string, i int, prefix, rest string) int {
+	var spans []span
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		t := strings.TrimSpace(line)
+		if t == "" || (len(spans) > 0 && startsBlock(line)) || isTableStart(lines, i) {
+			break
+		}
+		if len(spans) > 0 {
+			spans = append(spans, span{text: " "})
+		}
+		hard := strings.HasSuffix(line, "  ") || strings.HasSuffix(t, "\\")
+		spans = append(spans, parseInline(strings.TrimSuffix(t, "\\"))...)
+		if hard {
+			spans = append(spans, span{text: "\n"})
+		}
+	}
+	for _, l := range r.wrap(spans, prefix, rest) {
+		r.emit(l)
+	}
+	return i
+}
+
+func startsBlock(line string) bool {
+	t := strings.TrimSpace(line)
+	return isFence(t) || headingLevel(t) > 0 || strings.HasPrefix(t, ">") || listMarker(line) != nil || isRule(t)
+}
+
+// wrap fills lines with the words of spans, breaking at the width and at
+// "\n" spans. Words longer than a line are put on a line of their own.
+func (r *renderer) wrap(spans []span, first, rest string) []string {
+	return r.wrapTo(spans, r.opts.Width, first, rest)
+}
+
+// wrapTo is wrap with an explicit width, used for table cells.
+func (r *renderer) wrapTo(spans []span, width int, first, rest string) []string {
+	type piece = span
+	var words [][]piece
+	var cur []piece
+	breaks := map[int]bool{}
+	flush := func() {
+		if len(cur) > 0 {
+			words = append(words, cur)
+			cur = nil
+		}
+	}
+	for _, s := range spans {
+		if s.text == "\n" {
+			flush()
+			breaks[len(words)] = true
+			continue
+		}
+		start := 0
+		for j, c := range s.text {
+			if c == ' ' {
+				if j > start {
+					cur = append(cur, piece{text: s.text[start:j], style: s.style, url: s.url})
+				}
+				flush()
+				start = j + 1
+			}
+		}
+		if start < len(s.text) {
+			cur = append(cur, piece{text: s.text[start:], style: s.style, url: s.url})
+		}
+	}
+	flush()
+
+	var lines []string
+	line, lineWidth, prefix := "", 0, first
+	for i, w := range words {
+		ww := 0
+		for _, p := range w {
+			ww += term.StringWidth(p.text)
+		}
+		limit := width - term.StringWidth(prefix)
+		if lineWidth > 0 && (breaks[i] || (width > 0 && lineWidth+1+ww > limit)) {
+			lines = append(lines, prefix+line)
+			line, lineWidth, prefix = "", 0, rest
+		}
+		if lineWidth > 0 {
+			line += " "
+			lineWidth++
+		}
+		for _, p := range w {
+			line += r.style(p.text, p.style)
+		}
+		lineWidth += ww
+	}
+	if lineWidth > 0 || len(lines) == 0 {
+		lines = append(lines, strings.TrimRight(prefix+line, " "))
+	}
+	return lines
+}
+
+type style uint16
+
+const (
+	styleBold style = 1 << iota
+	styleItalic
+	styleUnderline
+	styleCode
+	styleLink
+	styleHeading
+	styleDim
+	styleBullet
+	styleStrike
+	styleKeyword
+	styleString
+	styleComment
+	styleNumber
+)
+
+// sgr returns the ANSI Select Graphic Rendition parameters for s.
+func (s style) sgr() string {
+	var p []string
+	if s&(styleBold|styleHeading) != 0 {
+		p = append(p, "1")
+	}
+	if s&(styleDim|styleComment) != 0 {
+		p = append(p, "2")
+	}
+	if s&(styleItalic|styleComment) != 0 {
+		p = append(p, "3")
+	}
+	if s&(styleUnderline|styleLink) != 0 {
+		p = append(p, "4")
+	}
+	if s&styleStrike != 0 {
+		p = append(p, "9")
+	}
+	switch {
+	case s&styleHeading != 0:
+		p = append(p, "35")
+	case s&styleKeyword != 0:
+		p = append(p, "34")
+	case s&styleString != 0:
+		p = append(p, "32")
+	case s&styleNumber != 0:
+		p = append(p, "33")
+	case s&styleComment != 0:
+	case s&styleCode != 0:
+		p = append(p, "36")
+	case s&styleLink != 0:
+		p = append(p, "34")
+	case s&styleBullet != 0:
+		p = append(p, "33")
+	}
+	return strings.Join(p, ";")
+}
+
+func (r *renderer) style(text string, s style) string {
+	if !r.opts.Color || s == 0 || text == "" {
+		return text
+	}
+	return "\x1b[" + s.sgr() + "m" + text + "\x1b[0m"
+}
+
+// span is a run of inline text with one style.
+type span struct {
+	text  string
+	style style
+	url   string
+}
+
+// parseInline splits text into styled spans: **bold**, *italic*, `code`,
+// ~~strike~~, [links](url) and images, whose alt text is kept. Delimiters
+// without a closing match are kept as text.
+func parseInline(text string) []span {
+	var spans []span
+	var buf strings.Builder
+	cur := style(0)
+	flush := func() {
+		if buf.Len() > 0 {
+			spans = append(spans, span{text: buf.String(), style: cur})
+			buf.Reset()
+		}
+	}
+	toggle := func(s style) {
+		flush()
+		cur ^= s
+	}
+
+	for i := 0; i < len(text); {
+		c := text[i]
+		rest := text[i:]
+		switch {
+		case c == '\\' && i+1 < len(text) && strings.IndexByte("\\`*_{}[]()#+-.!~>|", text[i+1]) >= 0:
+			buf.WriteByte(text[i+1])
+			i += 2
+			continue
+		case c == '`':
+			n := runLength(rest, '`')
+			if end := strings.Index(rest[n:], rest[:n]); end >= 0 {
+				flush()
+				code := strings.TrimSpace(rest[n : n+end])
+				spans = append(spans, span{text: code, style: cur | styleCode})
+				i += n + end + n
+				continue
+			}
+			buf.WriteString(rest[:n])
+			i += n
+			continue
+		case strings.HasPrefix(rest, "**") || strings.HasPrefix(rest, "__"):
+			d := rest[:2]
+			if cur&styleBold != 0 || strings.Contains(rest[2:], d) {
+				toggle(styleBold)
+				i += 2
+				continue
+			}
+		case strings.HasPrefix(rest, "~~"):
+			if cur&styleStrike != 0 || strings.Contains(rest[2:], "~~") {
+				toggle(styleStrike)
+				i += 2
+				continue
+			}
+		case c == '*' || c == '_':
+			if cur&styleItalic != 0 {
+				if c == '*' || !wordAfter(text, i+1) {
+					toggle(styleItalic)
+					i++
+					continue
+				}
+			} else if (c == '*' || !wordBefore(text, i)) && i+1 < len(text) && text[i+1] != ' ' && strings.IndexByte(text[i+1:], c) > 0 {
+				toggle(styleItalic)
+				i++
+				continue
+			}
+		case c == '[' || (c == '!' && strings.HasPrefix(rest, "![")):
+			start := 1
+			if c == '!' {
+				start = 2
+			}
+			if label, url, n, ok := parseLink(rest[start:]); ok {
+				flush()
+				if c == '!' {
+					spans = append(spans, span{text: label, style: cur | styleItalic})
+				} else {
+					var text strings.Builder
+					for _, s := range parseInline(label) {
+						text.WriteString(s.text)
+						s.style |= cur | styleLink
+						s.url = url
+						spans = append(spans, s)
+					}
+					if url != "" && url != text.String() && !strings.HasPrefix(url, "#") {
+						spans = append(spans, span{text: " (" + url + ")", style: cur | styleDim})
+					}
+				}
+				i += start + n
+				continue
+			}
+		case c == '<':
+			if end := strings.IndexByte(rest, '>'); end > 0 && isAutolink(rest[1:end]) {
+				flush()
+				url := rest[1:end]
+				spans = append(spans, span{text: url, style: cur | styleLink, url: url})
+				i += end + 1
+				continue
+			}
+		}
+		buf.WriteByte(c)
+		i++
+	}
+	flush()
+	return spans
+}
+
+func runLength(s string, c byte) int {
+	n := 0
+	for n < len(s) && s[n] == c {
+		n++
+	}
+	return n
+}
+
+func wordBefore(s string, i int) bool {
+	r, _ := utf8.DecodeLastRuneInString(s[:i])
+	return i > 0 && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+func wordAfter(s string, i int) bool {
+	r, _ := utf8.DecodeRuneInString(s[i:])
+	return i < len(s) && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
+
+// parseLink reads "label](url)" and returns the label, the URL and the
+// bytes consumed.
+func parseLink(s string) (string, string, int, bool) {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '[':
+			depth++
+		case ']':
+			if depth > 0 {
+				depth--
+				continue
+			}
+			if i+1 >= len(s) || s[i+1] != '(' {
+				return "", "", 0, false
+			}
+			end := strings.IndexByte(s[i+2:], ')')
+			if end < 0 {
+				return "", "", 0, false
+			}
+			url := strings.TrimSpace(s[i+2 : i+2+end])
+			if sp := strings.IndexByte(url, ' '); sp >= 0 {
+				url = url[:sp] // drop a "title"
+			}
+			return s[:i], url, i + 2 + end + 1, true
+		}
+	}
+	return "", "", 0, false
+}
+
+func isAutolink(s string) bool {
+	return !strings.ContainsAny(s, " <") && (strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://") || strings.HasPrefix(s, "mailto:"))
+}
@@ -0,0 +1,146 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/maquina/recuerd0-cli/internal/term"
+)
+
+func TestRender_Plain(t *testing.T) {
+	src := "# Deploy *checklist*\n\nRun the **full** suite with `make test` before\ntagging. See [the guide](https://example.com/guide).\n\n## Steps\n\n1. Tag the release\n2. Push\n   the tag\n\n- [ ] announce\n- [x] build\n  - nested item\n\n> Careful with\n> migrations.\n\n```sh\nmake release\n  --verbose\n```\n\n---\nDone  \nnext line"
+	want := `Deploy checklist
+================
+
+Run the full suite with make test before tagging. See the guide
+(https://example.com/guide).
+
+Steps
+-----
+
+1. Tag the release
+2. Push the tag
+
+☐ announce
+☑ build
+  • nested item
+
+│ Careful with migrations.
+
+  make release
+    --verbose
+
+──────────────────────────────────────────────────────────────────────
+
+Done
+next line`
+	if got := Render(src, Options{Width: 70}); got != want {
+		t.Errorf("got:\n%s\n\nwant:\n%s", got, want)
+	}
+}
+
+func TestRender_Wrap(t *testing.T) {
+	src := "- one two three four five six seven\n\n> alpha beta gamma delta"
+	got := Render(src, Options{Width: 16})
+	want := "• one two three\n  four five six\n  seven\n\n│ alpha beta\n│ gamma delta"
+	if got != want {
+		t.Errorf("got:\n%q\nwant:\n%q", got, want)
+	}
+	for _, line := range strings.Split(Render("averyveryverylongword and more", Options{Width: 8}), "\n") {
+		if line == "" {
+			t.Error("expected no empty lines when a word is longer than the width")
+		}
+	}
+}
+
+func TestRender_Color(t *testing.T) {
+	got := Render("# Title\n\nSome **bold** and `code`.", Options{Color: true})
+	for _, want := range []string{"\x1b[1;4;35mTitle\x1b[0m", "\x1b[1mbold\x1b[0m", "\x1b[36mcode\x1b[0m"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in %q", want, got)
+		}
+	}
+}
+
+func TestParseInline_Unmatched(t *testing.T) {
+	tests := map[string]string{
+		"2 * 3 = 6":           "2 * 3 = 6",
+		"snake_case_name":     "snake_case_name",
+		"**not closed":        "**not closed",
+		"a `tick":             "a `tick",
+		`escaped \*star\*`:    "escaped *star*",
+		"![diagram](d.png)":   "diagram",
+		"<https://x.example>": "https://x.example",
+	}
+	for src, want := range tests {
+		var b strings.Builder
+		for _, s := range parseInline(src) {
+			b.WriteString(s.text)
+		}
+		if b.String() != want {
+			t.Errorf("parseInline(%q) = %q, want %q", src, b.String(), want)
+		}
+	}
+}
+
+func TestRender_Table(t *testing.T) {
+	src := "| Command | Purpose | Exit |\n|:--------|:-------:|-----:|\n| `show` | View a memory | 0 |\n| cat | Print the body only, for pipes | 5 |\nafter"
+	want := `┌─────────┬────────────────────────────────┬──────┐
+│ Command │            Purpose             │ Exit │
+├─────────┼────────────────────────────────┼──────┤
+│ show    │         View a memory          │    0 │
+│ cat     │ Print the body only, for pipes │    5 │
+└─────────┴────────────────────────────────┴──────┘
+
+after`
+	if got := Render(src, Options{}); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	narrow := Render(src, Options{Width: 36})
+	for _, line := range strings.Split(narrow, "\n") {
+		if term.StringWidth(line) > 36 {
+			t.Errorf("line wider than 36 columns: %q", line)
+		}
+	}
+	if !strings.Contains(narrow, "│ cat     │ Print the body  │    5 │") {
+		t.Errorf("expected cells to wrap:\n%s", narrow)
+	}
+
+	wide := Render("| 名前 | Note |\n|---|---|\n| 日本語 | 🚀 ok |\n| a | b |", Options{})
+	wantWide := `┌────────┬───────┐
+│ 名前   │ Note  │
+├────────┼───────┤
+│ 日本語 │ 🚀 ok │
+│ a      │ b     │
+└────────┴───────┘`
+	if wide != wantWide {
+		t.Errorf("expected wide runes to count two columns, got:\n%s", wide)
+	}
+	if Render("a | b\nnot a table", Options{}) != "a | b not a table" {
+		t.Error("expected pipes without a delimiter row to stay a paragraph")
+	}
+}
+
+func TestRender_Highlight(t *testing.T) {
+	src := "```go\n// Add sums.\nfunc Add(a int) int { return a + 42 } /* note\nstill a comment */ x := \"s\"\n```\n\n```\nplain\n```"
+	got := Render(src, Options{Color: true})
+	for _, want := range []string{
+		"\x1b[2;3m// Add sums.\x1b[0m",
+		"\x1b[34mfunc\x1b[0m Add(a int) int { \x1b[34mreturn\x1b[0m a + \x1b[33m42\x1b[0m } \x1b[2;3m/* note\x1b[0m",
+		"\x1b[2;3mstill a comment */\x1b[0m x := \x1b[32m\"s\"\x1b[0m",
+		"\x1b[36mplain\x1b[0m",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in:\n%q", want, got)
+		}
+	}
+	if plain := Render(src, Options{}); strings.Contains(plain, "\x1b") {
+		t.Error("expected no escape codes without color")
+	}
+
+	sql := newHighlighter("SQL")
+	if toks := sql.tokens("SELECT id -- all"); toks[0].style != styleKeyword || toks[len(toks)-1].style != styleComment {
+		t.Errorf("unexpected sql tokens: %+v", toks)
+	}
+}
@@ -0,0 +1,193 @@
+package markdown
+
+import (
+	"strings"
+
+	"github.com/maquina/recuerd0-cli/internal/term"
+)
+
+type align int
+
+const (
+	alignLeft align = iota
+	alignCenter
+	alignRight
+)
+
+// isTableStart reports whether lines[i] is a table header: a row with
+// pipes followed by a delimiter row such as |---|:--:|.
+func isTableStart(lines []string, i int) bool {
+	if i+1 >= len(lines) || !strings.Contains(lines[i], "|") {
+		return false
+	}
+	aligns := delimiterRow(lines[i+1])
+	return aligns != nil && len(aligns) == len(splitRow(lines[i]))
+}
+
+// delimiterRow returns the column alignments of a delimiter row, or nil
+// when line is not one.
+func delimiterRow(line string) []align {
+	t := strings.TrimSpace(line)
+	if !strings.Contains(t, "-") || strings.Trim(t, "|:- ") != "" {
+		return nil
+	}
+	var aligns []align
+	for _, cell := range splitRow(t) {
+		c := strings.TrimSpace(cell)
+		if strings.Trim(c, "-:") != "" || !strings.Contains(c, "-") {
+			return nil
+		}
+		switch {
+		case strings.HasPrefix(c, ":") && strings.HasSuffix(c, ":"):
+			aligns = append(aligns, alignCenter)
+		case strings.HasSuffix(c, ":"):
+			aligns = append(aligns, alignRight)
+		default:
+			aligns = append(aligns, alignLeft)
+		}
+	}
+	return aligns
+}
+
+// splitRow splits a table row on unescaped pipes outside code spans.
+func splitRow(line string) []string {
+	t := strings.TrimSpace(line)
+	t = strings.TrimPrefix(t, "|")
+	if strings.HasSuffix(t, "|") && !strings.HasSuffix(t, `\|`) {
+		t = t[:len(t)-1]
+	}
+	var cells []string
+	var cell strings.Builder
+	inCode := false
+	for i := 0; i < len(t); i++ {
+		switch {
+		case t[i] == '\\' && i+1 < len(t) && t[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+			continue
+		case t[i] == '`':
+			inCode = !inCode
+		case t[i] == '|' && !inCode:
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+			continue
+		}
+		cell.WriteByte(t[i])
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+// table renders a table with box-drawing borders. Columns are narrowed,
+// widest first, until the table fits the width; cells wrap inside them.
+func (r *renderer) table(lines []string, i int, prefix func() string) int {
+	header := splitRow(lines[i])
+	aligns := delimiterRow(lines[i+1])
+	rows := [][]string{header}
+	for i += 2; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "" || !strings.Contains(lines[i], "|") {
+			break
+		}
+		cells := splitRow(lines[i])
+		for len(cells) < len(header) {
+			cells = append(cells, "")
+		}
+		rows = append(rows, cells[:len(header)])
+	}
+
+	cells := make([][][]span, len(rows))
+	widths := make([]int, len(header))
+	for ri, row := range rows {
+		cells[ri] = make([][]span, len(row))
+		for ci, text := range row {
+			spans := parseInline(text)
+			if ri == 0 {
+				for j := range spans {
+					spans[j].style |= styleBold
+				}
+			}
+			cells[ri][ci] = spans
+			widths[ci] = max(widths[ci], spanWidth(spans), 1)
+		}
+	}
+	if r.opts.Width > 0 {
+		avail := r.opts.Width - term.StringWidth(r.indent) - 3*len(widths) - 1
+		for sum(widths) > avail {
+			widest := 0
+			for c := range widths {
+				if widths[c] > widths[widest] {
+					widest = c
+				}
+			}
+			if widths[widest] <= 3 {
+				break
+			}
+			widths[widest]--
+		}
+	}
+
+	border := func(left, mid, right string) string {
+		parts := make([]string, len(widths))
+		for c, w := range widths {
+			parts[c] = strings.Repeat("─", w+2)
+		}
+		return r.style(left+strings.Join(parts, mid)+right, styleDim)
+	}
+	bar := r.style("│", styleDim)
+
+	r.blank()
+	r.emit(prefix() + border("┌", "┬", "┐"))
+	for ri := range rows {
+		wrapped := make([][]string, len(widths))
+		height := 1
+		for c, w := range widths {
+			wrapped[c] = r.wrapTo(cells[ri][c], w, "", "")
+			height = max(height, len(wrapped[c]))
+		}
+		for l := 0; l < height; l++ {
+			var b strings.Builder
+			b.WriteString(bar)
+			for c, w := range widths {
+				text := ""
+				if l < len(wrapped[c]) {
+					text = term.Truncate(wrapped[c][l], w)
+				}
+				b.WriteString(" " + pad(text, w, aligns[c]) + " " + bar)
+			}
+			r.emit(prefix() + b.String())
+		}
+		if ri == 0 {
+			r.emit(prefix() + border("├", "┼", "┤"))
+		}
+	}
+	r.emit(prefix() + border("└", "┴", "┘"))
+	r.blank()
+	return i
+}
+
+func spanWidth(spans []span) int {
+	n := 0
+	for _, s := range spans {
+		n += term.StringWidth(s.text)
+	}
+	return n
+}
+
+func sum(ns []int) int {
+	total := 0
+	for _, n := range ns {
+		total += n
+	}
+	return total
+}
+
+// pad aligns s within width columns.
+func pad(s string, width int, a align) string {
+	gap := max(width-term.StringWidth(s), 0)
+	switch a {
+	case alignRight:
+		return strings.Repeat(" ", gap) + s
+	case alignCenter:
+		return strings.Repeat(" ", gap/2) + s + strings.Repeat(" ", gap-gap/2)
+	}
+	return s + strings.Repeat(" ", gap)
+}
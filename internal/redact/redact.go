@@ -0,0 +1,201 @@
+// Package redact finds secrets and personal data in text before it is sent
+// to the API.
+package redact
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Modes control what happens when findings are detected.
+const (
+	ModeWarn   = "warn"   // send unchanged, report findings
+	ModeRedact = "redact" // replace matches, report findings
+	ModeBlock  = "block"  // refuse to send
+)
+
+// DefaultMode is used when no mode is configured.
+const DefaultMode = ModeWarn
+
+// ValidMode reports whether mode is a known mode.
+func ValidMode(mode string) bool {
+	return mode == ModeWarn || mode == ModeRedact || mode == ModeBlock
+}
+
+// Entropy detection thresholds: long tokens mixing upper case, lower case
+// and digits with high Shannon entropy look like generated secrets. Hex
+// digests stay below the threshold (at most 4 bits per character) and lack
+// upper case.
+const (
+	minEntropyLen = 24
+	minEntropy    = 4.0
+)
+
+// Detector is a named pattern. When Group is set only that submatch is the
+// secret, e.g. the value in "password = hunter2".
+type Detector struct {
+	Name    string
+	Pattern *regexp.Regexp
+	Group   int
+}
+
+// Builtin returns the built-in detectors, most specific first.
+func Builtin() []Detector {
+	return []Detector{
+		{Name: "private_key", Pattern: regexp.MustCompile(`-----BEGIN [A-Z0-9 ]*PRIVATE KEY( BLOCK)?-----(?s:.*?)(-----END [A-Z0-9 ]*PRIVATE KEY( BLOCK)?-----|\z)`)},
+		{Name: "aws_access_key", Pattern: regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)},
+		{Name: "github_token", Pattern: regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+		{Name: "gitlab_token", Pattern: regexp.MustCompile(`\bglpat-[A-Za-z0-9_-]{20,}\b`)},
+		{Name: "slack_token", Pattern: regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}\b`)},
+		{Name: "stripe_key", Pattern: regexp.MustCompile(`\b[rs]k_(live|test)_[A-Za-z0-9]{16,}\b`)},
+		{Name: "anthropic_key", Pattern: regexp.MustCompile(`\bsk-ant-[A-Za-z0-9_-]{20,}`)},
+		{Name: "openai_key", Pattern: regexp.MustCompile(`\bsk-(proj-)?[A-Za-z0-9_-]{20,}`)},
+		{Name: "google_api_key", Pattern: regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+		{Name: "jwt", Pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}`)},
+		{Name: "bearer_token", Pattern: regexp.MustCompile(`(?i)\bbearer\s+([A-Za-z0-9._~+/=-]{16,})`), Group: 1},
+		{Name: "url_credentials", Pattern: regexp.MustCompile(`\b[a-z][a-z0-9+.-]*://[^\s:/@]+:([^\s@/]+)@`), Group: 1},
+		{Name: "password_assignment", Pattern: regexp.MustCompile(`(?i)\b[a-z0-9_]*?(?:password|passwd|pwd|secret|api[_-]?key|access[_-]?token|auth[_-]?token)\b["']?\s*[:=]\s*["']?([^\s"',;]{6,})`), Group: 1},
+		{Name: "email", Pattern: regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}\b`)},
+	}
+}
+
+// Custom compiles user-defined detectors. Each pattern may use one capture
+// group to mark the secret part.
+func Custom(patterns map[string]string) ([]Detector, error) {
+	names := make([]string, 0, len(patterns))
+	for name := range patterns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	detectors := make([]Detector, 0, len(names))
+	for _, name := range names {
+		re, err := regexp.Compile(patterns[name])
+		if err != nil {
+			return nil, fmt.Errorf("redaction pattern %q: %w", name, err)
+		}
+		d := Detector{Name: name, Pattern: re}
+		if re.NumSubexp() == 1 {
+			d.Group = 1
+		}
+		detectors = append(detectors, d)
+	}
+	return detectors, nil
+}
+
+// Finding is one detected secret. Start and End are byte offsets into the
+// scanned text; Line is 1-based.
+type Finding struct {
+	Detector string `json:"detector"`
+	Line     int    `json:"line"`
+	Preview  string `json:"preview"`
+	Start    int    `json:"-"`
+	End      int    `json:"-"`
+}
+
+// Scan runs the detectors and the entropy check over text. Overlapping
+// matches are reported once, for the detector listed first.
+func Scan(text string, detectors []Detector) []Finding {
+	var findings []Finding
+	taken := func(start, end int) bool {
+		for _, f := range findings {
+			if start < f.End && end > f.Start {
+				return true
+			}
+		}
+		return false
+	}
+	add := func(name string, start, end int) {
+		if start >= end || taken(start, end) {
+			return
+		}
+		findings = append(findings, Finding{
+			Detector: name,
+			Line:     strings.Count(text[:start], "\n") + 1,
+			Preview:  preview(text[start:end]),
+			Start:    start,
+			End:      end,
+		})
+	}
+
+	for _, d := range detectors {
+		for _, m := range d.Pattern.FindAllStringSubmatchIndex(text, -1) {
+			start, end := m[0], m[1]
+			if d.Group > 0 && len(m) > 2*d.Group+1 && m[2*d.Group] >= 0 {
+				start, end = m[2*d.Group], m[2*d.Group+1]
+			}
+			add(d.Name, start, end)
+		}
+	}
+	for _, loc := range entropyCandidates.FindAllStringIndex(text, -1) {
+		if word := text[loc[0]:loc[1]]; highEntropy(word) {
+			add("high_entropy", loc[0], loc[1])
+		}
+	}
+
+	sort.Slice(findings, func(i, j int) bool { return findings[i].Start < findings[j].Start })
+	return findings
+}
+
+var entropyCandidates = regexp.MustCompile(`[A-Za-z0-9+/_=-]{24,}`)
+
+func highEntropy(s string) bool {
+	if len(s) < minEntropyLen {
+		return false
+	}
+	var upper, lower, digit bool
+	for _, r := range s {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		}
+	}
+	return upper && lower && digit && shannon(s) >= minEntropy
+}
+
+// shannon returns the entropy of s in bits per character.
+func shannon(s string) float64 {
+	counts := map[rune]int{}
+	n := 0
+	for _, r := range s {
+		counts[r]++
+		n++
+	}
+	var h float64
+	for _, c := range counts {
+		p := float64(c) / float64(n)
+		h -= p * math.Log2(p)
+	}
+	return h
+}
+
+// preview shows just enough of a match to recognise it.
+func preview(s string) string {
+	runes := []rune(strings.SplitN(s, "\n", 2)[0])
+	if len(runes) <= 8 {
+		return strings.Repeat("*", len(runes))
+	}
+	return string(runes[:4]) + "…" + fmt.Sprintf("(%d chars)", len([]rune(s)))
+}
+
+// Apply replaces every finding in text with a [REDACTED:detector] marker.
+// findings must come from Scan on the same text.
+func Apply(text string, findings []Finding) string {
+	var sb strings.Builder
+	last := 0
+	for _, f := range findings {
+		sb.WriteString(text[last:f.Start])
+		sb.WriteString("[REDACTED:" + f.Detector + "]")
+		last = f.End
+	}
+	sb.WriteString(text[last:])
+	return sb.String()
+}
@@ -4,11 +4,19 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/maquina/recuerd0-cli/internal/errors"
+	"github.com/maquina/recuerd0-cli/internal/shellwords"
 )
 
+// SchemaVersion is reported as meta.schema_version. It is bumped when the
+// envelope or the data inside it changes in a way that breaks consumers:
+// a field removed, renamed or given a different type.
+const SchemaVersion = 1
+
 var prettyPrint bool
 
 // SetPrettyPrint enables or disables indented JSON output.
@@ -19,21 +27,73 @@ func SetPrettyPrint(enabled bool) {
 // Pagination holds pagination state for list responses.
 type Pagination struct {
 	HasNext bool   `json:"has_next"`
-	NextURL string `json:"next_url,omitempty"`
+	NextURL string `json:"next_url,omitempty" doc:"Pass its page to --page for the next page"`
+	// Set when results were filtered client-side: how many pages and items
+	// were fetched and how many items matched.
+	Pages   int `json:"pages,omitempty" doc:"Pages fetched to apply client-side filters"`
+	Fetched int `json:"fetched,omitempty" doc:"Items fetched before filtering"`
+	Matched int `json:"matched,omitempty" doc:"Items that matched the filters"`
 }
 
-// Breadcrumb suggests a next action for AI tool consumption.
+// Breadcrumb suggests a next action for AI tool consumption. Argv holds
+// the words of Cmd, so it can be run without a shell; Requires names the
+// placeholders, such as <memory_id>, the caller still has to fill in.
 type Breadcrumb struct {
-	Action      string `json:"action"`
-	Cmd         string `json:"cmd"`
-	Description string `json:"description"`
+	Action      string   `json:"action" doc:"Short name of the action; recuerd0 run-breadcrumb runs it"`
+	Cmd         string   `json:"cmd" doc:"Command line to run, with <placeholders> for missing values"`
+	Argv        []string `json:"argv" doc:"Words of cmd, starting with recuerd0"`
+	Requires    []string `json:"requires,omitempty" doc:"Names of the placeholders in argv"`
+	Description string   `json:"description"`
+}
+
+// placeholder matches a value a breadcrumb leaves to the caller: <name>,
+// or <name...> for several.
+var placeholder = regexp.MustCompile(`<([a-z][a-z0-9_]*)(?:\.\.\.)?>`)
+
+// NewBreadcrumb builds a breadcrumb from a command line, splitting it into
+// Argv and collecting its placeholders into Requires.
+func NewBreadcrumb(action, cmd, description string) Breadcrumb {
+	argv, err := shellwords.Split(cmd)
+	if err != nil {
+		argv = strings.Fields(cmd)
+	}
+	var requires []string
+	seen := map[string]bool{}
+	for _, word := range argv {
+		for _, m := range placeholder.FindAllStringSubmatch(word, -1) {
+			if !seen[m[1]] {
+				seen[m[1]] = true
+				requires = append(requires, m[1])
+			}
+		}
+	}
+	return Breadcrumb{Action: action, Cmd: cmd, Argv: argv, Requires: requires, Description: description}
+}
+
+// Fill replaces the placeholders in b's Argv with values by name and
+// returns the words, or the names of placeholders left without a value.
+func (b Breadcrumb) Fill(values map[string]string) ([]string, []string) {
+	var missing []string
+	argv := make([]string, len(b.Argv))
+	for i, word := range b.Argv {
+		argv[i] = placeholder.ReplaceAllStringFunc(word, func(p string) string {
+			name := placeholder.FindStringSubmatch(p)[1]
+			v, ok := values[name]
+			if !ok {
+				missing = append(missing, name)
+				return p
+			}
+			return v
+		})
+	}
+	return argv, missing
 }
 
 // ErrorDetail holds error information in the JSON envelope.
 type ErrorDetail struct {
-	Code    string `json:"code"`
+	Code    string `json:"code" doc:"Machine-readable error code, such as NOT_FOUND"`
 	Message string `json:"message"`
-	Status  int    `json:"status,omitempty"`
+	Status  int    `json:"status,omitempty" doc:"HTTP status, when the API returned the error"`
 }
 
 // Response is the JSON envelope for all CLI output.
@@ -43,14 +103,15 @@ type Response struct {
 	Error       *ErrorDetail           `json:"error,omitempty"`
 	Pagination  *Pagination            `json:"pagination,omitempty"`
 	Breadcrumbs []Breadcrumb           `json:"breadcrumbs,omitempty"`
-	Summary     string                 `json:"summary,omitempty"`
-	Location    string                 `json:"location,omitempty"`
+	Summary     string                 `json:"summary,omitempty" doc:"One-line description of the result"`
+	Location    string                 `json:"location,omitempty" doc:"URL of a created resource"`
 	Meta        map[string]interface{} `json:"meta,omitempty"`
 }
 
 func newMeta() map[string]interface{} {
 	return map[string]interface{}{
-		"timestamp": time.Now().UTC().Format(time.RFC3339),
+		"timestamp":      time.Now().UTC().Format(time.RFC3339),
+		"schema_version": SchemaVersion,
 	}
 }
 
@@ -122,6 +183,19 @@ func SuccessWithPaginationAndBreadcrumbs(data interface{}, hasNext bool, nextURL
 	}
 }
 
+// SuccessWithPageAndBreadcrumbs creates the full response from prepared
+// pagination info.
+func SuccessWithPageAndBreadcrumbs(data interface{}, page *Pagination, summary string, breadcrumbs []Breadcrumb) *Response {
+	return &Response{
+		Success:     true,
+		Data:        data,
+		Pagination:  page,
+		Summary:     summary,
+		Breadcrumbs: breadcrumbs,
+		Meta:        newMeta(),
+	}
+}
+
 // Error creates an error response from a CLIError.
 func Error(err *errors.CLIError) *Response {
 	return &Response{
@@ -2,6 +2,7 @@ package response
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/maquina/recuerd0-cli/internal/errors"
@@ -18,6 +19,9 @@ func TestSuccess(t *testing.T) {
 	if r.Meta == nil || r.Meta["timestamp"] == nil {
 		t.Error("expected meta.timestamp to be set")
 	}
+	if r.Meta["schema_version"] != SchemaVersion {
+		t.Errorf("expected meta.schema_version %d, got %v", SchemaVersion, r.Meta["schema_version"])
+	}
 }
 
 func TestSuccessWithSummary(t *testing.T) {
@@ -60,6 +64,32 @@ func TestSuccessWithBreadcrumbs(t *testing.T) {
 	}
 }
 
+func TestNewBreadcrumb(t *testing.T) {
+	b := NewBreadcrumb("enable", `recuerd0 config set encryption.<workspace_id> "team key" --title <title> --for <workspace_id>`, "Enable")
+	want := []string{"recuerd0", "config", "set", "encryption.<workspace_id>", "team key", "--title", "<title>", "--for", "<workspace_id>"}
+	if strings.Join(b.Argv, "|") != strings.Join(want, "|") {
+		t.Errorf("unexpected argv %q", b.Argv)
+	}
+	if strings.Join(b.Requires, ",") != "workspace_id,title" {
+		t.Errorf("expected each placeholder once, got %v", b.Requires)
+	}
+
+	argv, missing := b.Fill(map[string]string{"workspace_id": "22"})
+	if argv[3] != "encryption.22" || argv[8] != "22" || argv[6] != "<title>" {
+		t.Errorf("unexpected filled argv %q", argv)
+	}
+	if strings.Join(missing, ",") != "title" {
+		t.Errorf("expected title to be missing, got %v", missing)
+	}
+	if b.Argv[3] != "encryption.<workspace_id>" {
+		t.Error("expected Fill to leave the breadcrumb unchanged")
+	}
+
+	if plain := NewBreadcrumb("list", "recuerd0 workspace list", "List"); plain.Requires != nil || len(plain.Argv) != 3 {
+		t.Errorf("unexpected breadcrumb %+v", plain)
+	}
+}
+
 func TestSuccessWithPaginationAndBreadcrumbs(t *testing.T) {
 	bc := []Breadcrumb{
 		{Action: "show", Cmd: "recuerd0 memory show 1", Description: "View memory"},
@@ -156,3 +186,15 @@ func TestErrorCodeToExitCode(t *testing.T) {
 		})
 	}
 }
+
+func TestSuccessWithPageAndBreadcrumbs(t *testing.T) {
+	page := &Pagination{Pages: 2, Fetched: 40, Matched: 3}
+	resp := SuccessWithPageAndBreadcrumbs([]string{"a"}, page, "3 of 40 match", nil)
+	if resp.Pagination != page || resp.Summary != "3 of 40 match" {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+	data, _ := resp.JSON()
+	if !strings.Contains(string(data), `"matched":3`) {
+		t.Errorf("expected matched count in JSON: %s", data)
+	}
+}
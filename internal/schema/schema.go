@@ -0,0 +1,166 @@
+// Package schema describes the CLI's JSON output as JSON Schema. The
+// schemas are generated from Go types: the response envelope, and the
+// workspace, memory and search data commands print inside it.
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/maquina/recuerd0-cli/internal/errors"
+	"github.com/maquina/recuerd0-cli/internal/response"
+)
+
+// Draft is the JSON Schema dialect of every document.
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Names lists the documents Document can build, in the order they are
+// offered to users.
+var Names = []string{"envelope", "workspace", "memory", "search", "error"}
+
+// Schemer is implemented by types that describe their own schema, such
+// as values that can take more than one JSON type.
+type Schemer interface {
+	JSONSchema() map[string]interface{}
+}
+
+// Document returns the named schema, ready to print.
+func Document(name string) (map[string]interface{}, error) {
+	var s map[string]interface{}
+	switch name {
+	case "envelope":
+		s = envelope()
+	case "error":
+		s = envelope()
+		props := s["properties"].(map[string]interface{})
+		props["success"] = map[string]interface{}{"const": false}
+		detail := props["error"].(map[string]interface{})
+		var codes []string
+		for _, e := range errors.ExitCodes {
+			if e.ErrorCode != "" {
+				codes = append(codes, e.ErrorCode)
+			}
+		}
+		detail["properties"].(map[string]interface{})["code"].(map[string]interface{})["enum"] = codes
+		s["required"] = append(s["required"].([]string), "error")
+		s["description"] = "The envelope of a failed command. The exit code follows error.code."
+	case "workspace":
+		s = For(Workspace{})
+		s["description"] = "A workspace, the data of workspace show, create and update; workspace list returns an array of them."
+	case "memory":
+		s = For(Memory{})
+		s["description"] = "A memory, the data of memory show, create and update; memory list returns an array of them without content."
+	case "search":
+		s = For(SearchResults{})
+		s["description"] = "The data of search."
+	default:
+		return nil, fmt.Errorf("unknown schema %q; expected one of: %s", name, strings.Join(Names, ", "))
+	}
+	out := map[string]interface{}{
+		"$schema": Draft,
+		"title":   "recuerd0 " + name,
+	}
+	for k, v := range s {
+		out[k] = v
+	}
+	return out, nil
+}
+
+func envelope() map[string]interface{} {
+	s := For(response.Response{})
+	s["description"] = "The envelope every command prints. data depends on the command."
+	props := s["properties"].(map[string]interface{})
+	props["meta"] = map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"timestamp":      map[string]interface{}{"type": "string", "format": "date-time"},
+			"schema_version": map[string]interface{}{"type": "integer", "const": response.SchemaVersion},
+		},
+		"required":    []string{"timestamp", "schema_version"},
+		"description": "When the response was made and the schema version it follows. Commands add entries such as dry_run or encryption.",
+	}
+	s["required"] = append(s["required"].([]string), "meta")
+	return s
+}
+
+var (
+	schemerType = reflect.TypeOf((*Schemer)(nil)).Elem()
+	timeType    = reflect.TypeOf(time.Time{})
+)
+
+// For returns the schema of v's type. Struct fields follow their json
+// tags: those without omitempty are required, and a doc tag becomes the
+// description.
+func For(v interface{}) map[string]interface{} {
+	return forType(reflect.TypeOf(v))
+}
+
+func forType(t reflect.Type) map[string]interface{} {
+	if t.Kind() == reflect.Ptr {
+		return forType(t.Elem())
+	}
+	if t.Implements(schemerType) {
+		return reflect.Zero(t).Interface().(Schemer).JSONSchema()
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": forType(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": forType(t.Elem())}
+	case reflect.Struct:
+		if t == timeType {
+			return map[string]interface{}{"type": "string", "format": "date-time"}
+		}
+		return object(t)
+	}
+	// interface{} and anything else accepts any value.
+	return map[string]interface{}{}
+}
+
+func object(t reflect.Type) map[string]interface{} {
+	props := map[string]interface{}{}
+	required := []string{}
+	var add func(t reflect.Type)
+	add = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			tag := f.Tag.Get("json")
+			if tag == "-" || (f.PkgPath != "" && !f.Anonymous) {
+				continue
+			}
+			name, opts, _ := strings.Cut(tag, ",")
+			if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+				add(f.Type)
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			p := forType(f.Type)
+			if doc := f.Tag.Get("doc"); doc != "" {
+				p["description"] = doc
+			}
+			props[name] = p
+			if !strings.Contains(","+opts+",", ",omitempty,") {
+				required = append(required, name)
+			}
+		}
+	}
+	add(t)
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": props,
+		"required":   required,
+	}
+}
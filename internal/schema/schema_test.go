@@ -0,0 +1,130 @@
+package schema
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/maquina/recuerd0-cli/internal/errors"
+	"github.com/maquina/recuerd0-cli/internal/response"
+)
+
+// roundTrip returns v as a consumer sees it: encoded and decoded again.
+func roundTrip(t *testing.T, v interface{}) interface{} {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out interface{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestFor(t *testing.T) {
+	s := For(SearchResult{})
+	props := s["properties"].(map[string]interface{})
+	if props["snippet"] == nil || props["title"] == nil {
+		t.Fatalf("expected the embedded memory fields to be flattened, got %v", props)
+	}
+	if got := strings.Join(s["required"].([]string), ","); got != "id,title" {
+		t.Errorf("expected fields without omitempty to be required, got %s", got)
+	}
+	if props["content"].(map[string]interface{})["oneOf"] == nil {
+		t.Error("expected content to describe itself")
+	}
+	if props["snippet"].(map[string]interface{})["description"] == "" {
+		t.Error("expected the doc tag as description")
+	}
+}
+
+func TestDocument(t *testing.T) {
+	for _, name := range Names {
+		t.Run(name, func(t *testing.T) {
+			doc, err := Document(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if doc["$schema"] != Draft || doc["type"] != "object" {
+				t.Errorf("unexpected document %v", doc)
+			}
+			// Printed schemas are read back as JSON.
+			if _, err := json.Marshal(doc); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+	if _, err := Document("nope"); err == nil {
+		t.Error("expected an unknown name to fail")
+	}
+}
+
+func TestValidate_Envelope(t *testing.T) {
+	env, _ := Document("envelope")
+	ok := response.SuccessWithBreadcrumbs([]interface{}{1}, "1 item", []response.Breadcrumb{response.NewBreadcrumb("list", "recuerd0 workspace list", "List workspaces")})
+	if err := Validate(env, roundTrip(t, ok)); err != nil {
+		t.Errorf("expected a valid envelope, got %v", err)
+	}
+
+	failed := roundTrip(t, response.Error(errors.NewNotFoundError("gone")))
+	errDoc, _ := Document("error")
+	if err := Validate(errDoc, failed); err != nil {
+		t.Errorf("expected a valid error envelope, got %v", err)
+	}
+	if err := Validate(errDoc, roundTrip(t, ok)); err == nil {
+		t.Error("expected a success envelope to fail the error schema")
+	}
+
+	failed.(map[string]interface{})["meta"].(map[string]interface{})["schema_version"] = float64(response.SchemaVersion + 1)
+	if err := Validate(env, failed); err == nil || !strings.Contains(err.Error(), "$.meta.schema_version") {
+		t.Errorf("expected a schema_version mismatch, got %v", err)
+	}
+}
+
+func TestValidate_Data(t *testing.T) {
+	memory, _ := Document("memory")
+	valid := map[string]interface{}{
+		"id": float64(3), "title": "T", "tags": []interface{}{"a"},
+		"content": map[string]interface{}{"body": "text"}, "extra": true,
+	}
+	if err := Validate(memory, valid); err != nil {
+		t.Errorf("expected a valid memory, got %v", err)
+	}
+
+	tests := []struct {
+		name string
+		data map[string]interface{}
+		want string
+	}{
+		{"missing title", map[string]interface{}{"id": float64(3)}, "$: missing title"},
+		{"fractional id", map[string]interface{}{"id": 1.5, "title": "T"}, "$.id: expected integer or string"},
+		{"tag type", map[string]interface{}{"id": "3", "title": "T", "tags": []interface{}{1.0}}, "$.tags[0]: expected string"},
+		{"content type", map[string]interface{}{"id": "3", "title": "T", "content": 1.0}, "$.content: expected exactly one"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(memory, tt.data)
+			if err == nil || !strings.HasPrefix(err.Error(), tt.want) {
+				t.Errorf("expected %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestTypes_Unmarshal(t *testing.T) {
+	var m Memory
+	if err := json.Unmarshal([]byte(`{"id":42,"title":"T","content":{"body":"a"}}`), &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.ID != "42" || m.Content.Body != "a" {
+		t.Errorf("unexpected memory %+v", m)
+	}
+	if err := json.Unmarshal([]byte(`{"id":"7","title":"T","content":"b"}`), &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.ID != "7" || m.Content.Body != "b" {
+		t.Errorf("unexpected memory %+v", m)
+	}
+}
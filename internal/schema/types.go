@@ -0,0 +1,107 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+)
+
+// ID identifies a resource: a number in API responses, a string in
+// offline search results.
+type ID string
+
+// UnmarshalJSON accepts a number or a string.
+func (id *ID) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*id = ID(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(b, &n); err != nil {
+		return err
+	}
+	*id = ID(n)
+	return nil
+}
+
+// JSONSchema implements Schemer.
+func (ID) JSONSchema() map[string]interface{} {
+	return map[string]interface{}{"type": []string{"integer", "string"}}
+}
+
+// Content is a memory's body. The API returns an object with the body;
+// commands accept a plain string as well.
+type Content contentBody
+
+type contentBody struct {
+	Body string `json:"body" doc:"Markdown body, or armored ciphertext when it could not be decrypted"`
+}
+
+// UnmarshalJSON accepts an object with a body or a string.
+func (c *Content) UnmarshalJSON(b []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(b), []byte(`"`)) {
+		return json.Unmarshal(b, &c.Body)
+	}
+	return json.Unmarshal(b, (*contentBody)(c))
+}
+
+// JSONSchema implements Schemer.
+func (Content) JSONSchema() map[string]interface{} {
+	return map[string]interface{}{
+		"oneOf": []interface{}{
+			object(reflect.TypeOf(contentBody{})),
+			map[string]interface{}{"type": "string"},
+		},
+	}
+}
+
+// WorkspaceRef is the workspace a memory or search result belongs to.
+type WorkspaceRef struct {
+	ID   ID     `json:"id"`
+	Name string `json:"name,omitempty"`
+	URL  string `json:"url,omitempty"`
+}
+
+// Workspace is the data of workspace show, create and update.
+type Workspace struct {
+	ID            ID     `json:"id"`
+	Name          string `json:"name"`
+	Description   string `json:"description,omitempty"`
+	MemoriesCount int    `json:"memories_count,omitempty"`
+	Archived      bool   `json:"archived,omitempty"`
+	CreatedAt     string `json:"created_at,omitempty" doc:"RFC 3339 time"`
+	UpdatedAt     string `json:"updated_at,omitempty" doc:"RFC 3339 time"`
+	URL           string `json:"url,omitempty" doc:"Web address of the workspace"`
+}
+
+// Memory is the data of memory show, create and update. Listings leave
+// out the content.
+type Memory struct {
+	ID        ID            `json:"id"`
+	Title     string        `json:"title"`
+	Version   int           `json:"version,omitempty" doc:"Version number, starting at 1"`
+	Source    string        `json:"source,omitempty" doc:"Where the memory came from, such as manual or offline"`
+	Tags      []string      `json:"tags,omitempty"`
+	CreatedAt string        `json:"created_at,omitempty" doc:"RFC 3339 time"`
+	UpdatedAt string        `json:"updated_at,omitempty" doc:"RFC 3339 time"`
+	URL       string        `json:"url,omitempty" doc:"Web address of the memory"`
+	Content   *Content      `json:"content,omitempty"`
+	Workspace *WorkspaceRef `json:"workspace,omitempty"`
+}
+
+// SearchResult is one memory matched by search.
+type SearchResult struct {
+	Memory
+	VersionLabel string `json:"version_label,omitempty" doc:"Version shown to people, such as v2"`
+	HasVersions  bool   `json:"has_versions,omitempty"`
+	Snippet      string `json:"snippet,omitempty" doc:"Matching text around the search terms"`
+}
+
+// SearchResults is the data of search.
+type SearchResults struct {
+	Query        string         `json:"query" doc:"The query as sent, after building it from the flags"`
+	TotalResults int            `json:"total_results"`
+	Results      []SearchResult `json:"results"`
+	Offline      bool           `json:"offline,omitempty" doc:"Set when the local index of encrypted memories was searched instead of the server"`
+}
@@ -0,0 +1,215 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Validate reports the first place v does not match s. v is a value as
+// encoding/json decodes it into interface{}; s is a schema from this
+// package or one decoded from its JSON. It covers the keywords this
+// package generates: type, const, enum, properties, required,
+// additionalProperties, items and oneOf.
+func Validate(s map[string]interface{}, v interface{}) error {
+	return validate(s, v, "$")
+}
+
+func validate(s map[string]interface{}, v interface{}, path string) error {
+	if t, ok := s["type"]; ok {
+		types := stringList(t)
+		if !matchesType(types, v) {
+			return fmt.Errorf("%s: expected %s, got %s", path, strings.Join(types, " or "), typeName(v))
+		}
+	}
+	if c, ok := s["const"]; ok && !equal(c, v) {
+		return fmt.Errorf("%s: expected %v, got %v", path, c, v)
+	}
+	if e, ok := s["enum"]; ok {
+		found := false
+		for _, item := range list(e) {
+			if equal(item, v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%s: %v is not one of %v", path, v, e)
+		}
+	}
+	if one, ok := s["oneOf"]; ok {
+		matched := 0
+		for _, sub := range list(one) {
+			if validate(asSchema(sub), v, path) == nil {
+				matched++
+			}
+		}
+		if matched != 1 {
+			return fmt.Errorf("%s: expected exactly one alternative to match, %d did", path, matched)
+		}
+	}
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for _, name := range stringList(s["required"]) {
+			if _, ok := v[name]; !ok {
+				return fmt.Errorf("%s: missing %s", path, name)
+			}
+		}
+		props, _ := s["properties"].(map[string]interface{})
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			sub, known := props[k]
+			if !known {
+				switch extra := s["additionalProperties"].(type) {
+				case bool:
+					if !extra {
+						return fmt.Errorf("%s: unexpected %s", path, k)
+					}
+					continue
+				case map[string]interface{}:
+					sub = extra
+				default:
+					continue
+				}
+			}
+			if err := validate(asSchema(sub), v[k], path+"."+k); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		if items, ok := s["items"]; ok {
+			for i, item := range v {
+				if err := validate(asSchema(items), item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func matchesType(types []string, v interface{}) bool {
+	for _, t := range types {
+		switch t {
+		case "object":
+			if _, ok := v.(map[string]interface{}); ok {
+				return true
+			}
+		case "array":
+			if _, ok := v.([]interface{}); ok {
+				return true
+			}
+		case "string":
+			if _, ok := v.(string); ok {
+				return true
+			}
+		case "boolean":
+			if _, ok := v.(bool); ok {
+				return true
+			}
+		case "number":
+			if _, ok := number(v); ok {
+				return true
+			}
+		case "integer":
+			if n, ok := number(v); ok && n == math.Trunc(n) {
+				return true
+			}
+		case "null":
+			if v == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	}
+	if _, ok := number(v); ok {
+		return "number"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func number(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// equal compares a schema value with a decoded one, treating numbers of
+// any Go type alike.
+func equal(a, b interface{}) bool {
+	if x, ok := number(a); ok {
+		y, ok := number(b)
+		return ok && x == y
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// stringList reads a keyword that is a string or a list of them, as
+// generated ([]string) or decoded ([]interface{}).
+func stringList(v interface{}) []string {
+	switch v := v.(type) {
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	case []interface{}:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+func list(v interface{}) []interface{} {
+	if items, ok := v.([]interface{}); ok {
+		return items
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice {
+		return nil
+	}
+	out := make([]interface{}, rv.Len())
+	for i := range out {
+		out[i] = rv.Index(i).Interface()
+	}
+	return out
+}
+
+func asSchema(v interface{}) map[string]interface{} {
+	s, _ := v.(map[string]interface{})
+	return s
+}
@@ -0,0 +1,83 @@
+// Package shellwords splits command lines into words and joins words back
+// into command lines, for the shell, breadcrumbs and audit records.
+package shellwords
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Join joins args into a command line, quoting those the shell would
+// split or expand.
+func Join(args []string) string {
+	parts := make([]string, 0, len(args))
+	for _, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t\n\"'$`\\|&;<>()*?") {
+			arg = strconv.Quote(arg)
+		}
+		parts = append(parts, arg)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Split splits a command line into words the way a POSIX shell
+// would for plain words, single quotes, double quotes and backslashes. It
+// reads back what Join writes.
+func Split(line string) ([]string, error) {
+	var words []string
+	var cur strings.Builder
+	inWord := false
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			if inWord {
+				words = append(words, cur.String())
+				cur.Reset()
+				inWord = false
+			}
+		case c == '\'':
+			end := strings.IndexByte(line[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated ' quote")
+			}
+			cur.WriteString(line[i+1 : i+1+end])
+			i += end + 1
+			inWord = true
+		case c == '"':
+			end := i + 1
+			for ; end < len(line) && line[end] != '"'; end++ {
+				if line[end] == '\\' {
+					end++
+				}
+			}
+			if end >= len(line) {
+				return nil, fmt.Errorf("unterminated \" quote")
+			}
+			quoted := line[i : end+1]
+			if s, err := strconv.Unquote(quoted); err == nil {
+				cur.WriteString(s)
+			} else {
+				r := strings.NewReplacer(`\"`, `"`, `\\`, `\`)
+				cur.WriteString(r.Replace(quoted[1 : len(quoted)-1]))
+			}
+			i = end
+			inWord = true
+		case c == '\\' && i+1 < len(line):
+			i++
+			cur.WriteByte(line[i])
+			inWord = true
+		default:
+			cur.WriteByte(c)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, cur.String())
+	}
+	if len(words) == 0 {
+		return nil, fmt.Errorf("empty command")
+	}
+	return words, nil
+}
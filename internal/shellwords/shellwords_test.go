@@ -0,0 +1,22 @@
+package shellwords
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitJoin(t *testing.T) {
+	args := []string{"memory", "create", "--title", "Deploy \"v2\" notes", "--content", "line 1\nline 2", "it's", `back\slash`}
+	got, err := Split(Join(args))
+	if err != nil || !reflect.DeepEqual(got, args) {
+		t.Errorf("expected Join output to split back, got %q (%v)", got, err)
+	}
+
+	got, _ = Split(`search 'two words' a\ b "x\"y"`)
+	if want := []string{"search", "two words", "a b", `x"y`}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if _, err := Split(`search "open`); err == nil {
+		t.Error("expected an unterminated quote to fail")
+	}
+}
@@ -0,0 +1,41 @@
+// Package term switches a terminal in and out of raw mode and reports its
+// size, for the interactive shell and terminal UI, and measures how many
+// columns text takes on screen. Raw mode is only supported on Linux and
+// macOS; elsewhere IsTerminal reports false and callers fall back to
+// line-based input.
+package term
+
+import (
+	"errors"
+	"os"
+	"strconv"
+)
+
+// ErrUnsupported is returned by MakeRaw on platforms without raw mode.
+var ErrUnsupported = errors.New("raw terminal mode is not supported on this platform")
+
+// DefaultWidth is the width assumed when it cannot be detected.
+const DefaultWidth = 80
+
+// Width returns the column count of the terminal on fd. It falls back to
+// $COLUMNS and then DefaultWidth.
+func Width(fd int) int {
+	if w := width(fd); w > 0 {
+		return w
+	}
+	if w, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && w > 0 {
+		return w
+	}
+	return DefaultWidth
+}
+
+// Size returns the columns and rows of the terminal on fd, or zeros when
+// fd is not a terminal.
+func Size(fd int) (int, int) {
+	return size(fd)
+}
+
+func width(fd int) int {
+	w, _ := size(fd)
+	return w
+}
@@ -0,0 +1,26 @@
+//go:build !linux && !darwin
+
+package term
+
+// State is the terminal mode to restore after MakeRaw.
+type State struct{}
+
+// IsTerminal reports whether fd refers to a terminal. It is always false
+// on this platform, so callers use line-based input.
+func IsTerminal(fd int) bool {
+	return false
+}
+
+// MakeRaw is not supported on this platform.
+func MakeRaw(fd int) (*State, error) {
+	return nil, ErrUnsupported
+}
+
+// Restore is a no-op on this platform.
+func Restore(fd int, state *State) error {
+	return nil
+}
+
+func size(fd int) (int, int) {
+	return 0, 0
+}
@@ -0,0 +1,30 @@
+package term
+
+import (
+	"os"
+	"testing"
+)
+
+func TestWidth_Fallback(t *testing.T) {
+	f, err := os.CreateTemp(t.TempDir(), "not-a-tty")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	fd := int(f.Fd())
+
+	if IsTerminal(fd) {
+		t.Fatal("expected a regular file not to be a terminal")
+	}
+	t.Setenv("COLUMNS", "132")
+	if w := Width(fd); w != 132 {
+		t.Errorf("expected $COLUMNS to be used, got %d", w)
+	}
+	t.Setenv("COLUMNS", "")
+	if w := Width(fd); w != DefaultWidth {
+		t.Errorf("expected default width, got %d", w)
+	}
+	if _, err := MakeRaw(fd); err == nil {
+		t.Error("expected MakeRaw to fail on a regular file")
+	}
+}
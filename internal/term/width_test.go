@@ -0,0 +1,61 @@
+package term
+
+import "testing"
+
+func TestStringWidth(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"abc", 3},
+		{"añb", 3},
+		{"é", 1},
+		{"日本語", 6},
+		{"한국어 ok", 9},
+		{"ｆｕｌｌ", 8},
+		{"🚀 launch", 9},
+		{"👩‍💻", 4},
+		{"\x1b[1mbold\x1b[0m", 4},
+		{"\x1b[1m日本\x1b[0m", 4},
+		{"tab\there", 7},
+	}
+	for _, tt := range tests {
+		if got := StringWidth(tt.in); got != tt.want {
+			t.Errorf("StringWidth(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestStripANSI(t *testing.T) {
+	if got := StripANSI("\x1b[1;4;35mTitle\x1b[0m and \x1b[36mcode\x1b[0m"); got != "Title and code" {
+		t.Errorf("StripANSI = %q", got)
+	}
+}
+
+func TestFit(t *testing.T) {
+	tests := []struct {
+		in    string
+		width int
+		want  string
+	}{
+		{"abc", 5, "abc  "},
+		{"abcdef", 4, "abc…"},
+		{"\x1b[1mbold\x1b[0m", 6, "\x1b[1mbold\x1b[0m  "},
+		{"\x1b[1mbolder\x1b[0m", 4, "\x1b[1mbol…\x1b[0m"},
+		{"añb", 3, "añb"},
+		{"x", 0, ""},
+		{"日本語", 6, "日本語"},
+		{"日本語", 5, "日本…"},
+		{"日本語", 4, "日… "},
+		{"🚀🚀🚀", 4, "🚀… "},
+	}
+	for _, tt := range tests {
+		got := Fit(tt.in, tt.width)
+		if got != tt.want {
+			t.Errorf("Fit(%q, %d) = %q, want %q", tt.in, tt.width, got, tt.want)
+		}
+		if w := StringWidth(got); w != tt.width {
+			t.Errorf("Fit(%q, %d) is %d columns wide", tt.in, tt.width, w)
+		}
+	}
+}
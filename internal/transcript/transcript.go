@@ -0,0 +1,432 @@
+// Package transcript parses AI conversation exports and renders them as
+// Markdown.
+package transcript
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Supported export formats.
+const (
+	FormatAuto    = "auto"
+	FormatChatGPT = "chatgpt"
+	FormatClaude  = "claude"
+	FormatJSONL   = "jsonl"
+)
+
+// Sources recorded on imported memories.
+const (
+	SourceChatGPT    = "chatgpt"
+	SourceClaude     = "claude"
+	SourceClaudeCode = "claude-code"
+	SourceChat       = "chat-log"
+)
+
+// maxTitleLen bounds titles derived from the first message.
+const maxTitleLen = 80
+
+// Turn is one message in a conversation.
+type Turn struct {
+	Role    string
+	Content string
+}
+
+// Conversation is a single chat with its turns in order.
+type Conversation struct {
+	Title     string
+	Source    string
+	CreatedAt time.Time
+	Turns     []Turn
+}
+
+// Parse detects the format of data (unless format is given) and returns the
+// conversations it contains.
+func Parse(data []byte, format string) ([]Conversation, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, fmt.Errorf("transcript is empty")
+	}
+	if format == "" || format == FormatAuto {
+		format = detect(data)
+	}
+
+	var convs []Conversation
+	var err error
+	switch format {
+	case FormatChatGPT:
+		convs, err = parseChatGPT(data)
+	case FormatClaude:
+		convs, err = parseClaude(data)
+	case FormatJSONL:
+		convs, err = parseJSONL(data)
+	default:
+		return nil, fmt.Errorf("unknown transcript format %q (want chatgpt, claude or jsonl)", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	out := convs[:0]
+	for _, c := range convs {
+		if len(c.Turns) > 0 {
+			out = append(out, c)
+		}
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no messages found in %s transcript", format)
+	}
+	return out, nil
+}
+
+// detect guesses the export format from the first object in data.
+func detect(data []byte) string {
+	var probe interface{}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return FormatJSONL
+	}
+	obj, ok := probe.(map[string]interface{})
+	if list, isList := probe.([]interface{}); isList && len(list) > 0 {
+		obj, ok = list[0].(map[string]interface{})
+	}
+	if !ok {
+		return FormatJSONL
+	}
+	switch {
+	case obj["mapping"] != nil:
+		return FormatChatGPT
+	case obj["chat_messages"] != nil:
+		return FormatClaude
+	}
+	return FormatJSONL
+}
+
+// decodeList accepts either a JSON array of objects or a single object.
+func decodeList(data []byte, v interface{}) error {
+	if data[0] == '{' {
+		data = append(append([]byte{'['}, data...), ']')
+	}
+	return json.Unmarshal(data, v)
+}
+
+// ChatGPT conversations.json: each conversation is a tree of nodes keyed by
+// ID; the visible thread runs from current_node up through its parents.
+type chatGPTConversation struct {
+	Title       string                 `json:"title"`
+	CreateTime  float64                `json:"create_time"`
+	CurrentNode string                 `json:"current_node"`
+	Mapping     map[string]chatGPTNode `json:"mapping"`
+}
+
+type chatGPTNode struct {
+	Parent  string `json:"parent"`
+	Message *struct {
+		Author struct {
+			Role string `json:"role"`
+		} `json:"author"`
+		CreateTime float64 `json:"create_time"`
+		Content    struct {
+			ContentType string        `json:"content_type"`
+			Parts       []interface{} `json:"parts"`
+			Text        string        `json:"text"`
+		} `json:"content"`
+	} `json:"message"`
+}
+
+func parseChatGPT(data []byte) ([]Conversation, error) {
+	var raw []chatGPTConversation
+	if err := decodeList(data, &raw); err != nil {
+		return nil, fmt.Errorf("parsing ChatGPT export: %w", err)
+	}
+
+	convs := make([]Conversation, 0, len(raw))
+	for _, rc := range raw {
+		c := Conversation{Title: strings.TrimSpace(rc.Title), Source: SourceChatGPT, CreatedAt: unixTime(rc.CreateTime)}
+		for _, id := range chatGPTThread(rc) {
+			node := rc.Mapping[id]
+			if node.Message == nil {
+				continue
+			}
+			var parts []string
+			for _, p := range node.Message.Content.Parts {
+				if s, ok := p.(string); ok && strings.TrimSpace(s) != "" {
+					parts = append(parts, s)
+				}
+			}
+			if node.Message.Content.Text != "" {
+				parts = append(parts, node.Message.Content.Text)
+			}
+			c.addTurn(node.Message.Author.Role, strings.Join(parts, "\n\n"))
+		}
+		convs = append(convs, c)
+	}
+	return convs, nil
+}
+
+// chatGPTThread returns node IDs from the root to the current node. Without
+// a current node, every node is ordered by creation time.
+func chatGPTThread(rc chatGPTConversation) []string {
+	if _, ok := rc.Mapping[rc.CurrentNode]; ok {
+		var ids []string
+		seen := map[string]bool{}
+		for id := rc.CurrentNode; id != "" && !seen[id]; id = rc.Mapping[id].Parent {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+		for i, j := 0, len(ids)-1; i < j; i, j = i+1, j-1 {
+			ids[i], ids[j] = ids[j], ids[i]
+		}
+		return ids
+	}
+
+	ids := make([]string, 0, len(rc.Mapping))
+	for id := range rc.Mapping {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		ti, tj := messageTime(rc.Mapping[ids[i]]), messageTime(rc.Mapping[ids[j]])
+		if ti != tj {
+			return ti < tj
+		}
+		return ids[i] < ids[j]
+	})
+	return ids
+}
+
+func messageTime(n chatGPTNode) float64 {
+	if n.Message == nil {
+		return 0
+	}
+	return n.Message.CreateTime
+}
+
+// Claude export conversations.json: chat_messages in order, with the text
+// either in "text" or in typed content blocks.
+type claudeConversation struct {
+	Name         string `json:"name"`
+	CreatedAt    string `json:"created_at"`
+	ChatMessages []struct {
+		Sender  string         `json:"sender"`
+		Text    string         `json:"text"`
+		Content []contentBlock `json:"content"`
+	} `json:"chat_messages"`
+}
+
+type contentBlock struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+func parseClaude(data []byte) ([]Conversation, error) {
+	var raw []claudeConversation
+	if err := decodeList(data, &raw); err != nil {
+		return nil, fmt.Errorf("parsing Claude export: %w", err)
+	}
+
+	convs := make([]Conversation, 0, len(raw))
+	for _, rc := range raw {
+		c := Conversation{Title: strings.TrimSpace(rc.Name), Source: SourceClaude}
+		c.CreatedAt, _ = time.Parse(time.RFC3339, rc.CreatedAt)
+		for _, m := range rc.ChatMessages {
+			text := m.Text
+			if text == "" {
+				text = blocksText(m.Content)
+			}
+			c.addTurn(m.Sender, text)
+		}
+		convs = append(convs, c)
+	}
+	return convs, nil
+}
+
+// JSONL chat logs: one turn per line, as {"role","content"} or wrapped in
+// {"message": {...}} as Claude Code session logs do. A JSON array of turns
+// is accepted too. Lines are grouped into conversations by session ID.
+type jsonlLine struct {
+	Role           string          `json:"role"`
+	Content        json.RawMessage `json:"content"`
+	SessionID      string          `json:"sessionId"`
+	ConversationID string          `json:"conversation_id"`
+	Timestamp      string          `json:"timestamp"`
+	Message        *struct {
+		Role    string          `json:"role"`
+		Content json.RawMessage `json:"content"`
+	} `json:"message"`
+}
+
+func parseJSONL(data []byte) ([]Conversation, error) {
+	var lines []jsonlLine
+	if data[0] == '[' {
+		if err := json.Unmarshal(data, &lines); err != nil {
+			return nil, fmt.Errorf("parsing chat log: %w", err)
+		}
+	} else {
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		scanner.Buffer(make([]byte, 1024*1024), 64*1024*1024)
+		for n := 1; scanner.Scan(); n++ {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			var l jsonlLine
+			if err := json.Unmarshal(line, &l); err != nil {
+				return nil, fmt.Errorf("parsing chat log line %d: %w", n, err)
+			}
+			lines = append(lines, l)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("reading chat log: %w", err)
+		}
+	}
+
+	var convs []Conversation
+	index := map[string]int{}
+	for _, l := range lines {
+		role, content := l.Role, l.Content
+		if l.Message != nil {
+			role, content = l.Message.Role, l.Message.Content
+		}
+		if role == "" {
+			continue
+		}
+
+		key := l.SessionID
+		if key == "" {
+			key = l.ConversationID
+		}
+		i, ok := index[key]
+		if !ok {
+			source := SourceChat
+			if l.SessionID != "" {
+				source = SourceClaudeCode
+			}
+			i = len(convs)
+			index[key] = i
+			convs = append(convs, Conversation{Source: source})
+			convs[i].CreatedAt, _ = time.Parse(time.RFC3339, l.Timestamp)
+		}
+		convs[i].addTurn(role, rawText(content))
+	}
+	return convs, nil
+}
+
+// rawText returns the text of a content field that is either a string or a
+// list of typed blocks. Non-text blocks such as tool calls are skipped.
+func rawText(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var blocks []contentBlock
+	if json.Unmarshal(raw, &blocks) == nil {
+		return blocksText(blocks)
+	}
+	return ""
+}
+
+func blocksText(blocks []contentBlock) string {
+	var parts []string
+	for _, b := range blocks {
+		if (b.Type == "" || b.Type == "text") && strings.TrimSpace(b.Text) != "" {
+			parts = append(parts, b.Text)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+func (c *Conversation) addTurn(role, content string) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return
+	}
+	c.Turns = append(c.Turns, Turn{Role: normalizeRole(role), Content: content})
+}
+
+func normalizeRole(role string) string {
+	switch strings.ToLower(role) {
+	case "user", "human":
+		return "user"
+	case "assistant", "ai", "model", "bot":
+		return "assistant"
+	}
+	return strings.ToLower(role)
+}
+
+func unixTime(sec float64) time.Time {
+	if sec <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(int64(sec), 0).UTC()
+}
+
+// speaker is the heading used for a role.
+func speaker(role string) string {
+	switch role {
+	case "user":
+		return "User"
+	case "assistant":
+		return "Assistant"
+	case "":
+		return "Unknown"
+	}
+	return strings.ToUpper(role[:1]) + role[1:]
+}
+
+// Markdown renders the turns with one heading per speaker. level sets the
+// heading depth so several conversations can be nested under their own
+// headings.
+func (c Conversation) Markdown(level int) string {
+	hashes := strings.Repeat("#", level)
+	var sb strings.Builder
+	for i, t := range c.Turns {
+		if i > 0 {
+			sb.WriteString("\n\n")
+		}
+		fmt.Fprintf(&sb, "%s %s\n\n%s", hashes, speaker(t.Role), t.Content)
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// DeriveTitle returns the export's title or, failing that, the first line
+// of the first user message, shortened at a word boundary.
+func (c Conversation) DeriveTitle() string {
+	if c.Title != "" {
+		return c.Title
+	}
+	for _, t := range c.Turns {
+		if t.Role != "user" {
+			continue
+		}
+		line := strings.TrimSpace(strings.SplitN(t.Content, "\n", 2)[0])
+		line = strings.TrimLeft(line, "#> ")
+		if line == "" {
+			continue
+		}
+		return shorten(line, maxTitleLen)
+	}
+	if !c.CreatedAt.IsZero() {
+		return "Conversation on " + c.CreatedAt.Format("2006-01-02")
+	}
+	return "Imported conversation"
+}
+
+func shorten(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	cut := string(runes[:max])
+	if i := strings.LastIndex(cut, " "); i > max/2 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " ,.;:") + "…"
+}
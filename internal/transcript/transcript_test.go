@@ -0,0 +1,139 @@
+package transcript
+
+import (
+	"strings"
+	"testing"
+)
+
+const chatGPTExport = `[{
+  "title": "Go generics",
+  "create_time": 1760000000,
+  "current_node": "c",
+  "mapping": {
+    "root": {"parent": "", "message": null},
+    "s": {"parent": "root", "message": {"author": {"role": "system"}, "content": {"content_type": "text", "parts": [""]}}},
+    "a": {"parent": "s", "message": {"author": {"role": "user"}, "content": {"content_type": "text", "parts": ["How do generics work?"]}}},
+    "x": {"parent": "a", "message": {"author": {"role": "assistant"}, "content": {"content_type": "text", "parts": ["An abandoned branch"]}}},
+    "c": {"parent": "a", "message": {"author": {"role": "assistant"}, "content": {"content_type": "text", "parts": ["With type parameters."]}}}
+  }
+}, {
+  "title": "",
+  "mapping": {
+    "m1": {"parent": "", "message": {"author": {"role": "user"}, "create_time": 2, "content": {"parts": ["Second?"]}}},
+    "m0": {"parent": "", "message": {"author": {"role": "user"}, "create_time": 1, "content": {"parts": ["First"]}}}
+  }
+}]`
+
+const claudeExport = `[{
+  "name": "Rails caching",
+  "created_at": "2026-03-01T10:00:00Z",
+  "chat_messages": [
+    {"sender": "human", "text": "How should I cache?"},
+    {"sender": "assistant", "text": "", "content": [{"type": "text", "text": "Use Solid Cache."}, {"type": "tool_use"}]}
+  ]
+}]`
+
+const jsonlLog = `{"role": "user", "content": "Fix the flaky test"}
+{"role": "assistant", "content": [{"type": "text", "text": "Done."}]}
+`
+
+const claudeCodeLog = `{"type": "user", "sessionId": "s1", "message": {"role": "user", "content": "Refactor config"}}
+{"type": "assistant", "sessionId": "s1", "message": {"role": "assistant", "content": [{"type": "text", "text": "Refactored."}, {"type": "tool_use", "name": "Edit"}]}}
+{"type": "user", "sessionId": "s2", "message": {"role": "user", "content": "Another session"}}
+{"type": "summary", "summary": "ignored"}
+`
+
+func TestParse_ChatGPT(t *testing.T) {
+	convs, err := Parse([]byte(chatGPTExport), FormatAuto)
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	if len(convs) != 2 {
+		t.Fatalf("expected 2 conversations, got %d", len(convs))
+	}
+	c := convs[0]
+	if c.Source != SourceChatGPT || c.Title != "Go generics" {
+		t.Errorf("unexpected conversation: %+v", c)
+	}
+	if len(c.Turns) != 2 || c.Turns[1].Content != "With type parameters." {
+		t.Errorf("expected thread to follow current_node, got %+v", c.Turns)
+	}
+	if got := convs[1].Turns[0].Content; got != "First" {
+		t.Errorf("expected turns ordered by time without current_node, got %q", got)
+	}
+	if got := convs[1].DeriveTitle(); got != "First" {
+		t.Errorf("unexpected derived title %q", got)
+	}
+}
+
+func TestParse_Claude(t *testing.T) {
+	convs, err := Parse([]byte(claudeExport), FormatAuto)
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	c := convs[0]
+	if c.Source != SourceClaude || c.Title != "Rails caching" {
+		t.Errorf("unexpected conversation: %+v", c)
+	}
+	want := "## User\n\nHow should I cache?\n\n## Assistant\n\nUse Solid Cache.\n"
+	if got := c.Markdown(2); got != want {
+		t.Errorf("unexpected markdown:\n%s", got)
+	}
+}
+
+func TestParse_JSONL(t *testing.T) {
+	convs, err := Parse([]byte(jsonlLog), FormatAuto)
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	if len(convs) != 1 || convs[0].Source != SourceChat || len(convs[0].Turns) != 2 {
+		t.Fatalf("unexpected conversations: %+v", convs)
+	}
+	if got := convs[0].DeriveTitle(); got != "Fix the flaky test" {
+		t.Errorf("unexpected title %q", got)
+	}
+
+	convs, err = Parse([]byte(`[{"role":"user","content":"Array form"}]`), FormatAuto)
+	if err != nil || len(convs) != 1 {
+		t.Fatalf("expected array of turns to parse, got %v, %v", convs, err)
+	}
+}
+
+func TestParse_ClaudeCodeSessions(t *testing.T) {
+	convs, err := Parse([]byte(claudeCodeLog), FormatJSONL)
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	if len(convs) != 2 {
+		t.Fatalf("expected 2 sessions, got %d", len(convs))
+	}
+	if convs[0].Source != SourceClaudeCode || convs[0].Turns[1].Content != "Refactored." {
+		t.Errorf("unexpected session: %+v", convs[0])
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for name, input := range map[string]string{
+		"empty":       "",
+		"bad line":    "{\"role\":\"user\",\"content\":\"hi\"}\nnot json",
+		"no messages": `[{"chat_messages": []}]`,
+	} {
+		if _, err := Parse([]byte(input), FormatAuto); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+	if _, err := Parse([]byte(jsonlLog), "slack"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestDeriveTitle_Shortens(t *testing.T) {
+	c := Conversation{Turns: []Turn{{Role: "user", Content: "# " + strings.Repeat("word ", 30) + "\nsecond line"}}}
+	got := c.DeriveTitle()
+	if len([]rune(got)) > maxTitleLen+1 || !strings.HasSuffix(got, "…") || strings.HasPrefix(got, "#") {
+		t.Errorf("unexpected title %q", got)
+	}
+	if got := (Conversation{}).DeriveTitle(); got != "Imported conversation" {
+		t.Errorf("unexpected fallback %q", got)
+	}
+}
@@ -0,0 +1,128 @@
+// Package trash keeps local snapshots of deleted memories so they can be
+// recreated later.
+package trash
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const appDir = "recuerd0"
+
+// trashDir can be overridden for testing.
+var trashDir string
+
+// SetDir overrides the trash directory. An empty string restores the
+// default.
+func SetDir(dir string) {
+	trashDir = dir
+}
+
+// Dir returns the directory snapshots are stored in. Unlike the cache it
+// lives under the data directory, since snapshots may be the only copy.
+func Dir() string {
+	if trashDir != "" {
+		return trashDir
+	}
+	if xdg := os.Getenv("XDG_DATA_HOME"); xdg != "" {
+		return filepath.Join(xdg, appDir, "trash")
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, ".local", "share", appDir, "trash")
+	}
+	return filepath.Join(os.TempDir(), appDir, "trash")
+}
+
+// Entry is the snapshot of one deleted memory as the API returned it.
+type Entry struct {
+	ID          string                 `json:"id"`
+	MemoryID    string                 `json:"memory_id"`
+	WorkspaceID string                 `json:"workspace_id"`
+	APIURL      string                 `json:"api_url"`
+	DeletedAt   time.Time              `json:"deleted_at"`
+	Memory      map[string]interface{} `json:"memory"`
+}
+
+// Path returns the file the entry with the given ID is stored in.
+func Path(id string) string {
+	return filepath.Join(Dir(), id+".json")
+}
+
+// NewID returns the ID Put assigns to the entry of a memory deleted at t.
+func NewID(workspaceID, memoryID string, t time.Time) string {
+	return fmt.Sprintf("%s-%s-%d", workspaceID, memoryID, t.Unix())
+}
+
+// Put stores e, assigning its ID and deletion time if they are unset.
+func Put(e *Entry) error {
+	if e.DeletedAt.IsZero() {
+		e.DeletedAt = time.Now().UTC()
+	}
+	if e.ID == "" {
+		e.ID = NewID(e.WorkspaceID, e.MemoryID, e.DeletedAt)
+	}
+	data, err := json.MarshalIndent(e, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling trash entry: %w", err)
+	}
+	if err := os.MkdirAll(Dir(), 0700); err != nil {
+		return fmt.Errorf("creating trash directory: %w", err)
+	}
+	return os.WriteFile(Path(e.ID), data, 0600)
+}
+
+// List returns every stored entry, most recently deleted first. Unreadable
+// files are skipped.
+func List() ([]*Entry, error) {
+	files, err := os.ReadDir(Dir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []*Entry{}, nil
+		}
+		return nil, fmt.Errorf("reading trash: %w", err)
+	}
+	entries := []*Entry{}
+	for _, f := range files {
+		id, ok := strings.CutSuffix(f.Name(), ".json")
+		if !ok || f.IsDir() {
+			continue
+		}
+		e, err := Get(id)
+		if err != nil {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].DeletedAt.After(entries[j].DeletedAt) })
+	return entries, nil
+}
+
+// Get loads the entry with the given ID.
+func Get(id string) (*Entry, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) {
+		return nil, fmt.Errorf("invalid trash id %q", id)
+	}
+	data, err := os.ReadFile(Path(id))
+	if err != nil {
+		return nil, err
+	}
+	var e Entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, fmt.Errorf("parsing trash entry %s: %w", id, err)
+	}
+	e.ID = id
+	return &e, nil
+}
+
+// Remove deletes the entry with the given ID.
+func Remove(id string) error {
+	if err := os.Remove(Path(id)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("removing trash entry: %w", err)
+	}
+	return nil
+}
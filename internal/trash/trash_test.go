@@ -0,0 +1,81 @@
+package trash
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func setupTestDir(t *testing.T) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "trash")
+	SetDir(dir)
+	t.Cleanup(func() { SetDir("") })
+	return dir
+}
+
+func TestPutGetRemove(t *testing.T) {
+	dir := setupTestDir(t)
+
+	e := &Entry{MemoryID: "7", WorkspaceID: "5", APIURL: "https://api.example.com", Memory: map[string]interface{}{"title": "Notes"}}
+	if err := Put(e); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if e.ID == "" || e.DeletedAt.IsZero() {
+		t.Fatalf("expected ID and time to be assigned: %+v", e)
+	}
+	info, err := os.Stat(filepath.Join(dir, e.ID+".json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	got, err := Get(e.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got.MemoryID != "7" || got.Memory["title"] != "Notes" {
+		t.Errorf("unexpected entry: %+v", got)
+	}
+
+	if err := Remove(e.ID); err != nil {
+		t.Fatalf("Remove: %v", err)
+	}
+	if _, err := Get(e.ID); err == nil {
+		t.Error("expected entry to be gone")
+	}
+	if err := Remove(e.ID); err != nil {
+		t.Errorf("removing a missing entry should not fail: %v", err)
+	}
+}
+
+func TestList_NewestFirst(t *testing.T) {
+	dir := setupTestDir(t)
+
+	if entries, err := List(); err != nil || len(entries) != 0 {
+		t.Fatalf("List on empty trash = %v, %v", entries, err)
+	}
+
+	now := time.Now().UTC()
+	_ = Put(&Entry{MemoryID: "1", WorkspaceID: "5", DeletedAt: now.Add(-time.Hour)})
+	_ = Put(&Entry{MemoryID: "2", WorkspaceID: "5", DeletedAt: now})
+	_ = os.WriteFile(filepath.Join(dir, "broken.json"), []byte("{"), 0600)
+
+	entries, err := List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(entries) != 2 || entries[0].MemoryID != "2" || entries[1].MemoryID != "1" {
+		t.Errorf("unexpected order: %+v", entries)
+	}
+}
+
+func TestGet_RejectsPaths(t *testing.T) {
+	setupTestDir(t)
+	if _, err := Get("../config"); err == nil {
+		t.Error("expected error for an ID containing a path separator")
+	}
+}
@@ -0,0 +1,178 @@
+// Package tui provides the pieces of a full-screen terminal interface:
+// decoding key presses from raw input and drawing whole frames with ANSI
+// escape codes. Fitting styled text into columns is term.Fit.
+package tui
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// KeyCode identifies a key that is not plain text.
+type KeyCode int
+
+const (
+	KeyRune KeyCode = iota
+	KeyEnter
+	KeyTab
+	KeyBackTab
+	KeyBackspace
+	KeyDelete
+	KeyEscape
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyHome
+	KeyEnd
+	KeyPageUp
+	KeyPageDown
+	KeyCtrl
+)
+
+// Key is one key press. For KeyRune, Rune is the character typed; for
+// KeyCtrl, Rune is the lower-case letter held with Ctrl.
+type Key struct {
+	Code KeyCode
+	Rune rune
+}
+
+// Rune returns the key for a typed character.
+func Rune(r rune) Key {
+	return Key{Code: KeyRune, Rune: r}
+}
+
+// Ctrl returns the key for Ctrl and a letter.
+func Ctrl(r rune) Key {
+	return Key{Code: KeyCtrl, Rune: r}
+}
+
+// ReadKey reads one key press from raw terminal input. An escape byte not
+// followed at once by more input is the Escape key; terminals send escape
+// sequences in a single write.
+func ReadKey(r *bufio.Reader) (Key, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return Key{}, err
+	}
+	switch b {
+	case '\r', '\n':
+		return Key{Code: KeyEnter}, nil
+	case '\t':
+		return Key{Code: KeyTab}, nil
+	case 8, 127:
+		return Key{Code: KeyBackspace}, nil
+	case 27:
+		if r.Buffered() == 0 {
+			return Key{Code: KeyEscape}, nil
+		}
+		return readEscape(r)
+	}
+	if b < 32 {
+		return Ctrl(rune('a' + b - 1)), nil
+	}
+	if b < utf8.RuneSelf {
+		return Rune(rune(b)), nil
+	}
+	seq := []byte{b}
+	for !utf8.FullRune(seq) && len(seq) < utf8.UTFMax {
+		next, err := r.ReadByte()
+		if err != nil {
+			return Key{}, err
+		}
+		seq = append(seq, next)
+	}
+	c, _ := utf8.DecodeRune(seq)
+	return Rune(c), nil
+}
+
+func readEscape(r *bufio.Reader) (Key, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return Key{Code: KeyEscape}, nil
+	}
+	if b != '[' && b != 'O' {
+		// Alt and a key; treat it as the key alone.
+		_ = r.UnreadByte()
+		return Key{Code: KeyEscape}, nil
+	}
+	var params []byte
+	for {
+		c, err := r.ReadByte()
+		if err != nil {
+			return Key{Code: KeyEscape}, nil
+		}
+		if c >= 0x40 && c <= 0x7e {
+			return escapeKey(string(params), c), nil
+		}
+		params = append(params, c)
+	}
+}
+
+func escapeKey(params string, final byte) Key {
+	switch final {
+	case 'A':
+		return Key{Code: KeyUp}
+	case 'B':
+		return Key{Code: KeyDown}
+	case 'C':
+		return Key{Code: KeyRight}
+	case 'D':
+		return Key{Code: KeyLeft}
+	case 'H':
+		return Key{Code: KeyHome}
+	case 'F':
+		return Key{Code: KeyEnd}
+	case 'Z':
+		return Key{Code: KeyBackTab}
+	case '~':
+		switch strings.SplitN(params, ";", 2)[0] {
+		case "1", "7":
+			return Key{Code: KeyHome}
+		case "4", "8":
+			return Key{Code: KeyEnd}
+		case "3":
+			return Key{Code: KeyDelete}
+		case "5":
+			return Key{Code: KeyPageUp}
+		case "6":
+			return Key{Code: KeyPageDown}
+		}
+	}
+	return Key{Code: KeyEscape}
+}
+
+// Screen draws frames on the terminal's alternate screen.
+type Screen struct {
+	out io.Writer
+}
+
+// NewScreen switches out to the alternate screen and hides the cursor.
+// Call Close to switch back.
+func NewScreen(out io.Writer) *Screen {
+	_, _ = io.WriteString(out, "\x1b[?1049h\x1b[?25l")
+	return &Screen{out: out}
+}
+
+// Draw replaces the screen with lines, one per row. Lines must already fit
+// the width.
+func (s *Screen) Draw(lines []string) {
+	var b strings.Builder
+	b.WriteString("\x1b[H")
+	for i, line := range lines {
+		if i > 0 {
+			b.WriteString("\r\n")
+		}
+		b.WriteString(line)
+		b.WriteString("\x1b[0m\x1b[K")
+	}
+	b.WriteString("\x1b[J")
+	_, _ = io.WriteString(s.out, b.String())
+}
+
+// Close shows the cursor and returns to the normal screen.
+func (s *Screen) Close() {
+	_, _ = io.WriteString(s.out, "\x1b[0m\x1b[?25h\x1b[?1049l")
+}
@@ -0,0 +1,45 @@
+package tui
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestReadKey(t *testing.T) {
+	input := "a\r\x1b[A\x1b[B\x1b[5~\x1b[6~\x1b[3~\x1b[Z\x1bOH\t\x7f\x03é"
+	want := []Key{
+		Rune('a'), {Code: KeyEnter}, {Code: KeyUp}, {Code: KeyDown}, {Code: KeyPageUp}, {Code: KeyPageDown},
+		{Code: KeyDelete}, {Code: KeyBackTab}, {Code: KeyHome}, {Code: KeyTab}, {Code: KeyBackspace}, Ctrl('c'), Rune('é'),
+	}
+	r := bufio.NewReader(strings.NewReader(input))
+	for i, w := range want {
+		got, err := ReadKey(r)
+		if err != nil || got != w {
+			t.Fatalf("key %d: got %+v (%v), want %+v", i, got, err, w)
+		}
+	}
+	if _, err := ReadKey(r); err != io.EOF {
+		t.Errorf("expected EOF, got %v", err)
+	}
+}
+
+func TestReadKey_LoneEscape(t *testing.T) {
+	r := bufio.NewReader(strings.NewReader("\x1b"))
+	if k, _ := ReadKey(r); k.Code != KeyEscape {
+		t.Errorf("expected Escape, got %+v", k)
+	}
+}
+
+func TestScreen(t *testing.T) {
+	var out bytes.Buffer
+	s := NewScreen(&out)
+	s.Draw([]string{"one", "two"})
+	s.Close()
+	want := "\x1b[?1049h\x1b[?25l\x1b[Hone\x1b[0m\x1b[K\r\ntwo\x1b[0m\x1b[K\x1b[J\x1b[0m\x1b[?25h\x1b[?1049l"
+	if out.String() != want {
+		t.Errorf("got %q", out.String())
+	}
+}